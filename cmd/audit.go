@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/audit"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// auditFormat, auditOutput, auditFailOn and auditSkipVuln are set by `gonext audit --format/--output/--fail-on/--skip-vuln`
+var auditFormat string
+var auditOutput string
+var auditFailOn string
+var auditSkipVuln bool
+
+// auditFiles returns the files the secret and CORS checks read: those tracked by
+// git in a repository, so local .env files are left alone, or every file otherwise
+func auditFiles() ([]string, error) {
+	if out, err := exec.Command("git", "ls-files", "--cached", "--others", "--exclude-standard").Output(); err == nil {
+		var files []string
+		for _, f := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			if f != "" {
+				files = append(files, f)
+			}
+		}
+		return files, nil
+	}
+	var files []string
+	err := filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != "." && (strings.HasPrefix(d.Name(), ".") || d.Name() == "vendor" || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != ".env" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// vulnFindings runs govulncheck on the project and converts its report
+func vulnFindings() ([]audit.Finding, error) {
+	if _, err := exec.LookPath("govulncheck"); err != nil {
+		return nil, fmt.Errorf("'govulncheck' is not installed: go install golang.org/x/vuln/cmd/govulncheck@latest (or pass --skip-vuln)")
+	}
+	var stdout, stderr bytes.Buffer
+	c := exec.Command("govulncheck", "-format", "json", "./...")
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	// With -format json, govulncheck exits 0 whether or not it finds vulnerabilities
+	if err := c.Run(); err != nil {
+		return nil, fmt.Errorf("govulncheck: %v\n%s", err, stderr.String())
+	}
+	return audit.Vulnerabilities(&stdout)
+}
+
+// writeAuditText prints the findings for people
+func writeAuditText(w io.Writer, findings []audit.Finding) {
+	counts := map[string]int{}
+	for _, f := range findings {
+		counts[f.Level]++
+		location := f.File
+		if f.Line > 0 {
+			location = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		fmt.Fprintf(w, "  %-8s %-18s %s\n", f.Level, f.RuleID, f.Message)
+		if location != "" {
+			fmt.Fprintf(w, "  %-8s %-18s %s\n", "", "", location)
+		}
+	}
+	fmt.Fprintf(w, "%d error(s), %d warning(s), %d note(s)\n", counts[audit.Error], counts[audit.Warning], counts[audit.Note])
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Scan the project for vulnerable dependencies, hardcoded secrets, unauthenticated routes and permissive CORS",
+	Long: `Runs the security checks in one pass, for local use or CI:
+
+  govulncheck        known vulnerabilities in the code the project calls
+  hardcoded-secret   passwords, tokens and keys written in Go sources, YAML, JSON,
+                     .env and Terraform files tracked by git
+  missing-auth       POST, PUT, PATCH and DELETE routes without authentication
+                     middleware on the route, its group or the global chain.
+                     Mark intentionally public routes with a // audit:public comment.
+  permissive-cors    cors.New configs that allow every origin, an error when
+                     credentials are allowed too
+
+The report is text, JSON or SARIF (for GitHub code scanning). The command exits
+non-zero when a finding reaches --fail-on.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if auditFormat != "text" && auditFormat != "json" && auditFormat != "sarif" {
+			fmt.Printf("Unknown format '%s' (expected text, json or sarif)\n", auditFormat)
+			return
+		}
+		if auditFailOn != "none" && auditFailOn != audit.Error && auditFailOn != audit.Warning && auditFailOn != audit.Note {
+			fmt.Printf("Unknown level '%s' (expected error, warning, note or none)\n", auditFailOn)
+			return
+		}
+		files, err := auditFiles()
+		if err != nil {
+			fmt.Printf("Error listing files: %v\n", err)
+			return
+		}
+		routes, err := workspace.Routes()
+		if err != nil {
+			fmt.Printf("Error scanning routes: %v\n", err)
+			return
+		}
+		var findings []audit.Finding
+		if !auditSkipVuln {
+			vulns, err := vulnFindings()
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			findings = append(findings, vulns...)
+		}
+		findings = append(findings, audit.HardcodedSecrets(files)...)
+		findings = append(findings, audit.MissingAuth(routes, middlewareChainFile)...)
+		findings = append(findings, audit.PermissiveCORS(files)...)
+		audit.Sort(findings)
+
+		var out io.Writer = os.Stdout
+		if auditOutput != "" && auditOutput != "-" {
+			f, err := os.Create(auditOutput)
+			if err != nil {
+				fmt.Printf("Error creating %s: %v\n", auditOutput, err)
+				return
+			}
+			defer f.Close()
+			out = f
+		}
+		switch auditFormat {
+		case "json":
+			err = audit.WriteJSON(out, findings)
+		case "sarif":
+			err = audit.WriteSARIF(out, findings)
+		default:
+			writeAuditText(out, findings)
+		}
+		if err != nil {
+			fmt.Printf("Error writing the report: %v\n", err)
+			os.Exit(1)
+		}
+		if out != os.Stdout {
+			fmt.Printf("Audit report with %d finding(s) written to %s\n", len(findings), auditOutput)
+		}
+		if auditFailOn != "none" && audit.AtLeast(findings, auditFailOn) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	auditCmd.Flags().StringVar(&auditFormat, "format", "text", "Report format: text, json or sarif")
+	auditCmd.Flags().StringVarP(&auditOutput, "output", "o", "", "Report file (default stdout)")
+	auditCmd.Flags().StringVar(&auditFailOn, "fail-on", audit.Error, "Exit non-zero on findings at this level or above: error, warning, note or none")
+	auditCmd.Flags().BoolVar(&auditSkipVuln, "skip-vuln", false, "Skip the govulncheck scan")
+	rootCmd.AddCommand(auditCmd)
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+// buildDocker, buildKo, buildImage, buildPush and buildPlatform are set by `gonext build` flags
+var buildDocker bool
+var buildKo bool
+var buildImage string
+var buildPush bool
+var buildPlatform string
+
+// dockerfileTemplate builds a static binary and runs it on distroless as a non-root user
+const dockerfileTemplate = `# syntax=docker/dockerfile:1
+# Generated by 'gonext build --docker'. Edit freely: it is only created once.
+
+FROM golang:%s AS build
+WORKDIR /src
+COPY go.* ./
+RUN go mod download
+COPY . .
+RUN CGO_ENABLED=0 go build -trimpath -ldflags "-s -w" -o /out/app .
+
+FROM gcr.io/distroless/static-debian12:nonroot
+COPY --from=build /out/app /app
+EXPOSE 3000
+USER nonroot:nonroot
+ENTRYPOINT ["/app"]
+`
+
+// dockerignoreTemplate keeps secrets and local state out of the build context
+const dockerignoreTemplate = `.git
+.gonext
+.env
+.env.*
+bin/
+tmp/
+*.test
+`
+
+// goDirective reads the Go version from the go directive in go.mod
+var goDirective = regexp.MustCompile(`(?m)^go (\d+\.\d+)`)
+
+// dockerTagChars lists the characters not allowed in an image tag
+var dockerTagChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// buildVersion describes the working tree with git, falling back to "dev" outside a repository
+func buildVersion() (version, revision string) {
+	version, revision = "dev", "unknown"
+	if out, err := exec.Command("git", "describe", "--tags", "--always", "--dirty").Output(); err == nil {
+		version = strings.TrimSpace(string(out))
+	}
+	if out, err := exec.Command("git", "rev-parse", "HEAD").Output(); err == nil {
+		revision = strings.TrimSpace(string(out))
+	}
+	return version, revision
+}
+
+// imageTag turns a version into a valid image tag
+func imageTag(version string) string {
+	tag := dockerTagChars.ReplaceAllString(version, "-")
+	if len(tag) > 128 {
+		tag = tag[:128]
+	}
+	return tag
+}
+
+// defaultImage names the image after the last element of the module path
+func defaultImage() string {
+	return strings.ToLower(path.Base(getModuleName()))
+}
+
+// ociLabels returns the OCI annotations embedded in every image
+func ociLabels(version, revision string) []string {
+	return []string{
+		"org.opencontainers.image.title=" + defaultImage(),
+		"org.opencontainers.image.version=" + version,
+		"org.opencontainers.image.revision=" + revision,
+		"org.opencontainers.image.created=" + time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// dockerfileFiles returns the Dockerfile and .dockerignore if the project does not have them yet
+func dockerfileFiles() []codegen.File {
+	goVersion := "1.23"
+	if m, err := manifest.Load(); err == nil && m.Go != "" {
+		goVersion = m.Go
+	} else if data, err := os.ReadFile("go.mod"); err == nil {
+		if m := goDirective.FindSubmatch(data); m != nil {
+			goVersion = string(m[1])
+		}
+	}
+	files := missingFile(codegen.File{Path: "Dockerfile", Content: fmt.Sprintf(dockerfileTemplate, goVersion)})
+	return append(files, missingFile(codegen.File{Path: ".dockerignore", Content: dockerignoreTemplate})...)
+}
+
+// envAssignment matches KEY=value arguments, whose values may be secrets
+var envAssignment = regexp.MustCompile(`^([A-Z_][A-Z0-9_]*)=.+$`)
+
+// commandLine renders a command for display, hiding the values of KEY=value arguments
+func commandLine(name string, args ...string) string {
+	shown := make([]string, len(args))
+	for i, a := range args {
+		shown[i] = envAssignment.ReplaceAllString(a, "$1=***")
+	}
+	return "$ " + name + " " + strings.Join(shown, " ")
+}
+
+// runCommand runs an external tool with the terminal attached, echoing the command line first
+func runCommand(env []string, name string, args ...string) error {
+	fmt.Println(commandLine(name, args...))
+	c := exec.Command(name, args...)
+	c.Env = append(os.Environ(), env...)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Stdin = os.Stdin
+	return c.Run()
+}
+
+// dockerBuildArgs returns the `docker build` arguments for ref in the current directory
+func dockerBuildArgs(ref, platform string, labels []string) []string {
+	args := []string{"build", "-t", ref}
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
+	for _, l := range labels {
+		args = append(args, "--label", l)
+	}
+	return append(args, ".")
+}
+
+// buildDockerImage builds image:tag from the project Dockerfile, generating one if needed
+func buildDockerImage(ref string, labels []string) error {
+	if files := dockerfileFiles(); len(files) > 0 && !writeGenerated(files...) {
+		return fmt.Errorf("could not write the Dockerfile")
+	}
+	if _, err := exec.LookPath("docker"); err != nil {
+		return fmt.Errorf("'docker' is required for --docker but was not found in PATH")
+	}
+	if err := runCommand(nil, "docker", dockerBuildArgs(ref, buildPlatform, labels)...); err != nil {
+		return err
+	}
+	if buildPush {
+		return runCommand(nil, "docker", "push", ref)
+	}
+	return nil
+}
+
+// buildKoImage builds a static binary into a distroless image with ko, without a Dockerfile.
+// ko publishes unless it is told to load the image into the local daemon, where it
+// is named ko.local/<name>; ko prints the final reference either way.
+func buildKoImage(image, tag string, labels []string) error {
+	if _, err := exec.LookPath("ko"); err != nil {
+		return fmt.Errorf("'ko' is required for --ko. Install it with 'go install github.com/google/ko@latest'")
+	}
+	args := []string{"build", "--bare", "--tags", tag}
+	if !buildPush {
+		args = append(args, "--local")
+	}
+	if buildPlatform != "" {
+		args = append(args, "--platform", buildPlatform)
+	}
+	for _, l := range labels {
+		args = append(args, "--image-label", l)
+	}
+	return runCommand([]string{"KO_DOCKER_REPO=" + image, "CGO_ENABLED=0"}, "ko", append(args, ".")...)
+}
+
+var buildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build the project binary, or a container image with --docker or --ko",
+	Long: `Without flags, builds a static binary to bin/<app>.
+
+--docker builds an image from the project's Dockerfile (a multi-stage, distroless
+one is generated if missing). --ko builds the same kind of image with ko, without
+a Dockerfile. Images are tagged with 'git describe' and carry OCI labels for the
+version and commit. --push publishes the image to the registry in --image.
+
+--sbom writes a CycloneDX or SPDX SBOM of the modules compiled into the app, and
+--sign signs the build output with cosign along with a build provenance record.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if buildDocker && buildKo {
+			fmt.Println("Use either --docker or --ko, not both.")
+			return
+		}
+		if buildSBOM != "" && buildSBOM != "cyclonedx" && buildSBOM != "spdx" {
+			fmt.Printf("Unknown SBOM format '%s' (expected cyclonedx or spdx)\n", buildSBOM)
+			return
+		}
+		image := buildDocker || buildKo
+		if buildSign {
+			if image && !buildPush {
+				fmt.Println("--sign needs --push: image signatures are stored in the registry.")
+				return
+			}
+			if _, err := exec.LookPath("cosign"); err != nil {
+				fmt.Println("'cosign' is required for --sign. Install it with 'go install github.com/sigstore/cosign/v2/cmd/cosign@latest'.")
+				return
+			}
+		}
+		version, revision := buildVersion()
+		if !image {
+			out := path.Join("bin", defaultImage())
+			err := runCommand([]string{"CGO_ENABLED=0"}, "go", "build", "-trimpath", "-ldflags", "-s -w", "-o", out, ".")
+			if err != nil {
+				fmt.Printf("Error building %s: %v\n", out, err)
+				return
+			}
+			fmt.Printf("Built %s (%s)\n", out, version)
+			if err := supplyChain(out, false, version, revision); err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+			return
+		}
+
+		repo := buildImage
+		if repo == "" {
+			repo = defaultImage()
+		}
+		tag := imageTag(version)
+		ref := repo + ":" + tag
+		labels := ociLabels(version, revision)
+		var err error
+		if buildKo {
+			err = buildKoImage(repo, tag, labels)
+		} else {
+			err = buildDockerImage(ref, labels)
+		}
+		if err != nil {
+			fmt.Printf("Error building image: %v\n", err)
+			return
+		}
+		if !buildKo {
+			if buildPush {
+				ref = imageDigestRef(ref)
+				fmt.Printf("Pushed %s\n", ref)
+			} else {
+				fmt.Printf("Built %s\n", ref)
+			}
+		}
+		if err := supplyChain(ref, true, version, revision); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	},
+}
+
+// supplyChain writes the SBOM and, with --sign, signs the artifact with cosign.
+// Binaries get .sigstore.json bundles; images get a signature and SBOM and
+// provenance attestations in the registry.
+func supplyChain(artifact string, image bool, version, revision string) error {
+	sbom := ""
+	if buildSBOM != "" {
+		var err error
+		if sbom, err = writeSBOM(buildSBOM, version); err != nil {
+			return fmt.Errorf("writing SBOM: %v", err)
+		}
+		fmt.Printf("SBOM written to %s\n", sbom)
+	}
+	if !buildSign {
+		return nil
+	}
+	provenance, err := writeProvenance(version, revision)
+	if err != nil {
+		return fmt.Errorf("writing provenance: %v", err)
+	}
+	fmt.Printf("Provenance written to %s\n", provenance)
+	if image {
+		err = signImage(artifact, sbom, provenance)
+	} else {
+		files := []string{artifact, provenance}
+		if sbom != "" {
+			files = append(files, sbom)
+		}
+		err = signBlobs(files...)
+	}
+	if err != nil {
+		return fmt.Errorf("signing: %v", err)
+	}
+	fmt.Printf("Signed %s\n", artifact)
+	return nil
+}
+
+func init() {
+	buildCmd.Flags().BoolVar(&buildDocker, "docker", false, "Build a container image from the Dockerfile (generated if missing)")
+	buildCmd.Flags().BoolVar(&buildKo, "ko", false, "Build a container image with ko, without a Dockerfile")
+	buildCmd.Flags().StringVar(&buildImage, "image", "", "Image repository, e.g. ghcr.io/acme/api (defaults to the app name)")
+	buildCmd.Flags().BoolVar(&buildPush, "push", false, "Push the image to its registry after building")
+	buildCmd.Flags().StringVar(&buildPlatform, "platform", "", "Target platform(s), e.g. linux/amd64,linux/arm64")
+	buildCmd.Flags().StringVar(&buildSBOM, "sbom", "", "Write an SBOM to bin/: cyclonedx or spdx")
+	buildCmd.Flags().BoolVar(&buildSign, "sign", false, "Sign the artifacts with cosign and attach the SBOM and provenance to pushed images")
+	rootCmd.AddCommand(buildCmd)
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// buildSBOM and buildSign are set by `gonext build --sbom/--sign`
+var buildSBOM string
+var buildSign bool
+
+// goModule is a module compiled into the app
+type goModule struct {
+	Path    string
+	Version string
+}
+
+// purl returns the package URL of the module
+func (m goModule) purl() string {
+	if m.Version == "" {
+		return "pkg:golang/" + m.Path
+	}
+	return "pkg:golang/" + m.Path + "@" + m.Version
+}
+
+// appModules lists the main module followed by every module its packages are built from
+func appModules() ([]goModule, error) {
+	out, err := exec.Command("go", "list", "-deps", "-f", "{{with .Module}}{{.Path}} {{.Version}}{{end}}", ".").Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %v", err)
+	}
+	main := goModule{Path: getModuleName()}
+	modules := []goModule{main}
+	seen := map[string]bool{main.Path: true}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		path, version, _ := strings.Cut(line, " ")
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+		modules = append(modules, goModule{Path: path, Version: version})
+	}
+	return modules, nil
+}
+
+// newUUID returns a random (version 4) UUID for document identifiers
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+type cdxComponent struct {
+	Type    string `json:"type"`
+	BOMRef  string `json:"bom-ref"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl"`
+}
+
+// cycloneDX renders a CycloneDX 1.5 SBOM
+func cycloneDX(modules []goModule, version string) any {
+	app := modules[0]
+	appComponent := cdxComponent{Type: "application", BOMRef: app.purl(), Name: app.Path, Version: version, PURL: app.purl()}
+	components := []cdxComponent{}
+	dependsOn := []string{}
+	for _, m := range modules[1:] {
+		components = append(components, cdxComponent{Type: "library", BOMRef: m.purl(), Name: m.Path, Version: m.Version, PURL: m.purl()})
+		dependsOn = append(dependsOn, m.purl())
+	}
+	return map[string]any{
+		"bomFormat":    "CycloneDX",
+		"specVersion":  "1.5",
+		"serialNumber": "urn:uuid:" + newUUID(),
+		"version":      1,
+		"metadata": map[string]any{
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"tools":     map[string]any{"components": []cdxComponent{{Type: "application", BOMRef: "gonext", Name: "gonext", PURL: "pkg:golang/github.com/Alexigbokwe/gonext"}}},
+			"component": appComponent,
+		},
+		"components":   components,
+		"dependencies": []map[string]any{{"ref": appComponent.BOMRef, "dependsOn": dependsOn}},
+	}
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs"`
+}
+
+type spdxExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+// spdx renders an SPDX 2.3 SBOM
+func spdx(modules []goModule, version string) any {
+	var packages []spdxPackage
+	relationships := []spdxRelationship{{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: "SPDXRef-Package-0"}}
+	for i, m := range modules {
+		id := fmt.Sprintf("SPDXRef-Package-%d", i)
+		v := m.Version
+		if i == 0 {
+			v = version
+		} else {
+			relationships = append(relationships, spdxRelationship{Element: "SPDXRef-Package-0", Type: "DEPENDS_ON", Related: id})
+		}
+		packages = append(packages, spdxPackage{
+			Name: m.Path, SPDXID: id, VersionInfo: v, DownloadLocation: "NOASSERTION",
+			ExternalRefs: []spdxExternalRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: m.purl()}},
+		})
+	}
+	name := defaultImage()
+	return map[string]any{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              name,
+		"documentNamespace": "https://spdx.org/spdxdocs/" + name + "-" + newUUID(),
+		"creationInfo": map[string]any{
+			"created":  time.Now().UTC().Format(time.RFC3339),
+			"creators": []string{"Tool: gonext"},
+		},
+		"packages":      packages,
+		"relationships": relationships,
+	}
+}
+
+// writeSBOM writes the SBOM for the app in the requested format next to the build output
+func writeSBOM(format, version string) (string, error) {
+	modules, err := appModules()
+	if err != nil {
+		return "", err
+	}
+	var doc any
+	var file string
+	switch format {
+	case "cyclonedx":
+		doc, file = cycloneDX(modules, version), defaultImage()+".cdx.json"
+	case "spdx":
+		doc, file = spdx(modules, version), defaultImage()+".spdx.json"
+	default:
+		return "", fmt.Errorf("unknown SBOM format '%s' (expected cyclonedx or spdx)", format)
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join("bin", file)
+	if err := os.MkdirAll("bin", 0755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// writeProvenance records how the artifact was built as a SLSA provenance predicate
+func writeProvenance(version, revision string) (string, error) {
+	source := ""
+	if out, err := exec.Command("git", "config", "--get", "remote.origin.url").Output(); err == nil {
+		source = strings.TrimSpace(string(out))
+	}
+	goVersion, _ := exec.Command("go", "env", "GOVERSION").Output()
+	predicate := map[string]any{
+		"builder":   map[string]any{"id": "https://github.com/Alexigbokwe/gonext"},
+		"buildType": "https://github.com/Alexigbokwe/gonext/build@v1",
+		"invocation": map[string]any{
+			"configSource": map[string]any{"uri": source, "digest": map[string]string{"sha1": revision}},
+			"parameters":   map[string]any{"version": version, "docker": buildDocker, "ko": buildKo, "platform": buildPlatform},
+			"environment":  map[string]any{"go": strings.TrimSpace(string(goVersion))},
+		},
+		"metadata":  map[string]any{"buildFinishedOn": time.Now().UTC().Format(time.RFC3339)},
+		"materials": []map[string]any{{"uri": source, "digest": map[string]string{"sha1": revision}}},
+	}
+	data, err := json.MarshalIndent(predicate, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join("bin", defaultImage()+".provenance.json")
+	if err := os.MkdirAll("bin", 0755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// signBlobs signs local files with cosign, writing a .sigstore.json bundle next to each
+func signBlobs(paths ...string) error {
+	for _, p := range paths {
+		if err := runCommand(nil, "cosign", "sign-blob", "--yes", "--bundle", p+".sigstore.json", p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// imageDigestRef resolves a pushed image to its digest so signatures cannot be moved to another tag
+func imageDigestRef(ref string) string {
+	out, err := exec.Command("docker", "inspect", "--format", "{{index .RepoDigests 0}}", ref).Output()
+	if digest := strings.TrimSpace(string(out)); err == nil && digest != "" {
+		return digest
+	}
+	return ref
+}
+
+// signImage signs a pushed image with cosign and attaches the SBOM and provenance as attestations
+func signImage(ref, sbom, provenance string) error {
+	if err := runCommand(nil, "cosign", "sign", "--yes", ref); err != nil {
+		return err
+	}
+	if sbom != "" {
+		kind := map[string]string{"cyclonedx": "cyclonedx", "spdx": "spdxjson"}[buildSBOM]
+		if err := runCommand(nil, "cosign", "attest", "--yes", "--type", kind, "--predicate", sbom, ref); err != nil {
+			return err
+		}
+	}
+	return runCommand(nil, "cosign", "attest", "--yes", "--type", "slsaprovenance", "--predicate", provenance, ref)
+}
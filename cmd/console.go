@@ -0,0 +1,425 @@
+package cmd
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// consoleFile is the console program regenerated by every `gonext console`
+var consoleFile = filepath.Join("cmd", "console", "main.go")
+
+const consoleTemplate = `// Code generated by 'gonext console'. It is regenerated on every run to pick up new components.
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+%[1]s)
+
+// components resolves the services and repositories the console can call, keyed
+// by module.Type, through the container the modules registered them in
+func components(container *app.Container) map[string]any {
+	registry := map[string]any{}%[2]s
+	return registry
+}
+
+%[3]s
+
+%[4]s
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func main() {
+	ctx := context.Background()
+	container := app.NewContainer()
+	db, disconnect := connect(ctx, container)
+	defer disconnect()
+	mods, err := modules()
+	if err != nil {
+		fmt.Println("error:", err)
+	}
+	for _, m := range mods {
+		register(container, m)
+	}
+	registry := components(container)
+
+	fmt.Printf("GoNext console: %%d component(s). Type 'help' for commands.\n", len(registry))
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for {
+		fmt.Print("> ")
+		if !in.Scan() {
+			fmt.Println()
+			return
+		}
+		line := strings.TrimSpace(in.Text())
+		var err error
+		switch {
+		case line == "":
+			continue
+		case line == "exit" || line == "quit":
+			return
+		case line == "help":
+			help()
+		case line == "ls":
+			list(registry)
+		case strings.HasPrefix(line, "sql "):
+			err = query(ctx, db, strings.TrimPrefix(line, "sql "))
+		default:
+			err = call(ctx, registry, line)
+		}
+		if err != nil {
+			fmt.Println("error:", err)
+		}
+	}
+}
+
+func help() {
+	fmt.Println(` + "`" + `Commands:
+  ls                               list components and their methods
+  module.Type.Method(args...)      call a method; arguments are JSON values,
+                                   context.Context parameters are passed for you
+  sql SELECT ...                   run a query against DATABASE_URL
+  exit                             leave the console` + "`" + `)
+}
+
+// register runs the module's Register method, as the app does at startup. A
+// module that panics is reported and skipped.
+func register(container *app.Container, module any) {
+	r, ok := module.(interface{ Register(*app.Container) })
+	if !ok {
+		return
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			fmt.Printf("%%T could not register: %%v\n", module, p)
+		}
+	}()
+	r.Register(container)
+}
+
+// add resolves the component of type *T and lists it under name, or reports why it
+// is unavailable
+func add[T any](registry map[string]any, container *app.Container, name string) {
+	component, err := resolve[T](container)
+	if err != nil {
+		fmt.Printf("%%s is unavailable: %%v\n", name, err)
+		return
+	}
+	registry[name] = component
+}
+
+// resolve returns the *T a module registered, with its dependencies injected. A
+// component no module registers is created and autowired on its own.
+func resolve[T any](container *app.Container) (*T, error) {
+	var registered struct {
+		Component *T ` + "`inject:\"type\"`" + `
+	}
+	if err := autowire(container, &registered); err == nil && registered.Component != nil {
+		return registered.Component, nil
+	}
+	component := new(T)
+	if err := autowire(container, component); err != nil {
+		return nil, err
+	}
+	return component, nil
+}
+
+// autowire injects the dependencies of target, turning a panic into an error
+func autowire(container *app.Container, target any) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%%v", p)
+		}
+	}()
+	container.MustAutowire(target)
+	return nil
+}
+
+func list(registry map[string]any) {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t := reflect.TypeOf(registry[name])
+		fmt.Println(name)
+		for i := 0; i < t.NumMethod(); i++ {
+			m := t.Method(i)
+			var params, results []string
+			for j := 1; j < m.Type.NumIn(); j++ {
+				params = append(params, m.Type.In(j).String())
+			}
+			for j := 0; j < m.Type.NumOut(); j++ {
+				results = append(results, m.Type.Out(j).String())
+			}
+			fmt.Printf("  .%%s(%%s) %%s\n", m.Name, strings.Join(params, ", "), strings.Join(results, ", "))
+		}
+	}
+}
+
+// call parses module.Type.Method(args...) and invokes it with JSON-decoded arguments
+func call(ctx context.Context, registry map[string]any, line string) error {
+	open := strings.Index(line, "(")
+	if open < 0 || !strings.HasSuffix(line, ")") {
+		return errors.New("expected module.Type.Method(args...), ls, sql or help")
+	}
+	target := line[:open]
+	dot := strings.LastIndex(target, ".")
+	if dot < 0 {
+		return fmt.Errorf("expected module.Type.Method, got %%q", target)
+	}
+	component, ok := registry[target[:dot]]
+	if !ok {
+		return fmt.Errorf("unknown component %%q (see ls)", target[:dot])
+	}
+	method := reflect.ValueOf(component).MethodByName(target[dot+1:])
+	if !method.IsValid() {
+		return fmt.Errorf("%%s has no method %%s", target[:dot], target[dot+1:])
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte("["+line[open+1:len(line)-1]+"]"), &raw); err != nil {
+		return fmt.Errorf("arguments must be JSON values: %%v", err)
+	}
+	t := method.Type()
+	var args []reflect.Value
+	n := 0
+	for i := 0; i < t.NumIn(); i++ {
+		p := t.In(i)
+		if p == contextType {
+			args = append(args, reflect.ValueOf(ctx))
+			continue
+		}
+		if n == len(raw) {
+			return fmt.Errorf("missing argument %%d (%%s)", n+1, p)
+		}
+		v := reflect.New(p)
+		if err := json.Unmarshal(raw[n], v.Interface()); err != nil {
+			return fmt.Errorf("argument %%d (%%s): %%v", n+1, p, err)
+		}
+		args = append(args, v.Elem())
+		n++
+	}
+	if n < len(raw) {
+		return fmt.Errorf("too many arguments: %%s takes %%d", target, n)
+	}
+	out, err := invoke(method, args, t.IsVariadic())
+	if err != nil {
+		return err
+	}
+	for _, v := range out {
+		if v.Type() == errorType {
+			if !v.IsNil() {
+				fmt.Println("error:", v.Interface())
+			}
+			continue
+		}
+		data, err := json.MarshalIndent(v.Interface(), "", "  ")
+		if err != nil {
+			fmt.Printf("%%+v\n", v.Interface())
+			continue
+		}
+		fmt.Println(string(data))
+	}
+	return nil
+}
+
+// invoke calls method, turning a panic into an error so the session survives it
+func invoke(method reflect.Value, args []reflect.Value, variadic bool) (out []reflect.Value, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %%v", p)
+		}
+	}()
+	if variadic {
+		return method.CallSlice(args), nil
+	}
+	return method.Call(args), nil
+}
+
+// query prints the rows of a SQL query as a table
+func query(ctx context.Context, db *sql.DB, q string) error {
+	if db == nil {
+		return errors.New("no database connection (set DATABASE_URL)")
+	}
+	rows, err := db.QueryContext(ctx, q)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(cols, "\t"))
+	n := 0
+	for rows.Next() {
+		values := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		cells := make([]string, len(cols))
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			cells[i] = fmt.Sprint(v)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+		n++
+	}
+	w.Flush()
+	fmt.Printf("(%%d row(s))\n", n)
+	return rows.Err()
+}
+`
+
+const consoleConnectWithDatabase = `// connect binds the project's database connections in the container, as main.go
+// does, and returns the default one for sql queries with a function closing them
+func connect(ctx context.Context, container *app.Container) (*sql.DB, func()) {
+	if os.Getenv("DATABASE_URL") == "" {
+		return nil, func() {}
+	}
+	connections := database.NewManager(ctx)
+	container.Bind("connections", connections)
+	disconnect := func() { connections.OnModuleDestroy() }
+	if err := database.BindProviders(container, connections); err != nil {
+		fmt.Println("database unavailable:", err)
+		return nil, disconnect
+	}
+	db, err := connections.Get(database.DefaultConnection)
+	if err != nil {
+		fmt.Println("database unavailable:", err)
+		return nil, disconnect
+	}
+	return db, disconnect
+}`
+
+const consoleConnectWithoutDatabase = `// connect binds nothing: generate app/database with 'gonext g db:provider' to query from the console
+func connect(ctx context.Context, container *app.Container) (*sql.DB, func()) {
+	return nil, func() {}
+}`
+
+const consoleModulesWithRegistry = `// modules constructs the enabled modules in dependency order, as main.go does
+func modules() ([]any, error) {
+	return bootstrap.OrderedModules()
+}`
+
+// consoleModulesContent renders the modules function of a project without the
+// bootstrap module registry, constructing every enabled module of app/
+func consoleModulesContent(modules []string) string {
+	var list strings.Builder
+	for _, name := range modules {
+		fmt.Fprintf(&list, "\n\t\t%s,", moduleConstructor(name))
+	}
+	if len(modules) > 0 {
+		list.WriteString("\n\t")
+	}
+	return fmt.Sprintf(`// modules constructs the enabled modules of the app
+func modules() ([]any, error) {
+	return []any{%s}, nil
+}`, list.String())
+}
+
+// consoleContent renders the console program for the project's current components
+func consoleContent() (string, error) {
+	components, err := workspace.Components()
+	if err != nil {
+		return "", err
+	}
+	moduleName := getModuleName()
+	var imports, entries strings.Builder
+	imports.WriteString(fmt.Sprintf("\n\t%q\n", moduleName+"/app"))
+	connect := consoleConnectWithoutDatabase
+	if _, err := os.Stat(filepath.Join(databaseDir, "provider.go")); err == nil {
+		imports.WriteString(fmt.Sprintf("\t%q\n", moduleName+"/app/database"))
+		connect = consoleConnectWithDatabase
+	}
+	mods := consoleModulesWithRegistry
+	if _, err := os.Stat(moduleRegistryFile); err == nil {
+		imports.WriteString(fmt.Sprintf("\t%q\n", moduleName+"/bootstrap"))
+	} else {
+		modules, err := workspace.Modules()
+		if err != nil {
+			return "", err
+		}
+		m, _ := manifest.Load()
+		var enabled []string
+		for _, mod := range modules {
+			if !m.Modules[mod.Name].Disabled {
+				enabled = append(enabled, mod.Name)
+				imports.WriteString(fmt.Sprintf("\t%q\n", moduleName+"/app/"+mod.Name))
+			}
+		}
+		mods = consoleModulesContent(enabled)
+	}
+	seen := map[string]bool{}
+	for _, c := range components {
+		alias := c.Module + c.Kind
+		if !seen[alias] {
+			seen[alias] = true
+			imports.WriteString(fmt.Sprintf("\t%s %q\n", alias, moduleName+"/"+filepath.ToSlash(c.Dir)))
+		}
+		entries.WriteString(fmt.Sprintf("\n\tadd[%s.%s](registry, container, %q)", alias, c.Type, c.Module+"."+c.Type))
+	}
+	src, err := format.Source([]byte(fmt.Sprintf(consoleTemplate, imports.String(), entries.String(), connect, mods)))
+	return string(src), err
+}
+
+var consoleCmd = &cobra.Command{
+	Use:   "console",
+	Short: "Open an interactive console to call services and repositories and query the database",
+	Long: `Generates cmd/console/main.go with every service and repository in app/ and
+runs it. The console builds the DI container like the app: it binds the database
+connections, runs the modules' Register methods and resolves the components
+through the container, so their injected dependencies are set. Call methods as module.Type.Method(args...) with JSON arguments, list
+them with 'ls', and run SQL with 'sql SELECT ...' when the project has a database
+provider. Variables from .env are passed to the console.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := consoleContent()
+		if err != nil {
+			fmt.Printf("Error reading modules: %v\n", err)
+			return
+		}
+		if !writeGenerated(codegen.File{Path: consoleFile, Content: content}) {
+			return
+		}
+		env, err := readEnvFile(".env")
+		if err != nil {
+			fmt.Printf("Error reading .env: %v\n", err)
+			return
+		}
+		if err := runCommand(envAssignments(env), "go", "run", "./"+filepath.ToSlash(filepath.Dir(consoleFile))); err != nil {
+			fmt.Printf("Error running console: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(consoleCmd)
+}
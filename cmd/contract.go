@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// contractDir, contractBrokerURL and contractVersion are set by `gonext contract publish --dir/--broker-url/--version`
+var contractDir string
+var contractBrokerURL string
+var contractVersion string
+
+// contractPublish is set by `gonext contract verify --publish`
+var contractPublish bool
+
+const contractTemplate = `package contract
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"%[1]s/app/%[2]s"
+	"%[1]s/app/%[2]s/controller"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-foundation/pact-go/v2/models"
+	"github.com/pact-foundation/pact-go/v2/provider"
+)
+
+// providerName is the name consumers use for this service in their pacts
+const providerName = "%[2]s"
+
+// The provider routes verified against the consumer pacts:
+//
+%[3]s
+// stateHandlers set up the data each interaction expects, keyed by the provider
+// state named in the consumer pact. Add one for every state your consumers use.
+var stateHandlers = models.StateHandlers{
+%[4]s}
+
+// TestProviderContract replays the consumer pacts against the %[2]s routes. Pacts
+// come from the broker at PACT_BROKER_BASE_URL (PACT_BROKER_TOKEN), or from the
+// pacts/ directory at the project root (PACT_DIR) when no broker is set. With
+// PACT_PUBLISH_VERIFICATION_RESULTS=true the results are published to the broker
+// for PACT_PROVIDER_VERSION; 'gonext contract verify %[2]s --publish' sets them.
+func TestProviderContract(t *testing.T) {
+	request := provider.VerifyRequest{
+		Provider:                   providerName,
+		ProviderVersion:            os.Getenv("PACT_PROVIDER_VERSION"),
+		ProviderBranch:             os.Getenv("PACT_PROVIDER_BRANCH"),
+		PublishVerificationResults: os.Getenv("PACT_PUBLISH_VERIFICATION_RESULTS") == "true",
+		StateHandlers:              stateHandlers,
+	}
+	if broker := os.Getenv("PACT_BROKER_BASE_URL"); broker != "" {
+		request.BrokerURL = broker
+		request.BrokerToken = os.Getenv("PACT_BROKER_TOKEN")
+		request.EnablePending = true
+		request.ConsumerVersionSelectors = []provider.Selector{
+			&provider.ConsumerVersionSelector{MainBranch: true},
+			&provider.ConsumerVersionSelector{DeployedOrReleased: true},
+		}
+	} else {
+		dir := os.Getenv("PACT_DIR")
+		if dir == "" {
+			dir = filepath.Join("..", "..", "..", "pacts")
+		}
+		files, _ := filepath.Glob(filepath.Join(dir, "*-"+providerName+".json"))
+		if len(files) == 0 {
+			t.Skipf("no pacts for %%s in %%s and PACT_BROKER_BASE_URL is not set", providerName, dir)
+		}
+		request.PactFiles = files
+	}
+
+	server := fiber.New()
+	module := %[2]s.New%[5]sModule()
+	// TODO: Give the controller services backed by fakes the state handlers can seed
+	module.%[5]sController = &controller.%[5]sController{}
+	module.MountRoutes(server)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Listener(listener)
+	defer server.Shutdown()
+	request.ProviderBaseURL = "http://" + listener.Addr().String()
+
+	if err := provider.NewVerifier().VerifyProvider(t, request); err != nil {
+		t.Fatal(err)
+	}
+}
+`
+
+// routeParam matches the parameters of a Fiber route path
+var routeParam = regexp.MustCompile(`:(\w+)\??`)
+
+const contractStateTemplate = `	// %s
+	%q: func(setup bool, s models.ProviderState) (models.ProviderStateResponse, error) {
+		if !setup {
+			return nil, nil
+		}
+		// TODO: Seed the record identified by s.Parameters[%q]
+		return nil, nil
+	},
+`
+
+// contractContent renders the provider verification test for a module's routes.
+// Each path parameter gets a state handler, e.g. "users with id exists" for /users/:id.
+func contractContent(module string, routes []workspace.Route) string {
+	var list strings.Builder
+	states := map[string][]string{}
+	for _, r := range routes {
+		list.WriteString(fmt.Sprintf("//\t%s %s\n", r.Method, r.Path))
+		for _, m := range routeParam.FindAllStringSubmatch(r.Path, -1) {
+			states[m[1]] = append(states[m[1]], r.Method+" "+r.Path)
+		}
+	}
+	params := make([]string, 0, len(states))
+	for p := range states {
+		params = append(params, p)
+	}
+	sort.Strings(params)
+	var handlers strings.Builder
+	for _, p := range params {
+		handlers.WriteString(fmt.Sprintf(contractStateTemplate, strings.Join(states[p], ", "), module+" with "+p+" exists", p))
+	}
+	return fmt.Sprintf(contractTemplate, getModuleName(), module, list.String(), handlers.String(), strings.Title(module))
+}
+
+// moduleRoutes returns the routes registered by a module, or an error if it does not exist
+func moduleRoutes(module string) ([]workspace.Route, error) {
+	if _, err := os.Stat(filepath.Join(workspace.AppDir, module, "module.go")); err != nil {
+		return nil, fmt.Errorf("Module '%s' not found in %s", module, workspace.AppDir)
+	}
+	all, err := workspace.Routes()
+	if err != nil {
+		return nil, fmt.Errorf("Error scanning routes: %v", err)
+	}
+	var routes []workspace.Route
+	for _, r := range all {
+		if r.Module == module {
+			routes = append(routes, r)
+		}
+	}
+	return routes, nil
+}
+
+// gitBranch returns the current git branch, or "" outside a repository
+func gitBranch() string {
+	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+var generateContractCmd = &cobra.Command{
+	Use:   "contract [module]",
+	Short: "Generate a Pact provider verification test for a module's routes",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		module := args[0]
+		routes, err := moduleRoutes(module)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		file := filepath.Join(workspace.AppDir, module, "contract", "provider_test.go")
+		if !writeGenerated(codegen.File{Path: file, Content: contractContent(module, routes)}) {
+			return
+		}
+		fmt.Printf("Provider verification for %d route(s) created in %s. Install Pact with:\n", len(routes), file)
+		fmt.Println("  go get github.com/pact-foundation/pact-go/v2 && go run github.com/pact-foundation/pact-go/v2 install")
+		fmt.Printf("Run it with 'gonext contract verify %s'. Put consumer pacts in pacts/ or set PACT_BROKER_BASE_URL.\n", module)
+		openIfRequested(file)
+	},
+}
+
+var contractCmd = &cobra.Command{
+	Use:   "contract",
+	Short: "Verify and publish consumer-driven contracts (Pact)",
+}
+
+var contractVerifyCmd = &cobra.Command{
+	Use:   "verify [module]",
+	Short: "Run a module's provider verification against the consumer pacts",
+	Long: `Runs the test generated by 'gonext g contract <module>'. With --publish, the
+results are published to the broker at PACT_BROKER_BASE_URL for the current
+git version and branch, so 'pact-broker can-i-deploy' can use them.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		module := args[0]
+		dir := filepath.Join(workspace.AppDir, module, "contract")
+		if _, err := os.Stat(filepath.Join(dir, "provider_test.go")); err != nil {
+			fmt.Printf("No provider verification in %s. Generate it with 'gonext g contract %s'.\n", dir, module)
+			return
+		}
+		var env []string
+		if contractPublish {
+			if os.Getenv("PACT_BROKER_BASE_URL") == "" {
+				fmt.Println("--publish needs PACT_BROKER_BASE_URL")
+				return
+			}
+			version, _ := buildVersion()
+			env = append(env, "PACT_PUBLISH_VERIFICATION_RESULTS=true", "PACT_PROVIDER_VERSION="+version, "PACT_PROVIDER_BRANCH="+gitBranch())
+		}
+		if err := runCommand(env, "go", "test", "-count=1", "-v", "./"+filepath.ToSlash(dir)); err != nil {
+			fmt.Printf("Contract verification failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var contractPublishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Publish the consumer pacts in pacts/ to the Pact broker",
+	Long: `Publishes the pacts written by this service's consumer tests with the
+pact-broker CLI, tagged with the git version and branch. The broker token is
+read from PACT_BROKER_TOKEN.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if contractBrokerURL == "" {
+			fmt.Println("No broker: set PACT_BROKER_BASE_URL or pass --broker-url")
+			return
+		}
+		if _, err := exec.LookPath("pact-broker"); err != nil {
+			fmt.Println("Error: 'pact-broker' is required but not installed. See https://github.com/pact-foundation/pact-ruby-standalone/releases.")
+			return
+		}
+		files, _ := filepath.Glob(filepath.Join(contractDir, "*.json"))
+		if len(files) == 0 {
+			fmt.Printf("No pacts in %s. Run the consumer tests first.\n", contractDir)
+			return
+		}
+		version := contractVersion
+		if version == "" {
+			version, _ = buildVersion()
+		}
+		publishArgs := []string{"publish", contractDir, "--consumer-app-version", version, "--broker-base-url", contractBrokerURL}
+		if branch := gitBranch(); branch != "" && branch != "HEAD" {
+			publishArgs = append(publishArgs, "--branch", branch)
+		}
+		if err := runCommand(nil, "pact-broker", publishArgs...); err != nil {
+			fmt.Printf("Error publishing pacts: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%d pact(s) published for version %s\n", len(files), version)
+	},
+}
+
+func init() {
+	contractVerifyCmd.Flags().BoolVar(&contractPublish, "publish", false, "Publish the verification results to the broker")
+	contractPublishCmd.Flags().StringVar(&contractDir, "dir", "pacts", "Directory holding the pact files")
+	contractPublishCmd.Flags().StringVar(&contractBrokerURL, "broker-url", os.Getenv("PACT_BROKER_BASE_URL"), "Pact broker URL")
+	contractPublishCmd.Flags().StringVar(&contractVersion, "version", "", "Consumer version (default: git describe)")
+	contractCmd.AddCommand(contractVerifyCmd)
+	contractCmd.AddCommand(contractPublishCmd)
+	rootCmd.AddCommand(contractCmd)
+	generateCmd.AddCommand(generateContractCmd)
+	gCmd.AddCommand(generateContractCmd)
+}
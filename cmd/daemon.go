@@ -0,0 +1,351 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/audit"
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// generateParams are the params of the generate method
+type generateParams struct {
+	Generator string         `json:"generator"`
+	Args      []string       `json:"args"`
+	Flags     map[string]any `json:"flags"`
+}
+
+// generateResult is what a generate call did
+type generateResult struct {
+	OK     bool   `json:"ok"`
+	Output string `json:"output"`
+}
+
+// daemon answers editor requests from results cached until a project file changes
+type daemon struct {
+	fingerprint string
+	cache       map[string]any
+}
+
+// projectFingerprint summarizes the size and modification time of the files the
+// cached results are computed from. Stat calls are far cheaper than parsing, so
+// every request checks it instead of watching the file system.
+func projectFingerprint() string {
+	h := sha256.New()
+	stat := func(path string) {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(h, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	stat("go.mod")
+	stat(manifest.FileName)
+	filepath.WalkDir(workspace.AppDir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			stat(path)
+		}
+		return nil
+	})
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// cached returns the result of compute for key, recomputing it after project changes
+func (d *daemon) cached(key string, compute func() (any, error)) (any, error) {
+	if fp := projectFingerprint(); fp != d.fingerprint {
+		d.fingerprint = fp
+		d.cache = map[string]any{}
+	}
+	if v, ok := d.cache[key]; ok {
+		return v, nil
+	}
+	v, err := compute()
+	if err != nil {
+		return nil, err
+	}
+	d.cache[key] = v
+	return v, nil
+}
+
+// daemonMethods lists the methods announced by initialize
+var daemonMethods = []string{"initialize", "introspect", "modules", "routes", "graph", "diagnostics", "generate", "shutdown"}
+
+// diagnostics runs the static checks of `gonext audit`, without govulncheck
+func diagnostics() (any, error) {
+	files, err := auditFiles()
+	if err != nil {
+		return nil, err
+	}
+	routes, err := workspace.Routes()
+	if err != nil {
+		return nil, err
+	}
+	findings := audit.HardcodedSecrets(files)
+	findings = append(findings, audit.MissingAuth(routes, middlewareChainFile)...)
+	findings = append(findings, audit.PermissiveCORS(files)...)
+	audit.Sort(findings)
+	if findings == nil {
+		findings = []audit.Finding{}
+	}
+	return findings, nil
+}
+
+// flagArgs turns generate flags into command-line flags: lists repeat the flag
+// and every other value is passed as --name=value
+func flagArgs(flags map[string]any) []string {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var args []string
+	for _, name := range names {
+		switch v := flags[name].(type) {
+		case bool:
+			args = append(args, fmt.Sprintf("--%s=%t", name, v))
+		case []any:
+			for _, item := range v {
+				args = append(args, fmt.Sprintf("--%s=%v", name, item))
+			}
+		case float64:
+			args = append(args, fmt.Sprintf("--%s=%s", name, strconv.FormatFloat(v, 'f', -1, 64)))
+		default:
+			args = append(args, fmt.Sprintf("--%s=%v", name, v))
+		}
+	}
+	return args
+}
+
+// generate runs a generator in a child process, whose output would otherwise be
+// mixed into the protocol stream, and whose flags start from their defaults
+func generate(p generateParams) (any, error) {
+	if p.Generator == "" {
+		return nil, fmt.Errorf("generator is required")
+	}
+	found := false
+	for _, c := range generateCmd.Commands() {
+		found = found || c.Name() == p.Generator
+	}
+	if !found {
+		return nil, fmt.Errorf("unknown generator '%s'", p.Generator)
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	args := append([]string{"generate", p.Generator}, p.Args...)
+	args = append(args, flagArgs(p.Flags)...)
+	c := exec.Command(self, args...)
+	var out bytes.Buffer
+	c.Stdout = &out
+	c.Stderr = &out
+	err = c.Run()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		return nil, err
+	}
+	// Generators report most failures with an "Error ..." line and a zero exit status
+	return generateResult{OK: err == nil && !strings.Contains(out.String(), "Error"), Output: out.String()}, nil
+}
+
+// handle answers one request
+func (d *daemon) handle(req rpcRequest) (any, *rpcError) {
+	var result any
+	var err error
+	switch req.Method {
+	case "initialize":
+		root, _ := os.Getwd()
+		result = map[string]any{"name": "gonext", "schema": introspectSchema, "root": root, "methods": daemonMethods}
+	case "introspect":
+		result, err = d.cached("introspect", func() (any, error) {
+			modules, err := workspace.Modules()
+			if modules == nil {
+				modules = []workspace.Module{}
+			}
+			return Introspection{Schema: introspectSchema, Generators: generatorSpecs(), Layout: projectLayout(), Modules: modules}, err
+		})
+	case "modules":
+		result, err = d.cached("modules", func() (any, error) {
+			modules, err := workspace.Modules()
+			if modules == nil {
+				modules = []workspace.Module{}
+			}
+			return modules, err
+		})
+	case "routes":
+		result, err = d.cached("routes", func() (any, error) {
+			routes, err := workspace.Routes()
+			if routes == nil {
+				routes = []workspace.Route{}
+			}
+			return routes, err
+		})
+	case "graph":
+		result, err = d.cached("graph", func() (any, error) { return workspace.Dependencies() })
+	case "diagnostics":
+		result, err = d.cached("diagnostics", diagnostics)
+	case "generate":
+		var p generateParams
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		result, err = generate(p)
+	case "shutdown":
+		result = map[string]any{}
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("unknown method '%s'", req.Method)}
+	}
+	if err != nil {
+		return nil, &rpcError{Code: rpcInternalError, Message: err.Error()}
+	}
+	return result, nil
+}
+
+// readMessage reads one Content-Length framed message, as in the Language Server Protocol
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, "Content-Length:"); ok {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %q", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("message without Content-Length")
+	}
+	body := make([]byte, length)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
+
+// writeMessage writes one Content-Length framed message
+func writeMessage(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	return err
+}
+
+// serve answers requests until the input ends or shutdown is requested
+func (d *daemon) serve(in io.Reader, out io.Writer) error {
+	r := bufio.NewReader(in)
+	for {
+		body, err := readMessage(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var req rpcRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := writeMessage(out, rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: rpcParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			if err := writeMessage(out, rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: rpcInvalidRequest, Message: "expected a JSON-RPC 2.0 request"}}); err != nil {
+				return err
+			}
+			continue
+		}
+		result, rpcErr := d.handle(req)
+		if req.ID == nil {
+			// Notifications get no response
+			if req.Method == "shutdown" || req.Method == "exit" {
+				return nil
+			}
+			continue
+		}
+		resp := rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}
+		if err := writeMessage(out, resp); err != nil {
+			return err
+		}
+		if req.Method == "shutdown" {
+			return nil
+		}
+	}
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Serve generate, routes, graph and diagnostics to editors over JSON-RPC on stdio",
+	Long: `Runs a long-lived process for editor integrations. Requests and responses are
+JSON-RPC 2.0 messages framed with Content-Length headers, as in the Language
+Server Protocol, on stdin and stdout.
+
+Methods:
+  initialize    name, schema and supported methods
+  introspect    the document of 'gonext introspect --json'
+  modules       the modules in app/
+  routes        the routes of every module
+  graph         modules and injected components with their dependencies
+  diagnostics   the static findings of 'gonext audit' (secrets, auth, CORS)
+  generate      run a generator: {"generator": "module", "args": ["users"], "flags": {"crud": true}}
+  shutdown      stop the daemon
+
+Results are cached and recomputed only after a file in app/, go.mod or
+gonext.yaml changes, so repeated requests answer without re-scanning the project.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		d := &daemon{}
+		if err := d.serve(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "gonext daemon: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the project's database: migrations, seeders, dumps and restores",
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending migrations with the project's migration runner",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(migrateCommandFile); os.IsNotExist(err) {
+			fmt.Printf("%s not found. Generate it with 'gonext g db:provider --migrate-on-start'.\n", migrateCommandFile)
+			return
+		}
+		c := exec.Command("go", "run", "./"+filepath.ToSlash(filepath.Dir(migrateCommandFile)))
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			fmt.Printf("Error running migrations: %v\n", err)
+		}
+	},
+}
+
+// migrationName keeps migration file names portable
+var migrationName = regexp.MustCompile(`[^a-z0-9]+`)
+
+var dbMigrateCreateCmd = &cobra.Command{
+	Use:   "migrate:create [name]",
+	Short: "Create an empty, timestamped SQL migration in migrations/",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := strings.Trim(migrationName.ReplaceAllString(strings.ToLower(args[0]), "_"), "_")
+		version := time.Now().UTC().Format("20060102150405") + "_" + name
+		file := filepath.Join(migrationsDir, version+".up.sql")
+		content := fmt.Sprintf("-- %s\n-- Applied once, in a transaction, by 'gonext db migrate' or on start with DB_MIGRATE_ON_START=true.\n", args[0])
+		if !writeGenerated(codegen.File{Path: file, Content: content}) {
+			return
+		}
+		fmt.Printf("Migration created at %s\n", file)
+		openIfRequested(file)
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbCmd.AddCommand(dbMigrateCreateCmd)
+	rootCmd.AddCommand(dbCmd)
+}
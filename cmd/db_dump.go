@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+// dbURL, dbDumpAnonymize and dbDumpOutput are set by `gonext db dump/restore --url/--anonymize/--output`
+var dbURL string
+var dbDumpAnonymize bool
+var dbDumpOutput string
+
+// defaultScrubRules apply after the rules in gonext.yaml. Names are only scrubbed in
+// person-like columns, so products.name or categories.name are kept.
+var defaultScrubRules = []manifest.ScrubRule{
+	{Column: "*email*", With: "email"},
+	{Column: "*phone*", With: "phone"},
+	{Column: "users.name", With: "name"},
+	{Column: "first_name", With: "name"},
+	{Column: "middle_name", With: "name"},
+	{Column: "last_name", With: "name"},
+	{Column: "full_name", With: "name"},
+	{Column: "*address*", With: "text"},
+}
+
+// scrubStrategies are the replacements rules can use
+var scrubStrategies = map[string]bool{"email": true, "name": true, "phone": true, "text": true, "hash": true, "null": true, "keep": true}
+
+var firstNames = []string{"Alex", "Sam", "Jordan", "Taylor", "Morgan", "Casey", "Riley", "Jamie", "Avery", "Quinn", "Rowan", "Drew"}
+var lastNames = []string{"Smith", "Okafor", "Garcia", "Chen", "Müller", "Silva", "Kowalski", "Haddad", "Tanaka", "Novak", "Adeyemi", "Larsen"}
+
+// copyStatement matches the header of a COPY block in a plain pg_dump
+var copyStatement = regexp.MustCompile(`^COPY ([^ ]+) \((.*)\) FROM stdin;$`)
+
+// anonymizer rewrites the rows of COPY blocks in a plain pg_dump, replacing the values of
+// columns matched by a scrub rule. Replacements are derived from the original value and a
+// per-dump salt, so equal values stay equal within one dump. They carry at least 50 bits
+// of that hash, so distinct values practically never collide and unique columns restore.
+type anonymizer struct {
+	rules    []manifest.ScrubRule
+	salt     []byte
+	scrubbed map[string]int // rows changed by table.column
+}
+
+func newAnonymizer(rules []manifest.ScrubRule) (*anonymizer, error) {
+	for _, r := range rules {
+		if !scrubStrategies[r.With] {
+			return nil, fmt.Errorf("unknown anonymize strategy '%s' for %s (expected email, name, phone, text, hash, null or keep)", r.With, r.Column)
+		}
+		if _, err := path.Match(r.Column, ""); err != nil {
+			return nil, fmt.Errorf("invalid anonymize column pattern '%s'", r.Column)
+		}
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return &anonymizer{rules: append(rules, defaultScrubRules...), salt: salt, scrubbed: map[string]int{}}, nil
+}
+
+// strategy returns how to scrub table.column, or "" to keep it
+func (a *anonymizer) strategy(table, column string) string {
+	column = strings.ToLower(column)
+	for _, r := range a.rules {
+		pattern := strings.ToLower(r.Column)
+		subject := column
+		if strings.Contains(pattern, ".") {
+			subject = strings.ToLower(table) + "." + column
+		}
+		if ok, _ := path.Match(pattern, subject); ok {
+			if r.With == "keep" {
+				return ""
+			}
+			return r.With
+		}
+	}
+	return ""
+}
+
+// replace returns the scrubbed value of a non-NULL COPY text field
+func (a *anonymizer) replace(strategy, value string) string {
+	h := sha256.Sum256(append(a.salt, value...))
+	n := binary.BigEndian.Uint64(h[:8])
+	short := hex.EncodeToString(h[:8])
+	switch strategy {
+	case "email":
+		return "user_" + short + "@example.com"
+	case "name":
+		// The names only make it readable; the suffix keeps it unique
+		m := binary.BigEndian.Uint64(h[8:16])
+		return firstNames[m%uint64(len(firstNames))] + " " + lastNames[(m/97)%uint64(len(lastNames))] + " " + short
+	case "phone":
+		// 20 characters: 15 digits after the 555 prefix, which fit a varchar(20)
+		return fmt.Sprintf("+1555%015d", n%1_000_000_000_000_000)
+	case "text":
+		return "redacted-" + short
+	case "hash":
+		return hex.EncodeToString(h[:16])
+	case "null":
+		return `\N`
+	}
+	return value
+}
+
+// copy streams a plain pg_dump from r to w, scrubbing COPY rows
+func (a *anonymizer) copy(w io.Writer, r io.Reader) error {
+	in := bufio.NewReaderSize(r, 1024*1024)
+	out := bufio.NewWriter(w)
+	var table string
+	var strategies []string // per column while inside a COPY block, nil otherwise
+	var columns []string
+	for {
+		line, err := in.ReadString('\n')
+		if line != "" {
+			text := strings.TrimSuffix(line, "\n")
+			switch {
+			case strategies != nil && text == `\.`:
+				strategies = nil
+			case strategies != nil:
+				fields := strings.Split(text, "\t")
+				for i, s := range strategies {
+					if s != "" && i < len(fields) && fields[i] != `\N` {
+						fields[i] = a.replace(s, fields[i])
+						a.scrubbed[table+"."+columns[i]]++
+					}
+				}
+				line = strings.Join(fields, "\t") + "\n"
+			default:
+				if m := copyStatement.FindStringSubmatch(text); m != nil {
+					table = unquoteIdent(m[1][strings.LastIndex(m[1], ".")+1:])
+					columns = strings.Split(m[2], ", ")
+					strategies = make([]string, len(columns))
+					for i, c := range columns {
+						columns[i] = unquoteIdent(c)
+						strategies[i] = a.strategy(table, columns[i])
+					}
+				}
+			}
+			if _, werr := out.WriteString(line); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return out.Flush()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func unquoteIdent(name string) string {
+	if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+		return strings.ReplaceAll(name[1:len(name)-1], `""`, `"`)
+	}
+	return name
+}
+
+// databaseURL returns --url, or DATABASE_URL from the environment or .env
+func databaseURL() (string, error) {
+	if dbURL != "" {
+		return dbURL, nil
+	}
+	if url := os.Getenv("DATABASE_URL"); url != "" {
+		return url, nil
+	}
+	env, err := readEnvFile(".env")
+	if err != nil {
+		return "", err
+	}
+	if env["DATABASE_URL"] == "" {
+		return "", fmt.Errorf("DATABASE_URL is not set; pass --url or set it in .env")
+	}
+	return env["DATABASE_URL"], nil
+}
+
+var dbDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Dump a database with pg_dump, optionally scrubbing personal data",
+	Long: `Runs pg_dump on --url (default DATABASE_URL) and writes a plain SQL dump that
+'gonext db restore' loads. With --anonymize, values of columns such as *email*,
+*phone*, name, *_name and *address* are replaced with fake ones as the dump is
+written. Add rules, or exclude columns with 'keep', in gonext.yaml:
+
+  anonymize:
+    - column: users.bio
+      with: text
+    - column: companies.name
+      with: keep`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		url, err := databaseURL()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if _, err := exec.LookPath("pg_dump"); err != nil {
+			fmt.Println("'pg_dump' is required. Install the PostgreSQL client tools.")
+			return
+		}
+		var anon *anonymizer
+		if dbDumpAnonymize {
+			m, err := manifest.Load()
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if anon, err = newAnonymizer(m.Anonymize); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+
+		out, err := os.Create(dbDumpOutput)
+		if err != nil {
+			fmt.Printf("Error creating %s: %v\n", dbDumpOutput, err)
+			return
+		}
+		defer out.Close()
+		// The URL may hold a password, so it is not echoed
+		fmt.Printf("$ pg_dump --no-owner --no-privileges --clean --if-exists <database url> > %s\n", dbDumpOutput)
+		c := exec.Command("pg_dump", "--no-owner", "--no-privileges", "--clean", "--if-exists", "--dbname", url)
+		c.Stderr = os.Stderr
+		if anon == nil {
+			c.Stdout = out
+			err = c.Run()
+		} else {
+			var stdout io.ReadCloser
+			if stdout, err = c.StdoutPipe(); err == nil {
+				if err = c.Start(); err == nil {
+					err = anon.copy(out, stdout)
+					if werr := c.Wait(); err == nil {
+						err = werr
+					}
+				}
+			}
+		}
+		if err != nil {
+			out.Close()
+			os.Remove(dbDumpOutput)
+			fmt.Printf("Error dumping the database: %v\n", err)
+			return
+		}
+		if anon != nil {
+			columns := make([]string, 0, len(anon.scrubbed))
+			for c := range anon.scrubbed {
+				columns = append(columns, c)
+			}
+			sort.Strings(columns)
+			for _, c := range columns {
+				fmt.Printf("  scrubbed %-40s %d row(s)\n", c, anon.scrubbed[c])
+			}
+			fmt.Printf("Anonymized dump written to %s (%d column(s) scrubbed)\n", dbDumpOutput, len(columns))
+			return
+		}
+		fmt.Printf("Dump written to %s\n", dbDumpOutput)
+	},
+}
+
+var dbRestoreCmd = &cobra.Command{
+	Use:   "restore <dump.sql>",
+	Short: "Load a dump made by 'gonext db dump' into the local database",
+	Long: `Runs the dump with psql against --url (default DATABASE_URL) in a single
+transaction, replacing the tables it contains. Refuses to run with APP_ENV=production.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		env, err := readEnvFile(".env")
+		if err != nil {
+			fmt.Printf("Error reading .env: %v\n", err)
+			return
+		}
+		if os.Getenv("APP_ENV") == "production" || env["APP_ENV"] == "production" {
+			fmt.Println("Restoring replaces data; refusing to run with APP_ENV=production.")
+			return
+		}
+		if _, err := os.Stat(args[0]); err != nil {
+			fmt.Println(err)
+			return
+		}
+		url, err := databaseURL()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if _, err := exec.LookPath("psql"); err != nil {
+			fmt.Println("'psql' is required. Install the PostgreSQL client tools.")
+			return
+		}
+		fmt.Printf("$ psql --single-transaction -v ON_ERROR_STOP=1 --quiet -f %s <database url>\n", args[0])
+		c := exec.Command("psql", "--single-transaction", "-v", "ON_ERROR_STOP=1", "--quiet", "-f", args[0], "--dbname", url)
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			fmt.Printf("Error restoring %s: %v\n", args[0], err)
+			return
+		}
+		fmt.Printf("Restored %s\n", args[0])
+	},
+}
+
+func init() {
+	dbDumpCmd.Flags().StringVar(&dbURL, "url", "", "Database to dump (defaults to DATABASE_URL)")
+	dbDumpCmd.Flags().BoolVar(&dbDumpAnonymize, "anonymize", false, "Replace personal data using the rules in gonext.yaml and the built-in ones")
+	dbDumpCmd.Flags().StringVarP(&dbDumpOutput, "output", "o", "dump.sql", "File to write the dump to")
+	dbRestoreCmd.Flags().StringVar(&dbURL, "url", "", "Database to restore into (defaults to DATABASE_URL)")
+	dbCmd.AddCommand(dbDumpCmd)
+	dbCmd.AddCommand(dbRestoreCmd)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+// dbSeedOnly and dbSeedFresh are set by `gonext db seed --only/--fresh`
+var dbSeedOnly []string
+var dbSeedFresh bool
+
+var dbSeedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Run the seeders of app/database/seeders in order",
+	Long: `Discovers the numbered seeders in app/database/seeders, rewrites the registry
+listing them, and runs them in number order against the database configured in
+the environment and .env. Each seeder runs in its own transaction.
+
+--only runs the named seeders, still in number order (--only users,roles).
+--fresh drops everything in the public schema and re-applies the migrations
+first; it needs the migration runner ('gonext g db:provider --migrate-on-start')
+and refuses to run with APP_ENV=production.
+
+The seeders run through the project's database provider, with the runner
+generated in cmd/seed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		env, err := readEnvFile(".env")
+		if err != nil {
+			fmt.Printf("Error reading .env: %v\n", err)
+			return
+		}
+		if _, err := os.Stat(filepath.Join(databaseDir, "provider.go")); os.IsNotExist(err) {
+			fmt.Println("No database provider found. Generate it with 'gonext g db:provider'.")
+			return
+		}
+		if dbSeedFresh {
+			if os.Getenv("APP_ENV") == "production" || env["APP_ENV"] == "production" {
+				fmt.Println("--fresh drops every table; APP_ENV is production.")
+				return
+			}
+			if _, err := os.Stat(filepath.Join(databaseDir, "migrate.go")); os.IsNotExist(err) {
+				fmt.Println("--fresh re-applies the migrations. Generate the runner with 'gonext g db:provider --migrate-on-start'.")
+				return
+			}
+		}
+		seeders, err := discoverSeeders()
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", seedersDir, err)
+			return
+		}
+		if len(seeders) == 0 {
+			fmt.Printf("No seeders found in %s. Create one with 'gonext g seeder <name>'.\n", seedersDir)
+			return
+		}
+		known := map[string]bool{}
+		for _, s := range seeders {
+			known[s.Name] = true
+		}
+		for _, name := range dbSeedOnly {
+			if !known[name] {
+				fmt.Printf("Unknown seeder '%s'. Seeders in %s:\n", name, seedersDir)
+				for _, s := range seeders {
+					fmt.Printf("  %s\n", s.Name)
+				}
+				return
+			}
+		}
+
+		files := seederRegistryFiles(seeders)
+		files = append(files, codegen.File{Path: seedCommandFile, Content: seedCommandContent()})
+		if !writeGenerated(files...) {
+			return
+		}
+		runArgs := []string{"run", "./" + filepath.ToSlash(filepath.Dir(seedCommandFile))}
+		if len(dbSeedOnly) > 0 {
+			runArgs = append(runArgs, "-only", strings.Join(dbSeedOnly, ","))
+		}
+		if dbSeedFresh {
+			runArgs = append(runArgs, "-fresh")
+		}
+		if err := runCommand(envAssignments(env), "go", runArgs...); err != nil {
+			fmt.Printf("Error running seeders: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	dbSeedCmd.Flags().StringSliceVar(&dbSeedOnly, "only", nil, "Run only these seeders, by name (repeatable or comma-separated)")
+	dbSeedCmd.Flags().BoolVar(&dbSeedFresh, "fresh", false, "Drop everything in the public schema and re-apply the migrations before seeding")
+	dbCmd.AddCommand(dbSeedCmd)
+}
@@ -0,0 +1,505 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// deployDryRun, deployApp, deployImage, deployRegion and deployEnvFile are set by `gonext deploy` flags
+var deployDryRun bool
+var deployApp string
+var deployImage string
+var deployRegion string
+var deployEnvFile string
+
+// deployStep is one action of a deployment. Run reports what it does as it runs;
+// Describe is what --dry-run prints instead.
+type deployStep struct {
+	Describe string
+	Run      func() error
+}
+
+// commandStep runs an external tool as a deployment step
+func commandStep(name string, args ...string) deployStep {
+	return deployStep{
+		Describe: commandLine(name, args...),
+		Run:      func() error { return runCommand(nil, name, args...) },
+	}
+}
+
+// deployPlan holds what a target needs to deploy the app
+type deployPlan struct {
+	App    string
+	Image  string // Image reference to build and push, empty when the platform builds from source
+	Region string
+	Env    map[string]string // Variables read from the env file
+}
+
+// deployTarget describes a platform `gonext deploy` can ship to
+type deployTarget struct {
+	Name       string
+	Tools      []string // CLIs the steps rely on, checked before deploying
+	ConfigFile string
+	Region     string                    // Default region, if the platform has one
+	FromSource bool                      // The platform builds the Dockerfile itself, so no image is pushed
+	Registry   func(p deployPlan) string // Default image repository, nil if --image is required
+	Config     func(p deployPlan) string
+	Steps      func(p deployPlan) ([]deployStep, error)
+	Check      func() error // Checks the settings the steps need, once the config is written; nil if none
+}
+
+// deployTargets are the supported platforms, keyed by name
+var deployTargets = map[string]deployTarget{
+	"fly": {
+		Name:       "Fly.io",
+		Tools:      []string{"flyctl", "docker"},
+		ConfigFile: "fly.toml",
+		Region:     "iad",
+		Registry:   func(p deployPlan) string { return "registry.fly.io/" + p.App },
+		Config:     flyConfig,
+		Steps: func(p deployPlan) ([]deployStep, error) {
+			steps := imageSteps(p)
+			steps = append([]deployStep{commandStep("flyctl", "auth", "docker")}, steps...)
+			if len(p.Env) > 0 {
+				steps = append(steps, commandStep("flyctl", append([]string{"secrets", "set", "--stage", "--app", p.App}, envAssignments(p.Env)...)...))
+			}
+			return append(steps, commandStep("flyctl", "deploy", "--app", p.App, "--image", p.Image)), nil
+		},
+	},
+	"railway": {
+		Name:       "Railway",
+		Tools:      []string{"railway"},
+		ConfigFile: "railway.json",
+		FromSource: true,
+		Config:     railwayConfig,
+		Steps: func(p deployPlan) ([]deployStep, error) {
+			var steps []deployStep
+			if len(p.Env) > 0 {
+				args := []string{"variables", "--skip-deploys"}
+				for _, kv := range envAssignments(p.Env) {
+					args = append(args, "--set", kv)
+				}
+				steps = append(steps, commandStep("railway", args...))
+			}
+			return append(steps, commandStep("railway", "up", "--detach")), nil
+		},
+	},
+	"render": {
+		Name:       "Render",
+		Tools:      []string{"docker"},
+		ConfigFile: "render.yaml",
+		Config:     renderConfig,
+		Steps: func(p deployPlan) ([]deployStep, error) {
+			steps := imageSteps(p)
+			if len(p.Env) > 0 {
+				steps = append(steps, renderEnvStep(p.Env))
+			}
+			return append(steps, deployStep{
+				Describe: "POST $RENDER_DEPLOY_HOOK_URL (imgURL=" + p.Image + ")",
+				Run: func() error {
+					hook := os.Getenv("RENDER_DEPLOY_HOOK_URL")
+					deploy := hook + "&imgURL=" + url.QueryEscape(p.Image)
+					if !strings.Contains(hook, "?") {
+						deploy = hook + "?imgURL=" + url.QueryEscape(p.Image)
+					}
+					fmt.Println("Triggering the Render deploy hook...")
+					return sendJSON(http.MethodPost, deploy, "", nil)
+				},
+			}), nil
+		},
+		Check: func() error {
+			if os.Getenv("RENDER_DEPLOY_HOOK_URL") == "" {
+				return fmt.Errorf("create the service from render.yaml (New > Blueprint), then set RENDER_DEPLOY_HOOK_URL to its deploy hook (Settings > Deploy Hook)")
+			}
+			return nil
+		},
+	},
+	"cloudrun": {
+		Name:       "Cloud Run",
+		Tools:      []string{"gcloud", "docker"},
+		ConfigFile: "service.yaml",
+		Region:     "us-central1",
+		Config:     cloudRunConfig,
+		Steps: func(p deployPlan) ([]deployStep, error) {
+			steps := imageSteps(p)
+			return append(steps, deployStep{
+				Describe: fmt.Sprintf("$ gcloud run services replace <service.yaml with image %s and %d variable(s) from %s> --region %s", p.Image, len(p.Env), deployEnvFile, p.Region),
+				Run: func() error {
+					rendered, err := cloudRunService(p)
+					if err != nil {
+						return err
+					}
+					defer os.Remove(rendered)
+					return runCommand(nil, "gcloud", "run", "services", "replace", rendered, "--region", p.Region)
+				},
+			}), nil
+		},
+	},
+}
+
+// imageSteps build and push the image for platforms that run a prebuilt image
+func imageSteps(p deployPlan) []deployStep {
+	version, revision := buildVersion()
+	return []deployStep{
+		commandStep("docker", dockerBuildArgs(p.Image, "linux/amd64", ociLabels(version, revision))...),
+		commandStep("docker", "push", p.Image),
+	}
+}
+
+// readEnvFile parses KEY=VALUE lines, skipping blanks, comments and `export` prefixes
+func readEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	env := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env[strings.TrimSpace(key)] = value
+	}
+	return env, scanner.Err()
+}
+
+// envKeys returns the variable names in a stable order
+func envKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// envAssignments renders the variables as KEY=value arguments
+func envAssignments(env map[string]string) []string {
+	var out []string
+	for _, k := range envKeys(env) {
+		out = append(out, k+"="+env[k])
+	}
+	return out
+}
+
+// sendJSON calls an HTTP API, with a bearer token when one is given, and fails on any non-2xx status
+func sendJSON(method, target, token string, body any) error {
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, target, &payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: %s", method, req.URL.Host+req.URL.Path, resp.Status)
+	}
+	return nil
+}
+
+// renderEnvStep replaces the service's environment through the Render API
+func renderEnvStep(env map[string]string) deployStep {
+	return deployStep{
+		Describe: fmt.Sprintf("PUT https://api.render.com/v1/services/$RENDER_SERVICE_ID/env-vars (%s)", strings.Join(envKeys(env), ", ")),
+		Run: func() error {
+			key, service := os.Getenv("RENDER_API_KEY"), os.Getenv("RENDER_SERVICE_ID")
+			if key == "" || service == "" {
+				fmt.Println("RENDER_API_KEY or RENDER_SERVICE_ID is not set, skipping environment sync.")
+				return nil
+			}
+			fmt.Printf("Syncing %d variable(s) to Render...\n", len(env))
+			var vars []map[string]string
+			for _, k := range envKeys(env) {
+				vars = append(vars, map[string]string{"key": k, "value": env[k]})
+			}
+			return sendJSON(http.MethodPut, "https://api.render.com/v1/services/"+service+"/env-vars", key, vars)
+		},
+	}
+}
+
+// cloudRunService writes service.yaml with the image and env file variables filled in to a
+// private temporary file, so secrets never end up in the committed config
+func cloudRunService(p deployPlan) (string, error) {
+	data, err := os.ReadFile("service.yaml")
+	if err != nil {
+		return "", err
+	}
+	var svc map[string]any
+	if err := yaml.Unmarshal(data, &svc); err != nil {
+		return "", fmt.Errorf("invalid service.yaml: %v", err)
+	}
+	container, err := firstContainer(svc)
+	if err != nil {
+		return "", err
+	}
+	container["image"] = p.Image
+	// Variables from the env file win over the ones declared in service.yaml
+	var env []any
+	declared, _ := container["env"].([]any)
+	for _, e := range declared {
+		if m, ok := e.(map[string]any); ok {
+			if _, override := p.Env[fmt.Sprint(m["name"])]; override {
+				continue
+			}
+		}
+		env = append(env, e)
+	}
+	for _, k := range envKeys(p.Env) {
+		env = append(env, map[string]any{"name": k, "value": p.Env[k]})
+	}
+	container["env"] = env
+	out, err := yaml.Marshal(svc)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp("", "gonext-service-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(out); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// firstContainer finds spec.template.spec.containers[0] in a Knative service
+func firstContainer(svc map[string]any) (map[string]any, error) {
+	node := any(svc)
+	for _, key := range []string{"spec", "template", "spec", "containers"} {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("service.yaml has no spec.template.spec.containers")
+		}
+		node = m[key]
+	}
+	containers, _ := node.([]any)
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("service.yaml has no spec.template.spec.containers")
+	}
+	container, ok := containers[0].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("service.yaml has an invalid container")
+	}
+	return container, nil
+}
+
+func flyConfig(p deployPlan) string {
+	return fmt.Sprintf(`# Generated by 'gonext deploy fly'. Secrets are synced from .env on every deploy.
+app = %q
+primary_region = %q
+
+[env]
+  SERVER_HOST = "0.0.0.0"
+  SERVER_PORT = "3000"
+
+[http_service]
+  internal_port = 3000
+  force_https = true
+  auto_stop_machines = "stop"
+  auto_start_machines = true
+  min_machines_running = 1
+
+  [[http_service.checks]]
+    method = "GET"
+    path = "/readyz"
+    interval = "10s"
+    timeout = "2s"
+    grace_period = "10s"
+`, p.App, p.Region)
+}
+
+func railwayConfig(p deployPlan) string {
+	return `{
+  "$schema": "https://railway.com/railway.schema.json",
+  "build": {
+    "builder": "DOCKERFILE",
+    "dockerfilePath": "Dockerfile"
+  },
+  "deploy": {
+    "healthcheckPath": "/readyz",
+    "restartPolicyType": "ON_FAILURE"
+  }
+}
+`
+}
+
+func renderConfig(p deployPlan) string {
+	var env strings.Builder
+	for _, k := range envKeys(p.Env) {
+		env.WriteString(fmt.Sprintf("      - key: %s\n        sync: false\n", k))
+	}
+	content := fmt.Sprintf(`# Generated by 'gonext deploy render'. Create the service from this blueprint,
+# then deploy with RENDER_DEPLOY_HOOK_URL set.
+services:
+  - type: web
+    name: %s
+    runtime: image
+    image:
+      url: %s
+    healthCheckPath: /readyz
+`, p.App, p.Image)
+	if env.Len() > 0 {
+		content += "    envVars:\n" + env.String()
+	}
+	return content
+}
+
+func cloudRunConfig(p deployPlan) string {
+	return fmt.Sprintf(`# Generated by 'gonext deploy cloudrun'. The image and .env variables are
+# filled in at deploy time; keep secrets out of this file.
+apiVersion: serving.knative.dev/v1
+kind: Service
+metadata:
+  name: %s
+spec:
+  template:
+    metadata:
+      annotations:
+        autoscaling.knative.dev/maxScale: "10"
+    spec:
+      containerConcurrency: 80
+      containers:
+        - image: IMAGE
+          ports:
+            - containerPort: 3000
+          env:
+            - name: SERVER_HOST
+              value: 0.0.0.0
+          startupProbe:
+            httpGet:
+              path: /readyz
+          livenessProbe:
+            httpGet:
+              path: /healthz
+`, p.App)
+}
+
+var deployCmd = &cobra.Command{
+	Use:   "deploy [fly|railway|render|cloudrun]",
+	Short: "Build, push and deploy the app to a hosting platform",
+	Long: `Generates the platform config (fly.toml, railway.json, render.yaml or service.yaml)
+and a Dockerfile on first use, then builds and pushes the image, syncs variables
+from the env file and deploys. Use --dry-run to print the steps without running them.`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"fly", "railway", "render", "cloudrun"},
+	Run: func(cmd *cobra.Command, args []string) {
+		target, ok := deployTargets[args[0]]
+		if !ok {
+			fmt.Printf("Unknown deploy target '%s' (expected fly, railway, render or cloudrun)\n", args[0])
+			return
+		}
+		env, err := readEnvFile(deployEnvFile)
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", deployEnvFile, err)
+			return
+		}
+		p := deployPlan{App: deployApp, Region: deployRegion, Env: env}
+		if p.App == "" {
+			p.App = defaultImage()
+		}
+		if p.Region == "" {
+			p.Region = target.Region
+		}
+		if !target.FromSource {
+			repo := deployImage
+			if repo == "" && target.Registry != nil {
+				repo = target.Registry(p)
+			}
+			if repo == "" {
+				fmt.Printf("%s runs a prebuilt image: pass --image with the repository to push to, e.g. ghcr.io/acme/%s\n", target.Name, p.App)
+				return
+			}
+			version, _ := buildVersion()
+			p.Image = repo + ":" + imageTag(version)
+		}
+
+		files := append(missingFile(codegen.File{Path: target.ConfigFile, Content: target.Config(p)}), dockerfileFiles()...)
+		steps, err := target.Steps(p)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if deployDryRun {
+			for _, f := range files {
+				fmt.Printf("Would create %s\n", f.Path)
+			}
+			for _, s := range steps {
+				fmt.Println(s.Describe)
+			}
+			return
+		}
+		// Write the config first: the platform may need it to create the service
+		if len(files) > 0 && !writeGenerated(files...) {
+			return
+		}
+		for _, tool := range target.Tools {
+			if _, err := exec.LookPath(tool); err != nil {
+				fmt.Printf("'%s' is required to deploy to %s but was not found in PATH.\n", tool, target.Name)
+				return
+			}
+		}
+		if target.Check != nil {
+			if err := target.Check(); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		for _, s := range steps {
+			if err := s.Run(); err != nil {
+				fmt.Printf("Deploy to %s failed: %v\n", target.Name, err)
+				return
+			}
+		}
+		fmt.Printf("Deployed %s to %s\n", p.App, target.Name)
+	},
+}
+
+func init() {
+	deployCmd.Flags().BoolVar(&deployDryRun, "dry-run", false, "Print the files and steps without running anything")
+	deployCmd.Flags().StringVar(&deployApp, "app", "", "App or service name on the platform (defaults to the app name)")
+	deployCmd.Flags().StringVar(&deployImage, "image", "", "Image repository to push to (required for render and cloudrun)")
+	deployCmd.Flags().StringVar(&deployRegion, "region", "", "Region (fly: primary region, cloudrun: Cloud Run region)")
+	deployCmd.Flags().StringVar(&deployEnvFile, "env-file", ".env", "Variables to sync to the platform")
+	rootCmd.AddCommand(deployCmd)
+}
@@ -0,0 +1,404 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// depsDryRun, depsMajor, depsKeep and depsOnly are set by `gonext deps upgrade --dry-run/--major/--keep/--only`
+var depsDryRun bool
+var depsMajor bool
+var depsKeep bool
+var depsOnly []string
+
+// frameworkDeps are the module path prefixes of the libraries scaffolded projects build on
+var frameworkDeps = map[string][]string{
+	"fiber":     {"github.com/gofiber/"},
+	"gorm":      {"gorm.io/", "github.com/jinzhu/gorm"},
+	"validator": {"github.com/go-playground/validator/", "gopkg.in/go-playground/validator."},
+}
+
+// listedModule is an entry of `go list -m -u -json`
+type listedModule struct {
+	Path     string
+	Version  string
+	Main     bool
+	Indirect bool
+	Update   *struct{ Version string }
+}
+
+// rewrite is a source change made by a migration
+type rewrite struct {
+	pattern *regexp.Regexp
+	replace string
+}
+
+// migration moves the code importing a library to its next major version
+type migration struct {
+	library  string
+	from     string            // module path migrated away from
+	to       string            // module path migrated to
+	imports  map[string]string // import path prefixes to replace
+	rewrites []rewrite         // applied to the files importing from
+	manual   []string          // breaking changes left to do by hand
+}
+
+// migrations are the codemods applied by --major
+var migrations = []migration{
+	{
+		library: "fiber",
+		from:    "github.com/gofiber/fiber/v2",
+		to:      "github.com/gofiber/fiber/v3",
+		imports: map[string]string{"github.com/gofiber/fiber/v2": "github.com/gofiber/fiber/v3"},
+		rewrites: []rewrite{
+			{regexp.MustCompile(`\*fiber\.Ctx\b`), "fiber.Ctx"},
+			{regexp.MustCompile(`\.BodyParser\(`), ".Bind().Body("},
+			{regexp.MustCompile(`\.QueryParser\(`), ".Bind().Query("},
+			{regexp.MustCompile(`\.ReqHeaderParser\(`), ".Bind().Header("},
+			{regexp.MustCompile(`\.ParamsParser\(`), ".Bind().URI("},
+		},
+		manual: []string{
+			"app.Listen takes a fiber.ListenConfig: move Prefork and DisableStartupMessage there from fiber.Config",
+			"c.Redirect(url) is c.Redirect().To(url)",
+			"middleware config fields changed: check every github.com/gofiber/fiber/v3/middleware import",
+		},
+	},
+	{
+		library: "gorm",
+		from:    "github.com/jinzhu/gorm",
+		to:      "gorm.io/gorm",
+		imports: map[string]string{
+			"github.com/jinzhu/gorm/dialects/postgres": "gorm.io/driver/postgres",
+			"github.com/jinzhu/gorm/dialects/mysql":    "gorm.io/driver/mysql",
+			"github.com/jinzhu/gorm/dialects/sqlite":   "gorm.io/driver/sqlite",
+			"github.com/jinzhu/gorm/dialects/mssql":    "gorm.io/driver/sqlserver",
+			"github.com/jinzhu/gorm":                   "gorm.io/gorm",
+		},
+		manual: []string{
+			"gorm.Open takes a dialector: gorm.Open(postgres.Open(dsn), &gorm.Config{})",
+			"RecordNotFound() is errors.Is(err, gorm.ErrRecordNotFound)",
+			"db.Close() is gone: close the *sql.DB returned by db.DB()",
+			"Count takes an *int64",
+		},
+	},
+	{
+		library: "validator",
+		from:    "gopkg.in/go-playground/validator.v9",
+		to:      "github.com/go-playground/validator/v10",
+		imports: map[string]string{"gopkg.in/go-playground/validator.v9": "github.com/go-playground/validator/v10"},
+	},
+	{
+		library: "validator",
+		from:    "gopkg.in/go-playground/validator.v8",
+		to:      "github.com/go-playground/validator/v10",
+		imports: map[string]string{"gopkg.in/go-playground/validator.v8": "github.com/go-playground/validator/v10"},
+		rewrites: []rewrite{
+			{regexp.MustCompile(`validator\.New\(&validator\.Config\{[^}]*\}\)`), "validator.New()"},
+		},
+		manual: []string{
+			"Struct returns validator.ValidationErrors as an error: type-assert it instead of using the returned map",
+		},
+	},
+}
+
+// depUpdate is a dependency bump planned by `gonext deps upgrade`
+type depUpdate struct {
+	library   string
+	path      string // module path required after the update
+	from      string // current version
+	to        string // target version
+	migration *migration
+}
+
+// depLibrary returns the library a module path belongs to, or "" for other modules
+func depLibrary(path string) string {
+	for library, prefixes := range frameworkDeps {
+		for _, p := range prefixes {
+			if strings.HasPrefix(path, p) {
+				return library
+			}
+		}
+	}
+	return ""
+}
+
+// goListModules returns the modules of the build list with their available updates
+func goListModules(args ...string) ([]listedModule, error) {
+	var stderr bytes.Buffer
+	c := exec.Command("go", append([]string{"list", "-m", "-json"}, args...)...)
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %v\n%s", err, stderr.String())
+	}
+	var modules []listedModule
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		var m listedModule
+		if err := dec.Decode(&m); err != nil {
+			return nil, err
+		}
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
+
+// planDepUpdates returns the updates of the direct framework dependencies, and with
+// major the migrations to their next major versions
+func planDepUpdates(only map[string]bool, major bool) ([]depUpdate, error) {
+	modules, err := goListModules("-u", "all")
+	if err != nil {
+		return nil, err
+	}
+	required := map[string]listedModule{}
+	for _, m := range modules {
+		if !m.Main && !m.Indirect {
+			required[m.Path] = m
+		}
+	}
+	var updates []depUpdate
+	for _, m := range required {
+		library := depLibrary(m.Path)
+		if library == "" || len(only) > 0 && !only[library] || m.Update == nil {
+			continue
+		}
+		updates = append(updates, depUpdate{library: library, path: m.Path, from: m.Version, to: m.Update.Version})
+	}
+	if major {
+		for i := range migrations {
+			c := &migrations[i]
+			m, ok := required[c.from]
+			if !ok || len(only) > 0 && !only[c.library] {
+				continue
+			}
+			latest, err := goListModules(c.to + "@latest")
+			if err != nil || len(latest) == 0 {
+				return nil, fmt.Errorf("could not resolve %s: %v", c.to, err)
+			}
+			// The migration replaces the bump within the old major version
+			for j := range updates {
+				if updates[j].path == c.from {
+					updates = append(updates[:j], updates[j+1:]...)
+					break
+				}
+			}
+			updates = append(updates, depUpdate{library: c.library, path: c.to, from: c.from + "@" + m.Version, to: latest[0].Version, migration: c})
+		}
+	}
+	sort.Slice(updates, func(i, j int) bool { return updates[i].path < updates[j].path })
+	return updates, nil
+}
+
+// importsPath reports whether a Go file imports path or one of its packages
+func importsPath(file string, src []byte, path string) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), file, src, parser.ImportsOnly)
+	if err != nil {
+		return false
+	}
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		if p == path || strings.HasPrefix(p, path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// applyMigration rewrites the imports and calls of a Go source file, returning whether it changed
+func applyMigration(c *migration, file string, src []byte) ([]byte, bool) {
+	if !importsPath(file, src, c.from) {
+		return src, false
+	}
+	out := string(src)
+	// Longest prefixes first, so package paths win over their module path
+	prefixes := make([]string, 0, len(c.imports))
+	for p := range c.imports {
+		prefixes = append(prefixes, p)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	for _, p := range prefixes {
+		out = regexp.MustCompile(`"`+regexp.QuoteMeta(p)+`(/[^"]*)?"`).ReplaceAllString(out, `"`+c.imports[p]+`$1"`)
+	}
+	for _, r := range c.rewrites {
+		out = r.pattern.ReplaceAllString(out, r.replace)
+	}
+	return []byte(out), out != string(src)
+}
+
+// projectGoFiles returns the Go files of the project, without vendored and hidden directories
+func projectGoFiles() ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != "." && (strings.HasPrefix(d.Name(), ".") || d.Name() == "vendor" || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".go") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+var depsCmd = &cobra.Command{
+	Use:   "deps",
+	Short: "Manage the framework dependencies of the project",
+}
+
+var depsUpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Update Fiber, GORM and validator, then build and test the project",
+	Long: `Finds newer versions of the Fiber, GORM and validator modules required by go.mod,
+updates them with 'go get', tidies go.mod and runs 'go build ./...' and 'go test ./...'.
+If the build or the tests fail, go.mod, go.sum and the rewritten files are restored
+(keep them with --keep).
+
+Updates stay within the current major version. With --major, projects are also
+migrated to the next major version, and codemods rewrite the breaking changes that
+can be done mechanically:
+
+  fiber      github.com/gofiber/fiber/v2 to v3: imports, *fiber.Ctx, BodyParser and friends
+  gorm       github.com/jinzhu/gorm to gorm.io/gorm: imports and dialect drivers
+  validator  gopkg.in/go-playground/validator.v8/v9 to v10: imports and validator.New
+
+The changes left to do by hand are listed after the upgrade.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat("go.mod"); err != nil {
+			fmt.Println("No go.mod in the current directory. Run this command from the project root.")
+			return
+		}
+		only := map[string]bool{}
+		for _, library := range depsOnly {
+			if _, ok := frameworkDeps[library]; !ok {
+				fmt.Printf("Unknown library '%s' (expected fiber, gorm or validator)\n", library)
+				return
+			}
+			only[library] = true
+		}
+		updates, err := planDepUpdates(only, depsMajor)
+		if err != nil {
+			fmt.Printf("Error checking for updates: %v\n", err)
+			return
+		}
+		if len(updates) == 0 {
+			fmt.Println("Framework dependencies are up to date")
+			return
+		}
+		for _, u := range updates {
+			note := ""
+			if u.migration != nil {
+				note = "  (codemod)"
+			}
+			fmt.Printf("  %-10s %s %s -> %s%s\n", u.library, u.path, u.from, u.to, note)
+		}
+		if depsDryRun {
+			return
+		}
+
+		// Everything the upgrade touches, to restore it if the build or the tests fail
+		backup := map[string][]byte{}
+		for _, f := range []string{"go.mod", "go.sum"} {
+			if data, err := os.ReadFile(f); err == nil {
+				backup[f] = data
+			}
+		}
+		restore := func() {
+			for f, data := range backup {
+				os.WriteFile(f, data, 0644)
+			}
+		}
+
+		files, err := projectGoFiles()
+		if err != nil {
+			fmt.Printf("Error listing Go files: %v\n", err)
+			return
+		}
+		var manual []string
+		for _, u := range updates {
+			if u.migration == nil {
+				continue
+			}
+			changed := 0
+			for _, file := range files {
+				src, err := os.ReadFile(file)
+				if err != nil {
+					continue
+				}
+				out, ok := applyMigration(u.migration, file, src)
+				if !ok {
+					continue
+				}
+				if _, saved := backup[file]; !saved {
+					backup[file] = src
+				}
+				if err := os.WriteFile(file, out, 0644); err != nil {
+					fmt.Printf("Error writing %s: %v\n", file, err)
+					restore()
+					return
+				}
+				changed++
+			}
+			fmt.Printf("Codemod %s -> %s rewrote %d file(s)\n", u.migration.from, u.migration.to, changed)
+			manual = append(manual, u.migration.manual...)
+		}
+
+		getArgs := []string{"get"}
+		for _, u := range updates {
+			getArgs = append(getArgs, u.path+"@"+u.to)
+		}
+		steps := [][]string{getArgs, {"mod", "tidy"}, {"build", "./..."}, {"test", "./..."}}
+		for _, step := range steps {
+			if err := runCommand(nil, "go", step...); err != nil {
+				if depsKeep {
+					fmt.Printf("'go %s' failed: %v. The changes were kept (--keep).\n", strings.Join(step, " "), err)
+				} else {
+					restore()
+					fmt.Printf("'go %s' failed: %v. go.mod, go.sum and the rewritten files were restored.\n", strings.Join(step, " "), err)
+				}
+				if len(manual) > 0 {
+					fmt.Println("Breaking changes the codemods don't handle:")
+				}
+				for _, m := range manual {
+					fmt.Printf("  - %s\n", m)
+				}
+				os.Exit(1)
+			}
+		}
+		fmt.Printf("%d dependency update(s) applied, build and tests pass\n", len(updates))
+		if len(manual) > 0 {
+			fmt.Println("Breaking changes the codemods don't handle:")
+			for _, m := range manual {
+				fmt.Printf("  - %s\n", m)
+			}
+		}
+	},
+}
+
+func init() {
+	depsUpgradeCmd.Flags().BoolVar(&depsDryRun, "dry-run", false, "List the updates without applying them")
+	depsUpgradeCmd.Flags().BoolVar(&depsMajor, "major", false, "Also migrate to new major versions, applying the codemods")
+	depsUpgradeCmd.Flags().BoolVar(&depsKeep, "keep", false, "Keep the changes when the build or the tests fail")
+	depsUpgradeCmd.Flags().StringSliceVar(&depsOnly, "only", nil, "Only upgrade these libraries: fiber, gorm, validator")
+	depsCmd.AddCommand(depsUpgradeCmd)
+	rootCmd.AddCommand(depsCmd)
+}
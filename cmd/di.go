@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/audit"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// diJSON is set by `gonext di check --json`
+var diJSON bool
+
+// Rules of `gonext di check`
+var (
+	diInvalidTagRule    = audit.Rule{ID: "invalid-inject", Description: "Inject tag without a provider name"}
+	diUnboundNameRule   = audit.Rule{ID: "unbound-name", Description: "Field injected by name without a provider bound under that name"}
+	diDuplicateNameRule = audit.Rule{ID: "duplicate-name", Description: "Provider name bound more than once"}
+	diUnknownTypeRule   = audit.Rule{ID: "unknown-type", Description: "Field injected by type whose type is not declared"}
+	diAmbiguousTypeRule = audit.Rule{ID: "ambiguous-type", Description: "Field injected by a type name that several modules declare"}
+)
+
+// diFinding builds a finding of `gonext di check`
+func diFinding(rule audit.Rule, level, file string, line int, format string, args ...any) audit.Finding {
+	return audit.Finding{Rule: rule, RuleID: rule.ID, Level: level, Message: fmt.Sprintf(format, args...), File: file, Line: line}
+}
+
+// diCheck validates that every injected field resolves to exactly one provider
+func diCheck() ([]audit.Finding, error) {
+	injections, err := workspace.Injections()
+	if err != nil {
+		return nil, err
+	}
+	types, err := workspace.DeclaredTypes()
+	if err != nil {
+		return nil, err
+	}
+	providers, err := workspace.Providers()
+	if err != nil {
+		return nil, err
+	}
+	var findings []audit.Finding
+	bound := map[string][]workspace.Provider{}
+	for _, p := range providers {
+		bound[p.Name] = append(bound[p.Name], p)
+	}
+	names := make([]string, 0, len(bound))
+	for name := range bound {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ps := bound[name]; len(ps) > 1 {
+			var others []string
+			for _, p := range ps[1:] {
+				others = append(others, fmt.Sprintf("%s:%d", p.File, p.Line))
+			}
+			findings = append(findings, diFinding(diDuplicateNameRule, audit.Warning, ps[0].File, ps[0].Line,
+				"%q is also bound at %s; the last binding wins", name, strings.Join(others, ", ")))
+		}
+	}
+	// Component types by name, to find names injection by type can't tell apart
+	components := map[string][]string{}
+	for id := range types {
+		dir, name, _ := strings.Cut(id, ".")
+		if parts := strings.Split(dir, "/"); len(parts) == 2 && (parts[1] == "controller" || parts[1] == "service" || parts[1] == "repository") {
+			components[name] = append(components[name], dir)
+		}
+	}
+	for _, in := range injections {
+		field := fmt.Sprintf("%s.%s", path.Base(in.Owner), in.Field)
+		switch {
+		case in.Name == "" && in.Tag != "type":
+			findings = append(findings, diFinding(diInvalidTagRule, audit.Error, in.File, in.Line,
+				"%s has inject:%q; use inject:\"type\" or inject:\"name=<provider>\"", field, in.Tag))
+		case in.Name != "":
+			if len(bound[in.Name]) == 0 {
+				findings = append(findings, diFinding(diUnboundNameRule, audit.Error, in.File, in.Line,
+					"%s injects %q, which nothing binds; add container.Bind(%q, ...) at startup", field, in.Name, in.Name))
+			}
+		case strings.Contains(in.Type, "/"):
+			if _, ok := types[in.Type]; !ok {
+				findings = append(findings, diFinding(diUnknownTypeRule, audit.Error, in.File, in.Line,
+					"%s injects %s, which is not declared", field, in.Type))
+				continue
+			}
+			_, name, _ := strings.Cut(in.Type, ".")
+			if dirs := components[name]; len(dirs) > 1 {
+				sort.Strings(dirs)
+				findings = append(findings, diFinding(diAmbiguousTypeRule, audit.Warning, in.File, in.Line,
+					"%s injects %s by type, but %s all declare %s; rename one or bind it and use inject:\"name=<provider>\"", field, in.Type, strings.Join(dirs, ", "), name))
+			}
+		}
+	}
+	audit.Sort(findings)
+	return findings, nil
+}
+
+var diCmd = &cobra.Command{
+	Use:   "di",
+	Short: "Inspect dependency injection",
+}
+
+var diCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check that every injected field resolves to exactly one provider",
+	Long: `Statically checks the inject tags of the structs in app/:
+
+  inject:"type"             the type must be declared, and no other module may
+                            declare a component with the same name
+  inject:"name=primaryDB"   a provider must be bound under the name, by a
+  inject:"queue"            container.Bind("primaryDB", ...) call or an entry of
+                            database.NamedConnections
+
+Names bound more than once are reported as warnings. The command exits non-zero
+when an injection can't be resolved.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		findings, err := diCheck()
+		if err != nil {
+			fmt.Printf("Error scanning the project: %v\n", err)
+			os.Exit(1)
+		}
+		if diJSON {
+			if err := audit.WriteJSON(os.Stdout, findings); err != nil {
+				fmt.Printf("Error writing the report: %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			writeAuditText(os.Stdout, findings)
+		}
+		if audit.AtLeast(findings, audit.Error) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	diCheckCmd.Flags().BoolVar(&diJSON, "json", false, "Output the findings as JSON")
+	diCmd.AddCommand(diCheckCmd)
+	rootCmd.AddCommand(diCmd)
+}
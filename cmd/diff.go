@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+var diffPatch bool
+var diffAll bool
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [paths...]",
+	Short: "Show which scaffold files have drifted from their recorded template version",
+	Long:  `Compares the project against the template baseline recorded in .gonext/ when it was scaffolded or generated. Modified files are what you have customized; unchanged files are safe for 'gonext upgrade' to replace.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		state, err := codegen.LoadState()
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", codegen.StateFile, err)
+			return
+		}
+		if len(state.Files) == 0 {
+			fmt.Println("No template baseline recorded for this project. Run this command from a project created with 'gonext new'.")
+			return
+		}
+		if state.Template != nil {
+			fmt.Printf("Template: %s@%s\n", state.Template.Repo, state.Template.Commit)
+		}
+
+		filter := map[string]bool{}
+		for _, a := range args {
+			filter[a] = true
+		}
+		counts := map[codegen.Drift]int{}
+		for _, res := range state.Drift() {
+			if len(filter) > 0 && !filter[res.Path] {
+				continue
+			}
+			counts[res.Status]++
+			if res.Status == codegen.Unchanged && !diffAll {
+				continue
+			}
+			fmt.Printf("  %-10s %s\n", res.Status, res.Path)
+			if diffPatch && res.Status == codegen.Modified {
+				base, _ := state.Base(res.Path)
+				current, err := os.ReadFile(res.Path)
+				if err != nil {
+					fmt.Printf("Error reading %s: %v\n", res.Path, err)
+					continue
+				}
+				fmt.Print(codegen.UnifiedDiff("template/"+res.Path, res.Path, base, string(current)))
+			}
+		}
+		fmt.Printf("%d modified, %d missing, %d unchanged\n", counts[codegen.Modified], counts[codegen.Missing], counts[codegen.Unchanged])
+	},
+}
+
+func init() {
+	diffCmd.Flags().BoolVar(&diffPatch, "patch", false, "Show a unified diff for each modified file")
+	diffCmd.Flags().BoolVar(&diffAll, "all", false, "Also list unchanged files")
+	rootCmd.AddCommand(diffCmd)
+}
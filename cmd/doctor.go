@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+// doctorCheck inspects one aspect of the development setup. It returns a
+// one-line summary when everything is fine and the problems otherwise.
+type doctorCheck struct {
+	name string
+	run  func(m *manifest.Manifest) (string, []string)
+}
+
+var doctorChecks = []doctorCheck{
+	{"go toolchain", func(m *manifest.Manifest) (string, []string) {
+		local, err := localGoVersion()
+		if err != nil {
+			return "", []string{err.Error()}
+		}
+		if m.Go == "" {
+			return fmt.Sprintf("Go %s (no version required in %s, record one with 'gonext fix go-version')", local, manifest.FileName), nil
+		}
+		if warning := goToolchainWarning(m.Go); warning != "" {
+			return "", []string{warning}
+		}
+		return fmt.Sprintf("Go %s as required by %s", local, manifest.FileName), nil
+	}},
+	{"go directives", func(m *manifest.Manifest) (string, []string) {
+		if m.Go == "" {
+			return "not checked without a Go version in " + manifest.FileName, nil
+		}
+		if problems := goDirectiveProblems(m.Go); len(problems) > 0 {
+			return "", append(problems, "run 'gonext fix go-version' to update them")
+		}
+		return fmt.Sprintf("%d go.mod/go.work file(s) agree on Go %s", len(workspaceGoFiles()), m.Go), nil
+	}},
+	{"git", func(m *manifest.Manifest) (string, []string) {
+		if _, err := exec.LookPath("git"); err != nil {
+			return "", []string{"'git' is not installed: 'gonext new' and version stamping need it"}
+		}
+		return "installed", nil
+	}},
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the local toolchain and project setup against gonext.yaml",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		m, err := manifest.Load()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		failed := 0
+		for _, c := range doctorChecks {
+			summary, problems := c.run(m)
+			if len(problems) == 0 {
+				fmt.Printf("  ok         %-14s %s\n", c.name, summary)
+				continue
+			}
+			failed++
+			fmt.Printf("  problem    %-14s %s\n", c.name, problems[0])
+			for _, p := range problems[1:] {
+				fmt.Printf("  %-10s %-14s %s\n", "", "", p)
+			}
+		}
+		if failed > 0 {
+			fmt.Printf("%d check(s) failed\n", failed)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// drainURL and drainToken are set by `gonext drain --url/--token`
+var drainURL string
+var drainToken string
+
+var drainCmd = &cobra.Command{
+	Use:   "drain",
+	Short: "Tell a running instance to report unready so traffic shifts away before it stops",
+	Long: `Calls POST /drain on an instance generated with 'gonext g health'. Its /readyz
+then returns 503, so the load balancer stops routing new requests to it while
+in-flight requests finish. The token defaults to $HEALTH_DRAIN_TOKEN.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		token := drainToken
+		if token == "" {
+			token = os.Getenv("HEALTH_DRAIN_TOKEN")
+		}
+		if token == "" {
+			fmt.Println("A drain token is required: pass --token or set HEALTH_DRAIN_TOKEN.")
+			return
+		}
+		req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(drainURL, "/")+"/drain", nil)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		client := &http.Client{Timeout: 10 * time.Second}
+		resp, err := client.Do(req)
+		if err != nil {
+			fmt.Printf("Error contacting %s: %v\n", drainURL, err)
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			body, _ := io.ReadAll(resp.Body)
+			fmt.Printf("Drain failed: %s %s\n", resp.Status, strings.TrimSpace(string(body)))
+			return
+		}
+		fmt.Printf("%s is draining; /readyz now reports unready.\n", drainURL)
+	},
+}
+
+func init() {
+	drainCmd.Flags().StringVar(&drainURL, "url", "http://localhost:3000", "Base URL of the instance to drain")
+	drainCmd.Flags().StringVar(&drainToken, "token", "", "Drain token (defaults to $HEALTH_DRAIN_TOKEN)")
+	rootCmd.AddCommand(drainCmd)
+}
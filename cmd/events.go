@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/openapi"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// eventsFormat and eventsOutput are set by `gonext events catalog --format/--output`
+var eventsFormat string
+var eventsOutput string
+
+// eventsDefaultOutputs are the catalog files written when --output is not set
+var eventsDefaultOutputs = map[string]string{
+	"markdown": filepath.Join("docs", "events.md"),
+	"asyncapi": filepath.Join("docs", "asyncapi.json"),
+}
+
+// asyncAPIDocument is an AsyncAPI 2.6 document, with its keys in the usual order
+type asyncAPIDocument struct {
+	AsyncAPI   string                     `json:"asyncapi"`
+	Info       asyncAPIInfo               `json:"info"`
+	Channels   map[string]asyncAPIChannel `json:"channels"`
+	Components asyncAPIComponents         `json:"components"`
+}
+
+type asyncAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// asyncAPIChannel describes a topic. In AsyncAPI 2, subscribe is what the
+// application sends (clients subscribe to it) and publish is what it receives.
+type asyncAPIChannel struct {
+	Description string               `json:"description,omitempty"`
+	Subscribe   *asyncAPIOperation   `json:"subscribe,omitempty"`
+	Publish     *asyncAPIOperation   `json:"publish,omitempty"`
+	Producers   []workspace.EventUse `json:"x-producers,omitempty"`
+	Consumers   []workspace.EventUse `json:"x-consumers,omitempty"`
+}
+
+type asyncAPIOperation struct {
+	OperationID string         `json:"operationId"`
+	Message     map[string]any `json:"message"`
+}
+
+type asyncAPIComponents struct {
+	Messages map[string]asyncAPIMessage `json:"messages,omitempty"`
+	Schemas  map[string]*openapi.Schema `json:"schemas,omitempty"`
+}
+
+type asyncAPIMessage struct {
+	Name    string          `json:"name"`
+	Summary string          `json:"summary,omitempty"`
+	Payload *openapi.Schema `json:"payload"`
+}
+
+// eventSchemas returns the schemas of the event payloads and of the DTOs and entities they may reference
+func eventSchemas() (map[string]*openapi.Schema, error) {
+	patterns := slices.Clone(openapi.SchemaSources)
+	for _, dir := range workspace.EventDirs {
+		patterns = append(patterns, filepath.Join(workspace.AppDir, "*", dir, "*.go"))
+	}
+	all := map[string]*openapi.Schema{}
+	for _, pattern := range patterns {
+		schemas, err := openapi.StructSchemas(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for name, s := range schemas {
+			all[name] = s
+		}
+	}
+	return all, nil
+}
+
+// referencedSchemas returns the payload schemas and every schema they reference
+func referencedSchemas(events []workspace.Event, all map[string]*openapi.Schema) map[string]*openapi.Schema {
+	used := map[string]*openapi.Schema{}
+	var collect func(name string)
+	collect = func(name string) {
+		s := all[name]
+		if s == nil || used[name] != nil {
+			return
+		}
+		used[name] = s
+		var walk func(s *openapi.Schema)
+		walk = func(s *openapi.Schema) {
+			if s == nil {
+				return
+			}
+			if s.Ref != "" {
+				collect(refName(s.Ref))
+			}
+			walk(s.Items)
+			for _, p := range s.Properties {
+				walk(p)
+			}
+		}
+		walk(s)
+	}
+	for _, e := range events {
+		collect(e.Payload)
+	}
+	return used
+}
+
+// eventUseList renders the producers or consumers of a topic as Markdown list items
+func eventUseList(uses []workspace.EventUse) string {
+	if len(uses) == 0 {
+		return "- _none found_\n"
+	}
+	var b strings.Builder
+	for _, u := range uses {
+		b.WriteString(fmt.Sprintf("- %s: `%s` (%s:%d)\n", u.Module, u.Function, filepath.ToSlash(u.File), u.Line))
+	}
+	return b.String()
+}
+
+// schemaTypeName describes a property type for the Markdown catalog, e.g. array of Address
+func schemaTypeName(s *openapi.Schema) string {
+	switch {
+	case s.Ref != "":
+		return refName(s.Ref)
+	case s.Type == "array" && s.Items != nil:
+		return "array of " + schemaTypeName(s.Items)
+	case s.Format != "":
+		return s.Type + " (" + s.Format + ")"
+	case len(s.Enum) > 0:
+		var values []string
+		for _, v := range s.Enum {
+			values = append(values, fmt.Sprint(v))
+		}
+		return s.Type + ": " + strings.Join(values, ", ")
+	}
+	return s.Type
+}
+
+// markdownCatalog renders the event catalog as a Markdown document
+func markdownCatalog(events []workspace.Event, schemas map[string]*openapi.Schema) string {
+	var b strings.Builder
+	b.WriteString("# Event Catalog\n\n")
+	b.WriteString("Generated by `gonext events catalog` from the event payloads in `app/<module>/event` and the\n")
+	b.WriteString("Publish/Produce/Emit and Subscribe/Consume calls of every module. Do not edit by hand.\n\n")
+	b.WriteString("| Topic | Payload | Producers | Consumers |\n|---|---|---|---|\n")
+	for _, e := range events {
+		payload := e.Payload
+		if payload == "" {
+			payload = "-"
+		}
+		b.WriteString(fmt.Sprintf("| [%s](#%s) | %s | %d | %d |\n", e.Topic, markdownAnchor(e.Topic), payload, len(e.Producers), len(e.Consumers)))
+	}
+	for _, e := range events {
+		b.WriteString(fmt.Sprintf("\n## %s\n\n", e.Topic))
+		if e.Description != "" {
+			b.WriteString(e.Description + "\n\n")
+		}
+		if s := schemas[e.Payload]; s != nil {
+			b.WriteString(fmt.Sprintf("Payload: `%s` (%s)\n\n", e.Payload, filepath.ToSlash(e.File)))
+			b.WriteString("| Field | Type | Required | Description |\n|---|---|---|---|\n")
+			for _, name := range s.PropertyOrder {
+				p := s.Properties[name]
+				required := ""
+				if slices.Contains(s.Required, name) {
+					required = "yes"
+				}
+				description := p.Description
+				if p.Deprecated {
+					description = strings.TrimSpace("Deprecated. " + description)
+				}
+				b.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s |\n", name, schemaTypeName(p), required, description))
+			}
+			b.WriteString("\n")
+		} else {
+			b.WriteString("Payload: no struct declares this topic. Add one to `app/<module>/event` with a `Topic:` line in its doc comment.\n\n")
+		}
+		b.WriteString("Producers:\n\n" + eventUseList(e.Producers) + "\n")
+		b.WriteString("Consumers:\n\n" + eventUseList(e.Consumers))
+	}
+	return b.String()
+}
+
+// markdownAnchor returns the heading anchor GitHub generates for a topic
+func markdownAnchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// asyncAPICatalog renders the event catalog as an AsyncAPI document. Producers and
+// consumers from every module are listed on the channel as x-producers and x-consumers.
+func asyncAPICatalog(events []workspace.Event, schemas map[string]*openapi.Schema) asyncAPIDocument {
+	version, _ := buildVersion()
+	doc := asyncAPIDocument{
+		AsyncAPI: "2.6.0",
+		Info:     asyncAPIInfo{Title: getModuleName() + " events", Version: version},
+		Channels: map[string]asyncAPIChannel{},
+		Components: asyncAPIComponents{
+			Messages: map[string]asyncAPIMessage{},
+			Schemas:  schemas,
+		},
+	}
+	for _, e := range events {
+		message := map[string]any{"name": e.Topic}
+		if schemas[e.Payload] != nil {
+			doc.Components.Messages[e.Payload] = asyncAPIMessage{
+				Name:    e.Payload,
+				Summary: e.Description,
+				Payload: &openapi.Schema{Ref: "#/components/schemas/" + e.Payload},
+			}
+			message = map[string]any{"$ref": "#/components/messages/" + e.Payload}
+		}
+		channel := asyncAPIChannel{Description: e.Description, Producers: e.Producers, Consumers: e.Consumers}
+		id := nonAvroChars.ReplaceAllString(e.Topic, "_")
+		if len(e.Producers) > 0 {
+			channel.Subscribe = &asyncAPIOperation{OperationID: "on_" + id, Message: message}
+		}
+		if len(e.Consumers) > 0 {
+			channel.Publish = &asyncAPIOperation{OperationID: "send_" + id, Message: message}
+		}
+		doc.Channels[e.Topic] = channel
+	}
+	return doc
+}
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Document the events exchanged by the modules of the workspace",
+}
+
+var eventsCatalogCmd = &cobra.Command{
+	Use:   "catalog",
+	Short: "Write a Markdown or AsyncAPI catalog of the topics, payloads, producers and consumers",
+	Long: `Scans every module for event payloads and broker calls:
+
+  - payloads are the exported structs in app/<module>/event(s). The topic is read
+    from a "Topic: users.created" line in the struct's doc comment and defaults
+    to the struct name. Fields are documented from the same struct tags as
+    'gonext openapi'.
+  - producers are Publish, Produce and Emit calls, consumers are Subscribe and
+    Consume calls. The topic is the call's first string argument, or the topic of
+    the payload struct it is passed, e.g. bus.Publish(ctx, event.UserCreated{...}).
+
+The catalog is written to docs/events.md, or docs/asyncapi.json with --format asyncapi.
+Topics without producers or consumers are reported.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, ok := eventsDefaultOutputs[eventsFormat]
+		if !ok {
+			fmt.Printf("Unknown format '%s' (expected markdown or asyncapi)\n", eventsFormat)
+			return
+		}
+		if eventsOutput != "" {
+			output = eventsOutput
+		}
+		events, err := workspace.Events()
+		if err != nil {
+			fmt.Printf("Error scanning events: %v\n", err)
+			return
+		}
+		if len(events) == 0 {
+			fmt.Printf("No events found. Declare payloads in %s/<module>/event or publish with a topic name.\n", workspace.AppDir)
+			return
+		}
+		all, err := eventSchemas()
+		if err != nil {
+			fmt.Printf("Error reading structs: %v\n", err)
+			return
+		}
+		schemas := referencedSchemas(events, all)
+		for _, e := range events {
+			if s := schemas[e.Payload]; s != nil {
+				// Without the Topic: line of the doc comment
+				payload := *s
+				payload.Description = e.Description
+				schemas[e.Payload] = &payload
+			}
+		}
+
+		var data []byte
+		if eventsFormat == "asyncapi" {
+			data, err = json.MarshalIndent(asyncAPICatalog(events, schemas), "", "  ")
+			if err != nil {
+				fmt.Printf("Error encoding the AsyncAPI document: %v\n", err)
+				return
+			}
+			data = append(data, '\n')
+		} else {
+			data = []byte(markdownCatalog(events, schemas))
+		}
+		if output == "-" {
+			fmt.Print(string(data))
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+			fmt.Printf("Error creating %s: %v\n", filepath.Dir(output), err)
+			return
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			fmt.Printf("Error writing %s: %v\n", output, err)
+			return
+		}
+		for _, e := range events {
+			if len(e.Producers) == 0 {
+				fmt.Printf("Warning: no producer publishes %s\n", e.Topic)
+			}
+			if len(e.Consumers) == 0 {
+				fmt.Printf("Warning: no consumer subscribes to %s\n", e.Topic)
+			}
+		}
+		fmt.Printf("Event catalog written to %s (%d topic(s))\n", output, len(events))
+	},
+}
+
+func init() {
+	eventsCatalogCmd.Flags().StringVar(&eventsFormat, "format", "markdown", "Catalog format: markdown or asyncapi")
+	eventsCatalogCmd.Flags().StringVarP(&eventsOutput, "output", "o", "", "Output file ('-' for stdout, default docs/events.md or docs/asyncapi.json)")
+	eventsCmd.AddCommand(eventsCatalogCmd)
+	rootCmd.AddCommand(eventsCmd)
+}
@@ -0,0 +1,370 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// fixturesDir and fixturesTruncate are set by `gonext fixtures load --dir/--truncate`
+var fixturesDir string
+var fixturesTruncate bool
+
+// fixturesCommandFile is the project's fixture loader, invoked by `gonext fixtures load`
+var fixturesCommandFile = filepath.Join("cmd", "fixtures", "main.go")
+
+const dbFixturesTemplate = `package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Fixture is one record to insert. A value of the form {"$ref": "table.label"}
+// is replaced by the id of the record inserted earlier under that label.
+type Fixture struct {
+	Table  string         ` + "`json:\"table\"`" + `
+	Label  string         ` + "`json:\"label\"`" + `
+	Values map[string]any ` + "`json:\"values\"`" + `
+}
+
+// LoadFixtures inserts fixtures in order, in one transaction, and returns the
+// id of every record by "table.label". Tables need an id column. With truncate,
+// the fixtures' tables are emptied first and their sequences reset.
+func LoadFixtures(ctx context.Context, db *sql.DB, fixtures []Fixture, truncate bool) (map[string]any, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if truncate {
+		var tables []string
+		seen := map[string]bool{}
+		for _, f := range fixtures {
+			if !seen[f.Table] {
+				seen[f.Table] = true
+				tables = append(tables, quoteIdent(f.Table))
+			}
+		}
+		if len(tables) > 0 {
+			if _, err := tx.ExecContext(ctx, "TRUNCATE "+strings.Join(tables, ", ")+" RESTART IDENTITY CASCADE"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	ids := map[string]any{}
+	for _, f := range fixtures {
+		var columns, params []string
+		var args []any
+		for column, value := range f.Values {
+			v, err := fixtureValue(value, ids)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", f.Table, f.Label, err)
+			}
+			columns = append(columns, quoteIdent(column))
+			args = append(args, v)
+			params = append(params, fmt.Sprintf("$%d", len(args)))
+		}
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING id", quoteIdent(f.Table), strings.Join(columns, ", "), strings.Join(params, ", "))
+		if len(columns) == 0 {
+			query = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES RETURNING id", quoteIdent(f.Table))
+		}
+		var id any
+		if err := tx.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", f.Table, f.Label, err)
+		}
+		if b, ok := id.([]byte); ok {
+			id = string(b)
+		}
+		ids[f.Table+"."+f.Label] = id
+	}
+	return ids, tx.Commit()
+}
+
+// fixtureValue resolves references and converts JSON values to driver arguments
+func fixtureValue(value any, ids map[string]any) (any, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok && len(v) == 1 {
+			id, ok := ids[ref]
+			if !ok {
+				return nil, fmt.Errorf("reference to %s, which is not loaded yet", ref)
+			}
+			return id, nil
+		}
+		data, err := json.Marshal(v)
+		return string(data), err
+	case []any:
+		data, err := json.Marshal(v)
+		return string(data), err
+	}
+	return value, nil
+}
+
+func quoteIdent(name string) string {
+	return ` + "`\"`" + ` + strings.ReplaceAll(name, ` + "`\"`, `\"\"`" + `) + ` + "`\"`" + `
+}
+`
+
+const fixturesCommandTemplate = `package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"%s/app/database"
+)
+
+// Loads the fixtures prepared by 'gonext fixtures load'
+func main() {
+	truncate := flag.Bool("truncate", false, "empty the fixtures' tables first")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Fatal("usage: fixtures [-truncate] <fixtures.json>")
+	}
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	var fixtures []database.Fixture
+	decoder := json.NewDecoder(f)
+	decoder.UseNumber()
+	if err := decoder.Decode(&fixtures); err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	db, err := database.Open(ctx, database.PoolConfigFromEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	ids, err := database.LoadFixtures(ctx, db, fixtures, *truncate)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("loaded %%d fixture(s)", len(ids))
+}
+`
+
+// fixtureRef matches references to other fixtures, e.g. $users.alice
+var fixtureRef = regexp.MustCompile(`^\$([A-Za-z0-9_]+)\.([A-Za-z0-9_-]+)$`)
+
+// fixture is a record read from fixtures/<table>.yaml or .json
+type fixture struct {
+	Table  string         `json:"table"`
+	Label  string         `json:"label"`
+	Values map[string]any `json:"values"`
+	deps   []string
+}
+
+// readFixtures reads every YAML and JSON fixture file in dir. Each file is named after its
+// table and maps record labels to column values.
+func readFixtures(dir string) (map[string]*fixture, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		return nil, err
+	}
+	fixtures := map[string]*fixture{}
+	for _, file := range files {
+		ext := filepath.Ext(file)
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		records := map[string]map[string]any{}
+		if ext == ".json" {
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.UseNumber()
+			err = decoder.Decode(&records)
+		} else {
+			err = yaml.Unmarshal(data, &records)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		table := strings.TrimSuffix(filepath.Base(file), ext)
+		for label, values := range records {
+			key := table + "." + label
+			if _, ok := fixtures[key]; ok {
+				return nil, fmt.Errorf("%s: %s is defined twice", file, key)
+			}
+			if values == nil {
+				values = map[string]any{}
+			}
+			fixtures[key] = &fixture{Table: table, Label: label, Values: values}
+		}
+	}
+	return fixtures, nil
+}
+
+// resolveFixtures turns $table.label references into {"$ref": "table.label"} and orders the
+// records so every record comes after the ones it references
+func resolveFixtures(fixtures map[string]*fixture) ([]*fixture, error) {
+	keys := make([]string, 0, len(fixtures))
+	for key := range fixtures {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		f := fixtures[key]
+		for column, value := range f.Values {
+			s, ok := value.(string)
+			if !ok {
+				continue
+			}
+			// $$ escapes a literal leading $
+			if strings.HasPrefix(s, "$$") {
+				f.Values[column] = s[1:]
+				continue
+			}
+			m := fixtureRef.FindStringSubmatch(s)
+			if m == nil {
+				continue
+			}
+			ref := m[1] + "." + m[2]
+			if _, ok := fixtures[ref]; !ok {
+				return nil, fmt.Errorf("%s.%s references unknown fixture %s", key, column, s)
+			}
+			f.Values[column] = map[string]string{"$ref": ref}
+			f.deps = append(f.deps, ref)
+		}
+	}
+
+	var ordered []*fixture
+	state := map[string]int{} // 1 while visiting, 2 once ordered
+	var visit func(key string, path []string) error
+	visit = func(key string, path []string) error {
+		switch state[key] {
+		case 1:
+			return fmt.Errorf("fixtures reference each other in a cycle: %s", strings.Join(append(path, key), " -> "))
+		case 2:
+			return nil
+		}
+		state[key] = 1
+		f := fixtures[key]
+		sort.Strings(f.deps)
+		for _, dep := range f.deps {
+			if err := visit(dep, append(path, key)); err != nil {
+				return err
+			}
+		}
+		state[key] = 2
+		ordered = append(ordered, f)
+		return nil
+	}
+	for _, key := range keys {
+		if err := visit(key, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+var fixturesCmd = &cobra.Command{
+	Use:   "fixtures",
+	Short: "Load fixture data into the development or test database",
+}
+
+var fixturesLoadCmd = &cobra.Command{
+	Use:   "load",
+	Short: "Insert the records in fixtures/*.yaml|json, resolving references between them",
+	Long: `Reads fixtures/<table>.yaml (or .yml, .json), each mapping record labels to
+column values, and inserts every record into DATABASE_URL in one transaction.
+A value like $users.alice is replaced by the id of that record, and records are
+inserted after the ones they reference. Refuses to run with APP_ENV=production.
+
+The loader runs through the project's database provider, generated in
+app/database/fixtures.go and cmd/fixtures.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		env, err := readEnvFile(".env")
+		if err != nil {
+			fmt.Printf("Error reading .env: %v\n", err)
+			return
+		}
+		if os.Getenv("APP_ENV") == "production" || env["APP_ENV"] == "production" {
+			fmt.Println("Fixtures are for development and test databases; APP_ENV is production.")
+			return
+		}
+		if _, err := os.Stat(filepath.Join(databaseDir, "provider.go")); os.IsNotExist(err) {
+			fmt.Println("No database provider found. Generate it with 'gonext g db:provider'.")
+			return
+		}
+		fixtures, err := readFixtures(fixturesDir)
+		if err != nil {
+			fmt.Printf("Error reading fixtures: %v\n", err)
+			return
+		}
+		if len(fixtures) == 0 {
+			fmt.Printf("No fixtures found in %s. Add files such as %s.\n", fixturesDir, filepath.Join(fixturesDir, "users.yaml"))
+			return
+		}
+		ordered, err := resolveFixtures(fixtures)
+		if err != nil {
+			fmt.Printf("Error resolving fixtures: %v\n", err)
+			return
+		}
+
+		files := []codegen.File{
+			{Path: filepath.Join(databaseDir, "fixtures.go"), Content: dbFixturesTemplate},
+			{Path: fixturesCommandFile, Content: fmt.Sprintf(fixturesCommandTemplate, getModuleName())},
+		}
+		if !writeGenerated(files...) {
+			return
+		}
+
+		plan, err := os.CreateTemp("", "gonext-fixtures-*.json")
+		if err != nil {
+			fmt.Printf("Error writing fixtures: %v\n", err)
+			return
+		}
+		defer os.Remove(plan.Name())
+		err = json.NewEncoder(plan).Encode(ordered)
+		plan.Close()
+		if err != nil {
+			fmt.Printf("Error writing fixtures: %v\n", err)
+			return
+		}
+		runArgs := []string{"run", "./" + filepath.ToSlash(filepath.Dir(fixturesCommandFile))}
+		if fixturesTruncate {
+			runArgs = append(runArgs, "-truncate")
+		}
+		if err := runCommand(envAssignments(env), "go", append(runArgs, plan.Name())...); err != nil {
+			fmt.Printf("Error loading fixtures: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	fixturesLoadCmd.Flags().StringVar(&fixturesDir, "dir", "fixtures", "Directory holding the fixture files")
+	fixturesLoadCmd.Flags().BoolVar(&fixturesTruncate, "truncate", false, "Empty the fixtures' tables before loading")
+	fixturesCmd.AddCommand(fixturesLoadCmd)
+	rootCmd.AddCommand(fixturesCmd)
+}
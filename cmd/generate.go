@@ -3,9 +3,20 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/Alexigbokwe/gonext/internal/audit"
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/Alexigbokwe/gonext/internal/editor"
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/Alexigbokwe/gonext/internal/progress"
+	"github.com/Alexigbokwe/gonext/internal/scaffolding"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
 	"github.com/spf13/cobra"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -24,6 +35,273 @@ func getModuleName() string {
 	return "myproject"
 }
 
+// moduleOwner is the owning team passed with `g module --owner`
+var moduleOwner string
+
+// moduleDependencies is set by `g module --depends-on`
+var moduleDependencies []string
+
+// moduleDynamic is set by `g module --dynamic`
+var moduleDynamic bool
+
+// modulePrefix and moduleTags are set by `g module --prefix/--tag` and recorded in the manifest
+var modulePrefix string
+var moduleTags []string
+
+// moduleDocs is set by `g module --docs` to emit a README.md and an ADR stub
+var moduleDocs bool
+
+// generateBulk is set by `g controller/repository/module --bulk` to add batch create/update/delete operations
+var generateBulk bool
+
+// repositoryConnection is set by `g repository --connection` to bind the repository to a named database connection
+var repositoryConnection string
+
+// middlewareGlobal is set by `g middleware --global`
+var middlewareGlobal bool
+
+// openGenerated is set by the --open flag shared by all generators
+var openGenerated bool
+
+// allowSecrets is set by the --allow-secrets flag of the generators and `gonext new`
+var allowSecrets bool
+
+// containsSecrets lists the credentials found in rendered files, such as private
+// keys or access keys left in a template, and reports whether any were found
+func containsSecrets(files []codegen.File) bool {
+	found := false
+	for _, f := range files {
+		for _, s := range audit.ObviousSecrets(f.Content) {
+			fmt.Printf("  secret     %s:%d (%s)\n", f.Path, s.Line, s.Kind)
+			found = true
+		}
+	}
+	return found
+}
+
+// strictGenerate is set by the --strict flag of the generators
+var strictGenerate bool
+
+// plannedFiles maps the paths of rendered files to their content
+func plannedFiles(files []codegen.File) map[string][]byte {
+	planned := map[string][]byte{}
+	for _, f := range files {
+		planned[f.Path] = []byte(f.Content)
+	}
+	return planned
+}
+
+// routeConflicts lists the generated routes that duplicate or shadow registered
+// routes and returns how many there are
+func routeConflicts(files []codegen.File) int {
+	routes, err := workspace.PlannedRoutes(plannedFiles(files))
+	if err != nil || len(routes) == 0 {
+		return 0
+	}
+	registered, err := workspace.Routes()
+	if err != nil {
+		return 0
+	}
+	conflicts := workspace.RouteConflicts(routes, registered)
+	for _, c := range conflicts {
+		existing := fmt.Sprintf("%s %s (%s:%d)", c.Existing.Method, c.Existing.Path, c.Existing.File, c.Existing.Line)
+		switch c.Kind {
+		case "duplicate":
+			fmt.Printf("  route      %s %s is already registered by %s\n", c.Route.Method, c.Route.Path, existing)
+		case "shadowed":
+			fmt.Printf("  route      %s %s may be shadowed by %s, depending on registration order\n", c.Route.Method, c.Route.Path, existing)
+		default:
+			fmt.Printf("  route      %s %s may shadow %s, depending on registration order\n", c.Route.Method, c.Route.Path, existing)
+		}
+	}
+	return len(conflicts)
+}
+
+// typeCollisions lists the generated components whose type name another module
+// already declares, with a qualified name to use instead, and returns how many there are
+func typeCollisions(files []codegen.File) int {
+	collisions, err := workspace.TypeCollisions(plannedFiles(files))
+	if err != nil {
+		return 0
+	}
+	for _, c := range collisions {
+		kind := strings.Title(c.Kind)
+		qualified := strings.Title(c.Module) + c.Type
+		fmt.Printf("  type       %s in %s is also declared in %s\n", c.Type, filepath.Dir(c.File), strings.Join(c.Existing, ", "))
+		if base := strings.TrimSuffix(c.Type, kind); base != c.Type {
+			fmt.Printf("             injection by type can't tell them apart: name it %s (gonext g %s %s%s %s), or inject it by name\n", qualified, c.Kind, c.Module, base, c.Module)
+		} else {
+			fmt.Printf("             injection by type can't tell them apart: name it %s, or inject it by name\n", qualified)
+		}
+	}
+	return len(collisions)
+}
+
+// checkConflicts warns about route and component name conflicts of the rendered
+// files and reports whether generation should stop, which --strict requests
+func checkConflicts(files []codegen.File) bool {
+	n := routeConflicts(files) + typeCollisions(files)
+	if n == 0 {
+		return false
+	}
+	if strictGenerate {
+		fmt.Printf("Refusing to write generated files: %d conflict(s). Change the names or route paths, or run without --strict.\n", n)
+		return true
+	}
+	fmt.Printf("Warning: %d conflict(s). Pass --strict to stop generation instead.\n", n)
+	return false
+}
+
+// checkModuleDependencies checks the --depends-on modules of a new or regenerated
+// module against the modules of app/. It warns about unknown dependencies, which
+// --strict refuses, and refuses cycles, which would stop the app at startup.
+// It reports whether generation should stop.
+func checkModuleDependencies(name string, dependsOn []string) bool {
+	if len(dependsOn) == 0 {
+		return false
+	}
+	modules, err := workspace.Modules()
+	if err != nil {
+		fmt.Printf("Error reading modules: %v\n", err)
+		return true
+	}
+	modules = slices.DeleteFunc(modules, func(m workspace.Module) bool { return m.Name == name })
+	modules = append(modules, workspace.Module{Name: name, DependsOn: dependsOn})
+	known := map[string]bool{}
+	for _, m := range modules {
+		known[m.Name] = true
+	}
+	unknown := 0
+	for i, m := range modules {
+		var deps []string
+		for _, dep := range m.DependsOn {
+			switch {
+			case known[dep]:
+				deps = append(deps, dep)
+			case m.Name == name:
+				fmt.Printf("Module %s depends on %s, which does not exist in app/\n", name, dep)
+				unknown++
+			}
+		}
+		modules[i].DependsOn = deps
+	}
+	if _, err := workspace.InitOrder(modules); err != nil {
+		fmt.Printf("Refusing to write generated files: %v. Change --depends-on.\n", err)
+		return true
+	}
+	if unknown == 0 {
+		return false
+	}
+	if strictGenerate {
+		fmt.Printf("Refusing to write generated files: %d unknown module(s). Create them first, or run without --strict.\n", unknown)
+		return true
+	}
+	fmt.Println("Warning: the app fails at startup until these modules exist. Pass --strict to stop generation instead.")
+	return false
+}
+
+// openIfRequested opens the generated paths in the detected editor when --open is set
+func openIfRequested(paths ...string) {
+	if !openGenerated {
+		return
+	}
+	if !editor.Open(paths...) {
+		fmt.Println("Could not detect an editor to open the generated files. Please open them manually.")
+	}
+}
+
+// editGenerated applies a codemod to a generated file. If the file still matched
+// its recorded hash, the edited content is recorded too, so tool edits are not
+// reported as user modifications by `gonext diff` or merged as such on regeneration.
+func editGenerated(path string, edit func([]byte) ([]byte, error)) error {
+	state, err := codegen.LoadState()
+	if err != nil {
+		return fmt.Errorf("Error reading %s: %v", codegen.StateFile, err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	recorded, tracked := state.Recorded(path)
+	if err := codemod.EditFile(path, edit); err != nil {
+		return err
+	}
+	if !tracked || recorded != codegen.Hash(before) {
+		return nil
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := state.Record(path, after); err != nil {
+		return err
+	}
+	return state.Save()
+}
+
+// recordFirstHeader remembers the header generated files were first written with,
+// so `headers fix` can replace it once the configured header changes
+func recordFirstHeader(header string) error {
+	state, err := codegen.LoadState()
+	if err != nil || state.Header != "" {
+		return err
+	}
+	state.Header = header
+	return state.Save()
+}
+
+// writeGenerated syncs generated files to disk and prints what changed.
+// It returns false if writing failed or a user-modified file was left untouched.
+func writeGenerated(files ...codegen.File) bool {
+	m, err := manifest.Load()
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	header := codegen.HeaderComment(m.Header)
+	if header != "" {
+		for i := range files {
+			if strings.HasSuffix(files[i].Path, ".go") {
+				files[i].Content = codegen.ApplyHeader(files[i].Content, header, "")
+			}
+		}
+	}
+	if !allowSecrets && containsSecrets(files) {
+		fmt.Println("Refusing to write generated files containing secrets. Remove them from the template, or pass --allow-secrets.")
+		return false
+	}
+	if checkConflicts(files) {
+		return false
+	}
+	if header != "" {
+		if err := recordFirstHeader(header); err != nil {
+			fmt.Printf("Error writing %s: %v\n", codegen.StateFile, err)
+			return false
+		}
+	}
+
+	var spinner *progress.Spinner
+	if len(files) > 1 {
+		spinner = progress.Start(fmt.Sprintf("Generating %d files", len(files)))
+	}
+	report, err := codegen.Sync(files)
+	if spinner != nil {
+		spinner.Stop(err)
+	}
+	if report != nil {
+		report.Print()
+	}
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	if n := report.Count(codegen.Conflicted); n > 0 {
+		fmt.Printf("%d file(s) have merge conflicts between your changes and the generated code. Resolve the conflict markers, or remove files left untouched because they have no generation history, before continuing.\n", n)
+		return false
+	}
+	return true
+}
+
 var generateCmd = &cobra.Command{
 	Use:   "generate",
 	Short: "Generate code from templates (coming soon)",
@@ -56,60 +334,38 @@ var controllerCmd = &cobra.Command{
 		module := args[1]
 		titleName := strings.Title(name)
 		moduleName := getModuleName()
+		if err := validatePagination(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		filters, err := parseFilters()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		if err := ensureModuleDirs(module); err != nil {
 			fmt.Println(err)
 			return
 		}
 		controllerFile := filepath.Join("app", module, "controller", fmt.Sprintf("%sController.go", name))
-		if _, err := os.Stat(controllerFile); err == nil {
-			fmt.Printf("Controller already exists: %s\n", controllerFile)
-			return
+		content := controllerContent(fmt.Sprintf("%s/app/%s/service", moduleName, module), titleName, controllerOptions{Bulk: generateBulk, Pagination: generatePagination, Filters: filters, ETag: generateETag, Model: generateModel, ModelPkg: modelPackage(module)})
+		files := append([]codegen.File{{Path: controllerFile, Content: content}}, paginationFiles()...)
+		files = append(files, filterFiles(filters)...)
+		files = append(files, etagFiles()...)
+		if injectInterfaces {
+			if files, err = withInterfaces(module, files); err != nil {
+				fmt.Println(err)
+				return
+			}
 		}
-		content := fmt.Sprintf(`package controller
-
-import (
-	"github.com/gofiber/fiber/v2"
-	"%s/internal/%s/service"
-)
-
-type %sController struct {
-	Service *service.%sService `+"`inject:\"type\"`"+`
-}
-
-// Create%s handles creating a new %s
-func (c *%sController) Create%s(ctx *fiber.Ctx) error {
-	// TODO: Implement create logic
-	return nil
-}
-
-// Get%s handles retrieving a %s by ID
-func (c *%sController) Get%s(ctx *fiber.Ctx) error {
-	// TODO: Implement get logic
-	return nil
-}
-
-// Update%s handles updating a %s by ID
-func (c *%sController) Update%s(ctx *fiber.Ctx) error {
-	// TODO: Implement update logic
-	return nil
-}
-
-// Delete%s handles deleting a %s by ID
-func (c *%sController) Delete%s(ctx *fiber.Ctx) error {
-	// TODO: Implement delete logic
-	return nil
-}
-`,
-			moduleName, module, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName)
-		if err := os.WriteFile(controllerFile, []byte(content), 0644); err != nil {
-			fmt.Printf("Error writing %s: %v\n", controllerFile, err)
+		if !writeGenerated(files...) {
 			return
 		}
 		fmt.Printf("Controller '%s' created in app/%s/controller\n", name, module)
+		if injectInterfaces {
+			printProviderBinding(module, name, "Service")
+		}
+		openIfRequested(controllerFile)
 	},
 }
 
@@ -121,60 +377,27 @@ var serviceCmd = &cobra.Command{
 		name := args[0]
 		module := args[1]
 		titleName := strings.Title(name)
-		moduleName := getModuleName()
 		if err := ensureModuleDirs(module); err != nil {
 			fmt.Println(err)
 			return
 		}
 		serviceFile := filepath.Join("app", module, "service", fmt.Sprintf("%sService.go", name))
-		if _, err := os.Stat(serviceFile); err == nil {
-			fmt.Printf("Service already exists: %s\n", serviceFile)
-			return
+		files := []codegen.File{{Path: serviceFile, Content: serviceContent(module, titleName, generateModel)}}
+		if injectInterfaces {
+			var err error
+			if files, err = withInterfaces(module, files); err != nil {
+				fmt.Println(err)
+				return
+			}
 		}
-		content := fmt.Sprintf(`package service
-
-import (
-	"%s/internal/%s/repository"
-)
-
-type %sService struct {
-	Repository *repository.%sRepository `+"`inject:\"type\"`"+`
-}
-
-// Create%s creates a new %s
-func (s *%sService) Create%s(data interface{}) error {
-	// TODO: Implement create logic
-	return nil
-}
-
-// Get%s retrieves a %s by ID
-func (s *%sService) Get%s(id string) (interface{}, error) {
-	// TODO: Implement get logic
-	return nil, nil
-}
-
-// Update%s updates a %s by ID
-func (s *%sService) Update%s(id string, data interface{}) error {
-	// TODO: Implement update logic
-	return nil
-}
-
-// Delete%s deletes a %s by ID
-func (s *%sService) Delete%s(id string) error {
-	// TODO: Implement delete logic
-	return nil
-}
-`,
-			moduleName, module, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName)
-		if err := os.WriteFile(serviceFile, []byte(content), 0644); err != nil {
-			fmt.Printf("Error writing %s: %v\n", serviceFile, err)
+		if !writeGenerated(files...) {
 			return
 		}
 		fmt.Printf("Service '%s' created in app/%s/service\n", name, module)
+		if injectInterfaces {
+			printProviderBinding(module, name, "Service")
+		}
+		openIfRequested(serviceFile)
 	},
 }
 
@@ -186,52 +409,73 @@ var repositoryCmd = &cobra.Command{
 		name := args[0]
 		module := args[1]
 		titleName := strings.Title(name)
+		if err := validatePagination(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := validateIDStrategy(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		filters, err := parseFilters()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		if err := ensureModuleDirs(module); err != nil {
 			fmt.Println(err)
 			return
 		}
 		repositoryFile := filepath.Join("app", module, "repository", fmt.Sprintf("%sRepository.go", name))
-		if _, err := os.Stat(repositoryFile); err == nil {
-			fmt.Printf("Repository already exists: %s\n", repositoryFile)
+		idType := entityIDType(module, name, titleName)
+		if declared, ok := declaredIDType(module, name, titleName); ok && declared != idType {
+			fmt.Printf("The %s entity's ID is of type %s, not %s; drop --id or regenerate the entity with it first\n", titleName, declared, idType)
 			return
 		}
-		content := fmt.Sprintf(`package repository
-
-type %sRepository struct{}
-
-// Create%s persists a new %s
-func (r *%sRepository) Create%s(data interface{}) error {
-	// TODO: Implement create logic
-	return nil
-}
-
-// Get%s retrieves a %s by ID
-func (r *%sRepository) Get%s(id string) (interface{}, error) {
-	// TODO: Implement get logic
-	return nil, nil
-}
-
-// Update%s updates a %s by ID
-func (r *%sRepository) Update%s(id string, data interface{}) error {
-	// TODO: Implement update logic
-	return nil
-}
-
-// Delete%s deletes a %s by ID
-func (r *%sRepository) Delete%s(id string) error {
-	// TODO: Implement delete logic
-	return nil
-}
-`,
-			titleName, titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName)
-		if err := os.WriteFile(repositoryFile, []byte(content), 0644); err != nil {
-			fmt.Printf("Error writing %s: %v\n", repositoryFile, err)
+		files := []codegen.File{{Path: repositoryFile, Content: repositoryContent(module, name, titleName, repositoryOptions{Connection: repositoryConnection, Versioned: generateVersioned, Bulk: generateBulk, Pagination: generatePagination, Filtered: len(filters) > 0, IDType: idType})}}
+		if repositoryConnection != "" {
+			files = append(files, missingDatabaseFiles()...)
+		}
+		if generateVersioned || generateBulk || generatePagination != "" {
+			files = append(files, missingFile(codegen.File{Path: entityFile(module, name), Content: entityContent(titleName, generateVersioned, idType)})...)
+		}
+		files = append(files, idFiles(generateIDStrategy)...)
+		files = append(files, paginationFiles()...)
+		files = append(files, filterFiles(filters)...)
+		files = append(files, etagFiles()...)
+		if generateVersioned {
+			files = append(files, missingFile(codegen.File{Path: filepath.Join(exceptionDir, "filter.go"), Content: exceptionFilterTemplate})...)
+			// An entity generated without --versioned has no Version field for Update to read
+			if err := addEntityVersion(module, name, titleName); err != nil {
+				fmt.Printf("Error adding a Version column to %s: %v\n", entityFile(module, name), err)
+				return
+			}
+		}
+		if injectInterfaces {
+			if files, err = withInterfaces(module, files); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		if !writeGenerated(files...) {
 			return
 		}
 		fmt.Printf("Repository '%s' created in app/%s/repository\n", name, module)
+		printIDColumn(strings.ToLower(name) + "s")
+		if injectInterfaces {
+			printProviderBinding(module, name, "Repository")
+		}
+		if generateVersioned {
+			fmt.Printf("Update%s enforces optimistic locking. Register the exception filter with fiber.Config{ErrorHandler: exception.Handler} to answer stale updates with 409.\n", titleName)
+		}
+		if repositoryConnection != "" {
+			if err := bindConnection(repositoryConnection); err != nil {
+				fmt.Printf("Error adding the connection to %s: %v\n", dbProvidersFile, err)
+				return
+			}
+			fmt.Printf("It uses the '%s' connection, configured by DATABASE_%s_URL and injected as %q\n", repositoryConnection, strings.ToUpper(repositoryConnection), connectionProvider(repositoryConnection))
+		}
+		openIfRequested(repositoryFile)
 	},
 }
 
@@ -243,6 +487,18 @@ var moduleCmd = &cobra.Command{
 		name := args[0]
 		titleName := strings.Title(name)
 		moduleName := getModuleName()
+		if checkModuleDependencies(name, moduleDependencies) {
+			return
+		}
+		if err := validatePagination(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		filters, err := parseFilters()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		moduleDir := filepath.Join("app", name)
 		subdirs := []string{"controller", "repository", "route", "service"}
 		for _, sub := range subdirs {
@@ -252,9 +508,126 @@ var moduleCmd = &cobra.Command{
 				return
 			}
 		}
+		m, err := manifest.Load()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		settings := m.Modules[name]
+		if cmd.Flags().Changed("prefix") {
+			settings.Prefix = modulePrefix
+		}
+		if len(moduleTags) > 0 {
+			settings.Tags = moduleTags
+		}
+		if moduleOwner != "" {
+			settings.Owner = moduleOwner
+		}
+		mountPath := path.Join("/", settings.Prefix, name+"s")
+
+		var files []codegen.File
 		// Create module.go
 		moduleGo := filepath.Join(moduleDir, "module.go")
-		moduleGoContent := fmt.Sprintf(`package %s
+		files = append(files, codegen.File{Path: moduleGo, Content: moduleContent(name, titleName, mountPath, moduleOptions{DependsOn: moduleDependencies, Dynamic: moduleDynamic})})
+		// Controller with CRUD and inject tag
+		controllerFile := filepath.Join(moduleDir, "controller", fmt.Sprintf("%sController.go", name))
+		files = append(files, codegen.File{Path: controllerFile, Content: controllerContent(fmt.Sprintf("%s/app/%s/service", moduleName, name), titleName, controllerOptions{Bulk: generateBulk, Pagination: generatePagination, Filters: filters, ETag: generateETag, Model: generateModel, ModelPkg: modelPackage(name)})})
+		// Service with CRUD and inject tag
+		serviceFile := filepath.Join(moduleDir, "service", fmt.Sprintf("%sService.go", name))
+		files = append(files, codegen.File{Path: serviceFile, Content: serviceContent(name, titleName, generateModel)})
+		// Repository with CRUD
+		repositoryFile := filepath.Join(moduleDir, "repository", fmt.Sprintf("%sRepository.go", name))
+		files = append(files, codegen.File{Path: repositoryFile, Content: repositoryContent(name, name, titleName, repositoryOptions{Bulk: generateBulk, Pagination: generatePagination, Filtered: len(filters) > 0, IDType: "string"})})
+		// Route
+		routeFile := filepath.Join(moduleDir, "route", fmt.Sprintf("%sRoute.go", name))
+		files = append(files, codegen.File{Path: routeFile, Content: routeContent(name, titleName, extraRoutes(titleName))})
+		if generateBulk || generatePagination != "" {
+			files = append(files, missingFile(codegen.File{Path: entityFile(name, name), Content: entityContent(titleName, false, "string")})...)
+		}
+		newConfig := configMissing()
+		files = append(files, settingsFiles(name)...)
+		files = append(files, paginationFiles()...)
+		files = append(files, filterFiles(filters)...)
+		files = append(files, etagFiles()...)
+
+		// Optional module README and ADR stub
+		if moduleDocs {
+			readme := filepath.Join(moduleDir, "README.md")
+			_, statErr := os.Stat(readme)
+			files = append(files, codegen.File{Path: readme, Content: moduleReadme(name, titleName)})
+			if os.IsNotExist(statErr) {
+				adrTitle := fmt.Sprintf("Introduce %s module", titleName)
+				adrFile, number, err := nextADRFile(adrTitle)
+				if err != nil {
+					fmt.Printf("Error reading %s: %v\n", adrDir, err)
+					return
+				}
+				context := fmt.Sprintf("The %s feature needs its own module (app/%s) with a controller, service, repository and routes.", name, name)
+				files = append(files, codegen.File{Path: adrFile, Content: adrContent(number, adrTitle, context)})
+			}
+		}
+
+		// Ownership metadata, recorded in the manifest and mirrored to CODEOWNERS
+		owner := settings.Owner
+		if owner != "" {
+			files = append(files, codegen.File{Path: filepath.Join(moduleDir, "OWNERS"), Content: scaffolding.OwnersFile(name, owner)})
+		}
+		if injectInterfaces {
+			if files, err = withInterfaces(name, files); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+
+		if !writeGenerated(files...) {
+			return
+		}
+		if cmd.Flags().Changed("prefix") || cmd.Flags().Changed("tag") || cmd.Flags().Changed("owner") {
+			m.SetModule(name, settings)
+			if err := m.Save(); err != nil {
+				fmt.Printf("Error writing %s: %v\n", manifest.FileName, err)
+				return
+			}
+		}
+		if owner != "" {
+			if err := scaffolding.UpdateCodeowners(m); err != nil {
+				fmt.Printf("Error updating %s: %v\n", scaffolding.CodeownersFile, err)
+				return
+			}
+			fmt.Printf("Module '%s' is owned by %s (see %s)\n", name, owner, scaffolding.CodeownersFile)
+		}
+		if err := registerModule(name, !settings.Disabled); err != nil {
+			fmt.Printf("Error adding module to %s: %v\n", moduleRegistryFile, err)
+			return
+		}
+		fmt.Printf("Module '%s' created in app/%s with boilerplate files and CRUD stubs, mounted at %s.\n", name, name, mountPath)
+		printSettings(name, newConfig)
+		openIfRequested(moduleDir)
+	},
+}
+
+// moduleOptions are the variations supported by the module template
+type moduleOptions struct {
+	DependsOn []string // Modules initialized first (--depends-on)
+	Dynamic   bool     // Constructor taking a <Name>Config set at registration (--dynamic)
+}
+
+// moduleContent renders a module's module.go, which registers its components and
+// mounts its routes at mountPath
+func moduleContent(name, titleName, mountPath string, opts moduleOptions) string {
+	moduleName := getModuleName()
+	deps := make([]string, len(opts.DependsOn))
+	for i, dep := range opts.DependsOn {
+		deps[i] = strconv.Quote(dep)
+	}
+	dependsOnMethod := fmt.Sprintf(`// DependsOn names the modules whose OnModuleInit runs before this module's.
+// 'gonext graph' shows the resulting order.
+func (m *%sModule) DependsOn() []string {
+	return []string{%s}
+}
+
+`, titleName, strings.Join(deps, ", "))
+	content := strings.Replace(fmt.Sprintf(`package %s
 
 import (
 	"fmt"
@@ -263,6 +636,7 @@ import (
 	"%s/app/%s/repository"
 	"%s/app/%s/route"
 	"%s/app/%s/service"
+	"%s/app/%s/settings"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -287,162 +661,110 @@ func (m *%sModule) OnModuleDestroy() error {
 	return nil
 }
 
+// Register binds the module's components. Those passed to RegisterModuleComponents
+// are singletons, built here and shared for the app's lifetime. Values that are
+// costly to build can be lazy singletons and per-request state such as a
+// transaction request-scoped; 'gonext g provider' generates both.
 func (m *%sModule) Register(container *app.Container) {
 	%sRepo := &repository.%sRepository{}
 	%sService := &service.%sService{}
 	%sController := &controller.%sController{}
+	container.Bind("%sSettings", settings.Values)
 	app.RegisterModuleComponents(container, %sRepo, %sService, %sController)
 	m.%sController = %sController
 }
 
 func (m *%sModule) MountRoutes(router fiber.Router) {
-	group := router.Group("/%ss")
+	group := router.Group("%s")
 	route.Register%sRoutes(group, m.%sController)
 }
 `,
-			name,
-			moduleName, moduleName, name, moduleName, name, moduleName, name, moduleName, name,
-			titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, name, titleName, name, titleName, name, titleName, name, name, name, titleName, name,
-			titleName, name, titleName, titleName)
-		if err := os.WriteFile(moduleGo, []byte(moduleGoContent), 0644); err != nil {
-			fmt.Printf("Error writing %s: %v\n", moduleGo, err)
-			return
-		}
-		// Controller with CRUD and inject tag
-		controllerFile := filepath.Join(moduleDir, "controller", fmt.Sprintf("%sController.go", name))
-		controllerContent := fmt.Sprintf(`package controller
-
-import (
-	"github.com/gofiber/fiber/v2"
-	"%s/app/%s/service"
-)
-
-type %sController struct {
-	Service *service.%sService `+"`inject:\"type\"`"+`
+		name,
+		moduleName, moduleName, name, moduleName, name, moduleName, name, moduleName, name, moduleName, name,
+		titleName, titleName,
+		titleName, titleName, titleName, titleName,
+		titleName, titleName, titleName, titleName,
+		titleName, name, titleName, name, titleName, name, titleName, name, name, name, name, titleName, name,
+		titleName, mountPath, titleName, titleName), "// Register binds", dependsOnMethod+"// Register binds", 1)
+	if opts.Dynamic {
+		content = dynamicModule(content, name, titleName)
+	}
+	return content
 }
 
-// Create%s handles creating a new %s
-func (c *%sController) Create%s(ctx *fiber.Ctx) error {
-	// TODO: Implement create logic
-	return nil
+// dynamicModule turns a rendered module.go into a dynamic module: New<Name>Module
+// takes a <Name>Config, passed by the module registry, and Register binds it so
+// the module's components can inject it by name
+func dynamicModule(content, name, titleName string) string {
+	config := fmt.Sprintf(`// %[1]sConfig parameterizes the %[2]s module where it is registered, in
+// bootstrap/modules.go. Components inject it with inject:"name=%[2]sConfig".
+type %[1]sConfig struct {
+	// TODO: Add the module's options, e.g. a TTL, a table prefix or a feature switch
 }
 
-// Get%s handles retrieving a %s by ID
-func (c *%sController) Get%s(ctx *fiber.Ctx) error {
-	// TODO: Implement get logic
-	return nil
+// Default%[1]sConfig returns the options the module registry passes by default
+func Default%[1]sConfig() %[1]sConfig {
+	return %[1]sConfig{}
 }
 
-// Update%s handles updating a %s by ID
-func (c *%sController) Update%s(ctx *fiber.Ctx) error {
-	// TODO: Implement update logic
-	return nil
+type %[1]sModule struct {
+	Config %[1]sConfig
+`, titleName, name)
+	content = strings.Replace(content, fmt.Sprintf("type %sModule struct {\n", titleName), config, 1)
+	content = strings.Replace(content, fmt.Sprintf("func New%[1]sModule() *%[1]sModule {\n\treturn &%[1]sModule{}\n}", titleName),
+		fmt.Sprintf("func New%[1]sModule(cfg %[1]sConfig) *%[1]sModule {\n\treturn &%[1]sModule{Config: cfg}\n}", titleName), 1)
+	return strings.Replace(content, "\tapp.RegisterModuleComponents(", fmt.Sprintf("\tcontainer.Bind(%q, m.Config)\n\tapp.RegisterModuleComponents(", name+"Config"), 1)
 }
 
-// Delete%s handles deleting a %s by ID
-func (c *%sController) Delete%s(ctx *fiber.Ctx) error {
-	// TODO: Implement delete logic
-	return nil
-}
-`,
-			moduleName, name, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName)
-		if err := os.WriteFile(controllerFile, []byte(controllerContent), 0644); err != nil {
-			fmt.Printf("Error writing %s: %v\n", controllerFile, err)
-			return
-		}
-		// Service with CRUD and inject tag
-		serviceFile := filepath.Join(moduleDir, "service", fmt.Sprintf("%sService.go", name))
-		serviceContent := fmt.Sprintf(`package service
+// serviceContent renders a service with CRUD stubs and its module's repository
+// injected. The stubs take and return *model.<Model> when a model is given and
+// interface{} otherwise.
+func serviceContent(module, titleName, model string) string {
+	imports := fmt.Sprintf("\t\"%s/app/%s/repository\"", getModuleName(), module)
+	data := "interface{}"
+	if model != "" {
+		imports = fmt.Sprintf("\t\"%s\"\n", modelPackage(module)) + imports
+		data = "*model." + model
+	}
+	return fmt.Sprintf(`package service
 
 import (
-	"%s/app/%s/repository"
+%[2]s
 )
 
-type %sService struct {
-	Repository *repository.%sRepository `+"`inject:\"type\"`"+`
+type %[1]sService struct {
+	Repository *repository.%[1]sRepository `+"`inject:\"type\"`"+`
 }
 
-// Create%s creates a new %s
-func (s *%sService) Create%s(data interface{}) error {
+// Create%[1]s creates a new %[1]s
+func (s *%[1]sService) Create%[1]s(data %[3]s) error {
 	// TODO: Implement create logic
 	return nil
 }
 
-// Get%s retrieves a %s by ID
-func (s *%sService) Get%s(id string) (interface{}, error) {
+// Get%[1]s retrieves a %[1]s by ID
+func (s *%[1]sService) Get%[1]s(id string) (%[3]s, error) {
 	// TODO: Implement get logic
 	return nil, nil
 }
 
-// Update%s updates a %s by ID
-func (s *%sService) Update%s(id string, data interface{}) error {
+// Update%[1]s updates a %[1]s by ID
+func (s *%[1]sService) Update%[1]s(id string, data %[3]s) error {
 	// TODO: Implement update logic
 	return nil
 }
 
-// Delete%s deletes a %s by ID
-func (s *%sService) Delete%s(id string) error {
+// Delete%[1]s deletes a %[1]s by ID
+func (s *%[1]sService) Delete%[1]s(id string) error {
 	// TODO: Implement delete logic
 	return nil
 }
-`,
-			moduleName, name, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName)
-		if err := os.WriteFile(serviceFile, []byte(serviceContent), 0644); err != nil {
-			fmt.Printf("Error writing %s: %v\n", serviceFile, err)
-			return
-		}
-		// Repository with CRUD
-		repositoryFile := filepath.Join(moduleDir, "repository", fmt.Sprintf("%sRepository.go", name))
-		repositoryContent := fmt.Sprintf(`package repository
-
-type %sRepository struct{}
-
-// Create%s persists a new %s
-func (r *%sRepository) Create%s(data interface{}) error {
-	// TODO: Implement create logic
-	return nil
-}
-
-// Get%s retrieves a %s by ID
-func (r *%sRepository) Get%s(id string) (interface{}, error) {
-	// TODO: Implement get logic
-	return nil, nil
-}
-
-// Update%s updates a %s by ID
-func (r *%sRepository) Update%s(id string, data interface{}) error {
-	// TODO: Implement update logic
-	return nil
+`, titleName, imports, data)
 }
 
-// Delete%s deletes a %s by ID
-func (r *%sRepository) Delete%s(id string) error {
-	// TODO: Implement delete logic
-	return nil
-}
-`,
-			titleName, titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName,
-			titleName, titleName, titleName, titleName)
-		if err := os.WriteFile(repositoryFile, []byte(repositoryContent), 0644); err != nil {
-			fmt.Printf("Error writing %s: %v\n", repositoryFile, err)
-			return
-		}
-		// Route
-		routeFile := filepath.Join(moduleDir, "route", fmt.Sprintf("%sRoute.go", name))
-		routeContent := fmt.Sprintf(`package route
+// routeContent renders a module's route file registering routes on the module group
+func routeContent(name, titleName, routes string) string {
+	return fmt.Sprintf(`package route
 
 import (
 	"github.com/gofiber/fiber/v2"
@@ -451,23 +773,102 @@ import (
 
 func Register%sRoutes(route fiber.Router, ctrl *controller.%sController) {
 	// TODO: Register routes for %s
+%s}
+`, getModuleName(), name, titleName, titleName, titleName, routes)
 }
-`, moduleName, name, titleName, titleName, titleName)
-		if err := os.WriteFile(routeFile, []byte(routeContent), 0644); err != nil {
-			fmt.Printf("Error writing %s: %v\n", routeFile, err)
-			return
+
+// extraRoutes registers the --pagination and --bulk endpoints of a module's controller
+func extraRoutes(titleName string) string {
+	routes := ""
+	if generatePagination != "" {
+		routes += fmt.Sprintf("\troute.Get(\"/\", ctrl.List%s)\n", titleName)
+	}
+	if generateBulk {
+		routes += fmt.Sprintf(`	route.Post("/bulk", ctrl.BulkCreate%[1]s)
+	route.Put("/bulk", ctrl.BulkUpdate%[1]s)
+	route.Delete("/bulk", ctrl.BulkDelete%[1]s)
+`, titleName)
+	}
+	return routes
+}
+
+// registerModule adds the module to the bootstrap module registry, if the project has one
+func registerModule(name string, enabled bool) error {
+	if _, err := os.Stat(moduleRegistryFile); os.IsNotExist(err) {
+		return nil
+	}
+	return editGenerated(moduleRegistryFile, func(src []byte) ([]byte, error) {
+		entries, err := codemod.ReadSlice(src, "Modules")
+		if err != nil {
+			return nil, err
 		}
-		fmt.Printf("Module '%s' created in app/%s with boilerplate files and CRUD stubs.\n", name, name)
-	},
+		for _, e := range entries {
+			if strings.Contains(e.Expr, fmt.Sprintf("Name: %q", name)) {
+				return src, nil
+			}
+		}
+		src, err = codemod.AddImport(src, getModuleName()+"/app/"+name)
+		if err != nil {
+			return nil, err
+		}
+		return codemod.AppendToSlice(src, "Modules", moduleRegistryEntry(name, enabled))
+	})
+}
+
+// moduleReadme describes the components and endpoints of a generated module
+func moduleReadme(name, titleName string) string {
+	return fmt.Sprintf(`# %s Module
+
+TODO: Describe what the %s module is responsible for.
+
+## Components
+
+| Component | File |
+| :--- | :--- |
+| %sModule | module.go |
+| %sController | controller/%sController.go |
+| %sService | service/%sService.go |
+| %sRepository | repository/%sRepository.go |
+| Routes | route/%sRoute.go |
+
+## Endpoints
+
+Mounted under /%ss.
+
+| Handler | Description |
+| :--- | :--- |
+| Create%s | Creates a new %s |
+| Get%s | Retrieves a %s by ID |
+| Update%s | Updates a %s by ID |
+| Delete%s | Deletes a %s by ID |
+`,
+		titleName, name,
+		titleName, titleName, name, titleName, name, titleName, name, name,
+		name,
+		titleName, titleName, titleName, titleName, titleName, titleName, titleName, titleName)
 }
 
 var dtoCmd = &cobra.Command{
-	Use:   "dto [name] [in_module]",
+	Use:   "dto [name] [in_module] [field:type[:modifier...]...]",
 	Short: "Generate a DTO struct in a module (creates module if needed)",
-	Args:  cobra.ExactArgs(2),
+	Long: `Generates app/<module>/dto/<name>DTO.go: a sample DTO, or one with the fields
+given as name:type[:modifier...], the specs of 'g model', e.g.
+
+  gonext g dto order orders price:money amount:decimal note:text:nullable
+
+money is a money.Amount of integer cents (app/money, created if missing) and
+decimal a shopspring decimal.Decimal; both are decimal strings in JSON, so
+amounts never round like float64. A DTO with decimal fields has a Validate
+method checking them. Nullable fields are optional pointers.`,
+	Args: cobra.MinimumNArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
 		module := args[1]
+		fields, err := parseModelFields(args[2:])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		if err := ensureModuleDirs(module); err != nil {
 			fmt.Println(err)
 			return
@@ -478,39 +879,104 @@ var dtoCmd = &cobra.Command{
 			return
 		}
 		dtoFile := filepath.Join(dtoDir, fmt.Sprintf("%sDTO.go", name))
-		if _, err := os.Stat(dtoFile); err == nil {
-			fmt.Printf("DTO already exists: %s\n", dtoFile)
-			return
-		}
 		c := cases.Title(language.Und)
 		structName := c.String(name) + "DTO"
 		content := fmt.Sprintf(`package dto
 
 type %s struct {
-	Username string `+"`json:\"username\" validate:\"required,min=3,max=20\"`"+`
-	FullName string `+"`json:\"full_name\" validate:\"required,min=3,max=50\"`"+`
-	Email    string `+"`json:\"email\" validate:\"required,email\"`"+`
-	Password string `+"`json:\"password\" validate:\"required,min=8\"`"+`
+	Username string `+"`json:\"username\" validate:\"required,min=3,max=20\" example:\"janedoe\"`"+`
+	FullName string `+"`json:\"full_name\" validate:\"required,min=3,max=50\" example:\"Jane Doe\"`"+`
+	Email    string `+"`json:\"email\" validate:\"required,email\" example:\"jane@example.com\"`"+`
+	Password string `+"`json:\"password\" validate:\"required,min=8\" description:\"At least 8 characters\"`"+`
 }
 `,
 			structName)
-		if err := os.WriteFile(dtoFile, []byte(content), 0644); err != nil {
-			fmt.Printf("Error writing %s: %v\n", dtoFile, err)
+		if len(fields) > 0 {
+			content = dtoContent(structName, fields)
+		}
+		if !writeGenerated(append([]codegen.File{{Path: dtoFile, Content: content}}, fieldFiles(fields)...)...) {
 			return
 		}
 		fmt.Printf("DTO '%s' created in app/%s/dto\n", name, module)
+		printDecimalDependency(fields)
+		openIfRequested(dtoFile)
 	},
 }
 
+// dtoContent renders a DTO with fields. Decimal fields, which validate tags
+// cannot compare, are checked by a Validate method.
+func dtoContent(structName string, fields []modelField) string {
+	nameWidth, typeWidth := 0, 0
+	for _, f := range fields {
+		nameWidth = max(nameWidth, len(f.Name))
+		typeWidth = max(typeWidth, len(f.goType()))
+	}
+	var body, checks strings.Builder
+	for _, f := range fields {
+		json, validate, present := f.Column, f.Type.Validate, ""
+		if f.Nullable {
+			// Optional fields are only validated when they are sent
+			json += ",omitempty"
+			validate = strings.TrimPrefix(strings.TrimPrefix(validate, "required"), ",")
+			if validate != "" {
+				validate = "omitempty," + validate
+			}
+			present = fmt.Sprintf("d.%s != nil && ", f.Name)
+		}
+		tag := fmt.Sprintf("json:%q", json)
+		if validate != "" {
+			tag += fmt.Sprintf(" validate:%q", validate)
+		}
+		if f.Type.Example != "" {
+			tag += fmt.Sprintf(" example:%q", f.Type.Example)
+		}
+		fmt.Fprintf(&body, "\t%-*s %-*s `%s`\n", nameWidth, f.Name, typeWidth, f.goType(), tag)
+		if strings.HasPrefix(f.Type.Go, "decimal.") {
+			fmt.Fprintf(&checks, `	if %[3]sd.%[1]s.IsNegative() {
+		return errors.New("%[2]s must not be negative")
+	}
+	if %[3]s!d.%[1]s.Equal(d.%[1]s.Round(4)) {
+		return errors.New("%[2]s has more than 4 decimal places")
+	}
+`, f.Name, f.Column, present)
+		}
+	}
+	std, other := fieldImports(fields)
+	validate := ""
+	if checks.Len() > 0 {
+		std = append(std, `"errors"`)
+		validate = fmt.Sprintf(`
+// Validate checks the decimal fields, which validate tags cannot compare
+func (d %s) Validate() error {
+%s	return nil
+}
+`, structName, checks.String())
+	}
+	return fmt.Sprintf(`package dto
+
+%stype %s struct {
+%s}
+%s`, importBlock(std, other), structName, body.String(), validate)
+}
+
 var middlewareCmd = &cobra.Command{
 	Use:   "middleware [name] [in_module]",
-	Short: "Generate a Fiber middleware in a module (creates module if needed)",
-	Args:  cobra.ExactArgs(2),
+	Short: "Generate a Fiber middleware in a module (creates module if needed), or app-wide with --global",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if middlewareGlobal {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
-		module := args[1]
 		c := cases.Title(language.Und)
 		funcName := c.String(name)
+		if middlewareGlobal {
+			generateGlobalMiddleware(name, funcName)
+			return
+		}
+		module := args[1]
 		if err := ensureModuleDirs(module); err != nil {
 			fmt.Println(err)
 			return
@@ -521,11 +987,18 @@ var middlewareCmd = &cobra.Command{
 			return
 		}
 		middlewareFile := filepath.Join(middlewareDir, fmt.Sprintf("%sMiddleware.go", name))
-		if _, err := os.Stat(middlewareFile); err == nil {
-			fmt.Printf("Middleware already exists: %s\n", middlewareFile)
+		content := middlewareContent(funcName)
+		if !writeGenerated(codegen.File{Path: middlewareFile, Content: content}) {
 			return
 		}
-		content := fmt.Sprintf(`package middleware
+		fmt.Printf("Middleware '%s' created in app/%s/middleware\n", name, module)
+		openIfRequested(middlewareFile)
+	},
+}
+
+// middlewareContent renders a sample Fiber middleware
+func middlewareContent(funcName string) string {
+	return fmt.Sprintf(`package middleware
 
 import (
 	"github.com/gofiber/fiber/v2"
@@ -539,16 +1012,60 @@ func %sMiddleware() fiber.Handler {
 	}
 }
 `,
-			funcName, funcName)
-		if err := os.WriteFile(middlewareFile, []byte(content), 0644); err != nil {
-			fmt.Printf("Error writing %s: %v\n", middlewareFile, err)
-			return
-		}
-		fmt.Printf("Middleware '%s' created in app/%s/middleware\n", name, module)
-	},
+		funcName, funcName)
+}
+
+// generateGlobalMiddleware writes an app-wide middleware to app/middleware and
+// appends it to the bootstrap middleware chain
+func generateGlobalMiddleware(name, funcName string) {
+	middlewareFile := filepath.Join("app", "middleware", fmt.Sprintf("%sMiddleware.go", name))
+	files := []codegen.File{{Path: middlewareFile, Content: middlewareContent(funcName)}}
+	if _, err := os.Stat(middlewareChainFile); os.IsNotExist(err) {
+		files = append(files, codegen.File{Path: middlewareChainFile, Content: middlewareChainTemplate})
+	}
+	if !writeGenerated(files...) {
+		return
+	}
+	err := addGlobalMiddleware(getModuleName()+"/app/middleware", fmt.Sprintf("middleware.%sMiddleware()", funcName))
+	if err != nil {
+		fmt.Printf("Error wiring middleware into %s: %v\n", middlewareChainFile, err)
+		return
+	}
+	fmt.Printf("Global middleware '%s' created in app/middleware and added to %s\n", name, middlewareChainFile)
+	openIfRequested(middlewareFile)
 }
 
 func init() {
+	generateCmd.PersistentFlags().BoolVar(&openGenerated, "open", false, "Open the generated files in the detected editor")
+	gCmd.PersistentFlags().BoolVar(&openGenerated, "open", false, "Open the generated files in the detected editor")
+	generateCmd.PersistentFlags().BoolVar(&allowSecrets, "allow-secrets", false, "Write generated files even if they contain private keys or access keys")
+	gCmd.PersistentFlags().BoolVar(&allowSecrets, "allow-secrets", false, "Write generated files even if they contain private keys or access keys")
+	generateCmd.PersistentFlags().BoolVar(&strictGenerate, "strict", false, "Fail instead of warning when generated routes or component names conflict with existing ones")
+	gCmd.PersistentFlags().BoolVar(&strictGenerate, "strict", false, "Fail instead of warning when generated routes or component names conflict with existing ones")
+	moduleCmd.Flags().BoolVar(&moduleDocs, "docs", false, "Also generate a module README.md and an ADR stub in docs/adr")
+	moduleCmd.Flags().StringVar(&modulePrefix, "prefix", "", "Route prefix the module mounts under (e.g. /api/v1)")
+	moduleCmd.Flags().StringSliceVar(&moduleTags, "tag", nil, "Tags recorded for the module and used in the OpenAPI spec (repeatable)")
+	moduleCmd.Flags().BoolVar(&moduleDynamic, "dynamic", false, "Take a <Name>Config in New<Name>Module, passed where the module is registered")
+	moduleCmd.Flags().StringSliceVar(&moduleDependencies, "depends-on", nil, "Modules initialized before this one, declared by its DependsOn method (repeatable)")
+	moduleCmd.Flags().StringVar(&moduleOwner, "owner", "", "Owning team recorded in gonext.yaml and CODEOWNERS (e.g. @acme/payments)")
+	repositoryCmd.Flags().StringVar(&repositoryConnection, "connection", "", "Named database connection the repository uses (e.g. reporting), configured by DATABASE_<NAME>_URL")
+	repositoryCmd.Flags().BoolVar(&generateVersioned, "versioned", false, "Enforce optimistic locking in Update against the entity's Version column")
+	repositoryCmd.Flags().StringVar(&generateIDStrategy, "id", "", "Type and generate the entity's ID: uuid, ulid, snowflake or autoincrement (default: the entity's, or untyped string)")
+	controllerCmd.Flags().BoolVar(&generateBulk, "bulk", false, "Add batch create/update/delete endpoints")
+	repositoryCmd.Flags().BoolVar(&generateBulk, "bulk", false, "Add chunked, transactional batch create/update/delete methods")
+	moduleCmd.Flags().BoolVar(&generateBulk, "bulk", false, "Add batch create/update/delete endpoints, routes and repository methods")
+	controllerCmd.Flags().StringVar(&generatePagination, "pagination", "", "Add a paginated List endpoint: offset or cursor (keyset)")
+	repositoryCmd.Flags().StringVar(&generatePagination, "pagination", "", "Add a paginated List method: offset or cursor (keyset)")
+	moduleCmd.Flags().StringVar(&generatePagination, "pagination", "", "Add a paginated list endpoint, route and repository method: offset or cursor (keyset)")
+	controllerCmd.Flags().StringSliceVar(&generateFilters, "filter", nil, "Allow-list a query filter on the list endpoint as field:string|int|time|bool (repeatable)")
+	repositoryCmd.Flags().StringSliceVar(&generateFilters, "filter", nil, "Accept query filter predicates in the List method (fields as field:kind, repeatable)")
+	moduleCmd.Flags().StringSliceVar(&generateFilters, "filter", nil, "Allow-list a query filter on the list endpoint as field:string|int|time|bool (repeatable)")
+	controllerCmd.Flags().BoolVar(&generateETag, "etag", false, "Make Get answer 304 via If-None-Match and Update enforce If-Match with 412")
+	moduleCmd.Flags().BoolVar(&generateETag, "etag", false, "Make Get answer 304 via If-None-Match and Update enforce If-Match with 412")
+	for _, c := range []*cobra.Command{moduleCmd, controllerCmd, serviceCmd, repositoryCmd} {
+		c.Flags().BoolVar(&injectInterfaces, "inject-interfaces", false, "Depend on service and repository interfaces injected by name instead of concrete types")
+	}
+	middlewareCmd.Flags().BoolVar(&middlewareGlobal, "global", false, "Generate into the shared app/middleware package and add it to the bootstrap middleware chain")
 	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(gCmd)
 	generateCmd.AddCommand(moduleCmd)
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+// adrDir holds the numbered architecture decision records
+var adrDir = filepath.Join("docs", "adr")
+
+var adrNumber = regexp.MustCompile(`^(\d{4})-`)
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// nextADRFile returns the path of the next numbered ADR for title, e.g. docs/adr/0003-use-outbox-pattern.md
+func nextADRFile(title string) (string, int, error) {
+	entries, err := os.ReadDir(adrDir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", 0, err
+	}
+	next := 1
+	for _, e := range entries {
+		if m := adrNumber.FindStringSubmatch(e.Name()); m != nil {
+			if n, _ := strconv.Atoi(m[1]); n >= next {
+				next = n + 1
+			}
+		}
+	}
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	return filepath.Join(adrDir, fmt.Sprintf("%04d-%s.md", next, slug)), next, nil
+}
+
+// adrContent renders an ADR stub in the Nygard format
+func adrContent(number int, title, context string) string {
+	if context == "" {
+		context = "TODO: Describe the forces at play and why a decision is needed."
+	}
+	return fmt.Sprintf(`# %d. %s
+
+Date: %s
+
+## Status
+
+Proposed
+
+## Context
+
+%s
+
+## Decision
+
+TODO: Describe the change being proposed or made.
+
+## Consequences
+
+TODO: Describe what becomes easier or harder because of this decision.
+`, number, title, time.Now().Format("2006-01-02"), context)
+}
+
+var adrCmd = &cobra.Command{
+	Use:   "adr [title]",
+	Short: "Generate a numbered architecture decision record in docs/adr",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		title := args[0]
+		adrFile, number, err := nextADRFile(title)
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", adrDir, err)
+			return
+		}
+		if !writeGenerated(codegen.File{Path: adrFile, Content: adrContent(number, title, "")}) {
+			return
+		}
+		fmt.Printf("ADR %d created at %s\n", number, adrFile)
+		openIfRequested(adrFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(adrCmd)
+	gCmd.AddCommand(adrCmd)
+}
@@ -0,0 +1,494 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// bootstrapDir holds the generated startup and shutdown helpers used by main.go
+const bootstrapDir = "bootstrap"
+
+const shutdownTemplate = `package bootstrap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Destroyable is implemented by modules that define an OnModuleDestroy hook
+type Destroyable interface {
+	OnModuleDestroy() error
+}
+
+// Drainer is implemented by background consumers such as queue.TaskQueue
+type Drainer interface {
+	Shutdown()
+}
+
+// ShutdownOptions configures WaitForShutdown
+type ShutdownOptions struct {
+	// Timeout bounds how long in-flight HTTP requests get to finish (default 10s)
+	Timeout time.Duration
+	// Modules in registration order; OnModuleDestroy runs in reverse order
+	Modules []any
+	// Consumers are drained after the HTTP server stops accepting requests
+	Consumers []Drainer
+}
+
+// WaitForShutdown blocks until SIGINT or SIGTERM, then stops the server,
+// drains consumers and destroys modules. All failures are returned together.
+func WaitForShutdown(server *fiber.App, opts ShutdownOptions) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	sig := <-signals
+	fmt.Printf("Received %s, shutting down gracefully...\n", sig)
+	return Shutdown(server, opts)
+}
+
+// Shutdown performs the ordered shutdown without waiting for a signal
+func Shutdown(server *fiber.App, opts ShutdownOptions) error {
+	if opts.Timeout == 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	var errs []error
+
+	// 1. Stop accepting new requests and let in-flight ones finish
+	if err := server.ShutdownWithTimeout(opts.Timeout); err != nil {
+		errs = append(errs, fmt.Errorf("http shutdown: %w", err))
+	}
+
+	// 2. Drain queue consumers so running jobs complete
+	for _, c := range opts.Consumers {
+		c.Shutdown()
+	}
+
+	// 3. Destroy modules in reverse registration order
+	for i := len(opts.Modules) - 1; i >= 0; i-- {
+		if m, ok := opts.Modules[i].(Destroyable); ok {
+			if err := m.OnModuleDestroy(); err != nil {
+				errs = append(errs, fmt.Errorf("module %T: %w", opts.Modules[i], err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+`
+
+const bannerTemplate = `package bootstrap
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BannerInfo describes the running app for the startup banner
+type BannerInfo struct {
+	Name    string
+	Version string
+	Env     string // e.g. development, production; the route table is printed outside production
+	Addr    string
+	Modules int
+}
+
+// PrintBanner prints the app identity, bound address and module count, and in
+// non-production environments a table of every registered route
+func PrintBanner(server *fiber.App, info BannerInfo) {
+	if info.Env == "" {
+		info.Env = "development"
+	}
+	fmt.Printf("\n  %s %s (%s)\n", info.Name, info.Version, info.Env)
+	fmt.Printf("  Listening on %s\n", info.Addr)
+	fmt.Printf("  %d module(s) registered\n\n", info.Modules)
+
+	if info.Env == "production" {
+		return
+	}
+	routes := server.GetRoutes(true)
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  METHOD\tPATH\tNAME")
+	for _, r := range routes {
+		if r.Method == "HEAD" {
+			continue
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", r.Method, r.Path, r.Name)
+	}
+	w.Flush()
+	fmt.Println()
+}
+`
+
+const configCheckTemplate = `package bootstrap
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the expected format of a configuration value
+type Kind int
+
+const (
+	String Kind = iota
+	Port
+	URL
+	Duration
+	Int
+	Bool
+)
+
+// EnvVar declares a configuration variable checked at boot
+type EnvVar struct {
+	Name     string
+	Kind     Kind
+	Required bool
+}
+
+// ValidateEnv checks every declared variable and returns one error listing all problems
+func ValidateEnv(vars ...EnvVar) error {
+	var problems []string
+	for _, v := range vars {
+		value, ok := os.LookupEnv(v.Name)
+		if !ok || value == "" {
+			if v.Required {
+				problems = append(problems, fmt.Sprintf("%s is required but not set", v.Name))
+			}
+			continue
+		}
+		if err := checkKind(v.Kind, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s=%q %v", v.Name, value, err))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New("invalid configuration:\n  - " + strings.Join(problems, "\n  - "))
+}
+
+// MustValidateEnv validates the configuration and exits with the aggregated error if it is invalid
+func MustValidateEnv(vars ...EnvVar) {
+	if err := ValidateEnv(vars...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func checkKind(kind Kind, value string) error {
+	switch kind {
+	case Port:
+		p, err := strconv.Atoi(value)
+		if err != nil || p < 1 || p > 65535 {
+			return errors.New("is not a valid port (1-65535)")
+		}
+	case URL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New("is not a valid absolute URL")
+		}
+	case Duration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return errors.New("is not a valid duration (e.g. 30s, 5m)")
+		}
+	case Int:
+		if _, err := strconv.Atoi(value); err != nil {
+			return errors.New("is not a valid integer")
+		}
+	case Bool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New("is not a valid boolean")
+		}
+	}
+	return nil
+}
+`
+
+// middlewareChainFile is the bootstrap file holding the global middleware chain
+var middlewareChainFile = filepath.Join(bootstrapDir, "middleware.go")
+
+const middlewareChainTemplate = `package bootstrap
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// Middlewares returns the global middleware chain. Handlers run in the order listed.
+// Manage it with 'gonext g middleware --global' and 'gonext middleware'.
+func Middlewares() []fiber.Handler {
+	return []fiber.Handler{}
+}
+
+// UseMiddlewares registers the global middleware chain on the server
+func UseMiddlewares(server *fiber.App) {
+	for _, m := range Middlewares() {
+		server.Use(m)
+	}
+}
+`
+
+// moduleRegistryFile is the bootstrap file listing the app's modules
+var moduleRegistryFile = filepath.Join(bootstrapDir, "modules.go")
+
+const moduleRegistryTemplate = `package bootstrap
+
+import (
+	"os"
+	"strconv"
+	"strings"
+%s)
+
+// ModuleEntry registers a module with the app. Disabled modules are not constructed.
+type ModuleEntry struct {
+	Name    string
+	Enabled bool
+	New     func() any
+}
+
+// Modules returns every module known to the app, in registration order.
+// Manage it with 'gonext g module' and 'gonext module enable|disable'.
+func Modules() []ModuleEntry {
+	return []ModuleEntry{%s}
+}
+
+// IsEnabled reports whether the module is enabled. MODULE_<NAME>_ENABLED=true|false
+// overrides the registry at runtime, so modules can be toggled without code changes.
+func (m ModuleEntry) IsEnabled() bool {
+	if v, ok := os.LookupEnv("MODULE_" + strings.ToUpper(m.Name) + "_ENABLED"); ok {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return m.Enabled
+}
+
+// EnabledModules constructs the enabled modules in registration order
+func EnabledModules() []any {
+	var modules []any
+	for _, m := range Modules() {
+		if m.IsEnabled() {
+			modules = append(modules, m.New())
+		}
+	}
+	return modules
+}
+`
+
+// moduleInitFile orders module initialization by declared dependencies
+var moduleInitFile = filepath.Join(bootstrapDir, "init.go")
+
+const moduleInitTemplate = `package bootstrap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dependent is implemented by modules that define a DependsOn method
+type Dependent interface {
+	DependsOn() []string
+}
+
+// Initializable is implemented by modules that define an OnModuleInit hook
+type Initializable interface {
+	OnModuleInit() error
+}
+
+// OrderedModules constructs the enabled modules, each after the modules it
+// depends on and otherwise in registration order. It fails on a dependency that
+// is unknown or disabled, and on a cycle. Pass the result to InitModules and to
+// ShutdownOptions.Modules, so modules are destroyed in reverse.
+func OrderedModules() ([]any, error) {
+	entries := map[string]ModuleEntry{}
+	for _, m := range Modules() {
+		entries[m.Name] = m
+	}
+	built := map[string]any{}
+	state := map[string]int{} // 1 while visiting, 2 once ordered
+	var ordered []any
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("module dependency cycle: %s -> %s", strings.Join(path[indexOf(path, name):], " -> "), name)
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		path = append(path, name)
+		module := entries[name].New()
+		built[name] = module
+		if d, ok := module.(Dependent); ok {
+			for _, dep := range d.DependsOn() {
+				entry, ok := entries[dep]
+				if !ok {
+					return fmt.Errorf("module %s depends on %s, which is not registered", name, dep)
+				}
+				if !entry.IsEnabled() {
+					return fmt.Errorf("module %s depends on %s, which is disabled", name, dep)
+				}
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = 2
+		ordered = append(ordered, built[name])
+		return nil
+	}
+	for _, m := range Modules() {
+		if m.IsEnabled() {
+			if err := visit(m.Name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ordered, nil
+}
+
+// InitModules calls the OnModuleInit hooks in order and stops at the first failure
+func InitModules(modules []any) error {
+	for _, m := range modules {
+		if i, ok := m.(Initializable); ok {
+			if err := i.OnModuleInit(); err != nil {
+				return fmt.Errorf("module %T: %w", m, err)
+			}
+		}
+	}
+	return nil
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return 0
+}
+`
+
+// moduleRegistryEntry is the registry line for a module generated by `g module`
+func moduleRegistryEntry(name string, enabled bool) string {
+	return fmt.Sprintf("{Name: %q, Enabled: %t, New: func() any { return %s }}", name, enabled, moduleConstructor(name))
+}
+
+// moduleConstructor is the call constructing a module. Dynamic modules are
+// passed their default config.
+func moduleConstructor(name string) string {
+	args := ""
+	if isDynamicModule(name) {
+		args = fmt.Sprintf("%s.Default%sConfig()", name, strings.Title(name))
+	}
+	return fmt.Sprintf("%s.New%sModule(%s)", name, strings.Title(name), args)
+}
+
+// isDynamicModule reports whether the module's constructor takes a config, as
+// generated by `g module --dynamic`
+func isDynamicModule(name string) bool {
+	src, err := os.ReadFile(filepath.Join("app", name, "module.go"))
+	return err == nil && strings.Contains(string(src), fmt.Sprintf("func Default%sConfig() ", strings.Title(name)))
+}
+
+// moduleRegistryContent renders the registry with the modules currently in app/.
+// Modules marked disabled in gonext.yaml are registered with Enabled: false.
+func moduleRegistryContent() string {
+	modules, _ := workspace.Modules()
+	m, _ := manifest.Load()
+	var imports, entries strings.Builder
+	if len(modules) > 0 {
+		imports.WriteString("\n")
+		entries.WriteString("\n")
+	}
+	for _, mod := range modules {
+		imports.WriteString(fmt.Sprintf("\t%q\n", getModuleName()+"/app/"+mod.Name))
+		entries.WriteString("\t\t" + moduleRegistryEntry(mod.Name, !m.Modules[mod.Name].Disabled) + ",\n")
+	}
+	if len(modules) > 0 {
+		entries.WriteString("\t")
+	}
+	return fmt.Sprintf(moduleRegistryTemplate, imports.String(), entries.String())
+}
+
+// bootstrapFiles returns the files generated by `g bootstrap`
+func bootstrapFiles() []codegen.File {
+	return []codegen.File{
+		{Path: filepath.Join(bootstrapDir, "shutdown.go"), Content: shutdownTemplate},
+		{Path: filepath.Join(bootstrapDir, "banner.go"), Content: bannerTemplate},
+		{Path: filepath.Join(bootstrapDir, "config.go"), Content: configCheckTemplate},
+		{Path: middlewareChainFile, Content: middlewareChainTemplate},
+		{Path: moduleRegistryFile, Content: moduleRegistryContent()},
+		{Path: moduleInitFile, Content: moduleInitTemplate},
+	}
+}
+
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Generate config validation, module registry, middleware chain, startup banner and graceful-shutdown helpers in bootstrap/",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !writeGenerated(bootstrapFiles()...) {
+			return
+		}
+		fmt.Printf("Bootstrap helpers created in %s/. Wire them into main.go:\n", bootstrapDir)
+		fmt.Print(`
+	bootstrap.MustValidateEnv(
+		bootstrap.EnvVar{Name: "SERVER_PORT", Kind: bootstrap.Port, Required: true},
+		bootstrap.EnvVar{Name: "DATABASE_URL", Kind: bootstrap.URL},
+	)
+	modules, err := bootstrap.OrderedModules()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := bootstrap.InitModules(modules); err != nil {
+		log.Fatal(err)
+	}
+	bootstrap.PrintBanner(server, bootstrap.BannerInfo{
+		Name: "my-app", Version: "v0.1.0", Env: os.Getenv("APP_ENV"), Addr: addr, Modules: len(modules),
+	})
+	bootstrap.UseMiddlewares(server)
+	go func() {
+		if err := server.Listen(addr); err != nil {
+			log.Println(err)
+		}
+	}()
+	if err := bootstrap.WaitForShutdown(server, bootstrap.ShutdownOptions{
+		Modules:   modules,
+		Consumers: []bootstrap.Drainer{taskQueue},
+	}); err != nil {
+		log.Println(err)
+	}
+`)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(bootstrapCmd)
+	gCmd.AddCommand(bootstrapCmd)
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+// clockDir holds the Clock provider shared by services, jobs and tasks
+var clockDir = filepath.Join("app", "clock")
+
+const clockTemplate = `// Package clock is the time source of the app. Components read the time from
+// an injected Clock instead of the time package, so tests can control it with
+// a Fake.
+package clock
+
+import "time"
+
+// Clock tells the time, sleeps and ticks. Bind System at startup with
+// container.Bind("clock", clock.System{}) and inject it with
+// inject:"name=clock".
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks on C at its period, like time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// System is the Clock of the time package
+type System struct{}
+
+// Now returns the current time
+func (System) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep pauses for at least d
+func (System) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// NewTicker returns a ticker ticking every d
+func (System) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// Or returns c, or System when c is nil, so a component whose Clock field
+// is not set, such as a job decoded by a worker, uses the real time
+func Or(c Clock) Clock {
+	if c == nil {
+		return System{}
+	}
+	return c
+}
+`
+
+const clockFakeTemplate = `package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock for tests. Its time only moves on Advance or Set, which
+// wake the sleepers and fire the tickers that are due.
+//
+//	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	svc := &service.UsersService{Clock: clk}
+//	clk.Advance(24 * time.Hour)
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+// waiter is a sleeper (period 0) or a ticker waiting for the fake time to reach at
+type waiter struct {
+	at     time.Time
+	period time.Duration
+	ch     chan time.Time
+}
+
+// NewFake returns a Fake clock set to now
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the fake time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Sleep blocks until the fake time has advanced by d
+func (f *Fake) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	<-f.add(d, 0).ch
+}
+
+// NewTicker returns a ticker that ticks each time the fake time passes a
+// multiple of d. Like time.Ticker, it drops the ticks a slow receiver misses.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return &fakeTicker{f: f, w: f.add(d, d)}
+}
+
+// Advance moves the fake time forward by d
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the fake time to t, waking the sleepers and firing the tickers
+// that are due by then
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = t
+	pending := f.waiters[:0]
+	for _, w := range f.waiters {
+		for !w.at.After(t) {
+			select {
+			case w.ch <- w.at:
+			default:
+			}
+			if w.period == 0 {
+				break
+			}
+			w.at = w.at.Add(w.period)
+		}
+		if w.period != 0 || w.at.After(t) {
+			pending = append(pending, w)
+		}
+	}
+	f.waiters = pending
+}
+
+// BlockUntil waits until n sleepers and tickers wait on the fake time, so a
+// test can advance it once the code under test, e.g. in a goroutine, sleeps
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+func (f *Fake) add(d, period time.Duration) *waiter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := &waiter{at: f.now.Add(d), period: period, ch: make(chan time.Time, 1)}
+	f.waiters = append(f.waiters, w)
+	f.cond.Broadcast()
+	return w
+}
+
+func (f *Fake) remove(w *waiter) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, other := range f.waiters {
+		if other == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+type fakeTicker struct {
+	f *Fake
+	w *waiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.w.ch
+}
+
+func (t *fakeTicker) Stop() {
+	t.f.remove(t.w)
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("clock: non-positive interval for Reset")
+	}
+	t.f.remove(t.w)
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	t.w.at, t.w.period = t.f.now.Add(d), d
+	t.f.waiters = append(t.f.waiters, t.w)
+	t.f.cond.Broadcast()
+}
+`
+
+// clockFiles returns the Clock provider files the project does not have yet
+func clockFiles() []codegen.File {
+	files := missingFile(codegen.File{Path: filepath.Join(clockDir, "clock.go"), Content: clockTemplate})
+	return append(files, missingFile(codegen.File{Path: filepath.Join(clockDir, "fake.go"), Content: clockFakeTemplate})...)
+}
+
+var clockCmd = &cobra.Command{
+	Use:   "clock",
+	Short: "Generate a Clock provider (Now/Sleep/NewTicker) with a fake for tests",
+	Long: `Generates app/clock: the Clock interface with Now, Sleep and NewTicker,
+System, backed by the time package, and Fake, whose time only moves when a test
+calls Advance or Set. Bind System at startup and inject it into services:
+
+  container.Bind("clock", clock.System{})
+
+  Clock clock.Clock ` + "`inject:\"name=clock\"`" + `
+
+Tests set the field to clock.NewFake(...) instead. Jobs and tasks generated by
+'gonext g job' and 'gonext g task' have a Clock field read through clock.Or,
+which falls back to System when the field is not set.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		files := clockFiles()
+		if len(files) == 0 {
+			fmt.Printf("%s already exists\n", clockDir)
+			return
+		}
+		if !writeGenerated(files...) {
+			return
+		}
+		fmt.Printf("Clock provider created in %s. Bind it in main.go:\n  container.Bind(\"clock\", clock.System{})\n", clockDir)
+		fmt.Println("Inject it into services with:\n  Clock clock.Clock `inject:\"name=clock\"`")
+		openIfRequested(files[0].Path)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(clockCmd)
+	gCmd.AddCommand(clockCmd)
+}
@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/spf13/cobra"
+)
+
+// configProvidersFile binds the config structs generated by `g config`
+var configProvidersFile = filepath.Join("app", "config", "providers.go")
+
+const configProvidersTemplate = `package config
+
+// Binder is the part of the DI container that registers named providers
+type Binder interface {
+	Bind(name string, value any)
+}
+
+// NamedConfig is a config struct bound under a provider name, injected with
+// inject:"name=<Provider>"
+type NamedConfig struct {
+	Provider string
+	Value    any
+}
+
+// NamedConfigs lists the config structs BindProviders binds.
+// 'gonext g config' adds the ones it generates.
+func NamedConfigs() []NamedConfig {
+	return []NamedConfig{}
+}
+
+// BindProviders binds every config struct in the container. Call it after Load,
+// so the components are injected filled structs.
+func BindProviders(container Binder) {
+	for _, c := range NamedConfigs() {
+		container.Bind(c.Provider, c.Value)
+	}
+}
+`
+
+// configFieldTypes are the types accepted in `g config` field specs
+var configFieldTypes = map[string]string{
+	"string":   "string",
+	"int":      "int",
+	"int64":    "int64",
+	"uint":     "uint",
+	"float":    "float64",
+	"bool":     "bool",
+	"duration": "time.Duration",
+	"strings":  "[]string",
+}
+
+// configField is a field of a config struct, read from <PREFIX>_<Env>
+type configField struct {
+	Name     string
+	Env      string
+	Type     string
+	Default  string
+	Required bool
+}
+
+// configPresets are the fields of well-known config structs, used when
+// `g config` is given no field specs
+var configPresets = map[string][]configField{
+	"database": {
+		{Name: "URL", Env: "URL", Type: "string", Required: true},
+		{Name: "MaxOpenConns", Env: "MAX_OPEN_CONNS", Type: "int", Default: "25"},
+		{Name: "MaxIdleConns", Env: "MAX_IDLE_CONNS", Type: "int", Default: "25"},
+		{Name: "ConnMaxLifetime", Env: "CONN_MAX_LIFETIME", Type: "time.Duration", Default: "30m"},
+		{Name: "ConnMaxIdleTime", Env: "CONN_MAX_IDLE_TIME", Type: "time.Duration", Default: "5m"},
+		{Name: "ConnectRetries", Env: "CONNECT_RETRIES", Type: "int", Default: "5"},
+	},
+	"redis": {
+		{Name: "Addr", Env: "ADDR", Type: "string", Default: "localhost:6379"},
+		{Name: "Password", Env: "PASSWORD", Type: "string"},
+		{Name: "DB", Env: "DB", Type: "int", Default: "0"},
+		{Name: "DialTimeout", Env: "DIAL_TIMEOUT", Type: "time.Duration", Default: "5s"},
+	},
+	"mail": {
+		{Name: "Host", Env: "HOST", Type: "string", Default: "localhost"},
+		{Name: "Port", Env: "PORT", Type: "int", Default: "1025"},
+		{Name: "Username", Env: "USERNAME", Type: "string"},
+		{Name: "Password", Env: "PASSWORD", Type: "string"},
+		{Name: "From", Env: "FROM", Type: "string", Required: true},
+	},
+}
+
+// parseConfigField parses name:type[:required|default=value]. The default is
+// the rest of the spec, so it may contain colons, e.g. default=localhost:6379.
+func parseConfigField(spec string) (configField, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || parts[0] == "" {
+		return configField{}, fmt.Errorf("invalid field %q (expected name:type[:required|default=value])", spec)
+	}
+	typ, ok := configFieldTypes[parts[1]]
+	if !ok {
+		return configField{}, fmt.Errorf("unknown type %q in field %q (expected string, int, int64, uint, float, bool, duration or strings)", parts[1], spec)
+	}
+	env := strings.ToUpper(proto.SnakeCase(parts[0]))
+	f := configField{Name: proto.GoName(parts[0]), Env: env, Type: typ}
+	for i := 2; i < len(parts); i++ {
+		switch modifier := parts[i]; {
+		case modifier == "required":
+			f.Required = true
+		case strings.HasPrefix(modifier, "default="):
+			f.Default = strings.TrimPrefix(strings.Join(parts[i:], ":"), "default=")
+			i = len(parts)
+		default:
+			return configField{}, fmt.Errorf("unknown modifier %q in field %q (expected required or default=value)", modifier, spec)
+		}
+	}
+	if f.Required && f.Default != "" {
+		return configField{}, fmt.Errorf("field %q can't be both required and have a default", spec)
+	}
+	return f, nil
+}
+
+// configContent renders a config struct registered with app/config under prefix
+func configContent(titleName, prefix, provider string, fields []configField) string {
+	var body strings.Builder
+	usesTime := false
+	if len(fields) == 0 {
+		fmt.Fprintf(&body, "\t// TODO: Add the settings, e.g.\n\t// Timeout time.Duration `env:\"TIMEOUT\" default:\"5s\"` // %s_TIMEOUT\n", prefix)
+	}
+	nameWidth, typeWidth, tagWidth := 0, 0, 0
+	tags := make([]string, len(fields))
+	for i, f := range fields {
+		env := f.Env
+		if f.Required {
+			env += ",required"
+		}
+		tags[i] = fmt.Sprintf("`env:%q`", env)
+		if f.Default != "" {
+			tags[i] = fmt.Sprintf("`env:%q default:%q`", env, f.Default)
+		}
+		nameWidth = max(nameWidth, len(f.Name))
+		typeWidth = max(typeWidth, len(f.Type))
+		tagWidth = max(tagWidth, len(tags[i]))
+		usesTime = usesTime || f.Type == "time.Duration"
+	}
+	for i, f := range fields {
+		fmt.Fprintf(&body, "\t%-*s %-*s %-*s // %s_%s\n", nameWidth, f.Name, typeWidth, f.Type, tagWidth, tags[i], prefix, f.Env)
+	}
+	imports := ""
+	if usesTime {
+		imports = "import \"time\"\n\n"
+	}
+	return fmt.Sprintf(`package config
+
+%[5]s// %[1]sConfig is read from %[2]s_* environment variables by Load at startup.
+// Components inject it with inject:"name=%[3]s" instead of reading the
+// variables themselves.
+type %[1]sConfig struct {
+%[4]s}
+
+// %[1]s holds the configuration once Load has run. BindProviders binds it as %[3]q.
+var %[1]s = &%[1]sConfig{}
+
+func init() {
+	Register(%[2]q, %[1]s)
+}
+`, titleName, prefix, provider, body.String(), imports)
+}
+
+// configStructFile returns the path of a config struct in app/config
+func configStructFile(name string) string {
+	return filepath.Join(filepath.Dir(configFile), proto.SnakeCase(name)+".go")
+}
+
+var generateConfigCmd = &cobra.Command{
+	Use:   "config [name] [field:type[:required|default=value]...]",
+	Short: "Generate a typed config struct read from the environment and bound in the DI container",
+	Long: `Generates app/config/<name>.go with a <Name>Config struct whose fields are read
+from <NAME>_* environment variables by config.Load, and adds it to
+config.NamedConfigs so config.BindProviders binds it as <name>Config:
+
+  gonext g config payments api_key:string:required timeout:duration:default=10s
+
+  type PaymentsConfig struct {
+  	APIKey  string        ` + "`env:\"API_KEY,required\"`" + `
+  	Timeout time.Duration ` + "`env:\"TIMEOUT\" default:\"10s\"`" + `
+  }
+
+Components inject it instead of reading the variables themselves:
+
+  Payments *config.PaymentsConfig ` + "`inject:\"name=paymentsConfig\"`" + `
+
+Types: string, int, int64, uint, float, bool, duration and strings (comma
+separated). Without field specs, database, redis and mail get their usual
+settings, e.g. DATABASE_URL and DATABASE_MAX_OPEN_CONNS; other names get an
+empty struct to fill in.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := proto.GoName(args[0])
+		if name == "" {
+			fmt.Printf("Invalid config name %q\n", args[0])
+			return
+		}
+		key := strings.ToLower(name[:1]) + name[1:]
+		if file := configStructFile(key); file == configFile || file == configProvidersFile {
+			fmt.Printf("%s is taken by app/config itself; choose another name\n", file)
+			return
+		}
+		fields := configPresets[key]
+		if len(args) > 1 {
+			fields = nil
+			seen := map[string]bool{}
+			for _, spec := range args[1:] {
+				f, err := parseConfigField(spec)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				if seen[f.Name] {
+					fmt.Printf("Field %s is listed twice\n", f.Name)
+					return
+				}
+				seen[f.Name] = true
+				fields = append(fields, f)
+			}
+		}
+		prefix := envPrefix(key)
+		provider := key + "Config"
+		newConfig := configMissing()
+		file := configStructFile(key)
+		files := []codegen.File{{Path: file, Content: configContent(name, prefix, provider, fields)}}
+		files = append(files, missingFile(codegen.File{Path: configFile, Content: configTemplate})...)
+		files = append(files, missingFile(codegen.File{Path: configProvidersFile, Content: configProvidersTemplate})...)
+		if !writeGenerated(files...) {
+			return
+		}
+		entry := fmt.Sprintf("{Provider: %q, Value: %s}", provider, name)
+		if err := editGenerated(configProvidersFile, func(src []byte) ([]byte, error) {
+			return codemod.AppendToSlice(src, "NamedConfigs", entry)
+		}); err != nil {
+			fmt.Printf("Error adding %s to %s: %v\n", provider, configProvidersFile, err)
+			return
+		}
+		fmt.Printf("Config '%sConfig' created at %s, read from %s_* environment variables.\n", name, file, prefix)
+		fmt.Printf("Inject it with:\n  %s *config.%sConfig `inject:\"name=%s\"`\n", name, name, provider)
+		if newConfig {
+			fmt.Println("Load and bind the config at startup, before the modules are initialized:\n  if err := config.Load(); err != nil {\n  \tlog.Fatal(err)\n  }\n  config.BindProviders(container)")
+		} else if len(files) > 1 {
+			fmt.Println("Bind the config after config.Load() at startup:\n  config.BindProviders(container)")
+		}
+		openIfRequested(file)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(generateConfigCmd)
+	gCmd.AddCommand(generateConfigCmd)
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import "fmt"
+
+// controllerOptions are the variations supported by the controller template
+type controllerOptions struct {
+	Bulk       bool          // Batch create/update/delete endpoints (--bulk)
+	Pagination string        // List endpoint paginated by offset or cursor (--pagination)
+	Filters    []filterField // Query parameters the list endpoint may filter on (--filter)
+	ETag       bool          // Conditional Get (304) and Update (412) (--etag)
+	Model      string        // Model request bodies are parsed into (--model)
+	ModelPkg   string        // Import path of the model's package
+}
+
+// controllerContent renders a controller with CRUD handler stubs. servicePkg is
+// the import path of the service package the controller is injected with.
+func controllerContent(servicePkg, titleName string, opts controllerOptions) string {
+	imports, list := "", ""
+	found, create, body := "interface{}", "", ""
+	if opts.Model != "" {
+		imports = fmt.Sprintf("\n\t\"%s\"", opts.ModelPkg)
+		found = "*model." + opts.Model
+		body = fmt.Sprintf(`	var input model.%[1]s
+	if err := ctx.BodyParser(&input); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+`, opts.Model)
+		create = fmt.Sprintf(`// Create%[1]s handles creating a new %[1]s
+func (c *%[1]sController) Create%[1]s(ctx *fiber.Ctx) error {
+%[2]s	// TODO: Implement create logic, e.g. c.Service.Create%[1]s(&input)
+	return nil
+}
+`, titleName, body)
+	}
+	switch {
+	case len(opts.Filters) > 0:
+		imports += fmt.Sprintf("\n\t\"%[1]s/app/filter\"\n\t\"%[1]s/app/pagination\"", getModuleName())
+		list = fmt.Sprintf(`
+%[2]s
+// List%[1]s handles listing %[1]s records a page at a time, narrowed by %[1]sFilters
+func (c *%[1]sController) List%[1]s(ctx *fiber.Ctx) error {
+	params, err := pagination.%[3]s(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	filters, err := filter.Parse(ctx, %[1]sFilters)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	// TODO: Load the page from the service, e.g. c.Service.List%[1]s(ctx.UserContext(), params, filters)
+	_ = filters
+	return ctx.JSON(pagination.%[4]s[any]{Limit: params.Limit})
+}
+`, titleName, filterAllowList(titleName, opts.Filters), paginationParser(opts.Pagination), paginationPage(opts.Pagination))
+	case opts.Pagination != "":
+		imports += fmt.Sprintf("\n\t\"%s/app/pagination\"", getModuleName())
+		list = fmt.Sprintf(`
+// List%[1]s handles listing %[1]s records a page at a time
+func (c *%[1]sController) List%[1]s(ctx *fiber.Ctx) error {
+	params, err := pagination.%[2]s(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	// TODO: Load the page from the service, e.g. c.Service.List%[1]s(ctx.UserContext(), params)
+	return ctx.JSON(pagination.%[3]s[any]{Limit: params.Limit})
+}
+`, titleName, paginationParser(opts.Pagination), paginationPage(opts.Pagination))
+	}
+	get := fmt.Sprintf(`// Get%[1]s handles retrieving a %[1]s by ID
+func (c *%[1]sController) Get%[1]s(ctx *fiber.Ctx) error {
+	// TODO: Implement get logic
+	return nil
+}
+`, titleName)
+	update := fmt.Sprintf(`// Update%[1]s handles updating a %[1]s by ID
+func (c *%[1]sController) Update%[1]s(ctx *fiber.Ctx) error {
+%[2]s	// TODO: Implement update logic
+	return nil
+}
+`, titleName, body)
+	if opts.ETag {
+		imports += fmt.Sprintf("\n\t\"%s/app/etag\"", getModuleName())
+		get = fmt.Sprintf(`// Get%[1]s handles retrieving a %[1]s by ID. It sends an ETag and answers
+// 304 Not Modified when the client's If-None-Match already has the current version.
+func (c *%[1]sController) Get%[1]s(ctx *fiber.Ctx) error {
+	// TODO: Load the %[1]s from the service
+	var found %[2]s
+	return etag.JSON(ctx, found)
+}
+`, titleName, found)
+		update = fmt.Sprintf(`// Update%[1]s handles updating a %[1]s by ID. A stale If-Match header is
+// rejected with 412 Precondition Failed instead of overwriting a newer version.
+func (c *%[1]sController) Update%[1]s(ctx *fiber.Ctx) error {
+	// TODO: Load the current %[1]s from the service
+	var current %[2]s
+	if err := etag.IfMatch(ctx, current); err != nil {
+		return err
+	}
+%[3]s	// TODO: Implement update logic
+	return nil
+}
+`, titleName, found, body)
+	}
+	if create == "" {
+		create = fmt.Sprintf(`// Create%[1]s handles creating a new %[1]s
+func (c *%[1]sController) Create%[1]s(ctx *fiber.Ctx) error {
+	// TODO: Implement create logic
+	return nil
+}
+`, titleName)
+	}
+	bulk := ""
+	if opts.Bulk {
+		bulk = fmt.Sprintf(`
+// BulkCreate%[1]s handles creating many %[1]s records in one request
+func (c *%[1]sController) BulkCreate%[1]s(ctx *fiber.Ctx) error {
+	// TODO: Parse the list and pass it to the service; the repository writes it in chunks inside one transaction
+	return nil
+}
+
+// BulkUpdate%[1]s handles updating many %[1]s records in one request
+func (c *%[1]sController) BulkUpdate%[1]s(ctx *fiber.Ctx) error {
+	// TODO: Parse the list and pass it to the service; a failing item rolls back the whole batch
+	return nil
+}
+
+// BulkDelete%[1]s handles deleting many %[1]s records by ID in one request
+func (c *%[1]sController) BulkDelete%[1]s(ctx *fiber.Ctx) error {
+	// TODO: Parse the IDs and pass them to the service
+	return nil
+}
+`, titleName)
+	}
+	return fmt.Sprintf(`package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"%[2]s"%[4]s
+)
+
+type %[1]sController struct {
+	Service *service.%[1]sService `+"`inject:\"type\"`"+`
+}
+
+%[8]s
+%[6]s
+%[7]s
+// Delete%[1]s handles deleting a %[1]s by ID
+func (c *%[1]sController) Delete%[1]s(ctx *fiber.Ctx) error {
+	// TODO: Implement delete logic
+	return nil
+}
+%[5]s%[3]s`, titleName, servicePkg, bulk, imports, list, get, update, create)
+}
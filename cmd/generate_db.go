@@ -0,0 +1,463 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/spf13/cobra"
+)
+
+// databaseDir holds the generated database providers
+var databaseDir = filepath.Join("app", "database")
+
+const dbProviderTemplate = `package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/XSAM/otelsql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// PoolConfig sizes the connection pool. Every field can be set from the environment.
+type PoolConfig struct {
+	URL             string        // DATABASE_URL
+	MaxOpenConns    int           // DB_MAX_OPEN_CONNS (default 25)
+	MaxIdleConns    int           // DB_MAX_IDLE_CONNS (default 25)
+	ConnMaxLifetime time.Duration // DB_CONN_MAX_LIFETIME (default 30m)
+	ConnMaxIdleTime time.Duration // DB_CONN_MAX_IDLE_TIME (default 5m)
+	ConnectRetries  int           // DB_CONNECT_RETRIES (default 5)
+}
+
+// PoolConfigFromEnv reads the pool configuration, applying sane defaults
+func PoolConfigFromEnv() PoolConfig {
+	return PoolConfig{
+		URL:             os.Getenv("DATABASE_URL"),
+		MaxOpenConns:    envInt("DB_MAX_OPEN_CONNS", 25),
+		MaxIdleConns:    envInt("DB_MAX_IDLE_CONNS", 25),
+		ConnMaxLifetime: envDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		ConnMaxIdleTime: envDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+		ConnectRetries:  envInt("DB_CONNECT_RETRIES", 5),
+	}
+}
+
+// Open connects to Postgres through pgx with OpenTelemetry tracing and metrics,
+// retrying with exponential backoff while the database is starting up. Queries
+// are timed when DB_QUERY_LOG is enabled (see querylog.go).
+func Open(ctx context.Context, cfg PoolConfig) (*sql.DB, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("DATABASE_URL is not set")
+	}
+	db := otelsql.OpenDB(connector(cfg.URL), otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
+	if _, err := otelsql.RegisterDBStatsMetrics(db, otelsql.WithAttributes(semconv.DBSystemPostgreSQL)); err != nil {
+		db.Close()
+		return nil, err
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	backoff := 500 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := db.PingContext(pingCtx)
+		cancel()
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= cfg.ConnectRetries {
+			db.Close()
+			return nil, fmt.Errorf("database not reachable after %d attempts: %w", attempt+1, err)
+		}
+		log.Printf("database not ready (%v), retrying in %s", err, backoff)
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// HealthCheck returns a check suitable for health/readiness endpoints
+func HealthCheck(db *sql.DB) func(context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		return db.PingContext(ctx)
+	}
+}
+
+func envInt(key string, fallback int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return v
+	}
+	return fallback
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return v
+	}
+	return fallback
+}
+`
+
+const dbManagerTemplate = `package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DefaultConnection is the connection configured by DATABASE_URL
+const DefaultConnection = "default"
+
+// Manager opens named connections on first use. A connection named "reporting"
+// reads DATABASE_REPORTING_URL, so it can point at a read replica or a separate
+// database. Pool sizing falls back to the DB_* variables and can be overridden
+// per connection, e.g. DB_REPORTING_MAX_OPEN_CONNS.
+type Manager struct {
+	ctx   context.Context
+	mu    sync.Mutex
+	conns map[string]*sql.DB
+}
+
+// NewManager creates a connection manager. ctx bounds the retries while connecting.
+func NewManager(ctx context.Context) *Manager {
+	return &Manager{ctx: ctx, conns: map[string]*sql.DB{}}
+}
+
+// ConnectionConfig returns the pool configuration of a named connection
+func ConnectionConfig(name string) PoolConfig {
+	cfg := PoolConfigFromEnv()
+	if name == DefaultConnection {
+		return cfg
+	}
+	prefix := "DB_" + strings.ToUpper(name) + "_"
+	cfg.URL = os.Getenv("DATABASE_" + strings.ToUpper(name) + "_URL")
+	cfg.MaxOpenConns = envInt(prefix+"MAX_OPEN_CONNS", cfg.MaxOpenConns)
+	cfg.MaxIdleConns = envInt(prefix+"MAX_IDLE_CONNS", cfg.MaxIdleConns)
+	cfg.ConnMaxLifetime = envDuration(prefix+"CONN_MAX_LIFETIME", cfg.ConnMaxLifetime)
+	cfg.ConnMaxIdleTime = envDuration(prefix+"CONN_MAX_IDLE_TIME", cfg.ConnMaxIdleTime)
+	return cfg
+}
+
+// Get returns the named connection, opening it on first use
+func (m *Manager) Get(name string) (*sql.DB, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if db, ok := m.conns[name]; ok {
+		return db, nil
+	}
+	db, err := Open(m.ctx, ConnectionConfig(name))
+	if err != nil {
+		return nil, err
+	}
+	m.conns[name] = db
+	return db, nil
+}
+
+// HealthChecks returns a check per open connection, keyed by "database:<name>"
+func (m *Manager) HealthChecks() map[string]func(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	checks := make(map[string]func(context.Context) error, len(m.conns))
+	for name, db := range m.conns {
+		checks["database:"+name] = HealthCheck(db)
+	}
+	return checks
+}
+
+// OnModuleDestroy closes every open connection
+func (m *Manager) OnModuleDestroy() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var errs []error
+	for name, db := range m.conns {
+		errs = append(errs, db.Close())
+		delete(m.conns, name)
+	}
+	return errors.Join(errs...)
+}
+`
+
+// dbProvidersFile binds the connections injected by name
+var dbProvidersFile = filepath.Join(databaseDir, "providers.go")
+
+const dbProvidersTemplate = `package database
+
+import "fmt"
+
+// Binder is the part of the DI container that registers named providers
+type Binder interface {
+	Bind(name string, value any)
+}
+
+// NamedConnection is a connection bound under a provider name, injected with
+// inject:"name=<Provider>"
+type NamedConnection struct {
+	Provider   string
+	Connection string
+}
+
+// NamedConnections lists the connections BindProviders binds.
+// 'gonext g repository --connection' adds the connections it uses.
+func NamedConnections() []NamedConnection {
+	return []NamedConnection{
+		{Provider: "db", Connection: DefaultConnection},
+	}
+}
+
+// BindProviders opens the named connections and binds them in the container
+func BindProviders(container Binder, connections *Manager) error {
+	for _, n := range NamedConnections() {
+		db, err := connections.Get(n.Connection)
+		if err != nil {
+			return fmt.Errorf("connection %s: %w", n.Connection, err)
+		}
+		container.Bind(n.Provider, db)
+	}
+	return nil
+}
+`
+
+// connectionProvider is the provider name a connection is injected by:
+// db for the default connection and <name>DB for the others
+func connectionProvider(connection string) string {
+	if connection == "" || connection == "default" {
+		return "db"
+	}
+	return connection + "DB"
+}
+
+// bindConnection adds a connection to database.NamedConnections
+func bindConnection(connection string) error {
+	entry := fmt.Sprintf("{Provider: %q, Connection: %q}", connectionProvider(connection), connection)
+	return editGenerated(dbProvidersFile, func(src []byte) ([]byte, error) {
+		return codemod.AppendToSlice(src, "NamedConnections", entry)
+	})
+}
+
+// databaseFiles returns the database provider and connection manager
+func databaseFiles() []codegen.File {
+	return []codegen.File{
+		{Path: filepath.Join(databaseDir, "provider.go"), Content: dbProviderTemplate},
+		{Path: filepath.Join(databaseDir, "manager.go"), Content: dbManagerTemplate},
+		{Path: filepath.Join(databaseDir, "querylog.go"), Content: dbQueryLogTemplate},
+		{Path: dbProvidersFile, Content: dbProvidersTemplate},
+	}
+}
+
+// missingDatabaseFiles returns the database files that do not exist yet, so
+// generators that depend on them do not regenerate the user's copies
+func missingDatabaseFiles() []codegen.File {
+	var files []codegen.File
+	for _, f := range databaseFiles() {
+		files = append(files, missingFile(f)...)
+	}
+	return files
+}
+
+// dbMigrateOnStart is set by `g db:provider --migrate-on-start`
+var dbMigrateOnStart bool
+
+// migrationsDir holds the SQL migrations applied by `gonext db migrate` and on start
+const migrationsDir = "migrations"
+
+// migrateCommandFile is the project's migration runner, invoked by `gonext db migrate`
+var migrateCommandFile = filepath.Join("cmd", "migrate", "main.go")
+
+const dbMigrateTemplate = `package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MigrationsDir holds <timestamp>_<name>.up.sql files, applied in lexical order
+const MigrationsDir = "migrations"
+
+// migrationLockKey identifies the Postgres advisory lock held while migrating
+const migrationLockKey int64 = 4_242_001
+
+// MigrateOnStart applies pending migrations when DB_MIGRATE_ON_START is true.
+// Every instance of a rolling deploy may call it: the advisory lock makes the
+// others wait, and they then find nothing left to apply.
+func MigrateOnStart(ctx context.Context, db *sql.DB) error {
+	if on, _ := strconv.ParseBool(os.Getenv("DB_MIGRATE_ON_START")); !on {
+		return nil
+	}
+	return Migrate(ctx, db, MigrationsDir)
+}
+
+// Migrate applies every pending migration in dir, each in its own transaction,
+// while holding an advisory lock so concurrent instances never migrate at once.
+// Applied versions are recorded in schema_migrations.
+func Migrate(ctx context.Context, db *sql.DB, dir string) error {
+	// Advisory locks belong to a session, so lock, migrate and unlock on one connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return fmt.Errorf("waiting for the migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version text PRIMARY KEY, applied_at timestamptz NOT NULL DEFAULT now())"); err != nil {
+		return err
+	}
+	applied := map[string]bool{}
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		version := strings.TrimSuffix(filepath.Base(file), ".up.sql")
+		if applied[version] {
+			continue
+		}
+		script, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %w", version, err)
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", version); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		log.Printf("applied migration %s", version)
+	}
+	return nil
+}
+`
+
+const migrateCommandTemplate = `package main
+
+import (
+	"context"
+	"log"
+
+	"%s/app/database"
+)
+
+// Applies pending migrations, run with 'gonext db migrate'
+func main() {
+	ctx := context.Background()
+	db, err := database.Open(ctx, database.PoolConfigFromEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	if err := database.Migrate(ctx, db, database.MigrationsDir); err != nil {
+		log.Fatal(err)
+	}
+}
+`
+
+// migrationFiles returns the migration runner used on start and by `gonext db migrate`
+func migrationFiles() []codegen.File {
+	return []codegen.File{
+		{Path: filepath.Join(databaseDir, "migrate.go"), Content: dbMigrateTemplate},
+		{Path: migrateCommandFile, Content: fmt.Sprintf(migrateCommandTemplate, getModuleName())},
+	}
+}
+
+var dbProviderCmd = &cobra.Command{
+	Use:   "db:provider",
+	Short: "Generate a pgx/sql.DB provider and connection manager with env-driven pool sizing, OpenTelemetry, retry-on-start and health checks",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		files := databaseFiles()
+		if dbMigrateOnStart {
+			files = append(files, migrationFiles()...)
+		}
+		if !writeGenerated(files...) {
+			return
+		}
+		fmt.Printf("Database provider created in %s. Install its dependencies with:\n", databaseDir)
+		fmt.Println("  go get github.com/jackc/pgx/v5 github.com/XSAM/otelsql go.opentelemetry.io/otel")
+		fmt.Println("Then register it in main.go:")
+		fmt.Print(`
+	connections := database.NewManager(ctx)
+	if err := database.BindProviders(container, connections); err != nil {
+		log.Fatal(err)
+	}
+	container.Bind("connections", connections)
+`)
+		if dbMigrateOnStart {
+			if err := os.MkdirAll(migrationsDir, 0755); err != nil {
+				fmt.Printf("Error creating %s: %v\n", migrationsDir, err)
+				return
+			}
+			fmt.Print(`	db, err := connections.Get(database.DefaultConnection)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := database.MigrateOnStart(ctx, db); err != nil {
+		log.Fatal(err)
+	}
+`)
+			fmt.Println("\nSet DB_MIGRATE_ON_START=true to migrate on start. Create migrations with 'gonext db migrate:create <name>'.")
+		}
+		openIfRequested(files[0].Path, files[1].Path)
+	},
+}
+
+func init() {
+	dbProviderCmd.Flags().BoolVar(&dbMigrateOnStart, "migrate-on-start", false, "Also generate a migration runner that applies migrations on start under an advisory lock when DB_MIGRATE_ON_START=true")
+	generateCmd.AddCommand(dbProviderCmd)
+	gCmd.AddCommand(dbProviderCmd)
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"go/format"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// deprecationDir holds the middleware announcing deprecated routes and its route table
+var deprecationDir = filepath.Join("app", "deprecation")
+
+const deprecationTemplate = `package deprecation
+
+import "github.com/gofiber/fiber/v2"
+
+// Notice holds the headers sent on the responses of a deprecated route
+type Notice struct {
+	Deprecation string // @<unix time> of the deprecation, or true when undated
+	Sunset      string // HTTP date after which the route is removed
+	Link        string // migration guide
+}
+
+// Middleware adds the Deprecation, Sunset and Link headers (RFC 9745, RFC 8594)
+// to responses of the routes in Routes, so clients learn about a deprecation
+// before the route is removed.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		route := c.Route()
+		notice, ok := Routes[route.Method+" "+route.Path]
+		if !ok {
+			notice, ok = Routes["ALL "+route.Path]
+		}
+		if !ok {
+			return err
+		}
+		c.Set("Deprecation", notice.Deprecation)
+		if notice.Sunset != "" {
+			c.Set("Sunset", notice.Sunset)
+		}
+		if notice.Link != "" {
+			c.Set(fiber.HeaderLink, "<"+notice.Link+">; rel=\"deprecation\"")
+		}
+		return err
+	}
+}
+`
+
+const deprecationRoutesTemplate = `package deprecation
+
+// Routes are the deprecated routes, by method and path. Regenerate this file
+// with 'gonext g http:deprecation' after changing a Deprecated: annotation.
+var Routes = map[string]Notice{
+%s}
+`
+
+// deprecationRoutes renders the route table from the Deprecated: annotations
+// and returns it with the number of deprecated routes
+func deprecationRoutes(routes []workspace.Route) (string, int, error) {
+	var b strings.Builder
+	count := 0
+	for _, r := range routes {
+		d := r.Deprecation
+		if d == nil {
+			continue
+		}
+		location := fmt.Sprintf("%s:%d", r.File, r.Line)
+		notice := "true"
+		if d.Since != "" {
+			since, err := time.Parse(time.DateOnly, d.Since)
+			if err != nil {
+				return "", 0, fmt.Errorf("%s: invalid Since date %q, expected YYYY-MM-DD", location, d.Since)
+			}
+			notice = fmt.Sprintf("@%d", since.Unix())
+		}
+		sunset := ""
+		if d.Sunset != "" {
+			t, err := time.Parse(time.DateOnly, d.Sunset)
+			if err != nil {
+				return "", 0, fmt.Errorf("%s: invalid Sunset date %q, expected YYYY-MM-DD", location, d.Sunset)
+			}
+			sunset = t.Format(http.TimeFormat)
+		}
+		fmt.Fprintf(&b, "\t%q: {Deprecation: %q, Sunset: %q, Link: %q},\n", r.Method+" "+r.Path, notice, sunset, d.Link)
+		count++
+	}
+	src, err := format.Source([]byte(fmt.Sprintf(deprecationRoutesTemplate, b.String())))
+	return string(src), count, err
+}
+
+var deprecationCmd = &cobra.Command{
+	Use:   "http:deprecation",
+	Short: "Generate a middleware sending Deprecation and Sunset headers for routes annotated as deprecated",
+	Long: `Scans the module routes for a Deprecated: comment above the registration:
+
+	// Deprecated: use GET /api/v2/users instead.
+	// Since: 2025-01-31
+	// Sunset: 2025-12-31
+	// Link: https://example.com/docs/migrate-to-v2
+	router.Get("/users", ctrl.List)
+
+and generates a middleware adding the Deprecation, Sunset and Link headers to
+their responses. Run it again after changing an annotation.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		routes, err := workspace.Routes()
+		if err != nil {
+			fmt.Printf("Error scanning routes: %v\n", err)
+			return
+		}
+		table, count, err := deprecationRoutes(routes)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		middlewareFile := filepath.Join(deprecationDir, "deprecation.go")
+		routesFile := filepath.Join(deprecationDir, "routes.go")
+		files := append(missingFile(codegen.File{Path: middlewareFile, Content: deprecationTemplate}),
+			codegen.File{Path: routesFile, Content: table})
+		if !writeGenerated(files...) {
+			return
+		}
+		fmt.Printf("%d deprecated route(s) written to %s\n", count, routesFile)
+		if _, err := os.Stat(middlewareChainFile); err == nil {
+			if err := addGlobalMiddleware(getModuleName()+"/app/deprecation", "deprecation.Middleware()"); err != nil {
+				fmt.Printf("Error wiring middleware into %s: %v\n", middlewareChainFile, err)
+				return
+			}
+			fmt.Printf("deprecation.Middleware() added to %s\n", middlewareChainFile)
+		} else {
+			fmt.Println("Register the middleware with server.Use(deprecation.Middleware()) to announce deprecated routes.")
+		}
+		openIfRequested(routesFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(deprecationCmd)
+	gCmd.AddCommand(deprecationCmd)
+}
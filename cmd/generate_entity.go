@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/spf13/cobra"
+)
+
+// generateVersioned is set by `g entity --versioned` and `g repository --versioned`
+var generateVersioned bool
+
+// exceptionDir holds the typed errors and the exception filter that maps them to HTTP responses
+var exceptionDir = filepath.Join("app", "exception")
+
+const exceptionFilterTemplate = `package exception
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Problem is an RFC 9457 problem details body, sent as application/problem+json
+type Problem struct {
+	Type     string ` + "`json:\"type,omitempty\"`" + `
+	Title    string ` + "`json:\"title\"`" + `
+	Status   int    ` + "`json:\"status\"`" + `
+	Detail   string ` + "`json:\"detail,omitempty\"`" + `
+	Instance string ` + "`json:\"instance,omitempty\"`" + `
+}
+
+// NewProblem returns a problem with the standard title of the status
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{Title: http.StatusText(status), Status: status, Detail: detail}
+}
+
+// Filter maps an error to a problem, or returns nil to leave it to the next filter
+type Filter func(err error) *Problem
+
+var filters []Filter
+
+// Register adds a filter consulted by Handler before the built-in mappings, in
+// registration order. Modules register theirs in their Register method.
+func Register(filter Filter) {
+	filters = append(filters, filter)
+}
+
+// ConflictError is returned when an update carries a stale version
+type ConflictError struct {
+	Resource string
+	ID       string
+	Version  int
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %s was modified by someone else (version %d is stale)", e.Resource, e.ID, e.Version)
+}
+
+// Handler is the exception filter. Register it with fiber.Config{ErrorHandler: exception.Handler}.
+func Handler(c *fiber.Ctx, err error) error {
+	problem := toProblem(err)
+	problem.Instance = c.OriginalURL()
+	return c.Status(problem.Status).JSON(problem, "application/problem+json")
+}
+
+func toProblem(err error) *Problem {
+	for _, filter := range filters {
+		if problem := filter(err); problem != nil {
+			return problem
+		}
+	}
+	var conflict *ConflictError
+	if errors.As(err, &conflict) {
+		return NewProblem(fiber.StatusConflict, conflict.Error())
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return NewProblem(fiberErr.Code, fiberErr.Message)
+	}
+	// Unmapped errors may carry internal details, so only the status is reported
+	return NewProblem(fiber.StatusInternalServerError, "")
+}
+`
+
+// entityVersionField is the optimistic lock column of versioned entities
+const entityVersionField = "Version int `json:\"version\" db:\"version\" description:\"Optimistic lock version, send it back unchanged on update\"`"
+
+// printFieldColumns shows the columns and dependencies the fields need
+func printFieldColumns(table string, fields []modelField) {
+	if len(fields) == 0 {
+		return
+	}
+	fmt.Printf("Declare the columns of %s as:\n", table)
+	var indexes []string
+	for _, f := range fields {
+		column := f.Column + " " + f.Type.Column
+		if !f.Nullable {
+			column += " NOT NULL"
+		}
+		if f.Unique {
+			column += " UNIQUE"
+		} else if f.Index {
+			indexes = append(indexes, fmt.Sprintf("  CREATE INDEX ON %s (%s);", table, f.Column))
+		}
+		if f.Default != "" {
+			column += " DEFAULT " + f.Default
+		}
+		if f.Type.Import == "app/money" {
+			column += " -- cents"
+		}
+		fmt.Println("  " + column)
+	}
+	for _, index := range indexes {
+		fmt.Println(index)
+	}
+	printDecimalDependency(fields)
+}
+
+// entityContent renders a persisted entity whose ID is of type idType, with
+// fields after the ID and an optimistic lock version when versioned
+func entityContent(titleName string, versioned bool, idType string, fields ...modelField) string {
+	type column struct{ name, typ, tag string }
+	columns := []column{{"ID", idType, "`json:\"id\" db:\"id\"`"}}
+	for _, f := range fields {
+		json := f.Column
+		if f.Nullable {
+			json += ",omitempty"
+		}
+		columns = append(columns, column{f.Name, f.goType(), fmt.Sprintf("`json:%q db:%q`", json, f.Column)})
+	}
+	if versioned {
+		columns = append(columns, column{"Version", "int", "`json:\"version\" db:\"version\" description:\"Optimistic lock version, send it back unchanged on update\"`"})
+	}
+	columns = append(columns,
+		column{"CreatedAt", "time.Time", "`json:\"created_at\" db:\"created_at\"`"},
+		column{"UpdatedAt", "time.Time", "`json:\"updated_at\" db:\"updated_at\"`"})
+	nameWidth, typeWidth := 0, 0
+	for _, c := range columns {
+		nameWidth = max(nameWidth, len(c.name))
+		typeWidth = max(typeWidth, len(c.typ))
+	}
+	var body strings.Builder
+	for _, c := range columns {
+		fmt.Fprintf(&body, "\t%-*s %-*s %s\n", nameWidth, c.name, typeWidth, c.typ, c.tag)
+	}
+	std, other := fieldImports(fields)
+	if strings.HasPrefix(idType, "id.") {
+		other = append(other, fmt.Sprintf("%q", getModuleName()+"/app/id"))
+	}
+	imports := "import \"time\"\n\n"
+	if len(other) > 0 {
+		imports = importBlock(append(std, `"time"`), other)
+	}
+	return fmt.Sprintf(`package entity
+
+%[2]s// %[1]s is the persisted %[1]s record
+type %[1]s struct {
+%[3]s}
+`, titleName, imports, body.String())
+}
+
+// entityFile returns the path of an entity in a module
+func entityFile(module, name string) string {
+	return filepath.Join("app", module, "entity", fmt.Sprintf("%sEntity.go", name))
+}
+
+// addEntityVersion adds the Version column to an existing entity that was created
+// without it, so a versioned repository compiles against it
+func addEntityVersion(module, name, titleName string) error {
+	file := entityFile(module, name)
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return nil
+	}
+	added := false
+	err := editGenerated(file, func(src []byte) ([]byte, error) {
+		out, err := codemod.AddStructField(src, titleName, "Version", entityVersionField)
+		added = err == nil && string(out) != string(src)
+		return out, err
+	})
+	if err != nil {
+		return err
+	}
+	if added {
+		fmt.Printf("Added a Version column to %s. Add it to the table as well, e.g.:\n  ALTER TABLE %ss ADD COLUMN version integer NOT NULL DEFAULT 1;\n", file, strings.ToLower(name))
+	}
+	return nil
+}
+
+// missingFile returns f only if it does not exist yet, so dependent scaffolding
+// is created once and never regenerated over the user's copy
+func missingFile(f codegen.File) []codegen.File {
+	if _, err := os.Stat(f.Path); os.IsNotExist(err) {
+		return []codegen.File{f}
+	}
+	return nil
+}
+
+var entityCmd = &cobra.Command{
+	Use:   "entity [name] [in_module] [field:type[:modifier...]...]",
+	Short: "Generate a persisted entity in a module (creates module if needed)",
+	Long: `Generates app/<module>/entity/<name>Entity.go with ID and timestamp columns and
+the fields given as name:type[:modifier...], the specs of 'g model', e.g.
+
+  gonext g entity order orders price:money amount:decimal shipped_at:time:nullable
+
+money is a money.Amount of integer cents (app/money, created if missing) and
+decimal a shopspring decimal.Decimal, so amounts never round like float64. Both
+are decimal strings in JSON. The command prints the columns to declare.`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		titleName := strings.Title(name)
+		if err := validateIDStrategy(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fields, err := parseModelFields(args[2:])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+		file := entityFile(module, name)
+		files := []codegen.File{{Path: file, Content: entityContent(titleName, generateVersioned, entityIDType(module, name, titleName), fields...)}}
+		files = append(files, idFiles(generateIDStrategy)...)
+		if !writeGenerated(append(files, fieldFiles(fields)...)...) {
+			return
+		}
+		fmt.Printf("Entity '%s' created in app/%s/entity\n", name, module)
+		printIDColumn(strings.ToLower(name) + "s")
+		printFieldColumns(strings.ToLower(name)+"s", fields)
+		openIfRequested(file)
+	},
+}
+
+func init() {
+	entityCmd.Flags().BoolVar(&generateVersioned, "versioned", false, "Add a Version column for optimistic locking")
+	entityCmd.Flags().StringVar(&generateIDStrategy, "id", "", "Type and generate the ID: uuid, ulid, snowflake or autoincrement (default: untyped string)")
+	generateCmd.AddCommand(entityCmd)
+	gCmd.AddCommand(entityCmd)
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/spf13/cobra"
+)
+
+// enumModule is set by `g enum --module`
+var enumModule string
+
+// enumValue matches the values of `g enum`, e.g. pending or in_transit
+var enumValue = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// enumContent renders a string-backed enum with its constants, String, JSON
+// marshaling that rejects undeclared values, and validation helpers
+func enumContent(typeName string, values []string) string {
+	var consts, list, names []string
+	width := 0
+	for _, v := range values {
+		width = max(width, len(typeName+proto.GoName(v)))
+	}
+	for _, v := range values {
+		constName := typeName + proto.GoName(v)
+		consts = append(consts, fmt.Sprintf("\t%-*s %s = %q", width, constName, typeName, v))
+		list = append(list, constName)
+		names = append(names, v)
+	}
+	return fmt.Sprintf(`package enum
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// %[1]s is one of %[2]s. It is stored and sent as its string value.
+type %[1]s string
+
+const (
+%[3]s
+)
+
+// %[1]sValues lists the values of %[1]s in declaration order
+var %[1]sValues = []%[1]s{%[4]s}
+
+// String returns the value as it is stored and sent
+func (e %[1]s) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is one of the declared values
+func (e %[1]s) IsValid() bool {
+	switch e {
+	case %[5]s:
+		return true
+	}
+	return false
+}
+
+// Validate returns an error listing the declared values if e is not one of them
+func (e %[1]s) Validate() error {
+	if e.IsValid() {
+		return nil
+	}
+	return fmt.Errorf("invalid %[1]s %%q: expected one of %[6]s", string(e))
+}
+
+// Parse%[1]s returns the %[1]s of value, ignoring case and surrounding spaces
+func Parse%[1]s(value string) (%[1]s, error) {
+	e := %[1]s(strings.ToLower(strings.TrimSpace(value)))
+	return e, e.Validate()
+}
+
+// MarshalJSON fails on undeclared values rather than sending them
+func (e %[1]s) MarshalJSON() ([]byte, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(e))
+}
+
+// UnmarshalJSON accepts only the declared values, so a request body with any
+// other value fails to parse
+func (e *%[1]s) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("%[1]s must be a string: %%w", err)
+	}
+	parsed, err := Parse%[1]s(value)
+	if err != nil {
+		return err
+	}
+	*e = parsed
+	return nil
+}
+`, typeName, enumList(list), strings.Join(consts, "\n"), strings.Join(list, ", "), strings.Join(list, ", "), strings.Join(names, ", "))
+}
+
+// enumList joins names as "A, B or C"
+func enumList(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
+
+// enumFile returns the path of an enum: the shared app/enum package, or the
+// module's enum package with --module
+func enumFile(module, typeName string) string {
+	dir := filepath.Join("app", "enum")
+	if module != "" {
+		dir = filepath.Join("app", module, "enum")
+	}
+	return filepath.Join(dir, strings.ToLower(typeName[:1])+typeName[1:]+".go")
+}
+
+var enumCmd = &cobra.Command{
+	Use:   "enum [name] [value,value...]",
+	Short: "Generate a typed enum with constants, String, JSON marshaling and validation",
+	Long: `Generates a string-backed enum in app/enum, or app/<module>/enum with --module:
+
+  gonext g enum OrderStatus pending,paid,shipped
+
+declares OrderStatusPending, OrderStatusPaid and OrderStatusShipped, stored and
+sent as "pending", "paid" and "shipped", with OrderStatusValues, String,
+IsValid, Validate and ParseOrderStatus. MarshalJSON and UnmarshalJSON reject
+undeclared values, so a DTO field of the enum type only accepts the declared
+values. Values are lower snake case.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		typeName := proto.GoName(args[0])
+		if typeName == "" {
+			fmt.Printf("Invalid enum name %q\n", args[0])
+			return
+		}
+		var values []string
+		seen := map[string]bool{}
+		for _, v := range strings.Split(args[1], ",") {
+			v = strings.TrimSpace(v)
+			if !enumValue.MatchString(v) {
+				fmt.Printf("Invalid value %q: use lower snake case, e.g. in_transit\n", v)
+				return
+			}
+			if seen[v] {
+				fmt.Printf("Value %q is listed twice\n", v)
+				return
+			}
+			seen[v] = true
+			values = append(values, v)
+		}
+		if enumModule != "" {
+			if err := ensureModuleDirs(enumModule); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		file := enumFile(enumModule, typeName)
+		if !writeGenerated(codegen.File{Path: file, Content: enumContent(typeName, values)}) {
+			return
+		}
+		fmt.Printf("Enum '%s' created at %s with %d value(s)\n", typeName, file, len(values))
+		openIfRequested(file)
+	},
+}
+
+func init() {
+	enumCmd.Flags().StringVar(&enumModule, "module", "", "Module to generate the enum in, instead of the shared app/enum package")
+	generateCmd.AddCommand(enumCmd)
+	gCmd.AddCommand(enumCmd)
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/spf13/cobra"
+)
+
+// generateETag is set by `g controller/module --etag` to make Get and Update conditional
+var generateETag bool
+
+// etagFile holds the conditional request middleware and handler helpers
+var etagFile = filepath.Join("app", "etag", "etag.go")
+
+const etagTemplate = `package etag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	fiberetag "github.com/gofiber/fiber/v2/middleware/etag"
+)
+
+// Middleware adds a weak ETag to every GET and HEAD response and answers
+// 304 Not Modified when the client's If-None-Match already has it. The handler
+// still runs; use JSON in handlers to skip encoding unchanged responses.
+func Middleware() fiber.Handler {
+	return fiberetag.New(fiberetag.Config{Weak: true})
+}
+
+// Of returns a strong ETag for the JSON representation of v
+func Of(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return ` + "`\"`" + ` + hex.EncodeToString(sum[:16]) + ` + "`\"`" + `, nil
+}
+
+// Version returns a strong ETag for a versioned entity without hashing its body
+func Version(id string, version int) string {
+	return fmt.Sprintf(` + "`\"%s-%d\"`" + `, id, version)
+}
+
+// JSON sends v with its ETag, or 304 Not Modified when If-None-Match matches
+func JSON(c *fiber.Ctx, v any) error {
+	tag, err := Of(v)
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderETag, tag)
+	if header := c.Get(fiber.HeaderIfNoneMatch); header != "" && matches(header, tag, true) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	return c.JSON(v)
+}
+
+// IfMatch guards writes: when the request carries If-Match and it does not name
+// the current representation, it returns 412 Precondition Failed so the client
+// reloads instead of overwriting someone else's change
+func IfMatch(c *fiber.Ctx, current any) error {
+	header := c.Get(fiber.HeaderIfMatch)
+	if header == "" {
+		return nil
+	}
+	tag, err := Of(current)
+	if err != nil {
+		return err
+	}
+	if !matches(header, tag, false) {
+		return fiber.NewError(fiber.StatusPreconditionFailed, "the resource has changed, reload it and retry")
+	}
+	return nil
+}
+
+// matches reports whether a comma separated If-Match/If-None-Match header names tag.
+// If-None-Match uses weak comparison; If-Match requires strong tags.
+func matches(header, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate, tag = strings.TrimPrefix(candidate, "W/"), strings.TrimPrefix(tag, "W/")
+		} else if strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+`
+
+// etagFiles returns the ETag toolkit if --etag was requested and it does not exist yet
+func etagFiles() []codegen.File {
+	if !generateETag {
+		return nil
+	}
+	return missingFile(codegen.File{Path: etagFile, Content: etagTemplate})
+}
+
+var etagCmd = &cobra.Command{
+	Use:   "http:etag",
+	Short: "Generate ETag middleware and If-None-Match/If-Match helpers for conditional requests",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !writeGenerated(codegen.File{Path: etagFile, Content: etagTemplate}) {
+			return
+		}
+		fmt.Printf("ETag helpers created in %s\n", filepath.Dir(etagFile))
+		if _, err := os.Stat(middlewareChainFile); err == nil {
+			if err := addGlobalMiddleware(getModuleName()+"/app/etag", "etag.Middleware()"); err != nil {
+				fmt.Printf("Error wiring middleware into %s: %v\n", middlewareChainFile, err)
+				return
+			}
+			fmt.Printf("etag.Middleware() added to %s\n", middlewareChainFile)
+		} else {
+			fmt.Println("Register the middleware with server.Use(etag.Middleware()) to answer GET requests with 304 Not Modified.")
+		}
+		fmt.Println("Use etag.JSON in GET handlers and etag.IfMatch in PUT handlers, or generate controllers with --etag.")
+		openIfRequested(etagFile)
+	},
+}
+
+// addGlobalMiddleware imports pkg and appends call to the bootstrap middleware chain, unless it is
+// already there. pkg is empty for handlers defined in the bootstrap package itself.
+func addGlobalMiddleware(pkg, call string) error {
+	return editGenerated(middlewareChainFile, func(src []byte) ([]byte, error) {
+		entries, err := codemod.ReadSlice(src, "Middlewares")
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if strings.TrimSpace(e.Expr) == call {
+				return src, nil
+			}
+		}
+		if pkg != "" {
+			if src, err = codemod.AddImport(src, pkg); err != nil {
+				return nil, err
+			}
+		}
+		if src, err = codemod.AppendToSlice(src, "Middlewares", call); err != nil {
+			return nil, err
+		}
+		// Restores an import left blank by 'gonext middleware disable'
+		return codemod.FixImports(src)
+	})
+}
+
+func init() {
+	generateCmd.AddCommand(etagCmd)
+	gCmd.AddCommand(etagCmd)
+}
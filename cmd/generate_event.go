@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/spf13/cobra"
+)
+
+// eventTopic is set by `g event --topic`
+var eventTopic string
+
+// eventBusFile holds the in-process event bus shared by all modules
+var eventBusFile = filepath.Join("app", "eventbus", "bus.go")
+
+const eventBusTemplate = `package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Event is a payload published on the bus. Its topic routes it to subscribers.
+type Event interface {
+	Topic() string
+}
+
+// Handler reacts to an event
+type Handler func(ctx context.Context, event Event) error
+
+// Bus delivers events to the handlers subscribed to their topic, in process.
+// Modules publish and subscribe through it without importing one another.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// Default is the bus used by the package-level Subscribe and Publish
+var Default = New()
+
+// New returns an empty bus
+func New() *Bus {
+	return &Bus{handlers: map[string][]Handler{}}
+}
+
+// Subscribe registers a handler for a topic
+func (b *Bus) Subscribe(topic string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[topic] = append(b.handlers[topic], handler)
+}
+
+// Publish calls the handlers of the event's topic in subscription order and
+// returns their errors joined. A failing handler doesn't stop the others.
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	handlers := b.handlers[event.Topic()]
+	b.mu.RUnlock()
+	var errs []error
+	for _, handle := range handlers {
+		if err := handle(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", event.Topic(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Subscribe registers a handler for a topic on the default bus
+func Subscribe(topic string, handler Handler) {
+	Default.Subscribe(topic, handler)
+}
+
+// Publish delivers the event on the default bus
+func Publish(ctx context.Context, event Event) error {
+	return Default.Publish(ctx, event)
+}
+
+// Handle adapts a handler of one payload type:
+//
+//	eventbus.Subscribe("orders.order_created", eventbus.Handle(listener.OnOrderCreated))
+func Handle[T Event](handler func(ctx context.Context, event T) error) Handler {
+	return func(ctx context.Context, event Event) error {
+		payload, ok := event.(T)
+		if !ok {
+			return fmt.Errorf("expected a %T, got a %T", payload, event)
+		}
+		return handler(ctx, payload)
+	}
+}
+`
+
+// eventContent renders an event payload. The Topic: line names it in the event catalog.
+func eventContent(titleName, topic string) string {
+	return fmt.Sprintf(`package event
+
+import "time"
+
+// %[1]s is published on the event bus.
+// Topic: %[2]s
+type %[1]s struct {
+	// TODO: Add the fields subscribers need, e.g. the ID of the affected record
+	ID         string    `+"`json:\"id\"`"+`
+	OccurredAt time.Time `+"`json:\"occurred_at\"`"+`
+}
+
+// Topic routes the event to its subscribers
+func (%[1]s) Topic() string {
+	return %[2]q
+}
+`, titleName, topic)
+}
+
+// listenerContent renders the handler of an event
+func listenerContent(module, titleName, topic string) string {
+	return fmt.Sprintf(`package listener
+
+import (
+	"context"
+
+	"%[1]s/app/%[2]s/event"
+)
+
+// On%[3]s handles %[4]s events. It is subscribed in %[5]sModule.Register.
+func On%[3]s(ctx context.Context, e event.%[3]s) error {
+	// TODO: React to the event
+	return nil
+}
+`, getModuleName(), module, titleName, topic, strings.Title(module))
+}
+
+// subscribeListener subscribes the handler in the module's Register method, or
+// prints the subscription when the module has no module.go
+func subscribeListener(module, titleName, topic string) error {
+	moduleFile := filepath.Join("app", module, "module.go")
+	subscribe := fmt.Sprintf("eventbus.Subscribe(%q, eventbus.Handle(listener.On%s))", topic, titleName)
+	if _, err := os.Stat(moduleFile); os.IsNotExist(err) {
+		fmt.Printf("app/%s has no module.go. Subscribe the handler at startup:\n  %s\n", module, subscribe)
+		return nil
+	}
+	return editGenerated(moduleFile, func(src []byte) ([]byte, error) {
+		out, err := codemod.AppendStatement(src, "Register", subscribe)
+		if err != nil {
+			return nil, err
+		}
+		if out, err = codemod.AddImport(out, getModuleName()+"/app/eventbus"); err != nil {
+			return nil, err
+		}
+		return codemod.AddImport(out, fmt.Sprintf("%s/app/%s/listener", getModuleName(), module))
+	})
+}
+
+var eventCmd = &cobra.Command{
+	Use:   "event [name] [in_module]",
+	Short: "Generate an event, its handler and its subscription on the in-process event bus",
+	Long: `Generates app/<module>/event/<name>Event.go, the event payload, and
+app/<module>/listener/<name>Listener.go, its handler, and subscribes the handler
+in the module's Register method. The in-process bus in app/eventbus is created
+if missing.
+
+The topic defaults to <module>.<name in snake case>, e.g. orders.order_created
+for OrderCreated in orders. Publish the event from any module:
+
+  eventbus.Publish(ctx, event.OrderCreated{ID: id, OccurredAt: time.Now()})
+
+Events appear in 'gonext events catalog'.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		titleName := proto.GoName(name)
+		topic := eventTopic
+		if topic == "" {
+			topic = module + "." + proto.SnakeCase(titleName)
+		}
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+		eventFile := filepath.Join("app", module, "event", fmt.Sprintf("%sEvent.go", name))
+		listenerFile := filepath.Join("app", module, "listener", fmt.Sprintf("%sListener.go", name))
+		files := []codegen.File{
+			{Path: eventFile, Content: eventContent(titleName, topic)},
+			{Path: listenerFile, Content: listenerContent(module, titleName, topic)},
+		}
+		files = append(files, missingFile(codegen.File{Path: eventBusFile, Content: eventBusTemplate})...)
+		if !writeGenerated(files...) {
+			return
+		}
+		if err := subscribeListener(module, titleName, topic); err != nil {
+			fmt.Printf("Error subscribing the handler in app/%s/module.go: %v\n", module, err)
+			return
+		}
+		fmt.Printf("Event '%s' (topic %s) created in app/%s/event, handled by listener.On%s. Publish it with:\n  eventbus.Publish(ctx, event.%s{ID: id, OccurredAt: time.Now()})\n", titleName, topic, module, titleName, titleName)
+		openIfRequested(eventFile, listenerFile)
+	},
+}
+
+func init() {
+	eventCmd.Flags().StringVar(&eventTopic, "topic", "", "Topic the event is published on (default <module>.<name in snake case>)")
+	generateCmd.AddCommand(eventCmd)
+	gCmd.AddCommand(eventCmd)
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/spf13/cobra"
+)
+
+// notFoundError returns the Err<Module>NotFound sentinel declared by the module's
+// repository, as generated by `g resource --crud`, or "" if there is none
+func notFoundError(module string) string {
+	sentinel := fmt.Sprintf("Err%sNotFound", strings.Title(module))
+	files, _ := filepath.Glob(filepath.Join("app", module, "repository", "*.go"))
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err == nil && strings.Contains(string(src), "var "+sentinel+" ") {
+			return sentinel
+		}
+	}
+	return ""
+}
+
+// exceptionFilterContent renders a module's exception filter. A not-found sentinel
+// of the module's repository is mapped to 404.
+func exceptionFilterContent(module, titleName string) string {
+	moduleName := getModuleName()
+	imports := fmt.Sprintf("\t\"%s/app/exception\"\n", moduleName)
+	mapping := ""
+	if sentinel := notFoundError(module); sentinel != "" {
+		imports = fmt.Sprintf("\t\"errors\"\n\n\t\"%s/app/exception\"\n\t\"%s/app/%s/repository\"\n\n\t\"github.com/gofiber/fiber/v2\"\n", moduleName, moduleName, module)
+		mapping = fmt.Sprintf(`	if errors.Is(err, repository.%s) {
+		return exception.NewProblem(fiber.StatusNotFound, err.Error())
+	}
+`, sentinel)
+	}
+	return fmt.Sprintf(`package filter
+
+import (
+%[3]s)
+
+// %[1]sFilter maps the %[2]s module's domain errors to problem details. It is
+// registered in %[4]sModule.Register; errors it returns nil for fall through to the
+// next filter, then to the built-in mappings of exception.Handler.
+func %[1]sFilter(err error) *exception.Problem {
+%[5]s	// TODO: Map the module's domain errors, e.g.
+	//
+	//	if errors.Is(err, service.ErrEmailTaken) {
+	//		return exception.NewProblem(fiber.StatusConflict, err.Error())
+	//	}
+	return nil
+}
+`, titleName, module, imports, strings.Title(module), mapping)
+}
+
+// exceptionHandlerFiles returns the shared exception filter, rewritten when it
+// predates filter registration so module filters compile against it
+func exceptionHandlerFiles() []codegen.File {
+	file := codegen.File{Path: filepath.Join(exceptionDir, "filter.go"), Content: exceptionFilterTemplate}
+	src, err := os.ReadFile(file.Path)
+	if err == nil && !strings.Contains(string(src), "func Register(") {
+		return []codegen.File{file}
+	}
+	return missingFile(file)
+}
+
+// registerExceptionFilter adds the filter to the module's Register method
+func registerExceptionFilter(module, titleName string) error {
+	moduleFile := filepath.Join("app", module, "module.go")
+	if _, err := os.Stat(moduleFile); os.IsNotExist(err) {
+		return nil
+	}
+	return editGenerated(moduleFile, func(src []byte) ([]byte, error) {
+		out, err := codemod.AppendStatement(src, "Register", fmt.Sprintf("exception.Register(filter.%sFilter)", titleName))
+		if err != nil {
+			return nil, err
+		}
+		if out, err = codemod.AddImport(out, getModuleName()+"/app/exception"); err != nil {
+			return nil, err
+		}
+		return codemod.AddImport(out, fmt.Sprintf("%s/app/%s/filter", getModuleName(), module))
+	})
+}
+
+var exceptionFilterCmd = &cobra.Command{
+	Use:   "filter [name] [in_module]",
+	Short: "Generate an exception filter mapping a module's domain errors to problem+json responses",
+	Long: `Generates app/<module>/filter/<name>Filter.go, which maps the module's domain
+errors to HTTP statuses, and registers it in the module's Register method.
+
+Filters are consulted by the shared exception.Handler in app/exception/filter.go,
+created if missing. It answers every error with an RFC 9457 application/problem+json
+body. Set it as Fiber's error handler in main.go:
+
+  server := fiber.New(fiber.Config{ErrorHandler: exception.Handler})`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		titleName := strings.Title(name)
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+		filterFile := filepath.Join("app", module, "filter", fmt.Sprintf("%sFilter.go", name))
+		files := []codegen.File{{Path: filterFile, Content: exceptionFilterContent(module, titleName)}}
+		files = append(files, exceptionHandlerFiles()...)
+		if !writeGenerated(files...) {
+			return
+		}
+		if err := registerExceptionFilter(module, titleName); err != nil {
+			fmt.Printf("Error registering the filter in app/%s/module.go: %v\n", module, err)
+			return
+		}
+		fmt.Printf("Exception filter '%s' created in app/%s/filter and registered in app/%s/module.go. Set the error handler in main.go:\n  server := fiber.New(fiber.Config{ErrorHandler: exception.Handler})\n", name, module, module)
+		openIfRequested(filterFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(exceptionFilterCmd)
+	gCmd.AddCommand(exceptionFilterCmd)
+}
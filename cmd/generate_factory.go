@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+// factoryField is a field of the entity a factory builds
+type factoryField struct {
+	Name string
+	Type string
+}
+
+// fakeByName maps normalized field names to gofakeit calls returning strings
+var fakeByName = map[string]string{
+	"id":          "gofakeit.UUID()",
+	"uuid":        "gofakeit.UUID()",
+	"name":        "gofakeit.Name()",
+	"fullname":    "gofakeit.Name()",
+	"firstname":   "gofakeit.FirstName()",
+	"lastname":    "gofakeit.LastName()",
+	"username":    "gofakeit.Username()",
+	"password":    "gofakeit.Password(true, true, true, true, false, 16)",
+	"company":     "gofakeit.Company()",
+	"city":        "gofakeit.City()",
+	"country":     "gofakeit.Country()",
+	"zip":         "gofakeit.Zip()",
+	"postalcode":  "gofakeit.Zip()",
+	"address":     "gofakeit.Street()",
+	"street":      "gofakeit.Street()",
+	"title":       "gofakeit.Sentence(3)",
+	"description": "gofakeit.Sentence(12)",
+	"body":        "gofakeit.Sentence(12)",
+	"content":     "gofakeit.Sentence(12)",
+	"bio":         "gofakeit.Sentence(12)",
+	"slug":        "gofakeit.Word()",
+}
+
+// fakeValue returns the gofakeit expression for a field, or "" to leave it at
+// its zero value: pointers (nullable columns), unknown types and integer IDs,
+// which the database assigns
+func fakeValue(f factoryField) string {
+	name := strings.ToLower(f.Name)
+	switch f.Type {
+	case "string":
+		if call, ok := fakeByName[name]; ok {
+			return call
+		}
+		switch {
+		case strings.Contains(name, "email"):
+			return "gofakeit.Email()"
+		case strings.Contains(name, "phone"):
+			return "gofakeit.Phone()"
+		case strings.Contains(name, "url") || strings.Contains(name, "website"):
+			return "gofakeit.URL()"
+		}
+		return "gofakeit.Word()"
+	case "int", "int32", "int64", "uint", "uint32", "uint64":
+		if name == "id" {
+			return ""
+		}
+		if name == "version" {
+			return "1"
+		}
+		if f.Type == "int" {
+			return "gofakeit.IntRange(1, 100)"
+		}
+		return fmt.Sprintf("%s(gofakeit.IntRange(1, 100))", f.Type)
+	case "float32", "float64":
+		call := "gofakeit.Float64Range(1, 1000)"
+		if strings.Contains(name, "price") || strings.Contains(name, "amount") {
+			call = "gofakeit.Price(1, 1000)"
+		}
+		if f.Type == "float32" {
+			return "float32(" + call + ")"
+		}
+		return call
+	case "bool":
+		return "gofakeit.Bool()"
+	case "time.Time":
+		return "gofakeit.PastDate()"
+	case "id.UUID", "id.ULID", "id.Snowflake":
+		return newIDCall(f.Type)
+	case "money.Amount":
+		return "money.FromCents(int64(gofakeit.IntRange(100, 100000)))"
+	case "decimal.Decimal":
+		return "decimal.NewFromFloat(gofakeit.Price(1, 1000)).Round(2)"
+	}
+	return ""
+}
+
+// factorySource finds the struct a factory builds: the module's entity, or its
+// GORM model. It returns the file's package name and the struct's fields.
+func factorySource(module, name, titleName string) (pkg, path string, fields []factoryField, err error) {
+	candidates := []string{entityFile(module, name), entityFile(module, titleName), modelFile(module, name), modelFile(module, titleName)}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return "", "", nil, err
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || ts.Name.Name != titleName {
+					continue
+				}
+				for _, field := range st.Fields.List {
+					for _, n := range field.Names {
+						if n.IsExported() {
+							fields = append(fields, factoryField{Name: n.Name, Type: types.ExprString(field.Type)})
+						}
+					}
+				}
+				return f.Name.Name, path, fields, nil
+			}
+		}
+		return "", "", nil, fmt.Errorf("%s declares no %s struct", path, titleName)
+	}
+	return "", "", nil, fmt.Errorf("no %s entity found in app/%s. Generate it first with 'gonext g entity %s %s'", titleName, module, titleName, module)
+}
+
+// pluralName returns the name of the function building several entities
+func pluralName(name string) string {
+	if strings.HasSuffix(name, "s") {
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// factoryContent renders a factory with fake defaults for every field it knows
+// how to fill and functional overrides applied after them
+func factoryContent(module, pkg, titleName string, fields []factoryField) string {
+	width := 0
+	for _, f := range fields {
+		if fakeValue(f) != "" {
+			width = max(width, len(f.Name)+1)
+		}
+	}
+	var values strings.Builder
+	var zero []string
+	for _, f := range fields {
+		if v := fakeValue(f); v != "" {
+			fmt.Fprintf(&values, "\t\t%-*s %s,\n", width, f.Name+":", v)
+		} else {
+			zero = append(zero, f.Name)
+		}
+	}
+	example := "/* ... */"
+	for _, f := range fields {
+		if f.Type == "string" && fakeValue(f) != "" {
+			example = fmt.Sprintf("v.%s = %q", f.Name, "fixed")
+			break
+		}
+	}
+	note := ""
+	if len(zero) > 0 {
+		note = fmt.Sprintf("\n// %s stay at their zero value; set them with an override.", strings.Join(zero, ", "))
+	}
+	typ := pkg + "." + titleName
+	std, local, create := "", "", ""
+	if _, err := os.Stat(dbtestFile); err == nil {
+		std = "\t\"testing\"\n\n"
+		local = fmt.Sprintf("\t\"%s/app/dbtest\"\n", getModuleName())
+		create = fmt.Sprintf(`
+// Create%[1]s inserts a %[1]s built with the overrides into the %[3]s table of db,
+// e.g. dbtest.Open(t), and returns it as inserted
+func Create%[1]s(t testing.TB, db dbtest.DB, overrides ...func(*%[2]s)) %[2]s {
+	t.Helper()
+	v := %[1]s(overrides...)
+	dbtest.Insert(t, db, %[3]q, &v)
+	return v
+}
+`, titleName, typ, strings.ToLower(titleName)+"s")
+	}
+	for _, f := range fields {
+		if strings.HasPrefix(f.Type, "id.") {
+			local += fmt.Sprintf("\t\"%s/app/id\"\n", getModuleName())
+			break
+		}
+	}
+	third := ""
+	for _, f := range fields {
+		switch f.Type {
+		case "money.Amount":
+			if !strings.Contains(local, "/app/money\"") {
+				local += fmt.Sprintf("\t\"%s/app/money\"\n", getModuleName())
+			}
+		case "decimal.Decimal":
+			third = "\t\"github.com/shopspring/decimal\"\n"
+		}
+	}
+	return fmt.Sprintf(`package factory
+
+import (
+%[10]s	"github.com/brianvoe/gofakeit/v7"
+%[13]s
+%[11]s	"%[1]s/app/%[2]s/%[3]s"
+)
+
+// %[4]s returns test data of type %[5]s, filled with fake values for service
+// and repository tests. The overrides run after the defaults:
+//
+//	v := factory.%[4]s(func(v *%[5]s) { %[9]s })
+//
+// Call gofakeit.Seed(n) to make the data reproducible.%[6]s
+func %[4]s(overrides ...func(*%[5]s)) %[5]s {
+	v := %[5]s{
+%[7]s	}
+	for _, override := range overrides {
+		override(&v)
+	}
+	return v
+}
+
+// %[8]s builds n %[5]s values, each with the overrides applied
+func %[8]s(n int, overrides ...func(*%[5]s)) []%[5]s {
+	values := make([]%[5]s, n)
+	for i := range values {
+		values[i] = %[4]s(overrides...)
+	}
+	return values
+}
+%[12]s`, getModuleName(), module, pkg, titleName, typ, note, values.String(), pluralName(titleName), example, std, local, create, third)
+}
+
+// factoryFile returns the path of a factory in a module
+func factoryFile(module, name string) string {
+	return filepath.Join("app", module, "factory", fmt.Sprintf("%sFactory.go", name))
+}
+
+var factoryCmd = &cobra.Command{
+	Use:   "factory [name] [in_module]",
+	Short: "Generate a test factory building an entity with fake data (gofakeit) and overrides",
+	Long: `Generates app/<module>/factory/<name>Factory.go for the module's <Name> entity,
+or its GORM model:
+
+  u := factory.User()                                            // fake data in every field
+  u := factory.User(func(u *entity.User) { u.Email = "a@b.co" }) // with overrides
+  users := factory.Users(10)
+
+Defaults come from gofakeit by field name (Email, FirstName, Phone, URL...) and
+type. Pointer fields, integer IDs and types it does not know stay at their zero
+value. Run the command again after changing the entity to pick up new fields.
+
+Once app/dbtest exists ('gonext g test:db'), the factory also gets
+Create<Name>(t, db, overrides...), which inserts the built entity.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		titleName := strings.Title(name)
+		pkg, source, fields, err := factorySource(module, name, titleName)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		file := factoryFile(module, name)
+		if !writeGenerated(codegen.File{Path: file, Content: factoryContent(module, pkg, titleName, fields)}) {
+			return
+		}
+		fmt.Printf("Factory '%s' created in app/%s/factory from %s. Install gofakeit with:\n  go get github.com/brianvoe/gofakeit/v7\n", titleName, module, source)
+		openIfRequested(file)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(factoryCmd)
+	gCmd.AddCommand(factoryCmd)
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+)
+
+// generateFilters is set by `--filter field:kind,...` to allow-list query filters on list endpoints
+var generateFilters []string
+
+// filterFile is the shared query filter toolkit used by generated list endpoints
+var filterFile = filepath.Join("app", "filter", "filter.go")
+
+// filterField is an allow-listed query parameter and the kind its values are parsed as
+type filterField struct {
+	Name string
+	Kind string
+}
+
+// filterKinds maps --filter kinds to the toolkit's Kind constants
+var filterKinds = map[string]string{
+	"string": "String",
+	"int":    "Int",
+	"time":   "Time",
+	"bool":   "Bool",
+}
+
+// parseFilters parses the --filter flag. Fields without a kind are strings.
+func parseFilters() ([]filterField, error) {
+	var fields []filterField
+	for _, raw := range generateFilters {
+		name, kind, _ := strings.Cut(raw, ":")
+		if kind == "" {
+			kind = "string"
+		}
+		if _, ok := filterKinds[kind]; !ok || name == "" {
+			return nil, fmt.Errorf("invalid --filter %q (expected field:string|int|time|bool)", raw)
+		}
+		fields = append(fields, filterField{Name: name, Kind: kind})
+	}
+	if len(fields) > 0 && generatePagination == "" {
+		return nil, fmt.Errorf("--filter applies to the list endpoint, so it needs --pagination offset or cursor")
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields, nil
+}
+
+// filterAllowList renders the filter.Allow literal for the given fields
+func filterAllowList(titleName string, fields []filterField) string {
+	width := 0
+	for _, f := range fields {
+		width = max(width, len(f.Name)+3)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %sFilters allow-lists the query parameters List%s can filter on\n", titleName, titleName)
+	fmt.Fprintf(&b, "var %sFilters = filter.Allow{\n", titleName)
+	for _, f := range fields {
+		fmt.Fprintf(&b, "\t%-*s {Column: %q, Kind: filter.%s},\n", width, fmt.Sprintf("%q:", f.Name), f.Name, filterKinds[f.Kind])
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// filterFiles returns the filter toolkit if filters were requested and it does not exist yet
+func filterFiles(fields []filterField) []codegen.File {
+	if len(fields) == 0 {
+		return nil
+	}
+	return missingFile(codegen.File{Path: filterFile, Content: filterTemplate})
+}
+
+const filterTemplate = `package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Kind is the type a filter value is parsed as
+type Kind int
+
+const (
+	String Kind = iota
+	Int
+	Time
+	Bool
+)
+
+// Op is a comparison, written as ?field[op]=value. A bare ?field=value means eq.
+type Op string
+
+const (
+	Eq   Op = "eq"
+	Ne   Op = "ne"
+	Gt   Op = "gt"
+	Gte  Op = "gte"
+	Lt   Op = "lt"
+	Lte  Op = "lte"
+	In   Op = "in"   // Comma separated values
+	Like Op = "like" // Strings only, case insensitive substring match
+)
+
+var sqlOps = map[Op]string{Eq: "=", Ne: "<>", Gt: ">", Gte: ">=", Lt: "<", Lte: "<=", Like: "ILIKE"}
+
+// kindOps lists the operators each kind accepts
+var kindOps = map[Kind][]Op{
+	String: {Eq, Ne, In, Like},
+	Int:    {Eq, Ne, Gt, Gte, Lt, Lte, In},
+	Time:   {Eq, Ne, Gt, Gte, Lt, Lte},
+	Bool:   {Eq, Ne},
+}
+
+// Field maps an allow-listed query parameter to a column
+type Field struct {
+	Column string
+	Kind   Kind
+}
+
+// Allow maps query parameter names to the fields they filter. Anything not
+// listed is rejected, so clients can never filter on arbitrary columns.
+type Allow map[string]Field
+
+// Predicate is a parsed, typed comparison against a column
+type Predicate struct {
+	Column string
+	Op     Op
+	Value  any // []any for In
+}
+
+// reserved query parameters belong to pagination and sorting, not filtering
+var reserved = map[string]bool{"page": true, "limit": true, "cursor": true, "sort": true}
+
+// Parse turns the request's query string into predicates, rejecting unknown
+// fields, operators the field's kind does not support and unparsable values
+func Parse(c *fiber.Ctx, allow Allow) ([]Predicate, error) {
+	var preds []Predicate
+	for key, raw := range c.Queries() {
+		if reserved[key] {
+			continue
+		}
+		name, op := key, Eq
+		if i := strings.IndexByte(key, '['); i > 0 && strings.HasSuffix(key, "]") {
+			name, op = key[:i], Op(key[i+1:len(key)-1])
+		}
+		field, ok := allow[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter %q", name)
+		}
+		if !supports(field.Kind, op) {
+			return nil, fmt.Errorf("filter %q does not support %q", name, op)
+		}
+		var value any
+		var err error
+		if op == In {
+			var values []any
+			for _, part := range strings.Split(raw, ",") {
+				v, err := parse(field.Kind, part)
+				if err != nil {
+					return nil, fmt.Errorf("filter %q: %w", name, err)
+				}
+				values = append(values, v)
+			}
+			value = values
+		} else if value, err = parse(field.Kind, raw); err != nil {
+			return nil, fmt.Errorf("filter %q: %w", name, err)
+		}
+		preds = append(preds, Predicate{Column: field.Column, Op: op, Value: value})
+	}
+	return preds, nil
+}
+
+// Where renders predicates as SQL conditions to be joined with AND. Their values
+// are appended to args and referenced as numbered placeholders after the existing ones.
+func Where(preds []Predicate, args []any) ([]string, []any) {
+	var where []string
+	for _, p := range preds {
+		if values, ok := p.Value.([]any); ok {
+			placeholders := make([]string, len(values))
+			for i, v := range values {
+				args = append(args, v)
+				placeholders[i] = fmt.Sprintf("$%d", len(args))
+			}
+			where = append(where, fmt.Sprintf("%s IN (%s)", p.Column, strings.Join(placeholders, ", ")))
+			continue
+		}
+		value := p.Value
+		if p.Op == Like {
+			value = "%" + value.(string) + "%"
+		}
+		args = append(args, value)
+		where = append(where, fmt.Sprintf("%s %s $%d", p.Column, sqlOps[p.Op], len(args)))
+	}
+	return where, args
+}
+
+func supports(kind Kind, op Op) bool {
+	for _, allowed := range kindOps[kind] {
+		if op == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func parse(kind Kind, raw string) (any, error) {
+	switch kind {
+	case Int:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+		return v, nil
+	case Time:
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if v, err := time.Parse(layout, raw); err == nil {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not an RFC 3339 timestamp or a date", raw)
+	case Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", raw)
+		}
+		return v, nil
+	}
+	return raw, nil
+}
+`
@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+// goldenGenerator and goldenCheck are set by `g test:golden --generator/--check`
+var goldenGenerator string
+var goldenCheck bool
+
+// goldenDir holds the golden test and, in testdata, one directory per snapshot
+var goldenDir = filepath.Join("test", "golden")
+
+// goldenArgsFile records the command a snapshot was generated with
+const goldenArgsFile = "gonext.args"
+
+// goldenSeeds are copied from the project into the empty project a snapshot is
+// generated in; they are not part of the snapshot
+var goldenSeeds = []string{"go.mod", manifest.FileName}
+
+const goldenTestTemplate = `package golden
+
+// Golden tests of the generated code. Each directory of testdata holds the files
+// a generator wrote into an empty project, and the command that wrote them in
+// gonext.args. The test runs the command again and fails on any difference, so
+// a CLI or template upgrade that changes the output shows up in review:
+//
+//	go test ./test/golden            # verify
+//	go test ./test/golden -update    # accept the new output
+//
+// The gonext binary is $GONEXT_BIN, or gonext from PATH.
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current output")
+
+// projectRoot is where go.mod and gonext.yaml are copied from
+const projectRoot = "../.."
+
+// seeds are copied into the empty project and are not part of the output
+var seeds = []string{"go.mod", "gonext.yaml"}
+
+func TestGolden(t *testing.T) {
+	bin := os.Getenv("GONEXT_BIN")
+	if bin == "" {
+		var err error
+		if bin, err = exec.LookPath("gonext"); err != nil {
+			t.Skip("gonext is not installed; set GONEXT_BIN")
+		}
+	}
+	cases, err := os.ReadDir("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		if !c.IsDir() {
+			continue
+		}
+		t.Run(c.Name(), func(t *testing.T) {
+			dir := filepath.Join("testdata", c.Name())
+			args, err := os.ReadFile(filepath.Join(dir, "gonext.args"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := generate(t, bin, strings.Fields(string(args)))
+			if *update {
+				rewrite(t, dir, got)
+				return
+			}
+			want := readTree(t, dir, map[string]bool{"gonext.args": true})
+			for _, path := range union(got, want) {
+				g, inGot := got[path]
+				w, inWant := want[path]
+				switch {
+				case !inGot:
+					t.Errorf("%s is no longer generated", path)
+				case !inWant:
+					t.Errorf("%s is generated but has no golden file", path)
+				case g != w:
+					t.Errorf("%s differs from its golden file: %s", path, firstDifference(w, g))
+				}
+			}
+		})
+	}
+}
+
+// generate runs gonext with args in an empty project and returns what it wrote
+func generate(t *testing.T, bin string, args []string) map[string]string {
+	work := t.TempDir()
+	skip := map[string]bool{}
+	for _, seed := range seeds {
+		skip[seed] = true
+		data, err := os.ReadFile(filepath.Join(projectRoot, seed))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(work, seed), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(bin, args...)
+	cmd.Dir = work
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("gonext %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return readTree(t, work, skip)
+}
+
+// readTree reads the files under dir, except skip and gonext's state in .gonext
+func readTree(t *testing.T, dir string, skip map[string]bool) map[string]string {
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel == ".gonext" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if skip[rel] {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		files[rel] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// rewrite replaces the golden files of dir with files
+func rewrite(t *testing.T, dir string, files map[string]string) {
+	args, err := os.ReadFile(filepath.Join(dir, "gonext.args"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	files["gonext.args"] = string(args)
+	for path, content := range files {
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func union(a, b map[string]string) []string {
+	var paths []string
+	for path := range a {
+		paths = append(paths, path)
+	}
+	for path := range b {
+		if _, ok := a[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// firstDifference describes the first line that differs
+func firstDifference(want, got string) string {
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; i < len(w) || i < len(g); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl {
+			return fmt.Sprintf("line %d: want %q, got %q", i+1, wl, gl)
+		}
+	}
+	return "line endings differ"
+}
+`
+
+// goldenOutput runs gonext with args in an empty project seeded with the
+// project's go.mod and gonext.yaml, and returns the files it wrote
+func goldenOutput(args []string) (map[string]string, error) {
+	bin, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	work, err := os.MkdirTemp("", "gonext-golden-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(work)
+	skip := map[string]bool{}
+	for _, seed := range goldenSeeds {
+		skip[seed] = true
+		data, err := os.ReadFile(seed)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(work, seed), data, 0644); err != nil {
+			return nil, err
+		}
+	}
+	c := exec.Command(bin, args...)
+	c.Dir = work
+	if out, err := c.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("gonext %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return readGoldenTree(work, skip)
+}
+
+// readGoldenTree reads the files under dir, except skip and gonext's state
+func readGoldenTree(dir string, skip map[string]bool) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel == filepath.Dir(codegen.StateFile) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if skip[rel] {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		files[rel] = string(data)
+		return err
+	})
+	if os.IsNotExist(err) {
+		return files, nil
+	}
+	return files, err
+}
+
+// goldenDrift lists the files that differ between a snapshot and the output
+func goldenDrift(want, got map[string]string) []string {
+	var drift []string
+	for path, content := range got {
+		if w, ok := want[path]; !ok {
+			drift = append(drift, "added      "+path)
+		} else if w != content {
+			drift = append(drift, "changed    "+path)
+		}
+	}
+	for path := range want {
+		if _, ok := got[path]; !ok {
+			drift = append(drift, "removed    "+path)
+		}
+	}
+	sort.Slice(drift, func(i, j int) bool { return drift[i][11:] < drift[j][11:] })
+	return drift
+}
+
+// writeGoldenSnapshot replaces the snapshot in dir with files and the command
+func writeGoldenSnapshot(dir string, args []string, files map[string]string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	files[goldenArgsFile] = strings.Join(args, " ") + "\n"
+	for path, content := range files {
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var goldenCmd = &cobra.Command{
+	Use:   "test:golden [name]",
+	Short: "Snapshot a generator's output and add a golden test that fails when regenerating it drifts",
+	Long: `Runs 'gonext g module <name>' (or the generator given by --generator) in an
+empty project seeded with this project's go.mod and gonext.yaml, and stores the
+files it writes in test/golden/testdata/<generator>_<name>. test/golden/golden_test.go,
+created if missing, runs every snapshot's command again and fails on any
+difference:
+
+  go test ./test/golden            verify
+  go test ./test/golden -update    accept the new output
+
+Teams maintaining their own generator conventions catch unintended changes to the
+generated code when the CLI or gonext.yaml changes. --generator takes the
+generator and its flags, e.g. --generator "resource --crud".
+
+Running the command again updates the snapshot; --check only compares it and
+exits with status 1 on drift.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		fields := strings.Fields(goldenGenerator)
+		if len(fields) == 0 {
+			fmt.Println("--generator must name a generator, e.g. module or \"resource --crud\"")
+			os.Exit(1)
+		}
+		genArgs := append([]string{"g", fields[0], name}, fields[1:]...)
+		dir := filepath.Join(goldenDir, "testdata", fields[0]+"_"+name)
+
+		got, err := goldenOutput(genArgs)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if len(got) == 0 {
+			fmt.Printf("'gonext %s' wrote no files\n", strings.Join(genArgs, " "))
+			os.Exit(1)
+		}
+		want, err := readGoldenTree(dir, map[string]bool{goldenArgsFile: true})
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", dir, err)
+			os.Exit(1)
+		}
+		drift := goldenDrift(want, got)
+		if goldenCheck {
+			if len(want) == 0 {
+				fmt.Printf("No snapshot in %s. Create it with 'gonext g test:golden %s'.\n", dir, name)
+				os.Exit(1)
+			}
+			for _, d := range drift {
+				fmt.Println("  " + d)
+			}
+			if len(drift) > 0 {
+				fmt.Printf("%d file(s) drifted from %s\n", len(drift), dir)
+				os.Exit(1)
+			}
+			fmt.Printf("%d file(s) match %s\n", len(got), dir)
+			return
+		}
+
+		if err := writeGoldenSnapshot(dir, genArgs, got); err != nil {
+			fmt.Printf("Error writing %s: %v\n", dir, err)
+			os.Exit(1)
+		}
+		testFile := filepath.Join(goldenDir, "golden_test.go")
+		if !writeGenerated(missingFile(codegen.File{Path: testFile, Content: goldenTestTemplate})...) {
+			return
+		}
+		if len(want) > 0 {
+			for _, d := range drift {
+				fmt.Println("  " + d)
+			}
+			fmt.Printf("Snapshot %s updated: %d file(s) drifted\n", dir, len(drift))
+			return
+		}
+		fmt.Printf("Snapshot of %d file(s) written to %s. Verify it with:\n  go test ./%s\n", len(got), dir, filepath.ToSlash(goldenDir))
+	},
+}
+
+func init() {
+	goldenCmd.Flags().StringVar(&goldenGenerator, "generator", "module", "Generator to snapshot, with its flags, e.g. \"resource --crud\"")
+	goldenCmd.Flags().BoolVar(&goldenCheck, "check", false, "Compare the output with the snapshot without updating it, exiting with status 1 on drift")
+	generateCmd.AddCommand(goldenCmd)
+	gCmd.AddCommand(goldenCmd)
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// grpcStreams selects which streaming handler templates `g grpc` adds
+var grpcStreams []string
+
+// grpcStreamImports lists the standard library packages each streaming template uses
+var grpcStreamImports = map[string][]string{
+	"server": {`"time"`},
+	"client": {`"errors"`, `"io"`},
+	"bidi":   {`"errors"`, `"io"`},
+}
+
+// grpcStreamTemplates hold the streaming handler bodies, keyed by --stream value.
+// Each is formatted with the handler type name and the message base name.
+var grpcStreamTemplates = map[string]string{
+	"server": `
+// Watch%[2]s streams responses to the client until the client cancels or the stream ends.
+func (h *%[1]s) Watch%[2]s(req *pb.%[2]sRequest, stream grpc.ServerStreamingServer[pb.%[2]sResponse]) error {
+	ctx := stream.Context()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Client went away or the deadline expired: stop producing
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+			// Send blocks when the client's receive window is full, which
+			// provides natural backpressure; never buffer unboundedly here.
+			if err := stream.Send(&pb.%[2]sResponse{}); err != nil {
+				return err
+			}
+		}
+	}
+}
+`,
+	"client": `
+// Upload%[2]s receives a stream of requests and replies once the client closes its side.
+func (h *%[1]s) Upload%[2]s(stream grpc.ClientStreamingServer[pb.%[2]sRequest, pb.%[2]sResponse]) error {
+	ctx := stream.Context()
+	received := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			// TODO: Build the final response from what was received
+			return stream.SendAndClose(&pb.%[2]sResponse{})
+		}
+		if err != nil {
+			return err
+		}
+		// Process each message before receiving the next so a slow handler
+		// slows the client down instead of queueing messages in memory.
+		_ = req
+		received++
+	}
+}
+`,
+	"bidi": `
+// Sync%[2]s handles a bidirectional stream, replying to each request as it arrives.
+func (h *%[1]s) Sync%[2]s(stream grpc.BidiStreamingServer[pb.%[2]sRequest, pb.%[2]sResponse]) error {
+	ctx := stream.Context()
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return status.FromContextError(ctx.Err()).Err()
+			}
+			return err
+		}
+		// TODO: Handle the request
+		_ = req
+		// Send waits for flow-control credit from the client (backpressure)
+		if err := stream.Send(&pb.%[2]sResponse{}); err != nil {
+			return err
+		}
+	}
+}
+`,
+}
+
+var grpcCmd = &cobra.Command{
+	Use:   "grpc [name] [in_module]",
+	Short: "Generate a gRPC handler with unary and streaming templates, or the stubs and handlers of a .proto file",
+	Long: `Generates app/<in_module>/grpc/<name>Handler.go. Messages are expected in the
+module's pb package (app/<in_module>/grpc/pb) as <Name>Request and <Name>Response.
+Use --stream to choose which streaming handlers to include (default: all).
+
+With --proto path/to/file.proto, the handlers follow the file instead:
+  - protoc, or buf if protoc is not installed (--compiler), generates the message
+    and service stubs into app/<in_module>/grpc/pb, whatever go_package the file
+    declares. The protoc-gen-go and protoc-gen-go-grpc plugins must be installed.
+  - <name>Handler.go implements every service of the file, with one method per
+    RPC returning Unimplemented until it is filled in.
+  - The handlers are registered with app/grpcserver, created if missing, in the
+    module's Register method. Serve them with grpcserver.ListenAndServe.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		if grpcProto != "" {
+			generateGRPCFromProto(name, module, grpcProto)
+			return
+		}
+		titleName := cases.Title(language.Und, cases.NoLower).String(name)
+		moduleName := getModuleName()
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		handlerType := titleName + "Handler"
+		var streams strings.Builder
+		needed := map[string]bool{`"context"`: true}
+		for _, kind := range grpcStreams {
+			tmpl, ok := grpcStreamTemplates[kind]
+			if !ok {
+				fmt.Printf("Unknown stream kind '%s' (expected server, client or bidi)\n", kind)
+				return
+			}
+			for _, imp := range grpcStreamImports[kind] {
+				needed[imp] = true
+			}
+			streams.WriteString(fmt.Sprintf(tmpl, handlerType, titleName))
+		}
+		var imports []string
+		for imp := range needed {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+
+		content := fmt.Sprintf(`package grpc
+
+import (
+	%s
+
+	"%s/app/%s/grpc/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type %s struct {
+	pb.Unimplemented%sServiceServer
+}
+
+// Get%s handles a unary request. Always honour ctx cancellation in downstream calls.
+func (h *%s) Get%s(ctx context.Context, req *pb.%sRequest) (*pb.%sResponse, error) {
+	// TODO: Implement get logic
+	return nil, status.Error(codes.Unimplemented, "Get%s is not implemented")
+}
+
+// Register%s registers the handler with a gRPC server
+func Register%s(s grpc.ServiceRegistrar, h *%s) {
+	pb.Register%sServiceServer(s, h)
+}
+%s`,
+			strings.Join(imports, "\n\t"), moduleName, module,
+			handlerType, titleName,
+			titleName, handlerType, titleName, titleName, titleName, titleName,
+			handlerType, handlerType, handlerType, titleName,
+			streams.String())
+
+		handlerFile := filepath.Join("app", module, "grpc", fmt.Sprintf("%sHandler.go", name))
+		if !writeGenerated(codegen.File{Path: handlerFile, Content: content}) {
+			return
+		}
+		fmt.Printf("gRPC handler '%s' created in app/%s/grpc\n", name, module)
+		openIfRequested(handlerFile)
+	},
+}
+
+func init() {
+	grpcCmd.Flags().StringSliceVar(&grpcStreams, "stream", []string{"server", "client", "bidi"}, "Streaming handlers to include: server, client, bidi (empty for unary only)")
+	grpcCmd.Flags().StringVar(&grpcProto, "proto", "", "Generate the stubs and handlers of the services of this .proto file")
+	grpcCmd.Flags().StringVar(&grpcCompiler, "compiler", "auto", "What compiles --proto: auto, protoc or buf")
+	generateCmd.AddCommand(grpcCmd)
+	gCmd.AddCommand(grpcCmd)
+}
@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+)
+
+// grpcProto and grpcCompiler are set by `g grpc --proto/--compiler`
+var grpcProto string
+var grpcCompiler string
+
+// grpcServerFile holds the registry of gRPC services shared by all modules
+var grpcServerFile = filepath.Join("app", "grpcserver", "server.go")
+
+const grpcServerTemplate = `// Package grpcserver collects the gRPC services of the modules and serves them
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// Service is a gRPC handler that registers itself with a server, as generated by
+// 'gonext g grpc --proto'
+type Service interface {
+	RegisterGRPC(s grpc.ServiceRegistrar)
+}
+
+var (
+	mu       sync.Mutex
+	services []Service
+)
+
+// Register records a service. Modules call it in Register:
+//
+//	grpcserver.Register(&usersgrpc.UserServiceHandler{})
+func Register(service Service) {
+	mu.Lock()
+	defer mu.Unlock()
+	services = append(services, service)
+}
+
+// Attach registers every recorded service with s
+func Attach(s grpc.ServiceRegistrar) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, service := range services {
+		service.RegisterGRPC(s)
+	}
+}
+
+// ListenAndServe serves the recorded services on addr, e.g. ":50051", until ctx
+// is done, then stops gracefully, letting in-flight RPCs finish
+func ListenAndServe(ctx context.Context, addr string, opts ...grpc.ServerOption) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s := grpc.NewServer(opts...)
+	Attach(s)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.GracefulStop()
+		case <-done:
+		}
+	}()
+	return s.Serve(lis)
+}
+`
+
+// wellKnownGo maps the well-known message types an RPC may take or return to
+// their Go package
+var wellKnownGo = map[string]string{
+	"Empty":       "emptypb",
+	"Timestamp":   "timestamppb",
+	"Duration":    "durationpb",
+	"Struct":      "structpb",
+	"Value":       "structpb",
+	"ListValue":   "structpb",
+	"Any":         "anypb",
+	"FieldMask":   "fieldmaskpb",
+	"StringValue": "wrapperspb",
+	"BoolValue":   "wrapperspb",
+	"BytesValue":  "wrapperspb",
+	"Int32Value":  "wrapperspb",
+	"Int64Value":  "wrapperspb",
+	"UInt32Value": "wrapperspb",
+	"UInt64Value": "wrapperspb",
+	"FloatValue":  "wrapperspb",
+	"DoubleValue": "wrapperspb",
+}
+
+// grpcMessageType returns the Go type of an RPC's request or response message,
+// recording the import it needs. Messages of the file's package live in pb;
+// messages of other packages, except the well-known types, are not supported.
+func grpcMessageType(typ, pkg string, imports map[string]bool) (string, error) {
+	typ = strings.TrimPrefix(typ, ".")
+	if name, ok := strings.CutPrefix(typ, "google.protobuf."); ok {
+		goPkg, ok := wellKnownGo[name]
+		if !ok {
+			return "", fmt.Errorf("unsupported well-known type %s", typ)
+		}
+		imports[fmt.Sprintf("%q", "google.golang.org/protobuf/types/known/"+goPkg)] = true
+		return goPkg + "." + name, nil
+	}
+	if pkg != "" {
+		typ = strings.TrimPrefix(typ, pkg+".")
+	}
+	parts := strings.Split(typ, ".")
+	for _, part := range parts {
+		if !unicode.IsUpper([]rune(part)[0]) {
+			return "", fmt.Errorf("message %s is from another package; only messages of the file's package and well-known types are supported", typ)
+		}
+	}
+	// Nested messages are named Outer_Inner in Go
+	return "pb." + strings.Join(parts, "_"), nil
+}
+
+// grpcRPCHandler renders the handler method of an RPC with the signature the
+// generated <Service>Server interface expects
+func grpcRPCHandler(handlerType string, rpc proto.RPC, req, res string) string {
+	var b strings.Builder
+	if rpc.Comment != "" {
+		for _, line := range strings.Split(rpc.Comment, "\n") {
+			b.WriteString("// " + line + "\n")
+		}
+	} else {
+		b.WriteString(fmt.Sprintf("// %s handles the %s RPC\n", rpc.Name, rpc.Name))
+	}
+	switch {
+	case rpc.ClientStream && rpc.ServerStream:
+		b.WriteString(fmt.Sprintf("func (h *%s) %s(stream grpc.BidiStreamingServer[%s, %s]) error {\n", handlerType, rpc.Name, req, res))
+	case rpc.ClientStream:
+		b.WriteString(fmt.Sprintf("func (h *%s) %s(stream grpc.ClientStreamingServer[%s, %s]) error {\n", handlerType, rpc.Name, req, res))
+	case rpc.ServerStream:
+		b.WriteString(fmt.Sprintf("func (h *%s) %s(req *%s, stream grpc.ServerStreamingServer[%s]) error {\n", handlerType, rpc.Name, req, res))
+	default:
+		b.WriteString(fmt.Sprintf("func (h *%s) %s(ctx context.Context, req *%s) (*%s, error) {\n", handlerType, rpc.Name, req, res))
+		b.WriteString(fmt.Sprintf("\t// TODO: Implement %s. Honour ctx cancellation in downstream calls.\n", rpc.Name))
+		b.WriteString(fmt.Sprintf("\treturn nil, status.Error(codes.Unimplemented, \"%s is not implemented\")\n}\n", rpc.Name))
+		return b.String()
+	}
+	b.WriteString(fmt.Sprintf("\t// TODO: Implement %s. Stop when stream.Context() is done.\n", rpc.Name))
+	b.WriteString(fmt.Sprintf("\treturn status.Error(codes.Unimplemented, \"%s is not implemented\")\n}\n", rpc.Name))
+	return b.String()
+}
+
+// grpcProtoHandlers renders the handlers implementing the services of a .proto file
+func grpcProtoHandlers(module, protoPath, src string) (string, []proto.Service, error) {
+	services, err := proto.ParseServices(src)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %v", protoPath, err)
+	}
+	if len(services) == 0 {
+		return "", nil, fmt.Errorf("%s declares no service", protoPath)
+	}
+	pkg := proto.Package(src)
+	imports := map[string]bool{
+		`"google.golang.org/grpc"`:        true,
+		`"google.golang.org/grpc/codes"`:  true,
+		`"google.golang.org/grpc/status"`: true,
+		fmt.Sprintf("%q", fmt.Sprintf("%s/app/%s/grpc/pb", getModuleName(), module)): true,
+	}
+	var body strings.Builder
+	for _, svc := range services {
+		handlerType := svc.Name + "Handler"
+		body.WriteString(fmt.Sprintf(`
+// %[1]s implements the %[2]s service of %[3]s. It is
+// registered in %[4]sModule.Register.
+type %[1]s struct {
+	pb.Unimplemented%[2]sServer
+}
+
+// RegisterGRPC registers the handler with a gRPC server
+func (h *%[1]s) RegisterGRPC(s grpc.ServiceRegistrar) {
+	pb.Register%[2]sServer(s, h)
+}
+`, handlerType, svc.Name, filepath.Base(protoPath), strings.Title(module)))
+		for _, rpc := range svc.RPCs {
+			if rpc.Name == "RegisterGRPC" {
+				return "", nil, fmt.Errorf("%s: RPC %s.RegisterGRPC clashes with the handler's registration method", protoPath, svc.Name)
+			}
+			req, err := grpcMessageType(rpc.Request, pkg, imports)
+			if err != nil {
+				return "", nil, fmt.Errorf("%s: %s.%s: %v", protoPath, svc.Name, rpc.Name, err)
+			}
+			res, err := grpcMessageType(rpc.Response, pkg, imports)
+			if err != nil {
+				return "", nil, fmt.Errorf("%s: %s.%s: %v", protoPath, svc.Name, rpc.Name, err)
+			}
+			if !rpc.ClientStream && !rpc.ServerStream {
+				imports[`"context"`] = true
+			}
+			body.WriteString("\n" + grpcRPCHandler(handlerType, rpc, req, res))
+		}
+	}
+	// Standard library imports first, then the others
+	var std, other []string
+	for imp := range imports {
+		if strings.Contains(strings.SplitN(imp, "/", 2)[0], ".") {
+			other = append(other, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	groups := strings.Join(other, "\n\t")
+	if len(std) > 0 {
+		groups = strings.Join(std, "\n\t") + "\n\n\t" + groups
+	}
+	content := fmt.Sprintf("package grpc\n\nimport (\n\t%s\n)\n%s", groups, body.String())
+	out, err := format.Source([]byte(content))
+	if err != nil {
+		return "", nil, fmt.Errorf("rendering the handlers: %v", err)
+	}
+	return string(out), services, nil
+}
+
+// protoCompiler picks protoc or buf for --compiler auto, preferring protoc
+func protoCompiler(compiler string) (string, error) {
+	switch compiler {
+	case "protoc", "buf":
+		if _, err := exec.LookPath(compiler); err != nil {
+			return "", fmt.Errorf("'%s' is required. Install it, or pass --compiler with the other one.", compiler)
+		}
+		return compiler, nil
+	case "auto":
+		for _, c := range []string{"protoc", "buf"} {
+			if _, err := exec.LookPath(c); err == nil {
+				return c, nil
+			}
+		}
+		return "", fmt.Errorf("'protoc' or 'buf' is required. Install one of them with the Go plugins:\n  go install google.golang.org/protobuf/cmd/protoc-gen-go@latest\n  go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@latest")
+	}
+	return "", fmt.Errorf("invalid --compiler %q: expected auto, protoc or buf", compiler)
+}
+
+// compileProto generates the message and service stubs of protoPath into outDir
+// with protoc or buf. The file's go_package is overridden so the stubs are
+// package pb of the module, whatever the file declares.
+func compileProto(compiler, protoPath, outDir, goPackage string) error {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+	dir, base := filepath.Split(protoPath)
+	if dir == "" {
+		dir = "."
+	}
+	opts := []string{"paths=source_relative", fmt.Sprintf("M%s=%s;pb", base, goPackage)}
+	var c *exec.Cmd
+	if compiler == "buf" {
+		plugin := func(name string) map[string]any {
+			return map[string]any{"plugin": name, "out": filepath.ToSlash(outDir), "opt": opts}
+		}
+		template, err := json.Marshal(map[string]any{"version": "v1", "plugins": []any{plugin("go"), plugin("go-grpc")}})
+		if err != nil {
+			return err
+		}
+		c = exec.Command("buf", "generate", filepath.Clean(dir), "--template", string(template), "--path", protoPath)
+	} else {
+		args := []string{"-I", filepath.Clean(dir), "--go_out=" + outDir, "--go-grpc_out=" + outDir}
+		for _, opt := range opts {
+			args = append(args, "--go_opt="+opt, "--go-grpc_opt="+opt)
+		}
+		c = exec.Command("protoc", append(args, protoPath)...)
+	}
+	fmt.Printf("$ %s\n", strings.Join(c.Args, " "))
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// registerGRPCServices registers the module's handlers in its Register method, or
+// prints the registrations when the module has no module.go
+func registerGRPCServices(module string, services []proto.Service) error {
+	moduleFile := filepath.Join("app", module, "module.go")
+	alias := module + "grpc"
+	var registers []string
+	for _, svc := range services {
+		registers = append(registers, fmt.Sprintf("grpcserver.Register(&%s.%sHandler{})", alias, svc.Name))
+	}
+	if _, err := os.Stat(moduleFile); os.IsNotExist(err) {
+		fmt.Printf("app/%s has no module.go. Register the services at startup:\n  %s\n", module, strings.Join(registers, "\n  "))
+		return nil
+	}
+	return editGenerated(moduleFile, func(src []byte) ([]byte, error) {
+		out := src
+		for _, register := range registers {
+			if strings.Contains(string(out), register) {
+				continue
+			}
+			var err error
+			if out, err = codemod.AppendStatement(out, "Register", register); err != nil {
+				return nil, err
+			}
+		}
+		out, err := codemod.AddImport(out, getModuleName()+"/app/grpcserver")
+		if err != nil {
+			return nil, err
+		}
+		return codemod.AddNamedImport(out, alias, fmt.Sprintf("%s/app/%s/grpc", getModuleName(), module))
+	})
+}
+
+// generateGRPCFromProto compiles protoPath into app/<module>/grpc/pb, writes the
+// handlers of its services to app/<module>/grpc/<name>Handler.go and registers
+// them in the module
+func generateGRPCFromProto(name, module, protoPath string) {
+	src, err := os.ReadFile(protoPath)
+	if err != nil {
+		fmt.Printf("Error reading %s: %v\n", protoPath, err)
+		return
+	}
+	content, services, err := grpcProtoHandlers(module, protoPath, string(src))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	compiler, err := protoCompiler(grpcCompiler)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := ensureModuleDirs(module); err != nil {
+		fmt.Println(err)
+		return
+	}
+	pbDir := filepath.Join("app", module, "grpc", "pb")
+	if err := compileProto(compiler, protoPath, pbDir, fmt.Sprintf("%s/app/%s/grpc/pb", getModuleName(), module)); err != nil {
+		fmt.Printf("Error generating the stubs of %s with %s: %v\n", protoPath, compiler, err)
+		return
+	}
+	handlerFile := filepath.Join("app", module, "grpc", fmt.Sprintf("%sHandler.go", name))
+	files := []codegen.File{{Path: handlerFile, Content: content}}
+	files = append(files, missingFile(codegen.File{Path: grpcServerFile, Content: grpcServerTemplate})...)
+	if !writeGenerated(files...) {
+		return
+	}
+	if err := registerGRPCServices(module, services); err != nil {
+		fmt.Printf("Error registering the services in app/%s/module.go: %v\n", module, err)
+		return
+	}
+	fmt.Printf("Stubs of %s generated in %s and %d service handler(s) created in %s. Serve the registered services at startup:\n  go grpcserver.ListenAndServe(ctx, \":50051\")\n",
+		protoPath, pbDir, len(services), handlerFile)
+	openIfRequested(handlerFile)
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/spf13/cobra"
+)
+
+// guardRoutes and guardRoles are set by `g guard --route/--role`
+var guardRoutes []string
+var guardRoles []string
+
+// guardContent renders a guard: a handler factory that answers 401 without
+// credentials and 403 when the caller lacks every required role
+func guardContent(titleName string) string {
+	return fmt.Sprintf(`package guard
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// %[1]sGuard lets a request reach the route only if the caller is authenticated
+// (401 otherwise) and, when roles are given, has at least one of them (403 otherwise).
+// Attach it before the handler:
+//
+//	route.Delete("/:id", guard.%[1]sGuard("admin"), ctrl.Delete)
+func %[1]sGuard(roles ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		header := ctx.Get(fiber.HeaderAuthorization)
+		if !strings.HasPrefix(header, "Bearer ") || strings.TrimPrefix(header, "Bearer ") == "" {
+			return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+		}
+		// TODO: Verify the token and read the caller's roles from its claims
+		var callerRoles []string
+		if len(roles) > 0 && !hasAnyRole(callerRoles, roles) {
+			return fiber.NewError(fiber.StatusForbidden, "insufficient permissions")
+		}
+		ctx.Locals("roles", callerRoles)
+		return ctx.Next()
+	}
+}
+`, titleName)
+}
+
+// guardHelpersContent is shared by every guard of a module
+const guardHelpersContent = `package guard
+
+// hasAnyRole reports whether the caller has at least one of the required roles
+func hasAnyRole(callerRoles, required []string) bool {
+	for _, have := range callerRoles {
+		for _, want := range required {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+`
+
+// parseGuardRoute splits a --route value such as "DELETE /:id" into the Fiber
+// router method (Delete) and path. A bare path matches every method.
+func parseGuardRoute(spec string) (string, string, error) {
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 1:
+		return "", fields[0], nil
+	case 2:
+		method := strings.Title(strings.ToLower(fields[0]))
+		if method == "Any" {
+			method = "All"
+		}
+		if !codemod.RouteMethods[method] {
+			return "", "", fmt.Errorf("unknown method in --route %q", spec)
+		}
+		return method, fields[1], nil
+	}
+	return "", "", fmt.Errorf("invalid --route %q (expected 'METHOD /path' or '/path')", spec)
+}
+
+// attachGuard adds the guard expression to the matching routes in the module's route files
+func attachGuard(module, expr string, specs []string) error {
+	return attachMiddleware(module, "guard", specs, func(string) string { return expr })
+}
+
+// attachMiddleware adds the expression exprFor returns for a route's method
+// (Get, Post, ... or "" for every method) before the handler of the routes
+// matching specs in the module's route files, importing the module's pkg
+func attachMiddleware(module, pkg string, specs []string, exprFor func(method string) string) error {
+	files, err := filepath.Glob(filepath.Join("app", module, "route", "*.go"))
+	if err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		method, path, err := parseGuardRoute(spec)
+		if err != nil {
+			return err
+		}
+		expr := exprFor(method)
+		attached := 0
+		for _, file := range files {
+			err := editGenerated(file, func(src []byte) ([]byte, error) {
+				out, n, err := codemod.AddRouteMiddleware(src, method, path, expr)
+				if err != nil || n == 0 {
+					return src, err
+				}
+				attached += n
+				return codemod.AddImport(out, fmt.Sprintf("%s/app/%s/%s", getModuleName(), module, pkg))
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if attached == 0 {
+			fmt.Printf("Warning: no route without this %s matches --route %q in app/%s/route\n", pkg, spec, module)
+			continue
+		}
+		fmt.Printf("Attached %s to %d route(s) matching %q\n", expr, attached, spec)
+	}
+	return nil
+}
+
+var guardCmd = &cobra.Command{
+	Use:   "guard [name] [in_module]",
+	Short: "Generate an auth guard that answers 401/403, and attach it to routes with --route",
+	Long: `Generates app/<module>/guard/<name>Guard.go: a Fiber handler factory that
+rejects requests without a bearer token with 401 and callers lacking every
+required role with 403.
+
+--route attaches the guard before the handler of matching routes in the module's
+route files, e.g. --route "DELETE /:id" or --route /admin for every method.
+--role sets the roles the attached guard requires.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		titleName := strings.Title(name)
+		for _, spec := range guardRoutes {
+			if _, _, err := parseGuardRoute(spec); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+		guardDir := filepath.Join("app", module, "guard")
+		guardFile := filepath.Join(guardDir, fmt.Sprintf("%sGuard.go", name))
+		files := []codegen.File{{Path: guardFile, Content: guardContent(titleName)}}
+		files = append(files, missingFile(codegen.File{Path: filepath.Join(guardDir, "guard.go"), Content: guardHelpersContent})...)
+		if !writeGenerated(files...) {
+			return
+		}
+		roles := make([]string, len(guardRoles))
+		for i, r := range guardRoles {
+			roles[i] = strconv.Quote(r)
+		}
+		expr := fmt.Sprintf("guard.%sGuard(%s)", titleName, strings.Join(roles, ", "))
+		if len(guardRoutes) == 0 {
+			fmt.Printf("Guard '%s' created in app/%s/guard. Attach it with --route or by hand:\n  route.Delete(\"/:id\", %s, ctrl.Delete%s)\n", name, module, expr, strings.Title(module))
+		} else {
+			fmt.Printf("Guard '%s' created in app/%s/guard\n", name, module)
+			if err := attachGuard(module, expr, guardRoutes); err != nil {
+				fmt.Printf("Error attaching the guard: %v\n", err)
+				return
+			}
+		}
+		openIfRequested(guardFile)
+	},
+}
+
+func init() {
+	guardCmd.Flags().StringArrayVar(&guardRoutes, "route", nil, "Attach the guard to routes in the module's route files: 'METHOD /path' or '/path' for every method (repeatable)")
+	guardCmd.Flags().StringSliceVar(&guardRoles, "role", nil, "Roles the attached guard requires, any of which grants access (repeatable)")
+	generateCmd.AddCommand(guardCmd)
+	gCmd.AddCommand(guardCmd)
+}
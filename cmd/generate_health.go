@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+// healthFile holds the liveness/readiness endpoints and drain handling
+var healthFile = filepath.Join("app", "health", "health.go")
+
+const healthTemplate = `package health
+
+import (
+	"context"
+	"crypto/subtle"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Checker tracks whether the instance should receive traffic. It starts
+// unready, so load balancers wait for warmup, and turns unready again while
+// anything holds it (e.g. migrations) or after a drain.
+type Checker struct {
+	mu       sync.Mutex
+	ready    bool
+	draining bool
+	holds    map[string]int
+	checks   map[string]func(context.Context) error
+}
+
+// Default is the process-wide checker
+var Default = NewChecker()
+
+// NewChecker returns an unready checker
+func NewChecker() *Checker {
+	return &Checker{holds: map[string]int{}, checks: map[string]func(context.Context) error{}}
+}
+
+// AddCheck registers a dependency check (e.g. database.HealthCheck(db)) run on every readiness probe
+func (h *Checker) AddCheck(name string, check func(context.Context) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = check
+}
+
+// MarkReady ends warmup. Call it once the server is about to accept traffic.
+func (h *Checker) MarkReady() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ready = true
+}
+
+// Hold reports unready for reason until the returned release func is called:
+//
+//	release := health.Default.Hold("migrations")
+//	err := database.MigrateOnStart(ctx, db)
+//	release()
+func (h *Checker) Hold(reason string) (release func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.holds[reason]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if h.holds[reason]--; h.holds[reason] <= 0 {
+				delete(h.holds, reason)
+			}
+		})
+	}
+}
+
+// Drain permanently reports unready so the load balancer stops routing new
+// requests here before the instance is stopped
+func (h *Checker) Drain() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.draining = true
+}
+
+// Status evaluates readiness and returns the reasons the instance is not ready
+func (h *Checker) Status(ctx context.Context) (bool, []string) {
+	h.mu.Lock()
+	var reasons []string
+	if h.draining {
+		reasons = append(reasons, "draining")
+	}
+	if !h.ready {
+		reasons = append(reasons, "warming up")
+	}
+	for reason := range h.holds {
+		reasons = append(reasons, reason)
+	}
+	checks := make(map[string]func(context.Context) error, len(h.checks))
+	for name, check := range h.checks {
+		checks[name] = check
+	}
+	h.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	for name, check := range checks {
+		if err := check(ctx); err != nil {
+			reasons = append(reasons, name+": "+err.Error())
+		}
+	}
+	sort.Strings(reasons)
+	return len(reasons) == 0, reasons
+}
+
+// Mount registers the probes:
+//
+//	GET  /healthz  liveness, 200 while the process is running
+//	GET  /readyz   readiness, 503 with reasons during warmup, holds, failed checks or drain
+//	POST /drain    starts draining; requires "Authorization: Bearer $HEALTH_DRAIN_TOKEN"
+func Mount(router fiber.Router, h *Checker) {
+	router.Get("/healthz", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"status": "ok"})
+	})
+	router.Get("/readyz", func(c *fiber.Ctx) error {
+		ready, reasons := h.Status(c.UserContext())
+		if !ready {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"status": "unready", "reasons": reasons})
+		}
+		return c.JSON(fiber.Map{"status": "ready"})
+	})
+	router.Post("/drain", func(c *fiber.Ctx) error {
+		token := os.Getenv("HEALTH_DRAIN_TOKEN")
+		if token == "" || subtle.ConstantTimeCompare([]byte(c.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+		h.Drain()
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"status": "draining"})
+	})
+}
+`
+
+var healthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Generate liveness/readiness probes with warmup, hold and drain gating in app/health",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !writeGenerated(codegen.File{Path: healthFile, Content: healthTemplate}) {
+			return
+		}
+		fmt.Printf("Health probes created in %s. Wire them in main.go:\n", filepath.Dir(healthFile))
+		fmt.Print(`
+	health.Mount(server, health.Default)
+	health.Default.AddCheck("database", database.HealthCheck(db))
+	release := health.Default.Hold("migrations")
+	if err := database.MigrateOnStart(ctx, db); err != nil {
+		log.Fatal(err)
+	}
+	release()
+	health.Default.MarkReady()
+`)
+		fmt.Println("\nSet HEALTH_DRAIN_TOKEN and run 'gonext drain' before stopping an instance.")
+		openIfRequested(healthFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(healthCmd)
+	gCmd.AddCommand(healthCmd)
+}
@@ -0,0 +1,448 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+)
+
+// generateIDStrategy is set by `g entity --id` and `g repository --id`
+var generateIDStrategy string
+
+// idDir holds the ID types and generators of the entities
+var idDir = filepath.Join("app", "id")
+
+// idStrategy describes how the IDs of an entity are typed and made
+type idStrategy struct {
+	Type   string // Go type of the ID field
+	New    string // Call returning a new ID, empty when the database assigns it
+	Column string // Column type of the id column
+	File   string // File of app/id declaring the type
+}
+
+// idStrategies are the values accepted by --id
+var idStrategies = map[string]idStrategy{
+	"uuid":          {Type: "id.UUID", New: "id.NewUUID()", Column: "uuid PRIMARY KEY", File: "uuid.go"},
+	"ulid":          {Type: "id.ULID", New: "id.NewULID()", Column: "char(26) PRIMARY KEY", File: "ulid.go"},
+	"snowflake":     {Type: "id.Snowflake", New: "id.NewSnowflake()", Column: "bigint PRIMARY KEY", File: "snowflake.go"},
+	"autoincrement": {Type: "int64", Column: "bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY"},
+}
+
+// validateIDStrategy checks the --id flag; empty keeps the untyped string ID
+func validateIDStrategy() error {
+	if _, ok := idStrategies[generateIDStrategy]; generateIDStrategy != "" && !ok {
+		return fmt.Errorf("invalid --id %q (expected uuid, ulid, snowflake or autoincrement)", generateIDStrategy)
+	}
+	return nil
+}
+
+// idFiles returns the app/id files the strategy needs and the project does not have yet
+func idFiles(strategy string) []codegen.File {
+	s := idStrategies[strategy]
+	if s.File == "" {
+		return nil
+	}
+	files := missingFile(codegen.File{Path: filepath.Join(idDir, "id.go"), Content: idTemplate})
+	return append(files, missingFile(codegen.File{Path: filepath.Join(idDir, s.File), Content: idTypeTemplates[strategy]})...)
+}
+
+// entityIDType returns the type of the ID field of a module's entity: the one of
+// --id when given, else the one declared in the entity file, else string
+func entityIDType(module, name, titleName string) string {
+	if s, ok := idStrategies[generateIDStrategy]; ok {
+		return s.Type
+	}
+	if typ, ok := declaredIDType(module, name, titleName); ok {
+		return typ
+	}
+	return "string"
+}
+
+// declaredIDType reads the type of the ID field of the entity file, if it has one
+func declaredIDType(module, name, titleName string) (typ string, ok bool) {
+	f, err := parser.ParseFile(token.NewFileSet(), entityFile(module, name), nil, parser.SkipObjectResolution)
+	if err != nil {
+		return "", false
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		ts, isType := n.(*ast.TypeSpec)
+		if !isType || ts.Name.Name != titleName {
+			return true
+		}
+		if st, isStruct := ts.Type.(*ast.StructType); isStruct {
+			for _, field := range st.Fields.List {
+				for _, n := range field.Names {
+					if n.Name == "ID" {
+						typ, ok = types.ExprString(field.Type), true
+					}
+				}
+			}
+		}
+		return false
+	})
+	return typ, ok
+}
+
+// printIDColumn shows the id column the --id strategy needs in table
+func printIDColumn(table string) {
+	if s, ok := idStrategies[generateIDStrategy]; ok {
+		fmt.Printf("Declare the id column of %s as:\n  id %s\n", table, s.Column)
+	}
+}
+
+// idString renders expr, an ID of type typ, as a string
+func idString(typ, expr string) string {
+	switch {
+	case typ == "string":
+		return expr
+	case strings.HasPrefix(typ, "id."):
+		return expr + ".String()"
+	}
+	return "strconv.FormatInt(" + expr + ", 10)"
+}
+
+// newIDCall returns the call making a new ID of type typ, or "" when the
+// database assigns it
+func newIDCall(typ string) string {
+	for _, s := range idStrategies {
+		if s.Type == typ {
+			return s.New
+		}
+	}
+	return ""
+}
+
+const idTemplate = `// Package id types the IDs of the entities and generates new ones. The
+// generators are variables, so tests can replace them for predictable IDs.
+package id
+
+import "fmt"
+
+// scanText reads an ID stored as text, leaving it zero for NULL
+func scanText(src any, unmarshal func([]byte) error) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return unmarshal([]byte(v))
+	case []byte:
+		return unmarshal(v)
+	}
+	return fmt.Errorf("id: cannot scan %T", src)
+}
+`
+
+// idTypeTemplates are the files of app/id declaring each generated ID type
+var idTypeTemplates = map[string]string{
+	"uuid": `package id
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// UUID is a version 7 UUID: random, but ordered by creation time, so new rows
+// land at the end of the primary key index. Store it in a uuid column.
+type UUID [16]byte
+
+// NewUUID returns a new UUID
+var NewUUID = func() UUID {
+	var u UUID
+	if _, err := rand.Read(u[6:]); err != nil {
+		panic(fmt.Sprintf("id: reading random bytes: %v", err))
+	}
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(u[:6], ms[2:])
+	u[6] = u[6]&0x0f | 0x70
+	u[8] = u[8]&0x3f | 0x80
+	return u
+}
+
+// ParseUUID reads a UUID in its canonical form, e.g. 0190b1a2-7c3d-7e4f-8a5b-6c7d8e9f0a1b
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("id: invalid UUID %q", s)
+	}
+	if _, err := hex.Decode(u[:], []byte(s[:8]+s[9:13]+s[14:18]+s[19:23]+s[24:])); err != nil {
+		return u, fmt.Errorf("id: invalid UUID %q", s)
+	}
+	return u, nil
+}
+
+// String returns the canonical form of u
+func (u UUID) String() string {
+	h := hex.EncodeToString(u[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// IsZero reports whether u is unset
+func (u UUID) IsZero() bool {
+	return u == UUID{}
+}
+
+// MarshalText encodes u in JSON and query strings in its canonical form
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText decodes the canonical form
+func (u *UUID) UnmarshalText(b []byte) error {
+	v, err := ParseUUID(string(b))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+// Value stores u in a uuid column
+func (u UUID) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+// Scan reads u from a uuid column, as text or its 16 bytes
+func (u *UUID) Scan(src any) error {
+	if b, ok := src.([]byte); ok && len(b) == len(u) {
+		copy(u[:], b)
+		return nil
+	}
+	return scanText(src, u.UnmarshalText)
+}
+`,
+	"ulid": `package id
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// crockford is the base32 alphabet of ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID is a 128-bit ID of 48 bits of milliseconds and 80 random bits, written
+// as 26 characters that sort in creation order. Store it in a char(26) column.
+type ULID [16]byte
+
+// NewULID returns a new ULID
+var NewULID = func() ULID {
+	var u ULID
+	if _, err := rand.Read(u[6:]); err != nil {
+		panic(fmt.Sprintf("id: reading random bytes: %v", err))
+	}
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(u[:6], ms[2:])
+	return u
+}
+
+// ParseULID reads a ULID in either case, e.g. 01J3B5N8QK9V4XG2M7R6T0WZYA
+func ParseULID(s string) (ULID, error) {
+	var u ULID
+	if len(s) != 26 || s[0] > '7' {
+		return u, fmt.Errorf("id: invalid ULID %q", s)
+	}
+	var hi, lo uint64
+	for _, c := range strings.ToUpper(s) {
+		v := strings.IndexRune(crockford, c)
+		if v < 0 {
+			return u, fmt.Errorf("id: invalid ULID %q", s)
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+	binary.BigEndian.PutUint64(u[:8], hi)
+	binary.BigEndian.PutUint64(u[8:], lo)
+	return u, nil
+}
+
+// String returns the 26 characters of u
+func (u ULID) String() string {
+	hi, lo := binary.BigEndian.Uint64(u[:8]), binary.BigEndian.Uint64(u[8:])
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// IsZero reports whether u is unset
+func (u ULID) IsZero() bool {
+	return u == ULID{}
+}
+
+// MarshalText encodes u in JSON and query strings as its 26 characters
+func (u ULID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText decodes the 26 characters
+func (u *ULID) UnmarshalText(b []byte) error {
+	v, err := ParseULID(string(b))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+// Value stores u in a char(26) column
+func (u ULID) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+// Scan reads u from a char(26) column
+func (u *ULID) Scan(src any) error {
+	return scanText(src, u.UnmarshalText)
+}
+`,
+	"snowflake": `package id
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SnowflakeEpoch is the start of the timestamps of the Snowflakes. Never change
+// it once IDs are stored.
+var SnowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Snowflake is a 64-bit ID of 41 bits of milliseconds since SnowflakeEpoch,
+// 10 bits of node and 12 bits of sequence, ordered by creation time. Store it
+// in a bigint column. It is a string in JSON, since JavaScript numbers lose
+// precision past 2^53.
+type Snowflake int64
+
+// SnowflakeNode generates the Snowflakes of one process. Every instance of the
+// app needs its own node number, or two may generate the same ID.
+type SnowflakeNode struct {
+	mu     sync.Mutex
+	node   int64
+	lastMS int64
+	seq    int64
+}
+
+// NewSnowflakeNode returns the generator of node, from 0 to 1023
+func NewSnowflakeNode(node int64) (*SnowflakeNode, error) {
+	if node < 0 || node > 1023 {
+		return nil, fmt.Errorf("id: snowflake node %d is out of range [0, 1023]", node)
+	}
+	return &SnowflakeNode{node: node, lastMS: -1}, nil
+}
+
+// Next returns a new Snowflake. Within a millisecond the sequence counts up;
+// once it is exhausted, or if the system clock goes back, the node keeps
+// counting from the last millisecond it used.
+func (n *SnowflakeNode) Next() Snowflake {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	ms := time.Since(SnowflakeEpoch).Milliseconds()
+	if ms <= n.lastMS {
+		ms = n.lastMS
+		n.seq = (n.seq + 1) & 4095
+		if n.seq == 0 {
+			ms++
+		}
+	} else {
+		n.seq = 0
+	}
+	n.lastMS = ms
+	return Snowflake(ms<<22 | n.node<<12 | n.seq)
+}
+
+var (
+	defaultNodeOnce sync.Once
+	defaultNode     *SnowflakeNode
+)
+
+// NewSnowflake returns a new Snowflake from the node numbered by the
+// SNOWFLAKE_NODE environment variable, 0 when unset
+var NewSnowflake = func() Snowflake {
+	defaultNodeOnce.Do(func() {
+		number := int64(0)
+		if v := os.Getenv("SNOWFLAKE_NODE"); v != "" {
+			parsed, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				panic(fmt.Sprintf("id: invalid SNOWFLAKE_NODE %q", v))
+			}
+			number = parsed
+		}
+		node, err := NewSnowflakeNode(number)
+		if err != nil {
+			panic(err)
+		}
+		defaultNode = node
+	})
+	return defaultNode.Next()
+}
+
+// ParseSnowflake reads a Snowflake written in decimal
+func ParseSnowflake(s string) (Snowflake, error) {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("id: invalid Snowflake %q", s)
+	}
+	return Snowflake(v), nil
+}
+
+// String returns s in decimal
+func (s Snowflake) String() string {
+	return strconv.FormatInt(int64(s), 10)
+}
+
+// IsZero reports whether s is unset
+func (s Snowflake) IsZero() bool {
+	return s == 0
+}
+
+// MarshalText encodes s in JSON and query strings in decimal
+func (s Snowflake) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes the decimal form
+func (s *Snowflake) UnmarshalText(b []byte) error {
+	v, err := ParseSnowflake(string(b))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// Value stores s in a bigint column
+func (s Snowflake) Value() (driver.Value, error) {
+	return int64(s), nil
+}
+
+// Scan reads s from a bigint column
+func (s *Snowflake) Scan(src any) error {
+	if v, ok := src.(int64); ok {
+		*s = Snowflake(v)
+		return nil
+	}
+	return scanText(src, s.UnmarshalText)
+}
+`,
+}
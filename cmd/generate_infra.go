@@ -0,0 +1,794 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+// infraProvider and infraRuntime are set by `g infra --provider/--runtime`
+var infraProvider string
+var infraRuntime string
+
+// infraDir holds the generated Terraform, one directory per provider
+const infraDir = "infra"
+
+const infraVersionsTemplate = `terraform {
+  required_version = ">= 1.6"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+  }
+
+  # TODO: Configure remote state, e.g.
+  # backend "s3" {
+  #   bucket = "acme-terraform-state"
+  #   key    = "%[1]s/terraform.tfstate"
+  #   region = "us-east-1"
+  # }
+}
+
+provider "aws" {
+  region = var.region
+
+  default_tags {
+    tags = local.tags
+  }
+}
+`
+
+const infraVariablesTemplate = `variable "region" {
+  description = "AWS region to deploy to"
+  type        = string
+  default     = "us-east-1"
+}
+
+variable "environment" {
+  description = "Deployment environment, e.g. staging or production"
+  type        = string
+  default     = "staging"
+}
+
+variable "vpc_id" {
+  description = "VPC the service runs in"
+  type        = string
+}
+
+variable "private_subnet_ids" {
+  description = "Subnets for the service, database and cache"
+  type        = list(string)
+}
+%[1]s
+variable "image" {
+  description = "Container image to run, e.g. the one pushed by 'gonext build --docker --push'"
+  type        = string
+  default     = ""
+}
+
+variable "db_instance_class" {
+  description = "RDS instance class"
+  type        = string
+  default     = "db.t4g.micro"
+}
+
+variable "enable_redis" {
+  description = "Create an ElastiCache Redis cluster"
+  type        = bool
+  default     = true
+}
+`
+
+const infraECSVariables = `
+variable "public_subnet_ids" {
+  description = "Subnets for the load balancer"
+  type        = list(string)
+}
+
+variable "desired_count" {
+  description = "Number of running tasks"
+  type        = number
+  default     = 2
+}
+
+variable "cpu" {
+  description = "Task CPU units"
+  type        = number
+  default     = 256
+}
+
+variable "memory" {
+  description = "Task memory in MiB"
+  type        = number
+  default     = 512
+}
+`
+
+const infraLambdaVariables = `
+variable "lambda_zip" {
+  description = "Zip containing the bootstrap binary (GOOS=linux GOARCH=arm64 go build -o bootstrap)"
+  type        = string
+  default     = "../../bin/lambda.zip"
+}
+
+variable "memory" {
+  description = "Function memory in MiB"
+  type        = number
+  default     = 512
+}
+`
+
+const infraMainTemplate = `# Generated by 'gonext g infra --provider aws'. A starting point for platform
+# teams: review sizing, networking and security before applying.
+
+locals {
+  service = "%[1]s"
+  name    = "${local.service}-${var.environment}"
+
+  tags = {
+    Service     = local.service
+    Environment = var.environment
+    ManagedBy   = "terraform"%[2]s
+  }
+
+  # Secrets the service reads at runtime, created empty in Secrets Manager.
+  # Populate their values outside Terraform so they never land in state.
+  secret_names = [%[3]s]
+}
+
+module "secrets" {
+  source = "./modules/secrets"
+  name   = local.name
+  names  = local.secret_names
+}
+
+module "database" {
+  source         = "./modules/database"
+  name           = local.name
+  vpc_id         = var.vpc_id
+  subnet_ids     = var.private_subnet_ids
+  instance_class = var.db_instance_class
+  client_sg_id   = module.service.client_sg_id
+}
+
+module "cache" {
+  source       = "./modules/cache"
+  count        = var.enable_redis ? 1 : 0
+  name         = local.name
+  vpc_id       = var.vpc_id
+  subnet_ids   = var.private_subnet_ids
+  client_sg_id = module.service.client_sg_id
+}
+%[4]s`
+
+const infraECSMain = `
+module "service" {
+  source             = "./modules/ecs"
+  name               = local.name
+  image              = var.image
+  vpc_id             = var.vpc_id
+  private_subnet_ids = var.private_subnet_ids
+  public_subnet_ids  = var.public_subnet_ids
+  desired_count      = var.desired_count
+  cpu                = var.cpu
+  memory             = var.memory
+  secret_arns        = module.secrets.arns
+
+  environment = {
+    APP_ENV     = var.environment
+    SERVER_HOST = "0.0.0.0"
+    SERVER_PORT = "3000"
+    DB_HOST     = module.database.address
+    REDIS_URL   = var.enable_redis ? module.cache[0].url : ""
+  }
+}
+`
+
+const infraLambdaMain = `
+module "service" {
+  source      = "./modules/lambda"
+  name        = local.name
+  zip         = var.lambda_zip
+  vpc_id      = var.vpc_id
+  subnet_ids  = var.private_subnet_ids
+  memory      = var.memory
+  secret_arns = module.secrets.arns
+
+  environment = {
+    APP_ENV   = var.environment
+    DB_HOST   = module.database.address
+    REDIS_URL = var.enable_redis ? module.cache[0].url : ""
+  }
+}
+`
+
+const infraOutputsTemplate = `output "url" {
+  description = "Public URL of the service"
+  value       = module.service.url
+}
+
+output "database_endpoint" {
+  value = module.database.address
+}
+
+output "database_master_secret_arn" {
+  description = "Secrets Manager secret holding the generated RDS master password"
+  value       = module.database.master_secret_arn
+}
+
+output "redis_url" {
+  value = var.enable_redis ? module.cache[0].url : null
+}
+
+output "secret_arns" {
+  value = module.secrets.arns
+}
+`
+
+const infraSecretsModule = `variable "name" {
+  type = string
+}
+
+variable "names" {
+  type = list(string)
+}
+
+resource "aws_secretsmanager_secret" "this" {
+  for_each = toset(var.names)
+  name     = "${var.name}/${each.value}"
+}
+
+output "arns" {
+  value = { for k, s in aws_secretsmanager_secret.this : k => s.arn }
+}
+`
+
+const infraDatabaseModule = `variable "name" {
+  type = string
+}
+
+variable "vpc_id" {
+  type = string
+}
+
+variable "subnet_ids" {
+  type = list(string)
+}
+
+variable "instance_class" {
+  type = string
+}
+
+variable "client_sg_id" {
+  description = "Security group allowed to connect"
+  type        = string
+}
+
+resource "aws_db_subnet_group" "this" {
+  name       = var.name
+  subnet_ids = var.subnet_ids
+}
+
+resource "aws_security_group" "this" {
+  name   = "${var.name}-db"
+  vpc_id = var.vpc_id
+
+  ingress {
+    from_port       = 5432
+    to_port         = 5432
+    protocol        = "tcp"
+    security_groups = [var.client_sg_id]
+  }
+}
+
+resource "aws_db_instance" "this" {
+  identifier                  = var.name
+  engine                      = "postgres"
+  engine_version              = "16"
+  instance_class              = var.instance_class
+  allocated_storage           = 20
+  max_allocated_storage       = 100
+  db_name                     = replace(var.name, "-", "_")
+  username                    = "app"
+  manage_master_user_password = true
+  db_subnet_group_name        = aws_db_subnet_group.this.name
+  vpc_security_group_ids      = [aws_security_group.this.id]
+  storage_encrypted           = true
+  backup_retention_period     = 7
+  deletion_protection         = true
+  skip_final_snapshot         = false
+  final_snapshot_identifier   = "${var.name}-final"
+}
+
+output "address" {
+  value = aws_db_instance.this.address
+}
+
+output "master_secret_arn" {
+  value = aws_db_instance.this.master_user_secret[0].secret_arn
+}
+`
+
+const infraCacheModule = `variable "name" {
+  type = string
+}
+
+variable "vpc_id" {
+  type = string
+}
+
+variable "subnet_ids" {
+  type = list(string)
+}
+
+variable "client_sg_id" {
+  type = string
+}
+
+resource "aws_elasticache_subnet_group" "this" {
+  name       = var.name
+  subnet_ids = var.subnet_ids
+}
+
+resource "aws_security_group" "this" {
+  name   = "${var.name}-redis"
+  vpc_id = var.vpc_id
+
+  ingress {
+    from_port       = 6379
+    to_port         = 6379
+    protocol        = "tcp"
+    security_groups = [var.client_sg_id]
+  }
+}
+
+resource "aws_elasticache_replication_group" "this" {
+  replication_group_id       = var.name
+  description                = "${var.name} cache"
+  engine                     = "redis"
+  node_type                  = "cache.t4g.micro"
+  num_cache_clusters         = 1
+  subnet_group_name          = aws_elasticache_subnet_group.this.name
+  security_group_ids         = [aws_security_group.this.id]
+  transit_encryption_enabled = true
+  at_rest_encryption_enabled = true
+}
+
+output "url" {
+  value = "rediss://${aws_elasticache_replication_group.this.primary_endpoint_address}:6379"
+}
+`
+
+const infraIAMPolicy = `
+data "aws_iam_policy_document" "secrets" {
+  statement {
+    actions   = ["secretsmanager:GetSecretValue"]
+    resources = length(var.secret_arns) > 0 ? values(var.secret_arns) : ["arn:aws:secretsmanager:*:*:secret:none"]
+  }
+}
+`
+
+const infraECSModule = `variable "name" {
+  type = string
+}
+
+variable "image" {
+  type = string
+}
+
+variable "vpc_id" {
+  type = string
+}
+
+variable "private_subnet_ids" {
+  type = list(string)
+}
+
+variable "public_subnet_ids" {
+  type = list(string)
+}
+
+variable "desired_count" {
+  type = number
+}
+
+variable "cpu" {
+  type = number
+}
+
+variable "memory" {
+  type = number
+}
+
+variable "environment" {
+  type = map(string)
+}
+
+variable "secret_arns" {
+  description = "Secrets injected as environment variables, keyed by variable name"
+  type        = map(string)
+}
+
+data "aws_region" "current" {}
+
+resource "aws_security_group" "lb" {
+  name   = "${var.name}-lb"
+  vpc_id = var.vpc_id
+
+  ingress {
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  ingress {
+    from_port   = 80
+    to_port     = 80
+    protocol    = "tcp"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+resource "aws_security_group" "service" {
+  name   = "${var.name}-service"
+  vpc_id = var.vpc_id
+
+  ingress {
+    from_port       = 3000
+    to_port         = 3000
+    protocol        = "tcp"
+    security_groups = [aws_security_group.lb.id]
+  }
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+resource "aws_lb" "this" {
+  name               = var.name
+  load_balancer_type = "application"
+  subnets            = var.public_subnet_ids
+  security_groups    = [aws_security_group.lb.id]
+}
+
+resource "aws_lb_target_group" "this" {
+  name                 = var.name
+  port                 = 3000
+  protocol             = "HTTP"
+  target_type          = "ip"
+  vpc_id               = var.vpc_id
+  deregistration_delay = 30
+
+  # Only route to tasks that report ready (see 'gonext g health')
+  health_check {
+    path                = "/readyz"
+    healthy_threshold   = 2
+    unhealthy_threshold = 3
+    interval            = 10
+  }
+}
+
+# TODO: Add an HTTPS listener with an ACM certificate and redirect HTTP to it
+resource "aws_lb_listener" "http" {
+  load_balancer_arn = aws_lb.this.arn
+  port              = 80
+  protocol          = "HTTP"
+
+  default_action {
+    type             = "forward"
+    target_group_arn = aws_lb_target_group.this.arn
+  }
+}
+
+resource "aws_cloudwatch_log_group" "this" {
+  name              = "/ecs/${var.name}"
+  retention_in_days = 30
+}
+
+data "aws_iam_policy_document" "assume" {
+  statement {
+    actions = ["sts:AssumeRole"]
+    principals {
+      type        = "Service"
+      identifiers = ["ecs-tasks.amazonaws.com"]
+    }
+  }
+}
+` + infraIAMPolicy + `
+resource "aws_iam_role" "execution" {
+  name               = "${var.name}-execution"
+  assume_role_policy = data.aws_iam_policy_document.assume.json
+}
+
+resource "aws_iam_role_policy_attachment" "execution" {
+  role       = aws_iam_role.execution.name
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AmazonECSTaskExecutionRolePolicy"
+}
+
+resource "aws_iam_role_policy" "secrets" {
+  role   = aws_iam_role.execution.id
+  policy = data.aws_iam_policy_document.secrets.json
+}
+
+resource "aws_iam_role" "task" {
+  name               = "${var.name}-task"
+  assume_role_policy = data.aws_iam_policy_document.assume.json
+}
+
+resource "aws_ecs_cluster" "this" {
+  name = var.name
+}
+
+resource "aws_ecs_task_definition" "this" {
+  family                   = var.name
+  requires_compatibilities = ["FARGATE"]
+  network_mode             = "awsvpc"
+  cpu                      = var.cpu
+  memory                   = var.memory
+  execution_role_arn       = aws_iam_role.execution.arn
+  task_role_arn            = aws_iam_role.task.arn
+
+  container_definitions = jsonencode([{
+    name         = var.name
+    image        = var.image
+    essential    = true
+    portMappings = [{ containerPort = 3000 }]
+    environment  = [for k, v in var.environment : { name = k, value = v }]
+    secrets      = [for k, arn in var.secret_arns : { name = k, valueFrom = arn }]
+    # Give in-flight requests time to finish after SIGTERM
+    stopTimeout = 30
+    logConfiguration = {
+      logDriver = "awslogs"
+      options = {
+        awslogs-group         = aws_cloudwatch_log_group.this.name
+        awslogs-region        = data.aws_region.current.name
+        awslogs-stream-prefix = "app"
+      }
+    }
+  }])
+}
+
+resource "aws_ecs_service" "this" {
+  name            = var.name
+  cluster         = aws_ecs_cluster.this.id
+  task_definition = aws_ecs_task_definition.this.arn
+  desired_count   = var.desired_count
+  launch_type     = "FARGATE"
+
+  network_configuration {
+    subnets         = var.private_subnet_ids
+    security_groups = [aws_security_group.service.id]
+  }
+
+  load_balancer {
+    target_group_arn = aws_lb_target_group.this.arn
+    container_name   = var.name
+    container_port   = 3000
+  }
+
+  deployment_circuit_breaker {
+    enable   = true
+    rollback = true
+  }
+}
+
+output "url" {
+  value = "http://${aws_lb.this.dns_name}"
+}
+
+output "client_sg_id" {
+  value = aws_security_group.service.id
+}
+`
+
+const infraLambdaModule = `variable "name" {
+  type = string
+}
+
+variable "zip" {
+  type = string
+}
+
+variable "vpc_id" {
+  type = string
+}
+
+variable "subnet_ids" {
+  type = list(string)
+}
+
+variable "memory" {
+  type = number
+}
+
+variable "environment" {
+  type = map(string)
+}
+
+variable "secret_arns" {
+  description = "Secrets the function may read; their ARNs are passed as <NAME>_SECRET_ARN"
+  type        = map(string)
+}
+
+resource "aws_security_group" "this" {
+  name   = "${var.name}-lambda"
+  vpc_id = var.vpc_id
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+data "aws_iam_policy_document" "assume" {
+  statement {
+    actions = ["sts:AssumeRole"]
+    principals {
+      type        = "Service"
+      identifiers = ["lambda.amazonaws.com"]
+    }
+  }
+}
+` + infraIAMPolicy + `
+resource "aws_iam_role" "this" {
+  name               = var.name
+  assume_role_policy = data.aws_iam_policy_document.assume.json
+}
+
+resource "aws_iam_role_policy_attachment" "vpc" {
+  role       = aws_iam_role.this.name
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaVPCAccessExecutionRole"
+}
+
+resource "aws_iam_role_policy" "secrets" {
+  role   = aws_iam_role.this.id
+  policy = data.aws_iam_policy_document.secrets.json
+}
+
+# The Fiber app must be wrapped with a Lambda adapter (e.g. aws-lambda-go-api-proxy)
+resource "aws_lambda_function" "this" {
+  function_name    = var.name
+  role             = aws_iam_role.this.arn
+  runtime          = "provided.al2023"
+  architectures    = ["arm64"]
+  handler          = "bootstrap"
+  filename         = var.zip
+  source_code_hash = filebase64sha256(var.zip)
+  memory_size      = var.memory
+  timeout          = 30
+
+  vpc_config {
+    subnet_ids         = var.subnet_ids
+    security_group_ids = [aws_security_group.this.id]
+  }
+
+  environment {
+    variables = merge(var.environment, { for k, arn in var.secret_arns : "${k}_SECRET_ARN" => arn })
+  }
+}
+
+resource "aws_lambda_function_url" "this" {
+  function_name      = aws_lambda_function.this.function_name
+  authorization_type = "NONE"
+}
+
+output "url" {
+  value = aws_lambda_function_url.this.function_url
+}
+
+output "client_sg_id" {
+  value = aws_security_group.this.id
+}
+`
+
+// infraTags renders the module owners from gonext.yaml as a resource tag
+func infraTags(m *manifest.Manifest) string {
+	owners := map[string]bool{}
+	for _, mod := range m.Modules {
+		if mod.Owner != "" {
+			owners[mod.Owner] = true
+		}
+	}
+	if len(owners) == 0 {
+		return ""
+	}
+	var list []string
+	for o := range owners {
+		list = append(list, o)
+	}
+	sort.Strings(list)
+	return fmt.Sprintf("\n    Owners      = %q", strings.Join(list, " "))
+}
+
+// infraSecretNames lists the .env variables to create as secrets, without their values
+func infraSecretNames() string {
+	env, _ := readEnvFile(".env")
+	keys := envKeys(env)
+	if len(keys) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(keys))
+	for i, k := range keys {
+		quoted[i] = "\n    " + fmt.Sprintf("%q", k) + ","
+	}
+	return strings.Join(quoted, "") + "\n  "
+}
+
+// infraFiles renders the Terraform for the AWS provider with the chosen runtime
+func infraFiles(m *manifest.Manifest, runtime string) []codegen.File {
+	app := defaultImage()
+	dir := filepath.Join(infraDir, "aws")
+	variables, main, serviceModule := infraECSVariables, infraECSMain, infraECSModule
+	if runtime == "lambda" {
+		variables, main, serviceModule = infraLambdaVariables, infraLambdaMain, infraLambdaModule
+	}
+	return []codegen.File{
+		{Path: filepath.Join(dir, "versions.tf"), Content: fmt.Sprintf(infraVersionsTemplate, app)},
+		{Path: filepath.Join(dir, "variables.tf"), Content: fmt.Sprintf(infraVariablesTemplate, variables)},
+		{Path: filepath.Join(dir, "main.tf"), Content: fmt.Sprintf(infraMainTemplate, app, infraTags(m), infraSecretNames(), main)},
+		{Path: filepath.Join(dir, "outputs.tf"), Content: infraOutputsTemplate},
+		{Path: filepath.Join(dir, "modules", "secrets", "main.tf"), Content: infraSecretsModule},
+		{Path: filepath.Join(dir, "modules", "database", "main.tf"), Content: infraDatabaseModule},
+		{Path: filepath.Join(dir, "modules", "cache", "main.tf"), Content: infraCacheModule},
+		{Path: filepath.Join(dir, "modules", runtime, "main.tf"), Content: serviceModule},
+	}
+}
+
+var infraCmd = &cobra.Command{
+	Use:   "infra",
+	Short: "Generate Terraform for the service (ECS/Fargate or Lambda, RDS, Redis, secrets)",
+	Long: `Generates Terraform in infra/<provider>: a root module parameterized by the app
+name and gonext.yaml (module owners become tags), plus modules for the service,
+a Postgres database, a Redis cache and Secrets Manager secrets named after the
+variables in .env. Values are never copied.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if infraProvider != "aws" {
+			fmt.Printf("Unsupported provider '%s' (supported: aws)\n", infraProvider)
+			return
+		}
+		if infraRuntime != "ecs" && infraRuntime != "lambda" {
+			fmt.Printf("Unknown runtime '%s' (expected ecs or lambda)\n", infraRuntime)
+			return
+		}
+		m, err := manifest.Load()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if !writeGenerated(infraFiles(m, infraRuntime)...) {
+			return
+		}
+		dir := filepath.Join(infraDir, infraProvider)
+		fmt.Printf("Terraform created in %s. Set vpc_id and the subnet ids in a terraform.tfvars, then run:\n\n  cd %s && terraform init && terraform plan\n", dir, dir)
+		openIfRequested(dir)
+	},
+}
+
+func init() {
+	infraCmd.Flags().StringVar(&infraProvider, "provider", "aws", "Cloud provider (aws)")
+	infraCmd.Flags().StringVar(&infraRuntime, "runtime", "ecs", "Compute for the service: ecs (Fargate behind a load balancer) or lambda")
+	generateCmd.AddCommand(infraCmd)
+	gCmd.AddCommand(infraCmd)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+// interceptorContent renders an interceptor with a timing Before hook and a
+// response-mapping After hook
+func interceptorContent(module, titleName string) string {
+	return fmt.Sprintf(`package interceptor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// %[1]sInterceptor runs around the handlers it is registered for: Before before the
+// handler, After with the handler's result.
+//
+// Register it for every route of the module in %[2]sModule.MountRoutes:
+//
+//	group := router.Group("/%[3]s", interceptor.Use(interceptor.New%[1]sInterceptor()))
+//
+// or for a single route in the route file:
+//
+//	route.Get("/:id", interceptor.Use(interceptor.New%[1]sInterceptor()), ctrl.Get%[2]s)
+type %[1]sInterceptor struct{}
+
+func New%[1]sInterceptor() *%[1]sInterceptor {
+	return &%[1]sInterceptor{}
+}
+
+// Before records when the request started. Returning an error skips the handler.
+func (i *%[1]sInterceptor) Before(ctx *fiber.Ctx) error {
+	ctx.Locals("%[4]sStartedAt", time.Now())
+	return nil
+}
+
+// After reports the handler's duration in milliseconds and maps its result. The
+// returned error replaces the handler's.
+func (i *%[1]sInterceptor) After(ctx *fiber.Ctx, err error) error {
+	if start, ok := ctx.Locals("%[4]sStartedAt").(time.Time); ok {
+		ctx.Set("Server-Timing", fmt.Sprintf("app;dur=%%.3f", float64(time.Since(start).Microseconds())/1000))
+	}
+	if err != nil {
+		// TODO: Map errors to responses, e.g. repository not-found errors to 404
+		return err
+	}
+	// TODO: Map the response, e.g. wrap the body in an envelope
+	return nil
+}
+`, titleName, strings.Title(module), module, strings.ToLower(titleName[:1])+titleName[1:])
+}
+
+// interceptorHelpersContent is shared by every interceptor of a module
+const interceptorHelpersContent = `package interceptor
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// Interceptor wraps a handler with pre- and post-processing
+type Interceptor interface {
+	// Before runs before the handler. Returning an error skips the handler and the
+	// remaining interceptors.
+	Before(ctx *fiber.Ctx) error
+	// After runs after the handler with its error and returns the error to report.
+	After(ctx *fiber.Ctx, err error) error
+}
+
+// Use chains interceptors into a Fiber handler. Before hooks run in the order
+// given and After hooks in reverse order.
+func Use(interceptors ...Interceptor) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		return run(ctx, interceptors)
+	}
+}
+
+func run(ctx *fiber.Ctx, interceptors []Interceptor) error {
+	if len(interceptors) == 0 {
+		return ctx.Next()
+	}
+	if err := interceptors[0].Before(ctx); err != nil {
+		return err
+	}
+	return interceptors[0].After(ctx, run(ctx, interceptors[1:]))
+}
+`
+
+var interceptorCmd = &cobra.Command{
+	Use:   "interceptor [name] [in_module]",
+	Short: "Generate an interceptor with before/after hooks around handlers",
+	Long: `Generates app/<module>/interceptor/<name>Interceptor.go: a component whose
+Before hook runs before the handler and whose After hook receives the handler's
+result, for timing, logging or response mapping.
+
+interceptor.Use chains interceptors into a Fiber handler, registered for every
+route of the module in its MountRoutes or for a single route in its route file.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		titleName := strings.Title(name)
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+		interceptorDir := filepath.Join("app", module, "interceptor")
+		interceptorFile := filepath.Join(interceptorDir, fmt.Sprintf("%sInterceptor.go", name))
+		files := []codegen.File{{Path: interceptorFile, Content: interceptorContent(module, titleName)}}
+		files = append(files, missingFile(codegen.File{Path: filepath.Join(interceptorDir, "interceptor.go"), Content: interceptorHelpersContent})...)
+		if !writeGenerated(files...) {
+			return
+		}
+		fmt.Printf("Interceptor '%s' created in app/%s/interceptor. Register it in MountRoutes:\n  group := router.Group(\"/%s\", interceptor.Use(interceptor.New%sInterceptor()))\n", name, module, module, titleName)
+		openIfRequested(interceptorFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(interceptorCmd)
+	gCmd.AddCommand(interceptorCmd)
+}
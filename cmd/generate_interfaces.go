@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+)
+
+// injectInterfaces is set by `--inject-interfaces`
+var injectInterfaces bool
+
+// componentProvider is the name a component is bound and injected by with
+// --inject-interfaces: usersService for the users service of the users module,
+// ordersUsersService for a users service generated in orders
+func componentProvider(module, name, kind string) string {
+	if name == module {
+		return name + kind
+	}
+	return module + strings.Title(name) + kind
+}
+
+// withInterfaces rewrites the module's generated components so they depend on
+// interfaces: repositories and services declare one from their methods, services
+// and controllers inject them by name, and module.go binds the implementations
+func withInterfaces(module string, files []codegen.File) ([]codegen.File, error) {
+	for i, f := range files {
+		src := []byte(f.Content)
+		var err error
+		switch kind, name := componentKind(f.Path); kind {
+		case "Repository":
+			src, err = codemod.AddInterface(src, strings.Title(name)+"Repository", strings.Title(name)+"RepositoryInterface",
+				fmt.Sprintf("%sRepositoryInterface is what services depend on; bound as %q", strings.Title(name), componentProvider(module, name, "Repository")))
+		case "Service":
+			src, err = codemod.AddInterface(src, strings.Title(name)+"Service", strings.Title(name)+"ServiceInterface",
+				fmt.Sprintf("%sServiceInterface is what controllers depend on; bound as %q", strings.Title(name), componentProvider(module, name, "Service")))
+			if err == nil {
+				src, err = codemod.SetFieldType(src, strings.Title(name)+"Service", "Repository", "repository."+strings.Title(name)+"RepositoryInterface",
+					fmt.Sprintf(`inject:"name=%s"`, componentProvider(module, name, "Repository")))
+			}
+		case "Controller":
+			src, err = codemod.SetFieldType(src, strings.Title(name)+"Controller", "Service", "service."+strings.Title(name)+"ServiceInterface",
+				fmt.Sprintf(`inject:"name=%s"`, componentProvider(module, name, "Service")))
+		case "Module":
+			src = []byte(strings.Replace(f.Content, "\tapp.RegisterModuleComponents(", fmt.Sprintf("\tcontainer.Bind(%q, %sRepo)\n\tcontainer.Bind(%q, %sService)\n\tapp.RegisterModuleComponents(",
+				componentProvider(module, module, "Repository"), module, componentProvider(module, module, "Service"), module), 1))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Path, err)
+		}
+		files[i].Content = string(src)
+	}
+	return files, nil
+}
+
+// componentKind tells a module's module.go, controllers, services and repositories
+// apart by path, returning the kind and the component name
+func componentKind(path string) (kind, name string) {
+	base := filepath.Base(path)
+	if base == "module.go" {
+		return "Module", ""
+	}
+	for _, kind := range []string{"Controller", "Service", "Repository"} {
+		if filepath.Base(filepath.Dir(path)) == strings.ToLower(kind) && strings.HasSuffix(base, kind+".go") {
+			return kind, strings.TrimSuffix(base, kind+".go")
+		}
+	}
+	return "", ""
+}
+
+// printProviderBinding tells how to bind a component generated on its own with
+// --inject-interfaces, unless the module already binds it
+func printProviderBinding(module, name, kind string) {
+	provider := componentProvider(module, name, kind)
+	src, _ := os.ReadFile(filepath.Join("app", module, "module.go"))
+	if strings.Contains(string(src), fmt.Sprintf("Bind(%q,", provider)) {
+		return
+	}
+	fmt.Printf("It is injected with inject:\"name=%[1]s\". Bind it in the module's Register method and pass it to app.RegisterModuleComponents:\n  %[1]s := &%[2]s.%[3]s%[4]s{}\n  container.Bind(%[1]q, %[1]s)\n",
+		provider, strings.ToLower(kind), strings.Title(name), kind)
+}
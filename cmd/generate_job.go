@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/spf13/cobra"
+)
+
+// queueDir holds the task queue interface and the job registry shared by all modules
+var queueDir = filepath.Join("app", "queue")
+
+// queueTemplate is the driver-agnostic queue interface of the framework documentation
+const queueTemplate = `package queue
+
+// TaskQueue is implemented by queue drivers such as Redis (asynq), RabbitMQ,
+// Kafka or SQS. Bind the driver at startup with container.Bind("queue", q) and
+// inject it with inject:"queue".
+type TaskQueue interface {
+	Enqueue(typeName string, payload interface{}, opts ...interface{}) error
+	RegisterHandler(typeName string, handler func(payload []byte) error)
+	Start()
+	Shutdown()
+}
+`
+
+const queueJobTemplate = `package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Enqueuer is the part of a TaskQueue that producers use
+type Enqueuer interface {
+	Enqueue(typeName string, payload interface{}, opts ...interface{}) error
+}
+
+// Registrar is the part of a TaskQueue that workers use
+type Registrar interface {
+	RegisterHandler(typeName string, handler func(payload []byte) error)
+}
+
+// Job is a background job. Its exported fields are the payload, JSON-encoded
+// by the driver on enqueue.
+type Job interface {
+	Type() string
+}
+
+// Handler is a job that runs itself
+type Handler interface {
+	Job
+	Handle(ctx context.Context) error
+}
+
+var (
+	mu   sync.Mutex
+	jobs []func(Registrar)
+)
+
+// Dispatch enqueues a job under its type. opts are passed to the driver, e.g.
+// asynq.ProcessIn(time.Minute).
+func Dispatch(q Enqueuer, job Job, opts ...interface{}) error {
+	return q.Enqueue(job.Type(), job, opts...)
+}
+
+// RegisterJob records the handler of a job type. Modules call it in Register:
+//
+//	queue.RegisterJob[job.SendWelcomeEmailJob]()
+func RegisterJob[T any, P interface {
+	*T
+	Handler
+}]() {
+	mu.Lock()
+	defer mu.Unlock()
+	jobs = append(jobs, func(q Registrar) {
+		q.RegisterHandler(P(new(T)).Type(), func(payload []byte) error {
+			job := P(new(T))
+			if err := json.Unmarshal(payload, job); err != nil {
+				return fmt.Errorf("%s: decoding payload: %w", job.Type(), err)
+			}
+			return job.Handle(context.Background())
+		})
+	})
+}
+
+// AttachJobs registers the handlers of every recorded job on the worker's
+// queue. Call it after the modules are registered and before q.Start().
+func AttachJobs(q Registrar) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, attach := range jobs {
+		attach(q)
+	}
+}
+`
+
+// queueFiles returns the shared queue files the project does not have yet
+func queueFiles() []codegen.File {
+	files := missingFile(codegen.File{Path: filepath.Join(queueDir, "queue.go"), Content: queueTemplate})
+	return append(files, missingFile(codegen.File{Path: filepath.Join(queueDir, "job.go"), Content: queueJobTemplate})...)
+}
+
+// jobContent renders a job with its payload, handler and enqueue helper
+func jobContent(titleName, jobType string) string {
+	return fmt.Sprintf(`package job
+
+import (
+	"context"
+
+	"%[1]s/app/clock"
+	"%[1]s/app/queue"
+)
+
+// %[2]sJobType is the queue type %[2]sJob is enqueued under
+const %[2]sJobType = %[3]q
+
+// %[2]sJob is a background job
+type %[2]sJob struct {
+	// TODO: Add the payload fields, e.g. the ID of the record to process
+	ID string `+"`json:\"id\"`"+`
+
+	// Clock is where Handle reads the time; workers leave it nil for the
+	// system clock and tests set a clock.Fake
+	Clock clock.Clock `+"`json:\"-\"`"+`
+}
+
+// Type routes the job to its handler
+func (%[2]sJob) Type() string {
+	return %[2]sJobType
+}
+
+// Handle runs the job on a worker. A returned error lets the driver retry it.
+func (j *%[2]sJob) Handle(ctx context.Context) error {
+	// TODO: Do the work, reading the time from clock.Or(j.Clock).Now()
+	return nil
+}
+
+// Enqueue%[2]s enqueues the job on q, the TaskQueue injected with inject:"queue"
+func Enqueue%[2]s(q queue.Enqueuer, j %[2]sJob, opts ...interface{}) error {
+	return queue.Dispatch(q, j, opts...)
+}
+`, getModuleName(), titleName, jobType)
+}
+
+// registerJob records the job's handler in the module's Register method, or
+// prints the registration when the module has no module.go
+func registerJob(module, titleName string) error {
+	moduleFile := filepath.Join("app", module, "module.go")
+	register := fmt.Sprintf("queue.RegisterJob[job.%sJob]()", titleName)
+	if _, err := os.Stat(moduleFile); os.IsNotExist(err) {
+		fmt.Printf("app/%s has no module.go. Register the job at startup:\n  %s\n", module, register)
+		return nil
+	}
+	return editGenerated(moduleFile, func(src []byte) ([]byte, error) {
+		out, err := codemod.AppendStatement(src, "Register", register)
+		if err != nil {
+			return nil, err
+		}
+		if out, err = codemod.AddImport(out, getModuleName()+"/app/queue"); err != nil {
+			return nil, err
+		}
+		return codemod.AddImport(out, fmt.Sprintf("%s/app/%s/job", getModuleName(), module))
+	})
+}
+
+var jobCmd = &cobra.Command{
+	Use:   "job [name] [in_module]",
+	Short: "Generate a background job with its handler, enqueue helper and worker registration",
+	Long: `Generates app/<module>/job/<name>Job.go: the job payload, its Handle method
+and an Enqueue<Name> helper, and registers the job in the module's Register method.
+The queue type is <module>:<name in snake case>, e.g. users:send_welcome_email.
+
+The job reads the time from its Clock field (app/clock, created if missing),
+so tests can run it with a clock.Fake.
+
+The jobs work with any driver implementing queue.TaskQueue in app/queue, which is
+created if missing along with the job registry. Attach the registered jobs to the
+driver in the worker before starting it:
+
+  queue.AttachJobs(taskQueue)`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		titleName := proto.GoName(name)
+		jobType := module + ":" + proto.SnakeCase(titleName)
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+		jobFile := filepath.Join("app", module, "job", fmt.Sprintf("%sJob.go", name))
+		files := []codegen.File{{Path: jobFile, Content: jobContent(titleName, jobType)}}
+		files = append(files, queueFiles()...)
+		files = append(files, clockFiles()...)
+		if !writeGenerated(files...) {
+			return
+		}
+		if err := registerJob(module, titleName); err != nil {
+			fmt.Printf("Error registering the job in app/%s/module.go: %v\n", module, err)
+			return
+		}
+		fmt.Printf("Job '%s' (type %s) created in app/%s/job. Enqueue it with:\n  job.Enqueue%s(s.Queue, job.%sJob{ID: id})\n", titleName, jobType, module, titleName, titleName)
+		fmt.Println("Workers run it once the registered jobs are attached to the queue driver, before it starts:\n  queue.AttachJobs(taskQueue)")
+		openIfRequested(jobFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(jobCmd)
+	gCmd.AddCommand(jobCmd)
+}
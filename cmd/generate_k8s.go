@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+// k8sImage is set by `g k8s --image`
+var k8sImage string
+
+// k8sDir holds the generated Kubernetes manifests
+const k8sDir = "k8s"
+
+const k8sDeploymentTemplate = `# Generated by 'gonext g k8s'. Mesh settings come from the kubernetes section of
+# gonext.yaml; change them there and run the generator again.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %[1]s
+  labels:
+    app: %[1]s
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: %[1]s
+  template:
+    metadata:
+      labels:
+        app: %[1]s
+%[3]s    spec:
+      terminationGracePeriodSeconds: 30
+      containers:
+        - name: %[1]s
+          image: %[2]s
+          ports:
+            - name: http
+              containerPort: 3000
+          env:
+            - name: SERVER_HOST
+              value: 0.0.0.0
+          envFrom:
+            # Variables from .env, e.g. kubectl create secret generic %[1]s-env --from-env-file=.env
+            - secretRef:
+                name: %[1]s-env
+                optional: true
+          readinessProbe:
+            httpGet:
+              path: /readyz
+              port: http
+          livenessProbe:
+            httpGet:
+              path: /healthz
+              port: http
+`
+
+const k8sServiceTemplate = `apiVersion: v1
+kind: Service
+metadata:
+  name: %[1]s
+  labels:
+    app: %[1]s
+%[2]sspec:
+  selector:
+    app: %[1]s
+  ports:
+    - name: http
+      port: 80
+      targetPort: http
+`
+
+const k8sIngressTemplate = `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: %[1]s
+%[3]sspec:
+%[4]s  rules:
+    - host: %[2]s
+      http:
+        paths:
+          - path: /
+            pathType: Prefix
+            backend:
+              service:
+                name: %[1]s
+                port:
+                  name: http
+`
+
+const k8sVirtualServiceTemplate = `# Timeout and retries for traffic to the service inside the mesh
+apiVersion: networking.istio.io/v1
+kind: VirtualService
+metadata:
+  name: %[1]s
+spec:
+  hosts:
+    - %[1]s
+  http:
+    - route:
+        - destination:
+            host: %[1]s
+            port:
+              number: 80
+%[2]s`
+
+const k8sPeerAuthenticationTemplate = `# Mutual TLS for traffic to the service's pods
+apiVersion: security.istio.io/v1
+kind: PeerAuthentication
+metadata:
+  name: %[1]s
+spec:
+  selector:
+    matchLabels:
+      app: %[1]s
+  mtls:
+    mode: %[2]s
+`
+
+// k8sAnnotations renders a metadata annotations block indented for its object, empty without annotations
+func k8sAnnotations(indent string, pairs ...string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(indent + "annotations:\n")
+	for i := 0; i < len(pairs); i += 2 {
+		b.WriteString(fmt.Sprintf("%s  %s: %q\n", indent, pairs[i], pairs[i+1]))
+	}
+	return b.String()
+}
+
+// validateKubernetes checks the kubernetes section of gonext.yaml
+func validateKubernetes(k manifest.Kubernetes) error {
+	if k.Mesh != "" && k.Mesh != "istio" && k.Mesh != "linkerd" {
+		return fmt.Errorf("Unknown mesh '%s' in %s (expected istio or linkerd)", k.Mesh, manifest.FileName)
+	}
+	if k.MTLS != "" && k.MTLS != "strict" && k.MTLS != "permissive" {
+		return fmt.Errorf("Unknown mtls '%s' in %s (expected strict or permissive)", k.MTLS, manifest.FileName)
+	}
+	if k.Timeout != "" {
+		if _, err := time.ParseDuration(k.Timeout); err != nil {
+			return fmt.Errorf("Invalid timeout '%s' in %s (e.g. 15s)", k.Timeout, manifest.FileName)
+		}
+	}
+	if k.Mesh == "" && (k.Timeout != "" || k.Retries > 0 || k.MTLS != "") {
+		return fmt.Errorf("timeout, retries and mtls in %s are enforced by a mesh: set mesh to istio or linkerd", manifest.FileName)
+	}
+	return nil
+}
+
+// k8sFiles renders the manifests for the app, with the mesh settings of gonext.yaml
+func k8sFiles(app, image string, k manifest.Kubernetes) []codegen.File {
+	var podAnnotations, serviceAnnotations, ingressAnnotations []string
+	ingressClass := ""
+	var files []codegen.File
+
+	switch k.Mesh {
+	case "istio":
+		podAnnotations = append(podAnnotations, "sidecar.istio.io/inject", "true")
+		ingressClass = "  ingressClassName: istio\n"
+		if k.Timeout != "" || k.Retries > 0 {
+			var policy strings.Builder
+			if k.Timeout != "" {
+				policy.WriteString(fmt.Sprintf("      timeout: %s\n", k.Timeout))
+			}
+			if k.Retries > 0 {
+				policy.WriteString(fmt.Sprintf("      retries:\n        attempts: %d\n        retryOn: 5xx,gateway-error,connect-failure,reset\n", k.Retries))
+			}
+			files = append(files, codegen.File{Path: filepath.Join(k8sDir, "virtualservice.yaml"), Content: fmt.Sprintf(k8sVirtualServiceTemplate, app, policy.String())})
+		}
+		if k.MTLS != "" {
+			files = append(files, codegen.File{Path: filepath.Join(k8sDir, "peerauthentication.yaml"), Content: fmt.Sprintf(k8sPeerAuthenticationTemplate, app, strings.ToUpper(k.MTLS))})
+		}
+	case "linkerd":
+		// Linkerd always encrypts meshed traffic; strict also rejects unmeshed clients
+		podAnnotations = append(podAnnotations, "linkerd.io/inject", "enabled")
+		if k.MTLS == "strict" {
+			podAnnotations = append(podAnnotations, "config.linkerd.io/default-inbound-policy", "all-authenticated")
+		}
+		if k.Timeout != "" {
+			serviceAnnotations = append(serviceAnnotations, "timeout.linkerd.io/request", k.Timeout)
+		}
+		if k.Retries > 0 {
+			serviceAnnotations = append(serviceAnnotations, "retry.linkerd.io/http", "5xx", "retry.linkerd.io/limit", fmt.Sprint(k.Retries))
+		}
+		// Sends ingress traffic to the Service so the mesh balances and retries it
+		ingressAnnotations = append(ingressAnnotations, "nginx.ingress.kubernetes.io/service-upstream", "true")
+	}
+
+	files = append([]codegen.File{
+		{Path: filepath.Join(k8sDir, "deployment.yaml"), Content: fmt.Sprintf(k8sDeploymentTemplate, app, image, k8sAnnotations("      ", podAnnotations...))},
+		{Path: filepath.Join(k8sDir, "service.yaml"), Content: fmt.Sprintf(k8sServiceTemplate, app, k8sAnnotations("  ", serviceAnnotations...))},
+	}, files...)
+	if k.Host != "" {
+		files = append(files, codegen.File{Path: filepath.Join(k8sDir, "ingress.yaml"), Content: fmt.Sprintf(k8sIngressTemplate, app, k.Host, k8sAnnotations("  ", ingressAnnotations...), ingressClass)})
+	}
+	return files
+}
+
+var k8sCmd = &cobra.Command{
+	Use:   "k8s",
+	Short: "Generate Kubernetes manifests, with service mesh annotations, timeouts, retries and mTLS from gonext.yaml",
+	Long: `Generates a Deployment, a Service and, when a host is set, an Ingress in k8s/.
+The kubernetes section of gonext.yaml adds the mesh settings:
+
+  kubernetes:
+    host: api.example.com
+    mesh: istio        # or linkerd
+    timeout: 15s
+    retries: 3
+    mtls: strict       # or permissive
+
+With Istio, timeouts and retries go into a VirtualService and mTLS into a
+PeerAuthentication. With Linkerd, they are Service and pod annotations.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		m, err := manifest.Load()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := validateKubernetes(m.Kubernetes); err != nil {
+			fmt.Println(err)
+			return
+		}
+		app := defaultImage()
+		image := k8sImage
+		if image == "" {
+			version, _ := buildVersion()
+			image = app + ":" + imageTag(version)
+		}
+		if !writeGenerated(k8sFiles(app, image, m.Kubernetes)...) {
+			return
+		}
+		fmt.Printf("Kubernetes manifests created in %s. Apply them with:\n\n  kubectl apply -f %s\n", k8sDir, k8sDir)
+		openIfRequested(k8sDir)
+	},
+}
+
+func init() {
+	k8sCmd.Flags().StringVar(&k8sImage, "image", "", "Image to deploy (default: <app>:<git describe>)")
+	generateCmd.AddCommand(k8sCmd)
+	gCmd.AddCommand(k8sCmd)
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+// loggerFile holds the slog logger correlated with OpenTelemetry traces
+var loggerFile = filepath.Join("app", "logger", "logger.go")
+
+const loggerTemplate = `package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/contrib/bridges/otelslog"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Setup installs the default slog logger and returns a func flushing it on shutdown.
+//
+// Entries are written to stdout, as JSON unless LOG_FORMAT=text, from LOG_LEVEL
+// (debug, info, warn or error; default info). Entries logged with a context
+// carrying a span get its trace_id and span_id, so they can be joined with
+// traces. With OTEL_LOGS_EXPORTER=otlp they are also exported over OTLP/HTTP to
+// OTEL_EXPORTER_OTLP_ENDPOINT, next to the traces and metrics.
+func Setup(ctx context.Context, service string) (shutdown func(context.Context) error, err error) {
+	opts := &slog.HandlerOptions{Level: level()}
+	var stdout slog.Handler = slog.NewJSONHandler(os.Stdout, opts)
+	if strings.EqualFold(os.Getenv("LOG_FORMAT"), "text") {
+		stdout = slog.NewTextHandler(os.Stdout, opts)
+	}
+	handler := slog.Handler(traceHandler{stdout})
+	shutdown = func(context.Context) error { return nil }
+
+	if os.Getenv("OTEL_LOGS_EXPORTER") == "otlp" {
+		exporter, err := otlploghttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)))
+		handler = fanout{handler, otelslog.NewHandler(service, otelslog.WithLoggerProvider(provider))}
+		shutdown = provider.Shutdown
+	}
+	slog.SetDefault(slog.New(handler))
+	return shutdown, nil
+}
+
+// Middleware logs every request with the request context, so the entry carries
+// the trace of the request when tracing middleware runs before it
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+		status := c.Response().StatusCode()
+		var fe *fiber.Error
+		if errors.As(err, &fe) {
+			status = fe.Code
+		} else if err != nil {
+			status = fiber.StatusInternalServerError
+		}
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		}
+		slog.Log(c.UserContext(), level, "request",
+			slog.String("method", c.Method()),
+			slog.String("path", c.OriginalURL()),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+		)
+		return err
+	}
+}
+
+func level() slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(os.Getenv("LOG_LEVEL"))); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+// traceHandler adds the trace_id and span_id of the context's span to every entry
+type traceHandler struct{ slog.Handler }
+
+func (h traceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return traceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h traceHandler) WithGroup(name string) slog.Handler {
+	return traceHandler{h.Handler.WithGroup(name)}
+}
+
+// fanout sends every entry to each handler
+type fanout []slog.Handler
+
+func (f fanout) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanout) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range f {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(fanout, len(f))
+	for i, h := range f {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	out := make(fanout, len(f))
+	for i, h := range f {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
+`
+
+var loggerCmd = &cobra.Command{
+	Use:   "logger",
+	Short: "Generate an slog logger whose entries carry trace and span IDs and can be exported over OTLP",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !writeGenerated(codegen.File{Path: loggerFile, Content: loggerTemplate}) {
+			return
+		}
+		fmt.Printf("Logger created in %s. Install its dependencies with:\n", filepath.Dir(loggerFile))
+		fmt.Println("  go get go.opentelemetry.io/otel go.opentelemetry.io/contrib/bridges/otelslog go.opentelemetry.io/otel/sdk/log go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp")
+		if _, err := os.Stat(middlewareChainFile); err == nil {
+			if err := addGlobalMiddleware(getModuleName()+"/app/logger", "logger.Middleware()"); err != nil {
+				fmt.Printf("Error wiring middleware into %s: %v\n", middlewareChainFile, err)
+				return
+			}
+			fmt.Printf("logger.Middleware() added to %s\n", middlewareChainFile)
+		}
+		fmt.Println("Then set it up first thing in main.go:")
+		fmt.Print(`
+	shutdownLogs, err := logger.Setup(ctx, "my-app")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer shutdownLogs(context.Background())
+`)
+		fmt.Println("\nLog with slog.InfoContext(c.UserContext(), ...) so entries carry the request's trace. Set OTEL_LOGS_EXPORTER=otlp to export them.")
+		openIfRequested(loggerFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(loggerCmd)
+	gCmd.AddCommand(loggerCmd)
+}
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/spf13/cobra"
+)
+
+// mailerFile holds the mailer shared by the mailables of every module
+var mailerFile = filepath.Join("app", "mailer", "mailer.go")
+
+// mailTemplatesDir holds the email templates, embedded in the binary
+var mailTemplatesDir = filepath.Join("resources", "mail")
+
+const mailerTemplate = `// Package mailer sends the app's emails. Mailables render a template of
+// resources/mail and send it with the Mailer bound at startup.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"%[1]s/resources/mail"
+)
+
+// Message is an email ready to send. Text, if set, is sent as the plain text
+// alternative of HTML.
+type Message struct {
+	To      []string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Mailer sends messages. Bind one at startup with container.Bind("mailer", m)
+// and inject it with inject:"name=mailer".
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Render executes the template resources/mail/<name>.html with data
+func Render(name string, data any) (string, error) {
+	tmpl, err := template.ParseFS(mail.Templates, name+".html")
+	if err != nil {
+		return "", fmt.Errorf("mailer: parsing template %%s: %%w", name, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("mailer: rendering template %%s: %%w", name, err)
+	}
+	return out.String(), nil
+}
+
+// SMTP sends messages through an SMTP server, upgrading the connection with
+// STARTTLS when the server offers it. Username is optional.
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Send delivers msg, giving up when ctx is done
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("mailer: %%q has no recipients", msg.Subject)
+	}
+	body, err := msg.encode(s.From)
+	if err != nil {
+		return err
+	}
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("mailer: dialing %%s: %%w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("mailer: %%w", err)
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+			return fmt.Errorf("mailer: starting TLS: %%w", err)
+		}
+	}
+	if s.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return fmt.Errorf("mailer: authenticating: %%w", err)
+		}
+	}
+	if err := client.Mail(s.From); err != nil {
+		return fmt.Errorf("mailer: %%w", err)
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("mailer: recipient %%s: %%w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("mailer: %%w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("mailer: %%w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mailer: %%w", err)
+	}
+	return client.Quit()
+}
+
+// encode renders msg as a MIME message from from
+func (msg Message) encode(from string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %%s\r\nTo: %%s\r\nSubject: %%s\r\nDate: %%s\r\nMIME-Version: 1.0\r\n",
+		from, strings.Join(msg.To, ", "), mime.QEncoding.Encode("utf-8", msg.Subject), time.Now().Format(time.RFC1123Z))
+	if msg.Text == "" {
+		buf.WriteString("Content-Type: text/html; charset=UTF-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, msg.HTML); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	parts := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%%s\r\n\r\n", parts.Boundary())
+	for _, part := range []struct{ contentType, content string }{{"text/plain", msg.Text}, {"text/html", msg.HTML}} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// Recorder is a Mailer for tests: it keeps the messages instead of sending them
+type Recorder struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+// Send records msg
+func (r *Recorder) Send(ctx context.Context, msg Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, msg)
+	return nil
+}
+
+// Sent returns the messages recorded so far
+func (r *Recorder) Sent() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Message(nil), r.sent...)
+}
+`
+
+const mailTemplatesTemplate = `// Package mail holds the email templates rendered by mailer.Render. They are
+// embedded in the binary, so the app and its tests find them from any directory.
+package mail
+
+import "embed"
+
+// Templates are the *.html files of resources/mail
+//
+//go:embed *.html
+var Templates embed.FS
+`
+
+// mailableContent renders a mailable sending the template named templateName
+func mailableContent(titleName, templateName, subject string) string {
+	return fmt.Sprintf(`package mail
+
+import (
+	"context"
+
+	"%[1]s/app/mailer"
+)
+
+// %[2]sTemplate is the template %[2]s renders, resources/mail/%[3]s.html
+const %[2]sTemplate = %[3]q
+
+// %[2]s is an email. Its fields are the recipient and the template data.
+type %[2]s struct {
+	To string
+	// TODO: Add the fields the template shows
+	Name string
+}
+
+// Subject is the subject line of the email
+func (m %[2]s) Subject() string {
+	return %[4]q
+}
+
+// Send renders the email and sends it with the Mailer injected with
+// inject:"name=mailer". Tests pass a *mailer.Recorder.
+func (m %[2]s) Send(ctx context.Context, mlr mailer.Mailer) error {
+	html, err := mailer.Render(%[2]sTemplate, m)
+	if err != nil {
+		return err
+	}
+	return mlr.Send(ctx, mailer.Message{To: []string{m.To}, Subject: m.Subject(), HTML: html})
+}
+`, getModuleName(), titleName, templateName, subject)
+}
+
+// mailHTMLContent renders the starting HTML of an email template
+func mailHTMLContent(subject string) string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>%s</title>
+</head>
+<body style="font-family: sans-serif; line-height: 1.5;">
+  <p>Hello {{.Name}},</p>
+  <!-- TODO: Write the email. The fields of the mailable are available, e.g. {{.To}}. -->
+</body>
+</html>
+`, subject)
+}
+
+var mailCmd = &cobra.Command{
+	Use:   "mail [name] [in_module]",
+	Short: "Generate a mailable with its HTML template and a Send method",
+	Long: `Generates app/<module>/mail/<name>.go, a mailable whose fields are the
+recipient and the template data, and its template, resources/mail/<name>.html,
+e.g. for 'gonext g mail WelcomeEmail users':
+
+  mail.WelcomeEmail{To: user.Email, Name: user.Name}.Send(ctx, s.Mailer)
+
+The shared app/mailer is created if missing. It renders the templates, which are
+embedded in the binary, and sends them through SMTP, configured from the MAIL_*
+settings of 'gonext g config mail'. Its Recorder stands in for it in tests.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		titleName := proto.GoName(name)
+		if titleName == "" {
+			fmt.Printf("Invalid mail name %q\n", name)
+			return
+		}
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+		templateName := proto.SnakeCase(titleName)
+		subject := strings.ReplaceAll(templateName, "_", " ")
+		subject = strings.ToUpper(subject[:1]) + subject[1:]
+		mailFile := filepath.Join("app", module, "mail", strings.ToLower(titleName[:1])+titleName[1:]+".go")
+		htmlFile := filepath.Join(mailTemplatesDir, templateName+".html")
+		files := []codegen.File{
+			{Path: mailFile, Content: mailableContent(titleName, templateName, subject)},
+			{Path: htmlFile, Content: mailHTMLContent(subject)},
+		}
+		newMailer := missingFile(codegen.File{Path: mailerFile, Content: fmt.Sprintf(mailerTemplate, getModuleName())})
+		files = append(files, newMailer...)
+		files = append(files, missingFile(codegen.File{Path: filepath.Join(mailTemplatesDir, "templates.go"), Content: mailTemplatesTemplate})...)
+		if !writeGenerated(files...) {
+			return
+		}
+		fmt.Printf("Mail '%s' created in app/%s/mail with its template %s. Send it with:\n  mail.%s{To: to, Name: name}.Send(ctx, s.Mailer)\n", titleName, module, htmlFile, titleName)
+		if len(newMailer) > 0 {
+			bind := "container.Bind(\"mailer\", &mailer.SMTP{Host: config.Mail.Host, Port: config.Mail.Port, Username: config.Mail.Username, Password: config.Mail.Password, From: config.Mail.From})"
+			if _, err := os.Stat(configStructFile("mail")); os.IsNotExist(err) {
+				fmt.Println("Create the MAIL_* settings with 'gonext g config mail', then bind the mailer in main.go:\n  " + bind)
+			} else {
+				fmt.Println("Bind the mailer in main.go, after config.Load():\n  " + bind)
+			}
+			fmt.Println("Inject it into services with:\n  Mailer mailer.Mailer `inject:\"name=mailer\"`")
+		}
+		openIfRequested(mailFile, htmlFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(mailCmd)
+	gCmd.AddCommand(mailCmd)
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/spf13/cobra"
+)
+
+// migrationTool, migrationGo and migrationDir are set by `g migration --tool/--go/--dir`
+var migrationTool string
+var migrationGo bool
+var migrationDir string
+
+// createTableMigration matches migration names that create a table, e.g. create_users_table
+var createTableMigration = regexp.MustCompile(`^create_([a-z0-9_]+?)(_table)?$`)
+
+// migrationSQL returns the up and down statements of a migration, creating and
+// dropping the table for names such as create_users_table
+func migrationSQL(name string) (up, down string) {
+	if m := createTableMigration.FindStringSubmatch(name); m != nil {
+		return fmt.Sprintf(`CREATE TABLE %s (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);`, m[1]), fmt.Sprintf("DROP TABLE IF EXISTS %s;", m[1])
+	}
+	return "-- TODO: Write the migration", "-- TODO: Undo the migration"
+}
+
+// migrationFilesFor renders a migration for golang-migrate (an .up.sql and a
+// .down.sql file) or goose (one .sql file with both directions, or a .go file)
+func migrationFilesFor(tool string, goFile bool, dir, version, name, title string) ([]codegen.File, error) {
+	up, down := migrationSQL(name)
+	base := filepath.Join(dir, version+"_"+name)
+	switch {
+	case tool == "golang-migrate" && goFile:
+		return nil, fmt.Errorf("golang-migrate runs SQL migrations only; use --tool goose for Go migrations")
+	case tool == "golang-migrate":
+		return []codegen.File{
+			{Path: base + ".up.sql", Content: fmt.Sprintf("-- %s\n%s\n", title, up)},
+			{Path: base + ".down.sql", Content: fmt.Sprintf("-- Reverts %s\n%s\n", title, down)},
+		}, nil
+	case tool == "goose" && goFile:
+		fn := proto.GoName(name)
+		return []codegen.File{{Path: base + ".go", Content: fmt.Sprintf(`package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(up%[1]s, down%[1]s)
+}
+
+// up%[1]s applies %[2]s
+func up%[1]s(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `+"`"+`%[3]s`+"`"+`)
+	return err
+}
+
+// down%[1]s reverts %[2]s
+func down%[1]s(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `+"`"+`%[4]s`+"`"+`)
+	return err
+}
+`, fn, title, up, down)}}, nil
+	case tool == "goose":
+		return []codegen.File{{Path: base + ".sql", Content: fmt.Sprintf(`-- %s
+-- +goose Up
+-- +goose StatementBegin
+%s
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+%s
+-- +goose StatementEnd
+`, title, up, down)}}, nil
+	}
+	return nil, fmt.Errorf("invalid --tool %q: expected golang-migrate or goose", tool)
+}
+
+var migrationCmd = &cobra.Command{
+	Use:   "migration [name]",
+	Short: "Generate timestamped up/down migration files for golang-migrate or goose",
+	Long: `Generates a migration named <timestamp>_<name> in database/migrations (--dir):
+
+  --tool golang-migrate   <version>_<name>.up.sql and <version>_<name>.down.sql
+  --tool goose            <version>_<name>.sql with -- +goose Up/Down sections
+  --tool goose --go       <version>_<name>.go registering Go up/down functions
+
+A name such as create_users_table gets CREATE TABLE and DROP TABLE statements;
+other migrations start with TODOs. golang-migrate files also apply with the
+built-in runner ('gonext db migrate'), which reads migrations/*.up.sql: pass
+--dir migrations to use it.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := strings.Trim(migrationName.ReplaceAllString(strings.ToLower(proto.SnakeCase(args[0])), "_"), "_")
+		if name == "" {
+			fmt.Printf("Invalid migration name %q\n", args[0])
+			return
+		}
+		version := time.Now().UTC().Format("20060102150405")
+		files, err := migrationFilesFor(migrationTool, migrationGo, migrationDir, version, name, args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if !writeGenerated(files...) {
+			return
+		}
+		fmt.Printf("Migration %s_%s created in %s for %s\n", version, name, migrationDir, migrationTool)
+		if migrationTool == "goose" && migrationGo {
+			fmt.Println("Go migrations are compiled into a goose binary importing the migrations package. Install its dependency with:\n  go get github.com/pressly/goose/v3")
+		}
+		openIfRequested(files[0].Path)
+	},
+}
+
+func init() {
+	migrationCmd.Flags().StringVar(&migrationTool, "tool", "golang-migrate", "Migration tool the files are written for: golang-migrate or goose")
+	migrationCmd.Flags().BoolVar(&migrationGo, "go", false, "Write a Go migration instead of SQL (goose only)")
+	migrationCmd.Flags().StringVar(&migrationDir, "dir", filepath.Join("database", "migrations"), "Directory holding the migrations")
+	generateCmd.AddCommand(migrationCmd)
+	gCmd.AddCommand(migrationCmd)
+}
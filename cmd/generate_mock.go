@@ -0,0 +1,425 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+// mockStyle is set by `g mock --style`
+var mockStyle string
+
+// mocksDir holds the generated mocks, one package per module
+const mocksDir = "mocks"
+
+// mockInterface is an interface found in a module
+type mockInterface struct {
+	Name    string
+	Package string // package name
+	Path    string // import path
+	Methods []testMethod
+	Imports map[string]string // name -> import path, used by the method signatures
+}
+
+// qualifyType renders a type of the interface's package as seen from another
+// package: identifiers declared in it are prefixed with its name
+func qualifyType(expr ast.Expr, pkg string) string {
+	var qualify func(n ast.Node) bool
+	qualify = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			return false
+		case *ast.Field:
+			ast.Inspect(n.Type, qualify)
+			return false
+		case *ast.Ident:
+			if types.Universe.Lookup(n.Name) == nil {
+				n.Name = pkg + "." + n.Name
+			}
+		}
+		return true
+	}
+	ast.Inspect(expr, qualify)
+	return types.ExprString(expr)
+}
+
+// findInterface looks for an interface named name in the packages of a module
+func findInterface(module, name string) (*mockInterface, []string, error) {
+	root := filepath.Join("app", module)
+	var found *mockInterface
+	var names []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") || found != nil {
+			return nil
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		interfaces := map[string]*ast.InterfaceType{}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if it, ok := ts.Type.(*ast.InterfaceType); ok && ts.Name.IsExported() {
+					interfaces[ts.Name.Name] = it
+					names = append(names, ts.Name.Name)
+					if ts.Name.Name == name && ts.TypeParams != nil {
+						return fmt.Errorf("%s is generic; mocks of generic interfaces are not supported", name)
+					}
+				}
+			}
+		}
+		it, ok := interfaces[name]
+		if !ok {
+			return nil
+		}
+		dir := filepath.ToSlash(filepath.Dir(path))
+		found = &mockInterface{Name: name, Package: f.Name.Name, Path: getModuleName() + "/" + dir, Imports: map[string]string{}}
+		known := map[string]string{}
+		for _, spec := range f.Imports {
+			p := strings.Trim(spec.Path.Value, `"`)
+			if spec.Name != nil {
+				known[spec.Name.Name] = p
+			} else {
+				known[importName(p)] = p
+			}
+		}
+		render := func(expr ast.Expr) string {
+			ast.Inspect(expr, func(n ast.Node) bool {
+				if sel, ok := n.(*ast.SelectorExpr); ok {
+					if id, ok := sel.X.(*ast.Ident); ok && known[id.Name] != "" {
+						found.Imports[id.Name] = known[id.Name]
+					}
+				}
+				return true
+			})
+			typ := qualifyType(expr, f.Name.Name)
+			if strings.Contains(typ, f.Name.Name+".") {
+				found.Imports[f.Name.Name] = found.Path
+			}
+			return typ
+		}
+		var collect func(it *ast.InterfaceType) error
+		collect = func(it *ast.InterfaceType) error {
+			for _, field := range it.Methods.List {
+				fn, ok := field.Type.(*ast.FuncType)
+				if !ok {
+					embedded, ok := field.Type.(*ast.Ident)
+					if !ok || interfaces[embedded.Name] == nil {
+						return fmt.Errorf("%s embeds %s; only interfaces declared in the same file can be embedded", name, types.ExprString(field.Type))
+					}
+					if err := collect(interfaces[embedded.Name]); err != nil {
+						return err
+					}
+					continue
+				}
+				m := testMethod{Name: field.Names[0].Name}
+				for _, p := range fn.Params.List {
+					typ := p.Type
+					variadic := false
+					if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+						typ, variadic = ellipsis.Elt, true
+					}
+					for range max(1, len(p.Names)) {
+						rendered := render(typ)
+						if variadic {
+							rendered = "..." + rendered
+						}
+						m.Params = append(m.Params, testParam{Name: fmt.Sprintf("a%d", len(m.Params)), Type: rendered, Variadic: variadic})
+					}
+				}
+				if fn.Results != nil {
+					for _, r := range fn.Results.List {
+						for range max(1, len(r.Names)) {
+							m.Results = append(m.Results, render(r.Type))
+						}
+					}
+				}
+				found.Methods = append(found.Methods, m)
+			}
+			return nil
+		}
+		return collect(it)
+	})
+	sort.Strings(names)
+	return found, names, err
+}
+
+// mockName is the type name of the mock of an interface, e.g. MockUsersRepository
+// for UsersRepositoryInterface
+func mockName(iface string) string {
+	return "Mock" + strings.TrimSuffix(iface, "Interface")
+}
+
+// mockSignature renders the parameters and results of a mock method, with the
+// results named r0, r1... so they start at their zero value
+func mockSignature(m testMethod) (params, results string, args []string) {
+	var ps, rs []string
+	for _, p := range m.Params {
+		ps = append(ps, p.Name+" "+p.Type)
+		arg := p.Name
+		if p.Variadic {
+			arg += "..."
+		}
+		args = append(args, arg)
+	}
+	for i, r := range m.Results {
+		rs = append(rs, fmt.Sprintf("r%d %s", i, r))
+	}
+	results = strings.Join(rs, ", ")
+	if len(rs) > 0 {
+		results = " (" + results + ")"
+	}
+	return strings.Join(ps, ", "), results, args
+}
+
+// funcMockContent renders a hand-rolled mock: a func field per method stubs it,
+// calls are recorded, and unstubbed methods return zero values
+func funcMockContent(iface *mockInterface) string {
+	name := mockName(iface.Name)
+	var fields, methods strings.Builder
+	for _, m := range iface.Methods {
+		params, results, args := mockSignature(m)
+		var recorded []string
+		for _, p := range m.Params {
+			recorded = append(recorded, p.Name)
+		}
+		resultTypes := strings.Join(m.Results, ", ")
+		if len(m.Results) > 1 {
+			resultTypes = "(" + resultTypes + ")"
+		}
+		fmt.Fprintf(&fields, "\t%sFunc func(%s) %s\n", m.Name, params, resultTypes)
+		call := fmt.Sprintf("m.%sFunc(%s)", m.Name, strings.Join(args, ", "))
+		body := "\t\t" + call + "\n\t}\n"
+		if len(m.Results) > 0 {
+			body = "\t\treturn " + call + "\n\t}\n\treturn\n"
+		}
+		doc := "runs %sFunc, or returns zero values if it is nil"
+		if len(m.Results) == 0 {
+			doc = "runs %sFunc if it is set"
+		}
+		fmt.Fprintf(&methods, `
+// %[2]s records the call and %[7]s
+func (m *%[1]s) %[2]s(%[3]s)%[4]s {
+	m.record(%[2]q%[5]s)
+	if m.%[2]sFunc != nil {
+%[6]s}
+`, name, m.Name, params, results, prefixEach(", ", recorded), body, fmt.Sprintf(doc, m.Name))
+	}
+	return fmt.Sprintf(`package mocks
+
+import (
+%[6]s)
+
+// %[1]s is a mock of %[2]s.%[3]s. Set a method's func field to stub it:
+//
+//	m := &mocks.%[1]s{}
+//	m.%[4]sFunc = func(...) ... { ... }
+//
+// Unstubbed methods return zero values. Calls returns the arguments of each call.
+type %[1]s struct {
+%[5]s
+	mu    sync.Mutex
+	calls map[string][][]any
+}
+
+var _ %[2]s.%[3]s = (*%[1]s)(nil)
+
+func (m *%[1]s) record(method string, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.calls == nil {
+		m.calls = map[string][][]any{}
+	}
+	m.calls[method] = append(m.calls[method], args)
+}
+
+// Calls returns the arguments of each call of method, in order
+func (m *%[1]s) Calls(method string) [][]any {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+%[7]s`, name, iface.Package, iface.Name, firstMethod(iface), fields.String(), mockImports(iface, "sync"), methods.String())
+}
+
+// testifyMockContent renders a mockery-style mock built on testify's mock.Mock
+func testifyMockContent(iface *mockInterface) string {
+	name := mockName(iface.Name)
+	var methods strings.Builder
+	for _, m := range iface.Methods {
+		params, results, _ := mockSignature(m)
+		var called []string
+		for _, p := range m.Params {
+			called = append(called, p.Name)
+		}
+		var body strings.Builder
+		if len(m.Results) == 0 {
+			fmt.Fprintf(&body, "\tm.Called(%s)\n", strings.Join(called, ", "))
+		} else {
+			fmt.Fprintf(&body, "\tret := m.Called(%s)\n", strings.Join(called, ", "))
+			for i, r := range m.Results {
+				if r == "error" {
+					fmt.Fprintf(&body, "\tr%d = ret.Error(%d)\n", i, i)
+					continue
+				}
+				fmt.Fprintf(&body, "\tif v := ret.Get(%[1]d); v != nil {\n\t\tr%[1]d = v.(%[2]s)\n\t}\n", i, r)
+			}
+			body.WriteString("\treturn\n")
+		}
+		fmt.Fprintf(&methods, `
+// %[2]s returns what was set with m.On(%[2]q, ...).Return(...)
+func (m *%[1]s) %[2]s(%[3]s)%[4]s {
+%[5]s}
+`, name, m.Name, params, results, body.String())
+	}
+	return fmt.Sprintf(`package mocks
+
+import (
+%[5]s)
+
+// %[1]s is a mock of %[2]s.%[3]s built on testify:
+//
+//	m := &mocks.%[1]s{}
+//	m.On(%[4]q, mock.Anything).Return(...)
+//	defer m.AssertExpectations(t)
+type %[1]s struct {
+	mock.Mock
+}
+
+var _ %[2]s.%[3]s = (*%[1]s)(nil)
+%[6]s`, name, iface.Package, iface.Name, firstMethod(iface), mockImports(iface, "github.com/stretchr/testify/mock"), methods.String())
+}
+
+// prefixEach joins items, each preceded by sep
+func prefixEach(sep string, items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return sep + strings.Join(items, sep)
+}
+
+// firstMethod names a method of the interface for the usage example
+func firstMethod(iface *mockInterface) string {
+	if len(iface.Methods) == 0 {
+		return "Method"
+	}
+	return iface.Methods[0].Name
+}
+
+// mockImports renders the import block of a mock: extra, the interface's
+// package and the packages its method signatures use
+func mockImports(iface *mockInterface, extra ...string) string {
+	imports := map[string]string{iface.Package: iface.Path}
+	for name, p := range iface.Imports {
+		imports[name] = p
+	}
+	for _, p := range extra {
+		imports[importName(p)] = p
+	}
+	var std, other []string
+	for name, p := range imports {
+		spec := fmt.Sprintf("\t%q", p)
+		if importName(p) != name {
+			spec = fmt.Sprintf("\t%s %q", name, p)
+		}
+		if strings.Contains(strings.Split(p, "/")[0], ".") {
+			other = append(other, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	block := strings.Join(std, "\n")
+	if len(std) > 0 && len(other) > 0 {
+		block += "\n\n"
+	}
+	return block + strings.Join(other, "\n") + "\n"
+}
+
+var mockCmd = &cobra.Command{
+	Use:   "mock [interface] [in_module]",
+	Short: "Generate a mock of an interface of a module in mocks/<module>",
+	Long: `Finds the interface in the packages of app/<module> and generates a mock of it in
+mocks/<module>, so services can be unit-tested without real repositories:
+
+  gonext g mock UsersRepositoryInterface users   # mocks.MockUsersRepository
+
+--style func (the default) writes a hand-rolled mock: each method has a func
+field that stubs it, unstubbed methods return zero values, and Calls returns the
+arguments each method was called with. --style testify writes a mockery-style
+mock built on github.com/stretchr/testify/mock.
+
+Interfaces such as UsersRepositoryInterface are generated with
+--inject-interfaces. Run the command again after changing the interface.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, module := args[0], args[1]
+		if mockStyle != "func" && mockStyle != "testify" {
+			fmt.Printf("Invalid --style %q: expected func or testify\n", mockStyle)
+			return
+		}
+		if _, err := os.Stat(filepath.Join("app", module)); os.IsNotExist(err) {
+			fmt.Printf("Module '%s' does not exist\n", module)
+			return
+		}
+		iface, names, err := findInterface(module, name)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if iface == nil {
+			fmt.Printf("No interface %s found in app/%s.", name, module)
+			if len(names) > 0 {
+				fmt.Printf(" Interfaces in the module: %s", strings.Join(names, ", "))
+			} else {
+				fmt.Print(" Generate the module's interfaces with --inject-interfaces.")
+			}
+			fmt.Println()
+			return
+		}
+		content := funcMockContent(iface)
+		if mockStyle == "testify" {
+			content = testifyMockContent(iface)
+		}
+		src, err := format.Source([]byte(content))
+		if err != nil {
+			fmt.Printf("Error rendering the mock: %v\n", err)
+			return
+		}
+		file := filepath.Join(mocksDir, module, "mock"+strings.TrimPrefix(mockName(iface.Name), "Mock")+".go")
+		if !writeGenerated(codegen.File{Path: file, Content: string(src)}) {
+			return
+		}
+		fmt.Printf("Mock '%s' created at %s. Import it with:\n  %smocks %q\n", mockName(iface.Name), file, module, getModuleName()+"/"+filepath.ToSlash(filepath.Join(mocksDir, module)))
+		if mockStyle == "testify" {
+			fmt.Println("Install testify with:\n  go get github.com/stretchr/testify")
+		}
+		openIfRequested(file)
+	},
+}
+
+func init() {
+	mockCmd.Flags().StringVar(&mockStyle, "style", "func", "Mock style: func (hand-rolled, with a func field per method) or testify (mockery-style)")
+	generateCmd.AddCommand(mockCmd)
+	gCmd.AddCommand(mockCmd)
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/spf13/cobra"
+)
+
+// modelModule is set by `g model --module`
+var modelModule string
+
+// generateModel is set by `--model <Name>` to type controllers and services with a generated model
+var generateModel string
+
+// modelType is a field type of the model, entity and DTO field specs: its Go
+// type, the GORM type if it needs one, and its Postgres column
+type modelType struct {
+	Go       string
+	GORM     string
+	Column   string // Postgres column type
+	Import   string // Package of the Go type; app/... is relative to the project
+	Validate string // validate tag of DTO fields
+	Example  string // example tag of DTO fields
+}
+
+// modelTypes are the types accepted in `name:type` field specs. Money is integer
+// cents and decimal is shopspring/decimal, so neither rounds like float64.
+var modelTypes = map[string]modelType{
+	"string":  {Go: "string", GORM: "size:255", Column: "varchar(255)", Validate: "required"},
+	"text":    {Go: "string", GORM: "type:text", Column: "text", Validate: "required"},
+	"int":     {Go: "int", Column: "integer"},
+	"int64":   {Go: "int64", Column: "bigint"},
+	"uint":    {Go: "uint", Column: "bigint"},
+	"float":   {Go: "float64", Column: "double precision"},
+	"decimal": {Go: "decimal.Decimal", GORM: "type:numeric(20,4)", Column: "numeric(20,4)", Import: "github.com/shopspring/decimal", Example: "12.3456"},
+	"money":   {Go: "money.Amount", GORM: "type:bigint", Column: "bigint", Import: "app/money", Validate: "gte=0", Example: "12.34"},
+	"bool":    {Go: "bool", Column: "boolean"},
+	"time":    {Go: "time.Time", Column: "timestamptz", Import: "time", Validate: "required"},
+	"uuid":    {Go: "string", GORM: "type:uuid", Column: "uuid", Validate: "required,uuid"},
+}
+
+// modelField is one column of a field spec, e.g. email:string:unique
+type modelField struct {
+	Name     string // Go field name
+	Column   string // snake_case column and JSON name
+	Type     modelType
+	Unique   bool
+	Index    bool
+	Nullable bool
+	Default  string
+}
+
+// parseModelField parses name:type[:unique|index|nullable|default=value...]
+func parseModelField(spec string) (modelField, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || parts[0] == "" {
+		return modelField{}, fmt.Errorf("invalid field %q (expected name:type[:unique|index|nullable|default=value])", spec)
+	}
+	typ, ok := modelTypes[parts[1]]
+	if !ok {
+		return modelField{}, fmt.Errorf("unknown type %q in field %q (expected string, text, int, int64, uint, float, decimal, money, bool, time or uuid)", parts[1], spec)
+	}
+	column := proto.SnakeCase(parts[0])
+	f := modelField{Name: proto.GoName(column), Column: column, Type: typ}
+	for _, modifier := range parts[2:] {
+		switch {
+		case modifier == "unique":
+			f.Unique = true
+		case modifier == "index":
+			f.Index = true
+		case modifier == "nullable":
+			f.Nullable = true
+		case strings.HasPrefix(modifier, "default="):
+			f.Default = strings.TrimPrefix(modifier, "default=")
+		default:
+			return modelField{}, fmt.Errorf("unknown modifier %q in field %q (expected unique, index, nullable or default=value)", modifier, spec)
+		}
+	}
+	return f, nil
+}
+
+// parseModelFields parses the field specs of a command
+func parseModelFields(specs []string) ([]modelField, error) {
+	var fields []modelField
+	for _, spec := range specs {
+		f, err := parseModelField(spec)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// goType is the Go type of the field, a pointer when it is nullable
+func (f modelField) goType() string {
+	if f.Nullable {
+		return "*" + f.Type.Go
+	}
+	return f.Type.Go
+}
+
+// fieldImports returns the imports of fields, split into the standard library and the rest
+func fieldImports(fields []modelField) (std, other []string) {
+	for _, f := range fields {
+		switch {
+		case f.Type.Import == "":
+		case strings.HasPrefix(f.Type.Import, "app/"):
+			other = append(other, fmt.Sprintf("%q", getModuleName()+"/"+f.Type.Import))
+		case strings.Contains(f.Type.Import, "."):
+			other = append(other, fmt.Sprintf("%q", f.Type.Import))
+		default:
+			std = append(std, fmt.Sprintf("%q", f.Type.Import))
+		}
+	}
+	return std, other
+}
+
+// fieldFiles returns the files the field types need and the project does not have yet
+func fieldFiles(fields []modelField) []codegen.File {
+	for _, f := range fields {
+		if f.Type.Import == "app/money" {
+			return missingFile(codegen.File{Path: moneyFile, Content: moneyTemplate})
+		}
+	}
+	return nil
+}
+
+// printDecimalDependency shows how to install shopspring/decimal when a field uses it
+func printDecimalDependency(fields []modelField) {
+	for _, f := range fields {
+		if strings.HasPrefix(f.Type.Go, "decimal.") {
+			fmt.Println("Install shopspring/decimal with:\n  go get github.com/shopspring/decimal")
+			return
+		}
+	}
+}
+
+// tags renders the gorm and json struct tags of the field
+func (f modelField) tags() string {
+	var gorm []string
+	if f.Type.GORM != "" {
+		gorm = append(gorm, f.Type.GORM)
+	}
+	if f.Unique {
+		gorm = append(gorm, "uniqueIndex")
+	} else if f.Index {
+		gorm = append(gorm, "index")
+	}
+	if !f.Nullable {
+		gorm = append(gorm, "not null")
+	}
+	if f.Default != "" {
+		gorm = append(gorm, "default:"+f.Default)
+	}
+	json := f.Column
+	if f.Nullable {
+		json += ",omitempty"
+	}
+	if len(gorm) == 0 {
+		return fmt.Sprintf("`json:%q`", json)
+	}
+	return fmt.Sprintf("`gorm:%q json:%q`", strings.Join(gorm, ";"), json)
+}
+
+// modelContent renders a GORM model with an ID, the spec'd fields and timestamps
+func modelContent(titleName string, fields []modelField) string {
+	rows := [][3]string{{"ID", "uint", "`gorm:\"primaryKey\" json:\"id\"`"}}
+	for _, f := range fields {
+		rows = append(rows, [3]string{f.Name, f.goType(), f.tags()})
+	}
+	rows = append(rows,
+		[3]string{"CreatedAt", "time.Time", "`json:\"created_at\"`"},
+		[3]string{"UpdatedAt", "time.Time", "`json:\"updated_at\"`"},
+	)
+	nameWidth, typeWidth := 0, 0
+	for _, r := range rows {
+		nameWidth = max(nameWidth, len(r[0]))
+		typeWidth = max(typeWidth, len(r[1]))
+	}
+	var b strings.Builder
+	for _, r := range rows {
+		fmt.Fprintf(&b, "\t%-*s %-*s %s\n", nameWidth, r[0], typeWidth, r[1], r[2])
+	}
+	std, other := fieldImports(fields)
+	imports := "import \"time\"\n"
+	if len(other) > 0 {
+		imports = strings.TrimSuffix(importBlock(append(std, `"time"`), other), "\n")
+	}
+	return fmt.Sprintf(`package model
+
+%[4]s
+// %[1]s is the GORM model of the %[2]s table
+type %[1]s struct {
+%[3]s}
+`, titleName, proto.SnakeCase(titleName)+"s", b.String(), imports)
+}
+
+// modelFile returns the path of a model in a module
+func modelFile(module, name string) string {
+	return filepath.Join("app", module, "model", fmt.Sprintf("%sModel.go", name))
+}
+
+// modelPackage returns the import path of a module's model package
+func modelPackage(module string) string {
+	return fmt.Sprintf("%s/app/%s/model", getModuleName(), module)
+}
+
+var modelCmd = &cobra.Command{
+	Use:   "model [name] [field:type[:modifier...]...]",
+	Short: "Generate a GORM model from a field spec, e.g. model User name:string email:string:unique age:int",
+	Long: `Generates a struct with GORM and JSON tags in app/<module>/model. Each field is
+name:type followed by optional modifiers:
+
+  types      string, text, int, int64, uint, float, decimal, money, bool, time, uuid
+  modifiers  unique, index, nullable (a pointer without NOT NULL), default=value
+
+Use money or decimal for amounts, never float: money is a money.Amount of integer
+cents (app/money, created if missing) and decimal a shopspring decimal.Decimal.
+Both are decimal strings in JSON. 'g entity' and 'g dto' take the same specs.
+
+The module defaults to the lowercased model name. Pass --model <Name> to
+'g controller', 'g service' or 'g module' to use the model instead of interface{}.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		titleName := strings.Title(name)
+		module := modelModule
+		if module == "" {
+			module = strings.ToLower(name)
+		}
+		fields, err := parseModelFields(args[1:])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+		file := modelFile(module, name)
+		files := []codegen.File{{Path: file, Content: modelContent(titleName, fields)}}
+		if !writeGenerated(append(files, fieldFiles(fields)...)...) {
+			return
+		}
+		fmt.Printf("Model '%s' created in app/%s/model. Use it with --model %s in 'g controller', 'g service' or 'g module'.\n", titleName, module, titleName)
+		printDecimalDependency(fields)
+		openIfRequested(file)
+	},
+}
+
+func init() {
+	modelCmd.Flags().StringVar(&modelModule, "module", "", "Module the model belongs to (default: the lowercased model name)")
+	controllerCmd.Flags().StringVar(&generateModel, "model", "", "Parse request bodies into this model from the module's model package instead of leaving them untyped")
+	serviceCmd.Flags().StringVar(&generateModel, "model", "", "Use this model from the module's model package instead of interface{}")
+	moduleCmd.Flags().StringVar(&generateModel, "model", "", "Use this model from the module's model package in the controller and service instead of interface{}")
+	generateCmd.AddCommand(modelCmd)
+	gCmd.AddCommand(modelCmd)
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import "path/filepath"
+
+// moneyFile holds the money type of the money fields of entities and DTOs
+var moneyFile = filepath.Join("app", "money", "money.go")
+
+const moneyTemplate = `// Package money holds amounts of money as integer cents, so they add up
+// exactly where float64 would round.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Amount is an amount of money in cents. It is a decimal string in JSON,
+// e.g. "12.34", and a bigint column in the database.
+type Amount int64
+
+// FromCents returns the amount of cents
+func FromCents(cents int64) Amount {
+	return Amount(cents)
+}
+
+// Parse parses a decimal amount with at most 2 decimal places, e.g. "12.34"
+// or "-0.5". It never rounds: "0.001" is an error.
+func Parse(s string) (Amount, error) {
+	digits, negative := strings.CutPrefix(s, "-")
+	units, fraction, _ := strings.Cut(digits, ".")
+	switch {
+	case units == "" || !isDigits(units) || !isDigits(fraction):
+		return 0, fmt.Errorf("money: invalid amount %q", s)
+	case len(fraction) > 2:
+		return 0, fmt.Errorf("money: %q has more than 2 decimal places", s)
+	}
+	cents, err := strconv.ParseInt(units+(fraction + "00")[:2], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("money: %q is out of range", s)
+	}
+	if negative {
+		cents = -cents
+	}
+	return Amount(cents), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Cents returns the amount in cents
+func (a Amount) Cents() int64 {
+	return int64(a)
+}
+
+// String formats the amount with 2 decimal places, e.g. "12.34"
+func (a Amount) String() string {
+	sign, cents := "", uint64(a)
+	if a < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// Add returns a+b, failing on overflow
+func (a Amount) Add(b Amount) (Amount, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, errors.New("money: overflow")
+	}
+	return sum, nil
+}
+
+// Sub returns a-b, failing on overflow
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if b == math.MinInt64 {
+		return 0, errors.New("money: overflow")
+	}
+	return a.Add(-b)
+}
+
+// Mul returns the amount times n, e.g. a unit price times a quantity,
+// failing on overflow
+func (a Amount) Mul(n int64) (Amount, error) {
+	if a == 0 || n == 0 {
+		return 0, nil
+	}
+	product := a * Amount(n)
+	if product/Amount(n) != a || (a == -1 && n == math.MinInt64) || (n == -1 && a == math.MinInt64) {
+		return 0, errors.New("money: overflow")
+	}
+	return product, nil
+}
+
+// IsNegative reports whether the amount is below zero
+func (a Amount) IsNegative() bool {
+	return a < 0
+}
+
+// IsZero reports whether the amount is zero
+func (a Amount) IsZero() bool {
+	return a == 0
+}
+
+// MarshalJSON encodes the amount as a decimal string, which clients do not
+// round when they decode numbers as floats
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(a.String())), nil
+}
+
+// UnmarshalJSON decodes a decimal string or number, e.g. "12.34" or 12.34,
+// without going through float64
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	amount, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// Value stores the amount as cents
+func (a Amount) Value() (driver.Value, error) {
+	return int64(a), nil
+}
+
+// Scan reads the amount from a column of cents
+func (a *Amount) Scan(src any) error {
+	switch v := src.(type) {
+	case int64:
+		*a = Amount(v)
+	case []byte:
+		cents, err := strconv.ParseInt(string(v), 10, 64)
+		if err != nil {
+			return fmt.Errorf("money: scanning %q: %w", v, err)
+		}
+		*a = Amount(cents)
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+	return nil
+}
+`
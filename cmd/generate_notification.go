@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/spf13/cobra"
+)
+
+// notifierDir holds the notifier and channels shared by the notifications of every module
+var notifierDir = filepath.Join("app", "notifier")
+
+const notifierTemplate = `// Package notifier sends notifications over the channels each one picks with
+// Via: mail, Slack, SMS or channels of your own.
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// The channels a notification's Via can pick
+const (
+	Mail  = "mail"
+	Slack = "slack"
+	SMS   = "sms"
+)
+
+// Notifiable is a recipient of notifications, e.g. a user entity. It returns
+// its address on a channel: an email, a Slack webhook URL or a phone number.
+// An empty address skips the channel.
+type Notifiable interface {
+	RouteNotificationFor(channel string) string
+}
+
+// Notification picks the channels it is sent over. It renders itself for each
+// one with ToMail, ToSlack or ToSMS.
+type Notification interface {
+	Via(to Notifiable) []string
+}
+
+// Channel delivers notifications of one kind
+type Channel interface {
+	Deliver(ctx context.Context, address string, n Notification) error
+}
+
+// Sender sends notifications. Services inject it with inject:"name=notifier";
+// tests pass a *Recorder.
+type Sender interface {
+	Send(ctx context.Context, to Notifiable, n Notification) error
+}
+
+// Notifier is the Sender of the app. Bind it at startup with
+// container.Bind("notifier", notifier.New(...)).
+type Notifier struct {
+	channels map[string]Channel
+}
+
+// New returns a Notifier sending over channels, keyed by name:
+//
+//	notifier.New(map[string]notifier.Channel{
+//		notifier.Mail:  notifier.MailChannel{Send: ...},
+//		notifier.Slack: notifier.SlackChannel{},
+//	})
+func New(channels map[string]Channel) *Notifier {
+	return &Notifier{channels: channels}
+}
+
+// Send delivers n to every channel its Via picks. A failing channel does not
+// stop the others; their errors are joined.
+func (s *Notifier) Send(ctx context.Context, to Notifiable, n Notification) error {
+	var errs []error
+	for _, name := range n.Via(to) {
+		channel, ok := s.channels[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("notifier: %T picks channel %q, which is not registered", n, name))
+			continue
+		}
+		address := to.RouteNotificationFor(name)
+		if address == "" {
+			continue
+		}
+		if err := channel.Deliver(ctx, address, n); err != nil {
+			errs = append(errs, fmt.Errorf("notifier: sending %T over %s: %w", n, name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sent is a notification recorded by a Recorder
+type Sent struct {
+	To           Notifiable
+	Notification Notification
+	Channels     []string
+}
+
+// Recorder is a Sender for tests: it keeps the notifications instead of sending them
+type Recorder struct {
+	mu   sync.Mutex
+	sent []Sent
+}
+
+// Send records n with the channels its Via picks for to
+func (r *Recorder) Send(ctx context.Context, to Notifiable, n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, Sent{To: to, Notification: n, Channels: n.Via(to)})
+	return nil
+}
+
+// Sent returns the notifications recorded so far
+func (r *Recorder) Sent() []Sent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Sent(nil), r.sent...)
+}
+`
+
+const notifierChannelsTemplate = `package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// MailMessage is a notification rendered for the mail channel
+type MailMessage struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// SlackMessage is a notification rendered for the Slack channel
+type SlackMessage struct {
+	Text string
+}
+
+// SMSMessage is a notification rendered for the SMS channel
+type SMSMessage struct {
+	Text string
+}
+
+// MailNotification is a notification that can be sent by mail
+type MailNotification interface {
+	ToMail(to string) MailMessage
+}
+
+// SlackNotification is a notification that can be sent to Slack
+type SlackNotification interface {
+	ToSlack(to string) SlackMessage
+}
+
+// SMSNotification is a notification that can be sent by SMS
+type SMSNotification interface {
+	ToSMS(to string) SMSMessage
+}
+
+// MailChannel sends mail through Send, e.g. the app's mailer
+type MailChannel struct {
+	Send func(ctx context.Context, to string, msg MailMessage) error
+}
+
+// Deliver renders n for the mail channel and sends it to address
+func (c MailChannel) Deliver(ctx context.Context, address string, n Notification) error {
+	mail, ok := n.(MailNotification)
+	if !ok {
+		return fmt.Errorf("%T has no ToMail method", n)
+	}
+	if c.Send == nil {
+		// TODO: Set Send when binding the notifier, e.g. to the app's mailer
+		return errors.New("the mail channel has no Send function")
+	}
+	return c.Send(ctx, address, mail.ToMail(address))
+}
+
+// SlackChannel posts to Slack incoming webhooks. The address is the webhook URL.
+type SlackChannel struct {
+	Client *http.Client // http.DefaultClient when nil
+}
+
+// Deliver renders n for Slack and posts it to the webhook at address
+func (c SlackChannel) Deliver(ctx context.Context, address string, n Notification) error {
+	slack, ok := n.(SlackNotification)
+	if !ok {
+		return fmt.Errorf("%T has no ToSlack method", n)
+	}
+	body, err := json.Marshal(map[string]string{"text": slack.ToSlack(address).Text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack answered %s", resp.Status)
+	}
+	return nil
+}
+
+// SMSChannel sends text messages through Send, e.g. a Twilio or SNS client
+type SMSChannel struct {
+	Send func(ctx context.Context, to string, msg SMSMessage) error
+}
+
+// Deliver renders n for SMS and sends it to the phone number address
+func (c SMSChannel) Deliver(ctx context.Context, address string, n Notification) error {
+	sms, ok := n.(SMSNotification)
+	if !ok {
+		return fmt.Errorf("%T has no ToSMS method", n)
+	}
+	if c.Send == nil {
+		// TODO: Set Send when binding the notifier, e.g. to your SMS provider's client
+		return errors.New("the SMS channel has no Send function")
+	}
+	return c.Send(ctx, address, sms.ToSMS(address))
+}
+`
+
+// notifierFiles returns the shared notifier files the project does not have yet
+func notifierFiles() []codegen.File {
+	files := missingFile(codegen.File{Path: filepath.Join(notifierDir, "notifier.go"), Content: notifierTemplate})
+	return append(files, missingFile(codegen.File{Path: filepath.Join(notifierDir, "channels.go"), Content: notifierChannelsTemplate})...)
+}
+
+// notificationContent renders a notification with its channels and a message per channel
+func notificationContent(titleName, subject string) string {
+	return fmt.Sprintf(`package notification
+
+import "%[1]s/app/notifier"
+
+// %[2]sNotification is a notification. Its fields are the data the messages show.
+type %[2]sNotification struct {
+	// TODO: Add the data the messages show, e.g. the ID of the record concerned
+	ID string
+}
+
+// Via picks the channels the notification is sent over to the recipient
+func (n %[2]sNotification) Via(to notifier.Notifiable) []string {
+	// TODO: Pick the channels, e.g. add notifier.Slack or notifier.SMS, or decide
+	// per recipient
+	return []string{notifier.Mail}
+}
+
+// ToMail renders the notification as an email to the address to
+func (n %[2]sNotification) ToMail(to string) notifier.MailMessage {
+	return notifier.MailMessage{
+		Subject: %[3]q,
+		// TODO: Write the email
+		Text: %[3]q,
+	}
+}
+
+// ToSlack renders the notification as a Slack message
+func (n %[2]sNotification) ToSlack(to string) notifier.SlackMessage {
+	// TODO: Write the message
+	return notifier.SlackMessage{Text: %[3]q}
+}
+
+// ToSMS renders the notification as a text message to the phone number to
+func (n %[2]sNotification) ToSMS(to string) notifier.SMSMessage {
+	// TODO: Write the message, keeping it short
+	return notifier.SMSMessage{Text: %[3]q}
+}
+`, getModuleName(), titleName, subject)
+}
+
+var notificationCmd = &cobra.Command{
+	Use:   "notification [name] [in_module]",
+	Short: "Generate a notification sent over mail, Slack or SMS with a Via method picking its channels",
+	Long: `Generates app/<module>/notification/<name>Notification.go, a notification whose
+Via method picks the channels it is sent over, with ToMail, ToSlack and ToSMS
+rendering it for each one:
+
+  s.Notifier.Send(ctx, user, notification.OrderShippedNotification{ID: order.ID})
+
+The recipient implements notifier.Notifiable, returning its address on each
+channel. The shared app/notifier is created if missing: the Notifier, a Recorder
+for tests, and the channels. Slack posts to incoming webhooks; mail and SMS send
+through the functions they are given, e.g. the app's mailer.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		titleName := proto.GoName(name)
+		if titleName == "" {
+			fmt.Printf("Invalid notification name %q\n", name)
+			return
+		}
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+		subject := strings.ReplaceAll(proto.SnakeCase(titleName), "_", " ")
+		subject = strings.ToUpper(subject[:1]) + subject[1:]
+		notificationFile := filepath.Join("app", module, "notification", fmt.Sprintf("%sNotification.go", name))
+		files := []codegen.File{{Path: notificationFile, Content: notificationContent(titleName, subject)}}
+		newNotifier := notifierFiles()
+		if !writeGenerated(append(files, newNotifier...)...) {
+			return
+		}
+		fmt.Printf("Notification '%sNotification' created in app/%s/notification. Send it with:\n  s.Notifier.Send(ctx, user, notification.%sNotification{ID: id})\n", titleName, module, titleName)
+		if len(newNotifier) > 0 {
+			mail := "notifier.MailChannel{Send: ...}"
+			if _, err := os.Stat(mailerFile); err == nil {
+				mail = "notifier.MailChannel{Send: func(ctx context.Context, to string, m notifier.MailMessage) error {\n  \t\treturn mlr.Send(ctx, mailer.Message{To: []string{to}, Subject: m.Subject, HTML: m.HTML, Text: m.Text})\n  \t}}"
+			}
+			fmt.Printf("Bind the notifier in main.go with the channels you use:\n  container.Bind(\"notifier\", notifier.New(map[string]notifier.Channel{\n  \tnotifier.Mail:  %s,\n  \tnotifier.Slack: notifier.SlackChannel{},\n  \tnotifier.SMS:   notifier.SMSChannel{Send: ...},\n  }))\n", mail)
+			fmt.Println("Inject it into services with:\n  Notifier notifier.Sender `inject:\"name=notifier\"`\nRecipients implement notifier.Notifiable:\n  func (u *User) RouteNotificationFor(channel string) string")
+		}
+		openIfRequested(notificationFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(notificationCmd)
+	gCmd.AddCommand(notificationCmd)
+}
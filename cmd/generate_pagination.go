@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+)
+
+// generatePagination is set by `--pagination offset|cursor` on list-capable generators
+var generatePagination string
+
+// paginationFile is the shared pagination toolkit used by generated list endpoints
+var paginationFile = filepath.Join("app", "pagination", "pagination.go")
+
+const paginationTemplate = `package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DefaultLimit is the page size used when ?limit is not given
+const DefaultLimit = 20
+
+// MaxLimit caps ?limit so a single request cannot load a whole table
+const MaxLimit = 100
+
+// OffsetParams selects a numbered page. Offsets get slower the deeper the page,
+// so prefer cursors for large tables.
+type OffsetParams struct {
+	Page  int
+	Limit int
+}
+
+// Offset returns the number of rows to skip
+func (p OffsetParams) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// OffsetPage is a numbered page of items
+type OffsetPage[T any] struct {
+	Items []T ` + "`json:\"items\"`" + `
+	Page  int ` + "`json:\"page\"`" + `
+	Limit int ` + "`json:\"limit\"`" + `
+	Total int ` + "`json:\"total\"`" + `
+}
+
+// ParseOffset reads ?page and ?limit
+func ParseOffset(c *fiber.Ctx) (OffsetParams, error) {
+	limit, err := parseLimit(c)
+	if err != nil {
+		return OffsetParams{}, err
+	}
+	page := 1
+	if raw := c.Query("page"); raw != "" {
+		if page, err = strconv.Atoi(raw); err != nil || page < 1 {
+			return OffsetParams{}, fmt.Errorf("page must be a positive integer")
+		}
+	}
+	return OffsetParams{Page: page, Limit: limit}, nil
+}
+
+// Cursor marks the last row of a page by its sort key and ID. The next page
+// starts strictly after it, so rows never shift or repeat between pages.
+type Cursor struct {
+	SortKey time.Time ` + "`json:\"k\"`" + `
+	ID      string    ` + "`json:\"id\"`" + `
+}
+
+// Encode returns the opaque, URL-safe form of the cursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor produced by Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &c, nil
+}
+
+// CursorParams selects the page after a cursor; After is nil for the first page
+type CursorParams struct {
+	After *Cursor
+	Limit int
+}
+
+// CursorPage is a keyset page of items. NextCursor is empty on the last page.
+type CursorPage[T any] struct {
+	Items      []T    ` + "`json:\"items\"`" + `
+	NextCursor string ` + "`json:\"next_cursor,omitempty\"`" + `
+	Limit      int    ` + "`json:\"limit\"`" + `
+}
+
+// ParseCursor reads ?cursor and ?limit
+func ParseCursor(c *fiber.Ctx) (CursorParams, error) {
+	limit, err := parseLimit(c)
+	if err != nil {
+		return CursorParams{}, err
+	}
+	params := CursorParams{Limit: limit}
+	if raw := c.Query("cursor"); raw != "" {
+		if params.After, err = DecodeCursor(raw); err != nil {
+			return CursorParams{}, err
+		}
+	}
+	return params, nil
+}
+
+func parseLimit(c *fiber.Ctx) (int, error) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return DefaultLimit, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("limit must be a positive integer")
+	}
+	return min(limit, MaxLimit), nil
+}
+`
+
+// validatePagination checks the --pagination flag
+func validatePagination() error {
+	switch generatePagination {
+	case "", "offset", "cursor":
+		return nil
+	}
+	return fmt.Errorf("unknown --pagination %q (expected offset or cursor)", generatePagination)
+}
+
+// paginationFiles returns the pagination toolkit if a list endpoint needs it and it does not exist yet
+func paginationFiles() []codegen.File {
+	if generatePagination == "" {
+		return nil
+	}
+	return missingFile(codegen.File{Path: paginationFile, Content: paginationTemplate})
+}
+
+// paginationParser returns the toolkit function that parses the query string for the selected mode
+func paginationParser(mode string) string {
+	if mode == "cursor" {
+		return "ParseCursor"
+	}
+	return "ParseOffset"
+}
+
+// paginationPage returns the toolkit page type for the selected mode
+func paginationPage(mode string) string {
+	if mode == "cursor" {
+		return "CursorPage"
+	}
+	return "OffsetPage"
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+// httpTuningFile holds the environment-driven Fiber server configuration
+var httpTuningFile = filepath.Join(bootstrapDir, "http.go")
+
+const httpTuningTemplate = `package bootstrap
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+)
+
+// HTTPConfig returns the server configuration tuned from the environment.
+// Create the server with fiber.New(bootstrap.HTTPConfig("my-app")).
+func HTTPConfig(appName string) fiber.Config {
+	return fiber.Config{
+		AppName: appName,
+
+		// HTTP_BODY_LIMIT caps request bodies in bytes (default 4 MiB). Larger
+		// requests are rejected with 413 before the handler runs. Raise it only on
+		// apps that accept uploads.
+		BodyLimit: httpEnvInt("HTTP_BODY_LIMIT", 4*1024*1024),
+
+		// HTTP_READ_TIMEOUT bounds reading a whole request, headers and body
+		// (default 10s). It protects against slow clients holding connections open.
+		ReadTimeout: httpEnvDuration("HTTP_READ_TIMEOUT", 10*time.Second),
+
+		// HTTP_WRITE_TIMEOUT bounds writing the response (default 10s). Streaming
+		// or long-polling endpoints need a larger value.
+		WriteTimeout: httpEnvDuration("HTTP_WRITE_TIMEOUT", 10*time.Second),
+
+		// HTTP_IDLE_TIMEOUT is how long a keep-alive connection may wait for its
+		// next request (default 60s). Keep it above your load balancer's idle timeout.
+		IdleTimeout: httpEnvDuration("HTTP_IDLE_TIMEOUT", 60*time.Second),
+
+		// HTTP_CONCURRENCY is the maximum number of concurrent connections
+		// (default 256 * 1024). Connections beyond it are refused.
+		Concurrency: httpEnvInt("HTTP_CONCURRENCY", 256*1024),
+
+		// HTTP_PREFORK starts one process per CPU sharing the port (default false).
+		// It helps CPU-bound apps on bare metal but duplicates in-memory state and
+		// rarely helps in containers limited to one or two CPUs.
+		Prefork: httpEnvBool("HTTP_PREFORK", false),
+	}
+}
+
+// CompressMiddleware compresses responses with gzip, deflate or brotli,
+// whichever the client accepts. HTTP_COMPRESS_LEVEL selects the trade-off:
+// -1 disables compression, 0 is the default, 1 favours speed and 2 favours size.
+func CompressMiddleware() fiber.Handler {
+	level := compress.Level(httpEnvInt("HTTP_COMPRESS_LEVEL", int(compress.LevelDefault)))
+	if level == compress.LevelDisabled {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	return compress.New(compress.Config{Level: level})
+}
+
+func httpEnvInt(key string, fallback int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return v
+	}
+	return fallback
+}
+
+func httpEnvDuration(key string, fallback time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return v
+	}
+	return fallback
+}
+
+func httpEnvBool(key string, fallback bool) bool {
+	if v, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return v
+	}
+	return fallback
+}
+`
+
+var perfHTTPCmd = &cobra.Command{
+	Use:   "perf:http",
+	Short: "Generate environment-driven body limits, timeouts, prefork/concurrency and response compression in bootstrap/http.go",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !writeGenerated(codegen.File{Path: httpTuningFile, Content: httpTuningTemplate}) {
+			return
+		}
+		fmt.Printf("HTTP tuning created in %s. Create the server with:\n", httpTuningFile)
+		fmt.Println(`
+	server := fiber.New(bootstrap.HTTPConfig("my-app"))`)
+		if _, err := os.Stat(middlewareChainFile); err == nil {
+			if err := addGlobalMiddleware("", "CompressMiddleware()"); err != nil {
+				fmt.Printf("Error wiring compression into %s: %v\n", middlewareChainFile, err)
+				return
+			}
+			fmt.Printf("CompressMiddleware() added to %s\n", middlewareChainFile)
+		} else {
+			fmt.Println("Register compression with server.Use(bootstrap.CompressMiddleware()).")
+		}
+		openIfRequested(httpTuningFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(perfHTTPCmd)
+	gCmd.AddCommand(perfHTTPCmd)
+}
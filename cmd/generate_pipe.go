@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+// pipeBody is the implementation of a well-known pipe
+type pipeBody struct {
+	Doc     string
+	Imports []string
+	Body    string
+}
+
+// knownPipes are generated with a working Transform instead of a TODO, by lowercased name
+var knownPipes = map[string]pipeBody{
+	"parseint": {
+		Doc:     "converts the value to an int",
+		Imports: []string{"strconv"},
+		Body: `	n, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not an integer", s)
+	}
+	return n, nil`,
+	},
+	"parsefloat": {
+		Doc:     "converts the value to a float64",
+		Imports: []string{"strconv"},
+		Body: `	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a number", s)
+	}
+	return f, nil`,
+	},
+	"parsebool": {
+		Doc:     "converts the value to a bool (1, t, true, 0, f, false, ...)",
+		Imports: []string{"strconv"},
+		Body: `	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a boolean", s)
+	}
+	return b, nil`,
+	},
+	"trim": {
+		Doc:     "removes leading and trailing white space",
+		Imports: []string{"strings"},
+		Body:    `	return strings.TrimSpace(s), nil`,
+	},
+}
+
+// pipeContent renders a pipe. Well-known names get a working Transform.
+func pipeContent(name, titleName string) string {
+	known, ok := knownPipes[strings.ToLower(name)]
+	if !ok {
+		return fmt.Sprintf(`package pipe
+
+// %[1]sPipe validates or transforms a request value
+type %[1]sPipe struct{}
+
+// Transform returns the value for the next pipe. An error rejects the request with 400.
+func (p %[1]sPipe) Transform(value any) (any, error) {
+	// TODO: Validate or transform the value
+	return value, nil
+}
+`, titleName)
+	}
+	imports := append([]string{"fmt"}, known.Imports...)
+	for i, imp := range imports {
+		imports[i] = fmt.Sprintf("\t%q", imp)
+	}
+	return fmt.Sprintf(`package pipe
+
+import (
+%[2]s
+)
+
+// %[1]sPipe %[3]s
+type %[1]sPipe struct{}
+
+// Transform returns the value for the next pipe. An error rejects the request with 400.
+func (p %[1]sPipe) Transform(value any) (any, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a string, got %%T", value)
+	}
+%[4]s
+}
+`, titleName, strings.Join(imports, "\n"), known.Doc, known.Body)
+}
+
+// pipeHelpersContent is shared by every pipe of a module
+const pipeHelpersContent = `package pipe
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Pipe validates or transforms a request value. Pipes compose: the first receives
+// the raw string and each of the others the result of the one before it.
+type Pipe interface {
+	Transform(value any) (any, error)
+}
+
+// Apply runs the value through the pipes in order
+func Apply(value any, pipes ...Pipe) (any, error) {
+	for _, p := range pipes {
+		v, err := p.Transform(value)
+		if err != nil {
+			return nil, err
+		}
+		value = v
+	}
+	return value, nil
+}
+
+// Param binds a path parameter through the pipes:
+//
+//	id, err := pipe.Param[int](ctx, "id", pipe.TrimPipe{}, pipe.ParseIntPipe{})
+//	if err != nil {
+//		return err
+//	}
+func Param[T any](ctx *fiber.Ctx, name string, pipes ...Pipe) (T, error) {
+	return bind[T]("path parameter", name, ctx.Params(name), pipes)
+}
+
+// Query binds a query parameter through the pipes
+func Query[T any](ctx *fiber.Ctx, name string, pipes ...Pipe) (T, error) {
+	return bind[T]("query parameter", name, ctx.Query(name), pipes)
+}
+
+// bind answers 400 when a pipe rejects the value and 500 when the pipes don't
+// produce a T, which is a programming error
+func bind[T any](kind, name, raw string, pipes []Pipe) (T, error) {
+	var zero T
+	v, err := Apply(raw, pipes...)
+	if err != nil {
+		return zero, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid %s %s: %v", kind, name, err))
+	}
+	t, ok := v.(T)
+	if !ok {
+		return zero, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("%s %s is a %T, not a %T", kind, name, v, zero))
+	}
+	return t, nil
+}
+`
+
+var pipeCmd = &cobra.Command{
+	Use:   "pipe [name] [in_module]",
+	Short: "Generate a pipe that validates or transforms path and query parameters",
+	Long: `Generates app/<module>/pipe/<name>Pipe.go: a component with a Transform method
+that validates or converts a request value. parseInt, parseFloat, parseBool and
+trim are generated ready to use; other names get a TODO.
+
+pipe.Param and pipe.Query bind a parameter through a list of pipes in a
+controller, answering 400 when one rejects it:
+
+  id, err := pipe.Param[int](ctx, "id", pipe.TrimPipe{}, pipe.ParseIntPipe{})`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		titleName := strings.Title(name)
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+		pipeDir := filepath.Join("app", module, "pipe")
+		pipeFile := filepath.Join(pipeDir, fmt.Sprintf("%sPipe.go", name))
+		files := []codegen.File{{Path: pipeFile, Content: pipeContent(name, titleName)}}
+		files = append(files, missingFile(codegen.File{Path: filepath.Join(pipeDir, "pipe.go"), Content: pipeHelpersContent})...)
+		if !writeGenerated(files...) {
+			return
+		}
+		fmt.Printf("Pipe '%s' created in app/%s/pipe. Bind parameters through it in a controller:\n  value, err := pipe.Param[string](ctx, \"id\", pipe.%sPipe{})\n", name, module, titleName)
+		openIfRequested(pipeFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(pipeCmd)
+	gCmd.AddCommand(pipeCmd)
+}
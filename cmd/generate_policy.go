@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/spf13/cobra"
+)
+
+// policyRoutes is set by `g policy --route`
+var policyRoutes []string
+
+// authzFile holds the caller and the middleware shared by every policy
+var authzFile = filepath.Join("app", "authz", "authz.go")
+
+const authzTemplate = `// Package authz runs the policies of the modules: it finds the caller of a
+// request and turns a policy's decision into a response.
+package authz
+
+import "github.com/gofiber/fiber/v2"
+
+// userKey and resourceKey are the Fiber locals holding the caller and the
+// resource a policy allowed
+const (
+	userKey     = "authz.user"
+	resourceKey = "authz.resource"
+)
+
+// User is the caller a policy decides for
+type User struct {
+	ID    string
+	Roles []string
+}
+
+// HasRole reports whether the user has role
+func (u *User) HasRole(role string) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// SetUser records the caller of the request, once a guard has verified it
+func SetUser(c *fiber.Ctx, user *User) {
+	c.Locals(userKey, user)
+}
+
+// UserFrom returns the caller recorded by SetUser. Without one, a caller with
+// the roles a guard stored under "roles" is returned, or nil for anonymous
+// requests.
+func UserFrom(c *fiber.Ctx) *User {
+	if user, ok := c.Locals(userKey).(*User); ok {
+		return user
+	}
+	if roles, ok := c.Locals("roles").([]string); ok {
+		return &User{Roles: roles}
+	}
+	return nil
+}
+
+// Authorize returns middleware that loads the resource of the request and lets
+// the request through only if allow does. A denied request gets 401 when it is
+// anonymous and 403 otherwise. The handler reads the loaded resource with
+// Resource.
+func Authorize[T any](allow func(user *User, resource T) bool, load func(c *fiber.Ctx) (T, error)) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		resource, err := load(c)
+		if err != nil {
+			return err
+		}
+		user := UserFrom(c)
+		if !allow(user, resource) {
+			if user == nil {
+				return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+			}
+			return fiber.NewError(fiber.StatusForbidden, "not allowed")
+		}
+		c.Locals(resourceKey, resource)
+		return c.Next()
+	}
+}
+
+// Resource returns the resource Authorize loaded for the request
+func Resource[T any](c *fiber.Ctx) (T, bool) {
+	resource, ok := c.Locals(resourceKey).(T)
+	return resource, ok
+}
+`
+
+// policyActions maps the Fiber route methods to the policy action checked on them
+var policyActions = map[string]string{"Get": "view", "Post": "create", "Put": "update", "Patch": "update", "Delete": "delete"}
+
+// policyResource returns the type a policy decides on: the module's entity if
+// it has one, or any
+func policyResource(module, name, titleName string) (typ, importPath string) {
+	for _, path := range []string{entityFile(module, name), entityFile(module, titleName), entityFile(module, strings.ToLower(name[:1])+name[1:])} {
+		if _, err := os.Stat(path); err == nil {
+			return "*entity." + titleName, fmt.Sprintf("%s/app/%s/entity", getModuleName(), module)
+		}
+	}
+	return "any", ""
+}
+
+// policyContent renders the policy of a resource with its loader and middleware
+func policyContent(module, name, titleName string) string {
+	typ, entityImport := policyResource(module, name, titleName)
+	imports := fmt.Sprintf("\t\"fmt\"\n\n\t\"%s/app/authz\"\n", getModuleName())
+	if entityImport != "" {
+		imports += fmt.Sprintf("\t%q\n", entityImport)
+	}
+	imports += "\n\t\"github.com/gofiber/fiber/v2\"\n"
+	param := strings.ToLower(titleName[:1]) + titleName[1:]
+	if param == "user" || param == "c" || param == "p" {
+		param = "resource"
+	}
+	return fmt.Sprintf(`package policy
+
+import (
+%[1]s)
+
+// %[2]sPolicy decides what a user may do with a %[2]s. user is nil for
+// anonymous callers. Deny by default and open up case by case.
+type %[2]sPolicy struct{}
+
+// CanView reports whether user may read %[4]s
+func (%[2]sPolicy) CanView(user *authz.User, %[4]s %[3]s) bool {
+	// TODO: Restrict reading, e.g. to published ones or their owner
+	return user != nil
+}
+
+// CanCreate reports whether user may create a %[2]s. %[4]s is what Load%[2]s
+// returns for the route, usually nothing yet.
+func (%[2]sPolicy) CanCreate(user *authz.User, %[4]s %[3]s) bool {
+	return user != nil
+}
+
+// CanUpdate reports whether user may change %[4]s
+func (%[2]sPolicy) CanUpdate(user *authz.User, %[4]s %[3]s) bool {
+	// TODO: Let the owner update it too, e.g. user.ID == %[4]s.OwnerID
+	return user != nil && user.HasRole("admin")
+}
+
+// CanDelete reports whether user may delete %[4]s
+func (%[2]sPolicy) CanDelete(user *authz.User, %[4]s %[3]s) bool {
+	// TODO: Let the owner delete it too, e.g. user.ID == %[4]s.OwnerID
+	return user != nil && user.HasRole("admin")
+}
+
+// Load%[2]s reads the %[2]s a request acts on for the policy, e.g. by its :id
+// parameter. It returns the zero value for routes without one, such as create.
+var Load%[2]s = func(c *fiber.Ctx) (%[3]s, error) {
+	// TODO: Load it, e.g. with the repository, and return fiber.ErrNotFound when it doesn't exist
+	var %[4]s %[3]s
+	return %[4]s, nil
+}
+
+// Authorize%[2]s returns middleware enforcing %[2]sPolicy on a route for action:
+// view, create, update or delete. Attach it before the handler:
+//
+//	route.Put("/:id", policy.Authorize%[2]s("update"), ctrl.Update)
+//
+// The handler reads the loaded %[2]s with authz.Resource[%[3]s](c).
+func Authorize%[2]s(action string) fiber.Handler {
+	p := %[2]sPolicy{}
+	allow, ok := map[string]func(*authz.User, %[3]s) bool{
+		"view":   p.CanView,
+		"create": p.CanCreate,
+		"update": p.CanUpdate,
+		"delete": p.CanDelete,
+	}[action]
+	if !ok {
+		panic(fmt.Sprintf("policy: unknown %[2]s action %%q (expected view, create, update or delete)", action))
+	}
+	return authz.Authorize(allow, func(c *fiber.Ctx) (%[3]s, error) { return Load%[2]s(c) })
+}
+`, imports, titleName, typ, param)
+}
+
+var policyCmd = &cobra.Command{
+	Use:   "policy [name] [in_module]",
+	Short: "Generate an authorization policy with CanView/CanCreate/CanUpdate/CanDelete and middleware enforcing it",
+	Long: `Generates app/<module>/policy/<name>Policy.go, e.g. for 'gonext g policy Post posts':
+
+  func (PostPolicy) CanUpdate(user *authz.User, post *entity.Post) bool
+
+with CanView, CanCreate, CanUpdate and CanDelete deciding on the module's entity
+(or any without one), LoadPost reading the post a request acts on, and
+AuthorizePost(action) enforcing the policy on a route:
+
+  route.Put("/:id", policy.AuthorizePost("update"), ctrl.Update)
+
+Denied requests get 401 when anonymous and 403 otherwise. The caller comes from
+authz.SetUser, or the roles a guard stores. app/authz is created if missing.
+
+--route attaches the middleware to matching routes in the module's route files,
+checking view on GET, create on POST, update on PUT and PATCH and delete on
+DELETE, e.g. --route "DELETE /:id".`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		titleName := proto.GoName(name)
+		if titleName == "" {
+			fmt.Printf("Invalid policy name %q\n", name)
+			return
+		}
+		for _, spec := range policyRoutes {
+			method, _, err := parseGuardRoute(spec)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if policyActions[method] == "" {
+				fmt.Printf("--route %q needs one of GET, POST, PUT, PATCH or DELETE to pick the action\n", spec)
+				return
+			}
+		}
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+		policyFile := filepath.Join("app", module, "policy", fmt.Sprintf("%sPolicy.go", strings.ToLower(titleName[:1])+titleName[1:]))
+		files := []codegen.File{{Path: policyFile, Content: policyContent(module, name, titleName)}}
+		files = append(files, missingFile(codegen.File{Path: authzFile, Content: authzTemplate})...)
+		if !writeGenerated(files...) {
+			return
+		}
+		fmt.Printf("Policy '%sPolicy' created in app/%s/policy\n", titleName, module)
+		if len(policyRoutes) == 0 {
+			fmt.Printf("Attach it with --route or by hand:\n  route.Put(\"/:id\", policy.Authorize%s(\"update\"), ctrl.Update%s)\n", titleName, strings.Title(module))
+		} else if err := attachMiddleware(module, "policy", policyRoutes, func(method string) string {
+			return fmt.Sprintf("policy.Authorize%s(%q)", titleName, policyActions[method])
+		}); err != nil {
+			fmt.Printf("Error attaching the policy: %v\n", err)
+			return
+		}
+		openIfRequested(policyFile)
+	},
+}
+
+func init() {
+	policyCmd.Flags().StringArrayVar(&policyRoutes, "route", nil, "Attach the policy to routes in the module's route files: 'METHOD /path', the method picking the action (repeatable)")
+	generateCmd.AddCommand(policyCmd)
+	gCmd.AddCommand(policyCmd)
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// protoReverse is set by `g proto --reverse`
+var protoReverse bool
+
+// protoFile returns the .proto file of a module, next to its gRPC handlers
+func protoFile(module string) string {
+	return filepath.Join(workspace.AppDir, module, "grpc", module+".proto")
+}
+
+// protoSources are the DTO and entity files of a module
+func protoSources(module string) []string {
+	return []string{
+		filepath.Join(workspace.AppDir, module, "dto", "*.go"),
+		filepath.Join(workspace.AppDir, module, "entity", "*.go"),
+	}
+}
+
+// readProtoMessages parses the module's .proto file, returning nothing if it does not exist yet
+func readProtoMessages(file string) ([]proto.Message, error) {
+	src, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	messages, err := proto.Parse(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return messages, nil
+}
+
+// writeProto renders the module's DTOs and entities as protobuf messages
+func writeProto(module string) {
+	messages, err := proto.FromGo(protoSources(module)...)
+	if err != nil {
+		fmt.Printf("Error reading the structs of %s: %v\n", module, err)
+		return
+	}
+	if len(messages) == 0 {
+		fmt.Printf("No DTO or entity structs in app/%s. Create them with 'gonext g dto' or 'gonext g entity'.\n", module)
+		return
+	}
+	file := protoFile(module)
+	previous, err := readProtoMessages(file)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	messages = proto.Sync(messages, previous)
+	header := fmt.Sprintf("Messages for the DTOs and entities of app/%s, kept in sync by 'gonext g proto %s'.\n"+
+		"Field numbers are stable: removed fields are reserved, never reused.", module, module)
+	content := proto.Render(header, module+".v1", getModuleName()+"/app/"+module+"/grpc/pb", messages)
+	if !writeGenerated(codegen.File{Path: file, Content: content}) {
+		return
+	}
+	fmt.Printf("%d message(s) written to %s. Generate the Go code into app/%s/grpc/pb with:\n", len(messages), file, module)
+	fmt.Printf("  protoc --go_out=. --go_opt=module=%s %s\n", getModuleName(), filepath.ToSlash(file))
+	openIfRequested(file)
+}
+
+// writeProtoStructs creates DTO structs for the messages of the module's .proto file that have none
+func writeProtoStructs(module string) {
+	file := protoFile(module)
+	messages, err := readProtoMessages(file)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if messages == nil {
+		fmt.Printf("No %s to read. Write it, or generate it from the DTOs with 'gonext g proto %s'.\n", file, module)
+		return
+	}
+	target := filepath.Join(workspace.AppDir, module, "dto", "proto.go")
+	// Structs written by a previous run are regenerated; hand-written ones are kept
+	var sources []string
+	for _, pattern := range protoSources(module) {
+		files, _ := filepath.Glob(pattern)
+		for _, f := range files {
+			if f != target {
+				sources = append(sources, f)
+			}
+		}
+	}
+	existing, err := proto.FromGo(sources...)
+	if err != nil {
+		fmt.Printf("Error reading the structs of %s: %v\n", module, err)
+		return
+	}
+	defined := map[string]bool{}
+	for _, m := range existing {
+		defined[m.Name] = true
+	}
+	var missing []proto.Message
+	for _, m := range messages {
+		if !defined[m.Name] {
+			missing = append(missing, m)
+		}
+	}
+	if len(missing) == 0 {
+		fmt.Printf("Every message in %s already has a struct in app/%s\n", file, module)
+		return
+	}
+	src, err := format.Source([]byte(proto.GoStructs("dto", missing)))
+	if err != nil {
+		fmt.Printf("Error rendering the structs: %v\n", err)
+		return
+	}
+	if !writeGenerated(codegen.File{Path: target, Content: string(src)}) {
+		return
+	}
+	fmt.Printf("%d struct(s) for the messages of %s written to %s\n", len(missing), file, target)
+	openIfRequested(target)
+}
+
+var protoCmd = &cobra.Command{
+	Use:   "proto [module]",
+	Short: "Generate protobuf messages from a module's DTOs and entities, or DTOs from its .proto with --reverse",
+	Long: `Writes app/<module>/grpc/<module>.proto with a message for every struct in
+app/<module>/dto and app/<module>/entity. Field names come from the json tags and
+field numbers are kept across runs: new fields get new numbers and removed ones
+are reserved, so the wire format stays compatible. Messages written by hand in
+the .proto file are kept.
+
+With --reverse, the messages of the .proto file that have no struct yet are
+written as DTOs to app/<module>/dto/proto.go.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		module := args[0]
+		if _, err := os.Stat(filepath.Join(workspace.AppDir, module)); err != nil {
+			fmt.Printf("Module '%s' not found in %s\n", module, workspace.AppDir)
+			return
+		}
+		if protoReverse {
+			writeProtoStructs(module)
+			return
+		}
+		writeProto(module)
+	},
+}
+
+func init() {
+	protoCmd.Flags().BoolVar(&protoReverse, "reverse", false, "Generate DTO structs from the messages of the .proto file")
+	generateCmd.AddCommand(protoCmd)
+	gCmd.AddCommand(protoCmd)
+}
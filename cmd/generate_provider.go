@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/spf13/cobra"
+)
+
+// providerLifetime is set by `g provider --lifetime`
+var providerLifetime string
+
+// lifetimeFile holds the lazy and request-scoped providers shared by all modules
+var lifetimeFile = filepath.Join("app", "lifetime", "lifetime.go")
+
+const lifetimeTemplate = `// Package lifetime provides values with a lifetime other than the container's
+// singletons. A module's values live:
+//
+//   - For the app's lifetime (singleton): built in Register and shared. The default
+//     for controllers, services and repositories.
+//   - For the app's lifetime, built on first use (lazy singleton): for clients that
+//     are costly to build or not always needed. See Lazy.
+//   - For one request (request-scoped): built on first use within a request and
+//     released when it ends, such as a transaction. See Scoped.
+package lifetime
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Lazy builds a value on the first Get and shares it afterwards. A failed build
+// is retried on the next Get.
+type Lazy[T any] struct {
+	mu    sync.Mutex
+	build func() (T, error)
+	value T
+	done  bool
+}
+
+// NewLazy returns a lazy singleton built by build
+func NewLazy[T any](build func() (T, error)) *Lazy[T] {
+	return &Lazy[T]{build: build}
+}
+
+// Get returns the value, building it on the first call
+func (l *Lazy[T]) Get() (T, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.done {
+		value, err := l.build()
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		l.value, l.done = value, true
+	}
+	return l.value, nil
+}
+
+// scopeKey is the Fiber local holding the current request's scope
+const scopeKey = "lifetime.scope"
+
+// scope collects the releases of the values built during a request
+type scope struct {
+	releases []func(failed bool) error
+}
+
+// Scope ends request scopes: once the handler chain returns, it releases the
+// values built during the request in reverse order. The request failed if a
+// handler returned an error or the status is 400 or above. Register it before
+// the routes, e.g. in bootstrap.Middlewares.
+func Scope() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		s := &scope{}
+		c.Locals(scopeKey, s)
+		err := c.Next()
+		failed := err != nil || c.Response().StatusCode() >= fiber.StatusBadRequest
+		var errs []error
+		for i := len(s.releases) - 1; i >= 0; i-- {
+			if rerr := s.releases[i](failed); rerr != nil {
+				errs = append(errs, rerr)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// Scoped provides one value per request, built on the request's first Get
+type Scoped[T any] struct {
+	key     string
+	build   func(c *fiber.Ctx) (T, error)
+	release func(value T, failed bool) error
+}
+
+// NewScoped returns a request-scoped provider. key names the value among the
+// request's locals. release, if not nil, runs when the request ends and needs
+// the Scope middleware.
+func NewScoped[T any](key string, build func(c *fiber.Ctx) (T, error), release func(value T, failed bool) error) *Scoped[T] {
+	return &Scoped[T]{key: key, build: build, release: release}
+}
+
+// Get returns the request's value, building it on the first call
+func (p *Scoped[T]) Get(c *fiber.Ctx) (T, error) {
+	if value, ok := c.Locals(p.key).(T); ok {
+		return value, nil
+	}
+	var zero T
+	s, _ := c.Locals(scopeKey).(*scope)
+	if p.release != nil && s == nil {
+		return zero, fmt.Errorf("%s: releasing request-scoped values needs the lifetime.Scope() middleware", p.key)
+	}
+	value, err := p.build(c)
+	if err != nil {
+		return zero, err
+	}
+	c.Locals(p.key, value)
+	if p.release != nil {
+		s.releases = append(s.releases, func(failed bool) error {
+			return p.release(value, failed)
+		})
+	}
+	return value, nil
+}
+
+// Tx provides one transaction per request, committed when the request succeeds
+// and rolled back when it fails:
+//
+//	container.Bind("tx", lifetime.Tx(db))
+//
+// Repositories inject it with inject:"name=tx" and call Get(ctx) in each method.
+func Tx(db *sql.DB) *Scoped[*sql.Tx] {
+	return NewScoped("lifetime.tx", func(c *fiber.Ctx) (*sql.Tx, error) {
+		return db.BeginTx(c.UserContext(), nil)
+	}, func(tx *sql.Tx, failed bool) error {
+		if failed {
+			return tx.Rollback()
+		}
+		return tx.Commit()
+	})
+}
+
+// Bag holds values that middleware and handlers share during one request, such
+// as the authenticated user or a correlation ID
+type Bag struct {
+	mu     sync.RWMutex
+	values map[string]any
+}
+
+var bags = NewScoped("lifetime.bag", func(*fiber.Ctx) (*Bag, error) {
+	return &Bag{values: map[string]any{}}, nil
+}, nil)
+
+// BagOf returns the request's bag, creating it on first use
+func BagOf(c *fiber.Ctx) *Bag {
+	bag, _ := bags.Get(c)
+	return bag
+}
+
+// Set stores a value under key
+func (b *Bag) Set(key string, value any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.values[key] = value
+}
+
+// Get returns the value stored under key
+func (b *Bag) Get(key string) (any, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	value, ok := b.values[key]
+	return value, ok
+}
+
+// Value returns the bag's value under key if it has type T
+func Value[T any](b *Bag, key string) (T, bool) {
+	value, _ := b.Get(key)
+	typed, ok := value.(T)
+	return typed, ok
+}
+`
+
+// providerContent renders a singleton, lazy or request-scoped provider bound as name
+func providerContent(module, titleName, name, lifetime string) string {
+	if lifetime == "singleton" {
+		return fmt.Sprintf(`package provider
+
+// %[1]s is a component of the %[2]s module that is not a controller,
+// service or repository, such as an API client or a formatter.
+// %[3]sModule.Register builds it, injects its fields like a service's and
+// binds it as %[4]q; inject it with
+//
+//	%[1]s *provider.%[1]s `+"`inject:\"name=%[4]s\"`"+`
+type %[1]s struct {
+	// TODO: Add its dependencies, injected like a service's, e.g.
+	// Service *service.%[3]sService `+"`inject:\"type\"`"+`
+}
+`, titleName, module, strings.Title(module), name)
+	}
+	field := fmt.Sprintf("%[1]s *lifetime.Lazy[*provider.%[1]s] `inject:\"name=%[2]s\"`", titleName, name)
+	if lifetime == "scoped" {
+		field = fmt.Sprintf("%[1]s *lifetime.Scoped[*provider.%[1]s] `inject:\"name=%[2]s\"`", titleName, name)
+		return fmt.Sprintf(`package provider
+
+import (
+	"%[1]s/app/lifetime"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// %[2]s is request-scoped state of the %[3]s module: built on the first Get of a
+// request and shared by everything handling that request. It is bound as %[4]q
+// in %[5]sModule.Register; inject it with
+//
+//	%[6]s
+//
+// and call Get(ctx) in handlers.
+type %[2]s struct {
+	// TODO: Add the per-request state
+}
+
+// %[2]sProvider builds a %[2]s per request and releases it when the request ends.
+// Releasing needs the lifetime.Scope() middleware.
+var %[2]sProvider = lifetime.NewScoped(%[4]q, func(c *fiber.Ctx) (*%[2]s, error) {
+	// TODO: Build it from the request
+	return &%[2]s{}, nil
+}, func(value *%[2]s, failed bool) error {
+	// TODO: Release it, e.g. commit when the request succeeded and roll back when it failed
+	return nil
+})
+`, getModuleName(), titleName, module, name, strings.Title(module), field)
+	}
+	return fmt.Sprintf(`package provider
+
+import "%[1]s/app/lifetime"
+
+// %[2]s is a lazy singleton of the %[3]s module: built on the first Get, then
+// shared for the app's lifetime. It is bound as %[4]q in %[5]sModule.Register;
+// inject it with
+//
+//	%[6]s
+type %[2]s struct {
+	// TODO: Add what is costly to build or not always needed, e.g. an API client
+}
+
+// %[2]sProvider builds the %[2]s once. A failed build is retried on the next Get.
+var %[2]sProvider = lifetime.NewLazy(func() (*%[2]s, error) {
+	// TODO: Build it, e.g. read its configuration and connect
+	return &%[2]s{}, nil
+})
+`, getModuleName(), titleName, module, name, strings.Title(module), field)
+}
+
+// bindProvider binds the provider in the module's Register method, or prints the
+// binding when the module has no module.go. A singleton is built there too and
+// registered as a component, so its own dependencies are injected.
+func bindProvider(module, titleName, name, lifetime string) error {
+	moduleFile := filepath.Join("app", module, "module.go")
+	stmts := []string{fmt.Sprintf("container.Bind(%q, provider.%sProvider)", name, titleName)}
+	if lifetime == "singleton" {
+		stmts = []string{
+			fmt.Sprintf("%s := &provider.%s{}", name, titleName),
+			fmt.Sprintf("container.Bind(%q, %s)", name, name),
+			fmt.Sprintf("app.RegisterModuleComponents(container, %s)", name),
+		}
+	}
+	if _, err := os.Stat(moduleFile); os.IsNotExist(err) {
+		fmt.Printf("app/%s has no module.go. Bind the provider at startup:\n  %s\n", module, strings.Join(stmts, "\n  "))
+		return nil
+	}
+	return editGenerated(moduleFile, func(src []byte) ([]byte, error) {
+		out := src
+		for _, stmt := range stmts {
+			var err error
+			if out, err = codemod.AppendStatement(out, "Register", stmt); err != nil {
+				return nil, err
+			}
+		}
+		return codemod.AddImport(out, fmt.Sprintf("%s/app/%s/provider", getModuleName(), module))
+	})
+}
+
+var providerCmd = &cobra.Command{
+	Use:   "provider [name] [in_module]",
+	Short: "Generate an injectable component, lazy singleton or request-scoped provider bound in the module's Register method",
+	Long: `Generates app/<module>/provider/<name>Provider.go and binds it in the module's
+Register method under <module><Name>, e.g. usersReportClient.
+
+--lifetime singleton (the default) generates a plain struct for components that
+aren't controllers, services or repositories. Register builds it, binds it and
+registers it with the module's components, so its fields are injected like a
+service's.
+
+--lifetime lazy builds the value on first use and shares it for the app's
+lifetime. --lifetime scoped builds one per request and releases it when the
+request ends, through the lifetime.Scope() middleware, which is added to the
+bootstrap middleware chain if the project has one. Both use the helpers in
+app/lifetime, created if missing.
+
+app/lifetime also provides lifetime.Tx(db), a transaction per request committed
+when the request succeeds, and lifetime.BagOf(ctx), a bag of per-request values.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		if providerLifetime != "singleton" && providerLifetime != "lazy" && providerLifetime != "scoped" {
+			fmt.Printf("Invalid --lifetime %q: expected singleton, lazy or scoped\n", providerLifetime)
+			return
+		}
+		titleName := proto.GoName(name)
+		bound := componentProvider(module, name, "")
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+		providerFile := filepath.Join("app", module, "provider", fmt.Sprintf("%sProvider.go", name))
+		files := []codegen.File{{Path: providerFile, Content: providerContent(module, titleName, bound, providerLifetime)}}
+		if providerLifetime != "singleton" {
+			files = append(files, missingFile(codegen.File{Path: lifetimeFile, Content: lifetimeTemplate})...)
+		}
+		if !writeGenerated(files...) {
+			return
+		}
+		if err := bindProvider(module, titleName, bound, providerLifetime); err != nil {
+			fmt.Printf("Error binding the provider in app/%s/module.go: %v\n", module, err)
+			return
+		}
+		fmt.Printf("Provider '%s' (%s) created in app/%s/provider and bound as %q\n", titleName, providerLifetime, module, bound)
+		if providerLifetime == "scoped" {
+			if _, err := os.Stat(middlewareChainFile); err == nil {
+				if err := addGlobalMiddleware(getModuleName()+"/app/lifetime", "lifetime.Scope()"); err != nil {
+					fmt.Printf("Error wiring middleware into %s: %v\n", middlewareChainFile, err)
+					return
+				}
+			} else {
+				fmt.Println("Register the middleware that releases request-scoped values before the routes:\n  server.Use(lifetime.Scope())")
+			}
+		}
+		openIfRequested(providerFile)
+	},
+}
+
+func init() {
+	providerCmd.Flags().StringVar(&providerLifetime, "lifetime", "singleton", "How long the value lives: singleton (built in Register, shared), lazy (built on first use, shared) or scoped (one per request)")
+	generateCmd.AddCommand(providerCmd)
+	gCmd.AddCommand(providerCmd)
+}
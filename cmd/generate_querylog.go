@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+const dbQueryLogTemplate = `package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// QueryLogEnabled reports whether queries are timed, set with DB_QUERY_LOG=true.
+// 'gonext start' enables it in development.
+func QueryLogEnabled() bool {
+	on, _ := strconv.ParseBool(os.Getenv("DB_QUERY_LOG"))
+	return on
+}
+
+// slowQuery is the duration above which a query is logged on its own, DB_SLOW_QUERY (default 100ms)
+var slowQuery = envDuration("DB_SLOW_QUERY", 100*time.Millisecond)
+
+// repeatedQuery is how often one statement may run in a request before it is
+// reported as a likely N+1, DB_QUERY_REPEAT (default 5)
+var repeatedQuery = envInt("DB_QUERY_REPEAT", 5)
+
+// Query is a statement run with a context from WithQueryStats
+type Query struct {
+	SQL      string
+	Duration time.Duration
+}
+
+// QueryStats collects the queries run with a context from WithQueryStats
+type QueryStats struct {
+	mu      sync.Mutex
+	queries []Query
+}
+
+type queryStatsKey struct{}
+
+// WithQueryStats returns a context that records every query run with it.
+// Pass it (e.g. c.UserContext()) to QueryContext/ExecContext.
+func WithQueryStats(ctx context.Context) (context.Context, *QueryStats) {
+	stats := &QueryStats{}
+	return context.WithValue(ctx, queryStatsKey{}, stats), stats
+}
+
+// Queries returns the recorded queries in the order they ran
+func (s *QueryStats) Queries() []Query {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Query(nil), s.queries...)
+}
+
+// Summary describes the recorded queries: count, total time, the slowest
+// statements and those repeated often enough to suggest an N+1. It is empty
+// when no query ran.
+func (s *QueryStats) Summary() string {
+	queries := s.Queries()
+	if len(queries) == 0 {
+		return ""
+	}
+	var total time.Duration
+	counts := map[string]int{}
+	for _, q := range queries {
+		total += q.Duration
+		counts[q.SQL]++
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d queries in %s", len(queries), total.Round(time.Microsecond))
+	sort.SliceStable(queries, func(i, j int) bool { return queries[i].Duration > queries[j].Duration })
+	if len(queries) > 3 {
+		queries = queries[:3]
+	}
+	for _, q := range queries {
+		fmt.Fprintf(&b, "\n    %8s  %s", q.Duration.Round(time.Microsecond), compactSQL(q.SQL))
+	}
+	for sql, n := range counts {
+		if n >= repeatedQuery {
+			fmt.Fprintf(&b, "\n    possible N+1, ran %d times: %s", n, compactSQL(sql))
+		}
+	}
+	return b.String()
+}
+
+// compactSQL puts a statement on one line for logs
+func compactSQL(sql string) string {
+	sql = strings.Join(strings.Fields(sql), " ")
+	if len(sql) > 200 {
+		sql = sql[:200] + "..."
+	}
+	return sql
+}
+
+// record logs slow queries and adds the query to the context's stats
+func record(ctx context.Context, query string, d time.Duration) {
+	if d >= slowQuery {
+		log.Printf("slow query (%s): %s", d.Round(time.Microsecond), compactSQL(query))
+	}
+	if stats, ok := ctx.Value(queryStatsKey{}).(*QueryStats); ok {
+		stats.mu.Lock()
+		stats.queries = append(stats.queries, Query{SQL: query, Duration: d})
+		stats.mu.Unlock()
+	}
+}
+
+// connector returns the pgx connector, timing every query when the query log is enabled
+func connector(dsn string) driver.Connector {
+	var c driver.Connector = dsnConnector{dsn: dsn}
+	if QueryLogEnabled() {
+		c = logConnector{c}
+	}
+	return c
+}
+
+type dsnConnector struct{ dsn string }
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return stdlib.GetDefaultDriver().Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver { return stdlib.GetDefaultDriver() }
+
+type logConnector struct{ driver.Connector }
+
+func (c logConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return logConn{conn}, nil
+}
+
+// logConn times queries and forwards everything else to the pgx connection
+type logConn struct{ driver.Conn }
+
+func (c logConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	record(ctx, query, time.Since(start))
+	return rows, err
+}
+
+func (c logConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := e.ExecContext(ctx, query, args)
+	record(ctx, query, time.Since(start))
+	return result, err
+}
+
+func (c logConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c logConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c logConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c logConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c logConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c logConn) CheckNamedValue(v *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(v)
+	}
+	return driver.ErrSkip
+}
+`
+
+// queryLogFile holds the Fiber middleware reporting the queries of each request
+var queryLogFile = filepath.Join("app", "querylog", "querylog.go")
+
+const queryLogMiddlewareTemplate = `package querylog
+
+import (
+	"log"
+
+	"%s/app/database"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Middleware logs how many queries each request ran, the slowest ones and
+// statements repeated often enough to suggest an N+1. It does nothing unless
+// DB_QUERY_LOG is enabled. Handlers must query with c.UserContext().
+func Middleware() fiber.Handler {
+	if !database.QueryLogEnabled() {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	return func(c *fiber.Ctx) error {
+		ctx, stats := database.WithQueryStats(c.UserContext())
+		c.SetUserContext(ctx)
+		err := c.Next()
+		if summary := stats.Summary(); summary != "" {
+			log.Printf("%%s %%s: %%s", c.Method(), c.OriginalURL(), summary)
+		}
+		return err
+	}
+}
+`
+
+var queryLogCmd = &cobra.Command{
+	Use:   "db:querylog",
+	Short: "Generate a middleware that reports each request's query count, slowest queries and likely N+1s in development",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		files := append(missingDatabaseFiles(), codegen.File{Path: queryLogFile, Content: fmt.Sprintf(queryLogMiddlewareTemplate, getModuleName())})
+		if !writeGenerated(files...) {
+			return
+		}
+		if provider, err := os.ReadFile(filepath.Join(databaseDir, "provider.go")); err == nil && !strings.Contains(string(provider), "connector(cfg.URL)") {
+			fmt.Println("Your app/database/provider.go predates the query log. Re-run 'gonext g db:provider' to time queries.")
+		}
+		if _, err := os.Stat(middlewareChainFile); err == nil {
+			if err := addGlobalMiddleware(getModuleName()+"/app/querylog", "querylog.Middleware()"); err != nil {
+				fmt.Printf("Error wiring middleware into %s: %v\n", middlewareChainFile, err)
+				return
+			}
+			fmt.Printf("querylog.Middleware() added to %s\n", middlewareChainFile)
+		} else {
+			fmt.Println("Register the middleware with server.Use(querylog.Middleware()) to report the queries of each request.")
+		}
+		fmt.Println("'gonext start' sets DB_QUERY_LOG=true. Pass c.UserContext() to QueryContext/ExecContext so queries are attributed to their request.")
+		openIfRequested(queryLogFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(queryLogCmd)
+	gCmd.AddCommand(queryLogCmd)
+}
@@ -0,0 +1,453 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// repositoryOptions are the variations supported by the repository template
+type repositoryOptions struct {
+	Connection string // Named database connection from the connection manager (--connection)
+	Versioned  bool   // Optimistic locking on the entity's Version column (--versioned)
+	Bulk       bool   // Chunked, transactional batch create/update/delete (--bulk)
+	Pagination string // List method paginated by offset or cursor (--pagination)
+	Filtered   bool   // List method narrowed by allow-listed query filters (--filter)
+	IDType     string // Type of the entity's ID, string by default (--id)
+}
+
+// repositoryContent renders a repository with CRUD stubs for the given options
+func repositoryContent(module, name, titleName string, opts repositoryOptions) string {
+	moduleName := getModuleName()
+	idType := opts.IDType
+	if idType == "" {
+		idType = "string"
+	}
+	var std, local []string
+	var consts, constructor string
+	fields := "{}"
+	list := opts.Pagination != ""
+	if opts.Connection != "" || opts.Versioned || opts.Bulk || list {
+		std = append(std, `"database/sql"`)
+		fields = fmt.Sprintf(" {\n\tDB *sql.DB `inject:\"name=%s\"`\n}", connectionProvider(opts.Connection))
+	}
+	if opts.Versioned || opts.Bulk || list {
+		std = append(std, `"context"`)
+		local = append(local, fmt.Sprintf(`"%s/app/%s/entity"`, moduleName, module))
+	}
+	if opts.Versioned {
+		local = append(local, fmt.Sprintf(`"%s/app/exception"`, moduleName))
+	}
+	if strings.HasPrefix(idType, "id.") {
+		local = append(local, fmt.Sprintf(`"%s/app/id"`, moduleName))
+	}
+	if idType == "int64" && (opts.Versioned || opts.Pagination == "cursor") {
+		std = append(std, `"strconv"`)
+	}
+	var extra string
+	if opts.Bulk {
+		std = append(std, `"fmt"`, `"strings"`)
+		consts += fmt.Sprintf(`// %[1]sBulkChunkSize bounds the rows sent per statement in bulk operations
+const %[1]sBulkChunkSize = 500
+
+`, titleName)
+		extra = repositoryBulkMethods(name, titleName, opts.Versioned, idType)
+	}
+	if list {
+		local = append(local, fmt.Sprintf(`"%s/app/pagination"`, moduleName))
+		if opts.Filtered {
+			local = append(local, fmt.Sprintf(`"%s/app/filter"`, moduleName))
+			if !opts.Bulk {
+				std = append(std, `"fmt"`, `"strings"`)
+			}
+			extra += repositoryFilteredListMethod(name, titleName, opts.Pagination, idType)
+		} else {
+			extra += repositoryListMethod(name, titleName, opts.Pagination, idType)
+		}
+	}
+	if opts.Connection != "" {
+		local = append(local, fmt.Sprintf(`"%s/app/database"`, moduleName))
+		consts += fmt.Sprintf(`// %[1]sConnection is the connection manager entry this repository reads from,
+// configured by DATABASE_%[2]s_URL
+const %[1]sConnection = %[3]q
+
+`, titleName, strings.ToUpper(opts.Connection), opts.Connection)
+		constructor = fmt.Sprintf(`
+// New%[1]sRepository binds the repository to its named connection
+func New%[1]sRepository(connections *database.Manager) (*%[1]sRepository, error) {
+	db, err := connections.Get(%[1]sConnection)
+	if err != nil {
+		return nil, err
+	}
+	return &%[1]sRepository{DB: db}, nil
+}
+`, titleName)
+	}
+
+	update := fmt.Sprintf(`// Update%[1]s updates a %[1]s by ID
+func (r *%[1]sRepository) Update%[1]s(id %[2]s, data interface{}) error {
+	// TODO: Implement update logic
+	return nil
+}
+`, titleName, idType)
+	if opts.Versioned {
+		update = fmt.Sprintf(`// Update%[1]s saves e only if its Version still matches the stored row, then bumps it.
+// A stale version returns *exception.ConflictError, which the exception filter maps to 409.
+func (r *%[1]sRepository) Update%[1]s(ctx context.Context, e *entity.%[1]s) error {
+	// TODO: Set the updated columns alongside the version bump
+	res, err := r.DB.ExecContext(ctx,
+		"UPDATE %[2]ss SET version = version + 1, updated_at = now() WHERE id = $1 AND version = $2",
+		e.ID, e.Version)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return &exception.ConflictError{Resource: %[3]q, ID: %[4]s, Version: e.Version}
+	}
+	e.Version++
+	return nil
+}
+`, titleName, strings.ToLower(name), titleName, idString(idType, "e.ID"))
+	}
+
+	createTODO := "Implement create logic"
+	if call := newIDCall(idType); call != "" {
+		createTODO += ", assigning the ID with " + call
+	} else if idType == "int64" {
+		createTODO += ", reading the ID the database assigns with INSERT ... RETURNING id"
+	}
+	header := importBlock(std, local)
+	return fmt.Sprintf(`package repository
+
+%[2]s%[3]stype %[1]sRepository struct%[4]s
+%[5]s
+// Create%[1]s persists a new %[1]s
+func (r *%[1]sRepository) Create%[1]s(data interface{}) error {
+	// TODO: %[9]s
+	return nil
+}
+
+// Get%[1]s retrieves a %[1]s by ID
+func (r *%[1]sRepository) Get%[1]s(id %[8]s) (interface{}, error) {
+	// TODO: Implement get logic
+	return nil, nil
+}
+
+%[6]s
+// Delete%[1]s deletes a %[1]s by ID
+func (r *%[1]sRepository) Delete%[1]s(id %[8]s) error {
+	// TODO: Implement delete logic
+	return nil
+}
+%[7]s`, titleName, header, consts, fields, constructor, update, extra, idType, createTODO)
+}
+
+// repositoryBulkMethods renders batch create/update/delete methods that write in
+// chunks of <Name>BulkChunkSize inside a single transaction
+func repositoryBulkMethods(name, titleName string, versioned bool, idType string) string {
+	update := fmt.Sprintf(`// BulkUpdate%[1]s updates items inside one transaction, rolling back if any update fails
+func (r *%[1]sRepository) BulkUpdate%[1]s(ctx context.Context, items []entity.%[1]s) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		for _, item := range items {
+			// TODO: Set the updated columns
+			if _, err := tx.ExecContext(ctx, "UPDATE %[2]ss SET updated_at = now() WHERE id = $1", item.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+`, titleName, strings.ToLower(name))
+	if versioned {
+		update = fmt.Sprintf(`// BulkUpdate%[1]s updates items inside one transaction. A stale Version on any item
+// rolls back the whole batch with *exception.ConflictError.
+func (r *%[1]sRepository) BulkUpdate%[1]s(ctx context.Context, items []entity.%[1]s) error {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		for _, item := range items {
+			// TODO: Set the updated columns alongside the version bump
+			res, err := tx.ExecContext(ctx,
+				"UPDATE %[2]ss SET version = version + 1, updated_at = now() WHERE id = $1 AND version = $2",
+				item.ID, item.Version)
+			if err != nil {
+				return err
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return &exception.ConflictError{Resource: %[1]q, ID: %[3]s, Version: item.Version}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].Version++
+	}
+	return nil
+}
+`, titleName, strings.ToLower(name), idString(idType, "item.ID"))
+	}
+	create := fmt.Sprintf(`// BulkCreate%[1]s inserts items in chunks inside one transaction
+func (r *%[1]sRepository) BulkCreate%[1]s(ctx context.Context, items []entity.%[1]s) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		for start := 0; start < len(items); start += %[1]sBulkChunkSize {
+			chunk := items[start:min(start+%[1]sBulkChunkSize, len(items))]
+			// TODO: Insert the remaining columns
+			values := make([]string, len(chunk))
+			args := make([]any, len(chunk))
+			for i, item := range chunk {
+				values[i] = fmt.Sprintf("($%%d)", i+1)
+				args[i] = item.ID
+			}
+			if _, err := tx.ExecContext(ctx, "INSERT INTO %[2]ss (id) VALUES "+strings.Join(values, ", "), args...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+`, titleName, strings.ToLower(name))
+	if call := newIDCall(idType); call != "" {
+		create = fmt.Sprintf(`// BulkCreate%[1]s inserts items in chunks inside one transaction, assigning
+// a new ID to the items without one
+func (r *%[1]sRepository) BulkCreate%[1]s(ctx context.Context, items []entity.%[1]s) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		for start := 0; start < len(items); start += %[1]sBulkChunkSize {
+			chunk := items[start:min(start+%[1]sBulkChunkSize, len(items))]
+			// TODO: Insert the remaining columns
+			values := make([]string, len(chunk))
+			args := make([]any, len(chunk))
+			for i := range chunk {
+				if chunk[i].ID.IsZero() {
+					chunk[i].ID = %[3]s
+				}
+				values[i] = fmt.Sprintf("($%%d)", i+1)
+				args[i] = chunk[i].ID
+			}
+			if _, err := tx.ExecContext(ctx, "INSERT INTO %[2]ss (id) VALUES "+strings.Join(values, ", "), args...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+`, titleName, strings.ToLower(name), call)
+	} else if idType == "int64" {
+		create = fmt.Sprintf(`// BulkCreate%[1]s inserts items in chunks inside one transaction. The database
+// assigns their IDs; read the rows back to get them.
+func (r *%[1]sRepository) BulkCreate%[1]s(ctx context.Context, items []entity.%[1]s) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		for start := 0; start < len(items); start += %[1]sBulkChunkSize {
+			chunk := items[start:min(start+%[1]sBulkChunkSize, len(items))]
+			// TODO: Insert the columns of the items, e.g. "($1, $2)" with their values as args
+			values := make([]string, len(chunk))
+			for i := range chunk {
+				values[i] = "(DEFAULT)"
+			}
+			if _, err := tx.ExecContext(ctx, "INSERT INTO %[2]ss (id) VALUES "+strings.Join(values, ", ")); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+`, titleName, strings.ToLower(name))
+	}
+	return fmt.Sprintf(`
+%[4]s
+%[3]s
+// BulkDelete%[1]s deletes ids in chunks inside one transaction
+func (r *%[1]sRepository) BulkDelete%[1]s(ctx context.Context, ids []%[5]s) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		for start := 0; start < len(ids); start += %[1]sBulkChunkSize {
+			chunk := ids[start:min(start+%[1]sBulkChunkSize, len(ids))]
+			placeholders := make([]string, len(chunk))
+			args := make([]any, len(chunk))
+			for i, id := range chunk {
+				placeholders[i] = fmt.Sprintf("$%%d", i+1)
+				args[i] = id
+			}
+			if _, err := tx.ExecContext(ctx, "DELETE FROM %[2]ss WHERE id IN ("+strings.Join(placeholders, ", ")+")", args...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// inTx runs fn in a transaction, committing on success and rolling back on error
+func (r *%[1]sRepository) inTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+`, titleName, strings.ToLower(name), update, create, idType)
+}
+
+// importBlock renders a sorted import declaration with the standard library
+// grouped before project and third-party packages
+func importBlock(std, local []string) string {
+	var groups []string
+	for _, group := range [][]string{std, local} {
+		if len(group) == 0 {
+			continue
+		}
+		var sorted []string
+		for _, path := range group {
+			if !slices.Contains(sorted, path) {
+				sorted = append(sorted, path)
+			}
+		}
+		sort.Strings(sorted)
+		groups = append(groups, "\t"+strings.Join(sorted, "\n\t"))
+	}
+	if len(groups) == 0 {
+		return ""
+	}
+	return "import (\n" + strings.Join(groups, "\n\n") + "\n)\n\n"
+}
+
+// repositoryListMethod renders a List method paginated by offset or by keyset
+// cursor. Both order by (created_at, id) so pages are stable.
+func repositoryListMethod(name, titleName, mode, idType string) string {
+	if mode == "cursor" {
+		return fmt.Sprintf(`
+// List%[1]s returns the page of %[1]s records after params.After, ordered by
+// (created_at, id). Keyset pagination stays fast on large tables because it never
+// skips rows; one extra row is fetched to tell whether a next page exists.
+func (r *%[1]sRepository) List%[1]s(ctx context.Context, params pagination.CursorParams) (pagination.CursorPage[entity.%[1]s], error) {
+	page := pagination.CursorPage[entity.%[1]s]{Limit: params.Limit}
+	query := "SELECT id, created_at, updated_at FROM %[2]ss ORDER BY created_at, id LIMIT $1"
+	args := []any{params.Limit + 1}
+	if params.After != nil {
+		query = "SELECT id, created_at, updated_at FROM %[2]ss WHERE (created_at, id) > ($2, $3) ORDER BY created_at, id LIMIT $1"
+		args = append(args, params.After.SortKey, params.After.ID)
+	}
+	items, err := r.query(ctx, query, args...)
+	if err != nil {
+		return page, err
+	}
+	if len(items) > params.Limit {
+		items = items[:params.Limit]
+		last := items[len(items)-1]
+		page.NextCursor = pagination.Cursor{SortKey: last.CreatedAt, ID: %[4]s}.Encode()
+	}
+	page.Items = items
+	return page, nil
+}
+%[3]s`, titleName, strings.ToLower(name), repositoryQueryMethod(titleName), idString(idType, "last.ID"))
+	}
+	return fmt.Sprintf(`
+// List%[1]s returns a numbered page of %[1]s records ordered by (created_at, id)
+func (r *%[1]sRepository) List%[1]s(ctx context.Context, params pagination.OffsetParams) (pagination.OffsetPage[entity.%[1]s], error) {
+	page := pagination.OffsetPage[entity.%[1]s]{Page: params.Page, Limit: params.Limit}
+	if err := r.DB.QueryRowContext(ctx, "SELECT count(*) FROM %[2]ss").Scan(&page.Total); err != nil {
+		return page, err
+	}
+	items, err := r.query(ctx, "SELECT id, created_at, updated_at FROM %[2]ss ORDER BY created_at, id LIMIT $1 OFFSET $2", params.Limit, params.Offset())
+	if err != nil {
+		return page, err
+	}
+	page.Items = items
+	return page, nil
+}
+%[3]s`, titleName, strings.ToLower(name), repositoryQueryMethod(titleName))
+}
+
+// repositoryFilteredListMethod renders a List method like repositoryListMethod
+// whose results are narrowed by the predicates parsed from allow-listed query filters
+func repositoryFilteredListMethod(name, titleName, mode, idType string) string {
+	if mode == "cursor" {
+		return fmt.Sprintf(`
+// List%[1]s returns the page of %[1]s records matching filters after params.After,
+// ordered by (created_at, id). Keyset pagination stays fast on large tables because
+// it never skips rows; one extra row is fetched to tell whether a next page exists.
+func (r *%[1]sRepository) List%[1]s(ctx context.Context, params pagination.CursorParams, filters []filter.Predicate) (pagination.CursorPage[entity.%[1]s], error) {
+	page := pagination.CursorPage[entity.%[1]s]{Limit: params.Limit}
+	where, args := filter.Where(filters, nil)
+	if params.After != nil {
+		args = append(args, params.After.SortKey, params.After.ID)
+		where = append(where, fmt.Sprintf("(created_at, id) > ($%%d, $%%d)", len(args)-1, len(args)))
+	}
+	query := "SELECT id, created_at, updated_at FROM %[2]ss"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, params.Limit+1)
+	query += fmt.Sprintf(" ORDER BY created_at, id LIMIT $%%d", len(args))
+	items, err := r.query(ctx, query, args...)
+	if err != nil {
+		return page, err
+	}
+	if len(items) > params.Limit {
+		items = items[:params.Limit]
+		last := items[len(items)-1]
+		page.NextCursor = pagination.Cursor{SortKey: last.CreatedAt, ID: %[4]s}.Encode()
+	}
+	page.Items = items
+	return page, nil
+}
+%[3]s`, titleName, strings.ToLower(name), repositoryQueryMethod(titleName), idString(idType, "last.ID"))
+	}
+	return fmt.Sprintf(`
+// List%[1]s returns a numbered page of %[1]s records matching filters, ordered by (created_at, id)
+func (r *%[1]sRepository) List%[1]s(ctx context.Context, params pagination.OffsetParams, filters []filter.Predicate) (pagination.OffsetPage[entity.%[1]s], error) {
+	page := pagination.OffsetPage[entity.%[1]s]{Page: params.Page, Limit: params.Limit}
+	where, args := filter.Where(filters, nil)
+	conditions := ""
+	if len(where) > 0 {
+		conditions = " WHERE " + strings.Join(where, " AND ")
+	}
+	if err := r.DB.QueryRowContext(ctx, "SELECT count(*) FROM %[2]ss"+conditions, args...).Scan(&page.Total); err != nil {
+		return page, err
+	}
+	args = append(args, params.Limit, params.Offset())
+	query := fmt.Sprintf("SELECT id, created_at, updated_at FROM %[2]ss%%s ORDER BY created_at, id LIMIT $%%d OFFSET $%%d", conditions, len(args)-1, len(args))
+	items, err := r.query(ctx, query, args...)
+	if err != nil {
+		return page, err
+	}
+	page.Items = items
+	return page, nil
+}
+%[3]s`, titleName, strings.ToLower(name), repositoryQueryMethod(titleName))
+}
+
+// repositoryQueryMethod renders the row scanner shared by the list methods
+func repositoryQueryMethod(titleName string) string {
+	return fmt.Sprintf(`
+// query runs a select and scans every row into an entity
+func (r *%[1]sRepository) query(ctx context.Context, query string, args ...any) ([]entity.%[1]s, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []entity.%[1]s
+	for rows.Next() {
+		var item entity.%[1]s
+		// TODO: Scan the remaining columns
+		if err := rows.Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+`, titleName)
+}
@@ -0,0 +1,435 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// graphqlDir is the project-level GraphQL server module that mounts the resolvers of every module
+var graphqlDir = filepath.Join("app", "graphql")
+
+// gqlgenConfigFile configures gqlgen for the module layout
+const gqlgenConfigFile = "gqlgen.yml"
+
+// gqlgenConfigTemplate collects the schemas of every module and binds their
+// GraphQL types to the Go types next to them
+const gqlgenConfigTemplate = `# gqlgen configuration. Regenerate with: go run github.com/99designs/gqlgen generate
+schema:
+  - app/graphql/*.graphqls
+  - app/*/graph/*.graphqls
+
+exec:
+  filename: app/graphql/generated/generated.go
+  package: generated
+
+model:
+  filename: app/graphql/model/models_gen.go
+  package: model
+
+# One <schema>.resolvers.go per schema file. Implementations are kept when regenerating.
+resolver:
+  layout: follow-schema
+  dir: app/graphql
+  package: graphql
+  filename_template: "{name}.resolvers.go"
+
+# GraphQL types are bound to the Go types of the same name in these packages.
+# 'gonext g resolver' adds each module's graph package.
+autobind: []
+`
+
+const graphqlSchemaTemplate = `# Root types. Modules add their fields with 'extend type Query' in app/<module>/graph.
+type Query {
+  health: String!
+}
+`
+
+const graphqlSchemaResolversTemplate = `package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"%s/app/graphql/generated"
+)
+
+// Health is the resolver for the health field.
+func (r *queryResolver) Health(ctx context.Context) (string, error) {
+	return "ok", nil
+}
+
+// Query returns generated.QueryResolver implementation.
+func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
+
+type queryResolver struct{ *Resolver }
+`
+
+const graphqlResolverTemplate = `package graphql
+
+// Resolver is the root resolver. It holds the resolvers of the modules, injected
+// by type; the methods gqlgen generates in *.resolvers.go delegate to them.
+// 'gonext g resolver' adds a field per module resolver.
+type Resolver struct {
+}
+`
+
+const graphqlModuleTemplate = `package graphql
+
+import (
+	"fmt"
+	"os"
+
+	"%[1]s/app"
+	"%[1]s/app/graphql/generated"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+// GraphqlModule serves the GraphQL API built from the schemas of every module
+type GraphqlModule struct {
+	Resolver *Resolver
+}
+
+func NewGraphqlModule() *GraphqlModule {
+	return &GraphqlModule{}
+}
+
+// Called when a module is initialized.
+func (m *GraphqlModule) OnModuleInit() error {
+	fmt.Println("GraphqlModule initialized!")
+	return nil
+}
+
+// Called when a module is destroyed.
+func (m *GraphqlModule) OnModuleDestroy() error {
+	fmt.Println("GraphqlModule destroyed!")
+	return nil
+}
+
+// DependsOn lists the modules whose resolvers the root resolver injects.
+// 'gonext g resolver' adds them.
+func (m *GraphqlModule) DependsOn() []string {
+	return []string{}
+}
+
+// Register binds the root resolver, a singleton; its fields are injected with the
+// module resolvers.
+func (m *GraphqlModule) Register(container *app.Container) {
+	m.Resolver = &Resolver{}
+	app.RegisterModuleComponents(container, m.Resolver)
+}
+
+// MountRoutes serves the API at /graphql, and the GraphQL playground at
+// /graphql/playground outside production
+func (m *GraphqlModule) MountRoutes(router fiber.Router) {
+	server := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: m.Resolver}))
+	server.AddTransport(transport.GET{})
+	server.AddTransport(transport.POST{})
+	server.Use(extension.Introspection{})
+	router.All("/graphql", adaptor.HTTPHandler(server))
+	if os.Getenv("APP_ENV") != "production" {
+		router.Get("/graphql/playground", adaptor.HTTPHandlerFunc(playground.Handler("GraphQL", "/graphql")))
+	}
+}
+`
+
+// graphqlServerFiles returns the shared GraphQL server files the project does not have yet
+func graphqlServerFiles() []codegen.File {
+	var files []codegen.File
+	for _, f := range []codegen.File{
+		{Path: gqlgenConfigFile, Content: gqlgenConfigTemplate},
+		{Path: filepath.Join(graphqlDir, "schema.graphqls"), Content: graphqlSchemaTemplate},
+		{Path: filepath.Join(graphqlDir, "schema.resolvers.go"), Content: fmt.Sprintf(graphqlSchemaResolversTemplate, getModuleName())},
+		{Path: filepath.Join(graphqlDir, "resolver.go"), Content: graphqlResolverTemplate},
+		{Path: filepath.Join(graphqlDir, "module.go"), Content: fmt.Sprintf(graphqlModuleTemplate, getModuleName())},
+	} {
+		files = append(files, missingFile(f)...)
+	}
+	return files
+}
+
+// graphqlNames are the names a resolver is generated under
+type graphqlNames struct {
+	Type   string // GraphQL and Go type, e.g. Order
+	One    string // query field returning one, e.g. order
+	Many   string // query field returning the list, e.g. orders
+	Field  string // field of the root resolver, e.g. OrdersOrderResolver
+	Import string // import name of the module's resolver package, e.g. ordersresolver
+}
+
+func newGraphqlNames(module, name string) graphqlNames {
+	typeName := proto.GoName(name)
+	one := strings.ToLower(typeName[:1]) + typeName[1:]
+	return graphqlNames{
+		Type:   typeName,
+		One:    one,
+		Many:   one + "s",
+		Field:  strings.Title(componentProvider(module, name, "Resolver")),
+		Import: strings.ToLower(module) + "resolver",
+	}
+}
+
+// graphqlSchemaContent renders the schema stub of a module type
+func graphqlSchemaContent(n graphqlNames) string {
+	return fmt.Sprintf(`# Bound to %[1]s in graph/%[2]s.go; keep their fields in step.
+type %[1]s {
+  id: ID!
+  # TODO: Add fields
+}
+
+extend type Query {
+  %[2]s(id: ID!): %[1]s
+  %[3]s: [%[1]s!]!
+}
+`, n.Type, n.One, n.Many)
+}
+
+// graphqlModelContent renders the Go type gqlgen binds the schema type to
+func graphqlModelContent(n graphqlNames) string {
+	return fmt.Sprintf(`package graph
+
+// %[1]s is the Go type of the GraphQL type %[1]s, bound through autobind in gqlgen.yml
+type %[1]s struct {
+	ID string `+"`json:\"id\"`"+`
+}
+`, n.Type)
+}
+
+// resolverContent renders a module resolver with the queries of the schema stub
+func resolverContent(module string, n graphqlNames) string {
+	return fmt.Sprintf(`package resolver
+
+import (
+	"context"
+
+	"%[1]s/app/%[2]s/graph"
+)
+
+// %[3]sResolver resolves the %[3]s queries of app/%[2]s/graph/%[4]s.graphqls. It is
+// registered in %[6]sModule.Register and injected into the root resolver of app/graphql.
+type %[3]sResolver struct {
+	// TODO: Inject what the queries need, e.g. the module's service
+}
+
+// %[3]s returns the %[3]s with the given ID, or nil if there is none
+func (r *%[3]sResolver) %[3]s(ctx context.Context, id string) (*graph.%[3]s, error) {
+	// TODO: Load it
+	return nil, nil
+}
+
+// %[5]s returns every %[3]s
+func (r *%[3]sResolver) %[5]s(ctx context.Context) ([]*graph.%[3]s, error) {
+	// TODO: Load them
+	return []*graph.%[3]s{}, nil
+}
+`, getModuleName(), module, n.Type, n.One, strings.Title(n.Many), strings.Title(module))
+}
+
+// schemaResolversContent renders the gqlgen resolvers of a schema file, delegating
+// to the module resolver. gqlgen keeps the bodies when it regenerates the file.
+func schemaResolversContent(module string, n graphqlNames) string {
+	return fmt.Sprintf(`package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"%[1]s/app/%[2]s/graph"
+)
+
+// %[3]s is the resolver for the %[4]s field.
+func (r *queryResolver) %[3]s(ctx context.Context, id string) (*graph.%[3]s, error) {
+	return r.%[5]s.%[3]s(ctx, id)
+}
+
+// %[6]s is the resolver for the %[7]s field.
+func (r *queryResolver) %[6]s(ctx context.Context) ([]*graph.%[3]s, error) {
+	return r.%[5]s.%[6]s(ctx)
+}
+`, getModuleName(), module, n.Type, n.One, n.Field, strings.Title(n.Many), n.Many)
+}
+
+// addAutobind adds a package to the autobind list of gqlgen.yml. A block list, or
+// the empty list of the template, is edited in place to keep the file's layout.
+func addAutobind(pkg string) error {
+	return editGenerated(gqlgenConfigFile, func(src []byte) ([]byte, error) {
+		lines := strings.Split(string(src), "\n")
+		for i, line := range lines {
+			if line != "autobind:" && line != "autobind: []" {
+				continue
+			}
+			last, indent := i, "  "
+			for j := i + 1; j < len(lines); j++ {
+				item := strings.TrimLeft(lines[j], " ")
+				if !strings.HasPrefix(item, "- ") {
+					break
+				}
+				if strings.Trim(strings.TrimPrefix(item, "- "), `"'`) == pkg {
+					return src, nil
+				}
+				last, indent = j, lines[j][:len(lines[j])-len(item)]
+			}
+			lines[i] = "autobind:"
+			lines = append(lines[:last+1], append([]string{indent + "- " + pkg}, lines[last+1:]...)...)
+			return []byte(strings.Join(lines, "\n")), nil
+		}
+		return addAutobindNode(src, pkg)
+	})
+}
+
+// addAutobindNode adds a package to an autobind list written in another style, or
+// adds the list, re-encoding the file
+func addAutobindNode(src []byte, pkg string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping")
+	}
+	root := doc.Content[0]
+	var list *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "autobind" {
+			list = root.Content[i+1]
+		}
+	}
+	if list == nil {
+		list = &yaml.Node{Kind: yaml.SequenceNode}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "autobind"}, list)
+	}
+	for _, item := range list.Content {
+		if item.Value == pkg {
+			return src, nil
+		}
+	}
+	list.Style = 0
+	list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: pkg})
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), enc.Close()
+}
+
+// wireResolver injects the module resolver into the root resolver, makes the
+// GraphQL module depend on the module and registers the resolver in the module
+func wireResolver(module string, n graphqlNames) error {
+	resolverPkg := fmt.Sprintf("%s/app/%s/resolver", getModuleName(), module)
+	err := editGenerated(filepath.Join(graphqlDir, "resolver.go"), func(src []byte) ([]byte, error) {
+		out, err := codemod.AddNamedImport(src, n.Import, resolverPkg)
+		if err != nil {
+			return nil, err
+		}
+		return codemod.AddStructField(out, "Resolver", n.Field, fmt.Sprintf("%s *%s.%sResolver `inject:\"type\"`", n.Field, n.Import, n.Type))
+	})
+	if err != nil {
+		return err
+	}
+	if err := editGenerated(filepath.Join(graphqlDir, "module.go"), func(src []byte) ([]byte, error) {
+		return codemod.AppendToSlice(src, "DependsOn", fmt.Sprintf("%q", module))
+	}); err != nil {
+		return err
+	}
+	moduleFile := filepath.Join("app", module, "module.go")
+	register := fmt.Sprintf("app.RegisterModuleComponents(container, &resolver.%sResolver{})", n.Type)
+	if _, err := os.Stat(moduleFile); os.IsNotExist(err) {
+		fmt.Printf("app/%s has no module.go. Register the resolver at startup:\n  %s\n", module, register)
+		return nil
+	}
+	return editGenerated(moduleFile, func(src []byte) ([]byte, error) {
+		out, err := codemod.AppendStatement(src, "Register", register)
+		if err != nil {
+			return nil, err
+		}
+		return codemod.AddImport(out, resolverPkg)
+	})
+}
+
+var resolverCmd = &cobra.Command{
+	Use:   "resolver [name] [in_module]",
+	Short: "Generate a gqlgen resolver and schema stub served by the project's GraphQL module",
+	Long: `Generates, for a GraphQL type named after [name]:
+
+  app/<module>/graph/<name>.graphqls        the type and its queries (extend type Query)
+  app/<module>/graph/<name>.go              the Go type gqlgen binds it to
+  app/<module>/resolver/<name>Resolver.go   the module resolver, registered in module.go
+
+The project-level GraphQL module in app/graphql is created if missing, with
+gqlgen.yml. It serves /graphql (and /graphql/playground outside production) and
+its root resolver injects each module resolver; app/graphql/<name>.resolvers.go
+delegates the queries to it. Generate the executable schema afterwards:
+
+  go run github.com/99designs/gqlgen generate
+
+Schema file names must be unique across modules: gqlgen names resolver files after them.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		n := newGraphqlNames(module, name)
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+		schemaFile := filepath.Join("app", module, "graph", name+".graphqls")
+		resolverFile := filepath.Join("app", module, "resolver", fmt.Sprintf("%sResolver.go", name))
+		delegateFile := filepath.Join(graphqlDir, name+".resolvers.go")
+		if src, err := os.ReadFile(delegateFile); err == nil && !strings.Contains(string(src), fmt.Sprintf("/app/%s/graph\"", module)) {
+			fmt.Printf("%s already resolves another module's %s.graphqls. Pick a name that is unique across modules.\n", delegateFile, name)
+			return
+		}
+		_, statErr := os.Stat(filepath.Join(graphqlDir, "module.go"))
+		files := []codegen.File{
+			{Path: schemaFile, Content: graphqlSchemaContent(n)},
+			{Path: filepath.Join("app", module, "graph", name+".go"), Content: graphqlModelContent(n)},
+			{Path: resolverFile, Content: resolverContent(module, n)},
+		}
+		files = append(files, graphqlServerFiles()...)
+		files = append(files, missingFile(codegen.File{Path: delegateFile, Content: schemaResolversContent(module, n)})...)
+		if !writeGenerated(files...) {
+			return
+		}
+		if err := addAutobind(fmt.Sprintf("%s/app/%s/graph", getModuleName(), module)); err != nil {
+			fmt.Printf("Error adding the graph package to %s: %v\n", gqlgenConfigFile, err)
+			return
+		}
+		if err := wireResolver(module, n); err != nil {
+			fmt.Printf("Error wiring the resolver: %v\n", err)
+			return
+		}
+		if os.IsNotExist(statErr) {
+			if err := registerModule("graphql", true); err != nil {
+				fmt.Printf("Error adding module to %s: %v\n", moduleRegistryFile, err)
+				return
+			}
+		}
+		fmt.Printf("Resolver '%s' created in app/%s/resolver, with its schema in %s. Generate the executable schema with:\n  go run github.com/99designs/gqlgen generate\n", n.Type, module, schemaFile)
+		openIfRequested(schemaFile, resolverFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(resolverCmd)
+	gCmd.AddCommand(resolverCmd)
+}
@@ -0,0 +1,411 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+// resourceCRUD is set by `g resource --crud`
+var resourceCRUD bool
+
+// resourceRoutes registers the CRUD endpoints of a resource's controller
+func resourceRoutes(titleName string, crud bool) string {
+	list := ""
+	if crud {
+		list = fmt.Sprintf("\troute.Get(\"/\", ctrl.List%s)\n", titleName)
+	}
+	return fmt.Sprintf(`	route.Post("/", ctrl.Create%[1]s)
+%[2]s	route.Get("/:id", ctrl.Get%[1]s)
+	route.Put("/:id", ctrl.Update%[1]s)
+	route.Delete("/:id", ctrl.Delete%[1]s)
+`, titleName, list)
+}
+
+// resourceDTOContent renders the request body of a resource's create or update endpoint
+func resourceDTOContent(structName, name string) string {
+	return fmt.Sprintf(`package dto
+
+// %[1]s is the request body of the endpoint. Add the fields a %[2]s carries here
+// and in the entity.
+type %[1]s struct {
+	Name string `+"`json:\"name\" validate:\"required,max=100\" example:\"My %[2]s\"`"+`
+}
+`, structName, name)
+}
+
+// resourceEntityContent renders the record a --crud resource stores
+func resourceEntityContent(titleName string) string {
+	return fmt.Sprintf(`package entity
+
+import "time"
+
+// %[1]s is the persisted %[1]s record
+type %[1]s struct {
+	ID        string    `+"`json:\"id\" db:\"id\"`"+`
+	Name      string    `+"`json:\"name\" db:\"name\"`"+`
+	CreatedAt time.Time `+"`json:\"created_at\" db:\"created_at\"`"+`
+	UpdatedAt time.Time `+"`json:\"updated_at\" db:\"updated_at\"`"+`
+}
+`, titleName)
+}
+
+// resourceRepositoryContent renders a repository that keeps records in memory, so
+// the --crud endpoints work before a database is wired in
+func resourceRepositoryContent(name, titleName string) string {
+	return fmt.Sprintf(`package repository
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"%[3]s/app/%[2]s/entity"
+)
+
+// Err%[1]sNotFound is returned when no %[1]s has the requested ID
+var Err%[1]sNotFound = errors.New("%[2]s not found")
+
+// %[1]sRepository keeps %[1]s records in memory. Replace the map with queries
+// against the database provider before production; the method set can stay the same.
+type %[1]sRepository struct {
+	mu    sync.RWMutex
+	items map[string]entity.%[1]s
+}
+
+// Create%[1]s assigns e an ID and timestamps and stores it
+func (r *%[1]sRepository) Create%[1]s(e *entity.%[1]s) error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.items == nil {
+		r.items = map[string]entity.%[1]s{}
+	}
+	e.ID = hex.EncodeToString(id)
+	e.CreatedAt = time.Now().UTC()
+	e.UpdatedAt = e.CreatedAt
+	r.items[e.ID] = *e
+	return nil
+}
+
+// Get%[1]s retrieves a %[1]s by ID
+func (r *%[1]sRepository) Get%[1]s(id string) (*entity.%[1]s, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.items[id]
+	if !ok {
+		return nil, Err%[1]sNotFound
+	}
+	return &e, nil
+}
+
+// List%[1]s returns every %[1]s ordered by creation time
+func (r *%[1]sRepository) List%[1]s() ([]entity.%[1]s, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	items := make([]entity.%[1]s, 0, len(r.items))
+	for _, e := range r.items {
+		items = append(items, e)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+// Update%[1]s saves e over the stored %[1]s with the same ID
+func (r *%[1]sRepository) Update%[1]s(e *entity.%[1]s) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.items[e.ID]
+	if !ok {
+		return Err%[1]sNotFound
+	}
+	e.CreatedAt = current.CreatedAt
+	e.UpdatedAt = time.Now().UTC()
+	r.items[e.ID] = *e
+	return nil
+}
+
+// Delete%[1]s deletes a %[1]s by ID
+func (r *%[1]sRepository) Delete%[1]s(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.items[id]; !ok {
+		return Err%[1]sNotFound
+	}
+	delete(r.items, id)
+	return nil
+}
+`, titleName, name, getModuleName())
+}
+
+// resourceServiceContent renders a service that maps the DTOs onto entities and
+// delegates to the repository
+func resourceServiceContent(name, titleName string) string {
+	return fmt.Sprintf(`package service
+
+import (
+	"%[3]s/app/%[2]s/dto"
+	"%[3]s/app/%[2]s/entity"
+	"%[3]s/app/%[2]s/repository"
+)
+
+type %[1]sService struct {
+	Repository *repository.%[1]sRepository `+"`inject:\"type\"`"+`
+}
+
+// Create%[1]s creates a new %[1]s
+func (s *%[1]sService) Create%[1]s(input dto.Create%[1]sDTO) (*entity.%[1]s, error) {
+	e := &entity.%[1]s{Name: input.Name}
+	if err := s.Repository.Create%[1]s(e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Get%[1]s retrieves a %[1]s by ID
+func (s *%[1]sService) Get%[1]s(id string) (*entity.%[1]s, error) {
+	return s.Repository.Get%[1]s(id)
+}
+
+// List%[1]s returns every %[1]s
+func (s *%[1]sService) List%[1]s() ([]entity.%[1]s, error) {
+	return s.Repository.List%[1]s()
+}
+
+// Update%[1]s updates a %[1]s by ID
+func (s *%[1]sService) Update%[1]s(id string, input dto.Update%[1]sDTO) (*entity.%[1]s, error) {
+	e, err := s.Repository.Get%[1]s(id)
+	if err != nil {
+		return nil, err
+	}
+	e.Name = input.Name
+	if err := s.Repository.Update%[1]s(e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Delete%[1]s deletes a %[1]s by ID
+func (s *%[1]sService) Delete%[1]s(id string) error {
+	return s.Repository.Delete%[1]s(id)
+}
+`, titleName, name, getModuleName())
+}
+
+// resourceControllerContent renders a controller whose handlers parse the DTOs,
+// call the service and answer with the matching status codes
+func resourceControllerContent(name, titleName string) string {
+	return fmt.Sprintf(`package controller
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"%[3]s/app/%[2]s/dto"
+	"%[3]s/app/%[2]s/repository"
+	"%[3]s/app/%[2]s/service"
+)
+
+type %[1]sController struct {
+	Service *service.%[1]sService `+"`inject:\"type\"`"+`
+}
+
+// Create%[1]s handles creating a new %[1]s
+func (c *%[1]sController) Create%[1]s(ctx *fiber.Ctx) error {
+	var input dto.Create%[1]sDTO
+	if err := ctx.BodyParser(&input); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if input.Name == "" {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "name is required")
+	}
+	created, err := c.Service.Create%[1]s(input)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(created)
+}
+
+// List%[1]s handles listing every %[1]s
+func (c *%[1]sController) List%[1]s(ctx *fiber.Ctx) error {
+	items, err := c.Service.List%[1]s()
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(items)
+}
+
+// Get%[1]s handles retrieving a %[1]s by ID
+func (c *%[1]sController) Get%[1]s(ctx *fiber.Ctx) error {
+	found, err := c.Service.Get%[1]s(ctx.Params("id"))
+	if err != nil {
+		return %[4]sError(err)
+	}
+	return ctx.JSON(found)
+}
+
+// Update%[1]s handles updating a %[1]s by ID
+func (c *%[1]sController) Update%[1]s(ctx *fiber.Ctx) error {
+	var input dto.Update%[1]sDTO
+	if err := ctx.BodyParser(&input); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if input.Name == "" {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "name is required")
+	}
+	updated, err := c.Service.Update%[1]s(ctx.Params("id"), input)
+	if err != nil {
+		return %[4]sError(err)
+	}
+	return ctx.JSON(updated)
+}
+
+// Delete%[1]s handles deleting a %[1]s by ID
+func (c *%[1]sController) Delete%[1]s(ctx *fiber.Ctx) error {
+	if err := c.Service.Delete%[1]s(ctx.Params("id")); err != nil {
+		return %[4]sError(err)
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// %[4]sError maps a missing %[1]s to 404 and passes other errors through
+func %[4]sError(err error) error {
+	if errors.Is(err, repository.Err%[1]sNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	return err
+}
+`, titleName, name, getModuleName(), strings.ToLower(titleName[:1])+titleName[1:])
+}
+
+// resourceRouteContent renders the route file of a resource. With --crud every
+// route is registered, so it has no TODO left.
+func resourceRouteContent(name, titleName string) string {
+	content := routeContent(name, titleName, resourceRoutes(titleName, resourceCRUD))
+	if resourceCRUD {
+		content = strings.Replace(content, fmt.Sprintf("\t// TODO: Register routes for %s\n", titleName), "", 1)
+	}
+	return content
+}
+
+var resourceCmd = &cobra.Command{
+	Use:   "resource [name]",
+	Short: "Generate a module with controller, service, repository, DTOs and CRUD routes in one step",
+	Long: `Generates everything a REST resource needs in app/<name>: module.go, controller,
+service, repository, Create<Name>DTO and Update<Name>DTO, and a route file
+registering POST /, GET /:id, PUT /:id and DELETE /:id. The module is added to
+the module registry.
+
+By default the handlers are TODO stubs like 'g module'. With --crud they work:
+the controller parses and checks the DTOs, the service maps them onto an entity
+and the repository stores it in memory, so the endpoints answer 201, 200, 204,
+404 and 422 right away. GET / lists every record. Swap the repository's map for
+database queries when the schema is ready.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		titleName := strings.Title(name)
+		moduleName := getModuleName()
+		if checkModuleDependencies(name, moduleDependencies) {
+			return
+		}
+		moduleDir := filepath.Join("app", name)
+		for _, sub := range []string{"controller", "dto", "repository", "route", "service"} {
+			path := filepath.Join(moduleDir, sub)
+			if err := os.MkdirAll(path, 0755); err != nil {
+				fmt.Printf("Error creating %s: %v\n", path, err)
+				return
+			}
+		}
+		m, err := manifest.Load()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		settings := m.Modules[name]
+		if cmd.Flags().Changed("prefix") {
+			settings.Prefix = modulePrefix
+		}
+		mountPath := path.Join("/", settings.Prefix, name+"s")
+
+		controllerFile := filepath.Join(moduleDir, "controller", fmt.Sprintf("%sController.go", name))
+		serviceFile := filepath.Join(moduleDir, "service", fmt.Sprintf("%sService.go", name))
+		repositoryFile := filepath.Join(moduleDir, "repository", fmt.Sprintf("%sRepository.go", name))
+		files := []codegen.File{
+			{Path: filepath.Join(moduleDir, "module.go"), Content: moduleContent(name, titleName, mountPath, moduleOptions{DependsOn: moduleDependencies, Dynamic: moduleDynamic})},
+			{Path: filepath.Join(moduleDir, "dto", fmt.Sprintf("Create%sDTO.go", titleName)), Content: resourceDTOContent("Create"+titleName+"DTO", name)},
+			{Path: filepath.Join(moduleDir, "dto", fmt.Sprintf("Update%sDTO.go", titleName)), Content: resourceDTOContent("Update"+titleName+"DTO", name)},
+			{Path: filepath.Join(moduleDir, "route", fmt.Sprintf("%sRoute.go", name)), Content: resourceRouteContent(name, titleName)},
+		}
+		if resourceCRUD {
+			files = append(files,
+				codegen.File{Path: controllerFile, Content: resourceControllerContent(name, titleName)},
+				codegen.File{Path: serviceFile, Content: resourceServiceContent(name, titleName)},
+				codegen.File{Path: repositoryFile, Content: resourceRepositoryContent(name, titleName)},
+				codegen.File{Path: entityFile(name, name), Content: resourceEntityContent(titleName)},
+			)
+		} else {
+			files = append(files,
+				codegen.File{Path: controllerFile, Content: controllerContent(fmt.Sprintf("%s/app/%s/service", moduleName, name), titleName, controllerOptions{})},
+				codegen.File{Path: serviceFile, Content: serviceContent(name, titleName, "")},
+				codegen.File{Path: repositoryFile, Content: repositoryContent(name, name, titleName, repositoryOptions{})},
+			)
+		}
+		newConfig := configMissing()
+		files = append(files, settingsFiles(name)...)
+		if injectInterfaces {
+			if files, err = withInterfaces(name, files); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		if !writeGenerated(files...) {
+			return
+		}
+		if cmd.Flags().Changed("prefix") {
+			m.SetModule(name, settings)
+			if err := m.Save(); err != nil {
+				fmt.Printf("Error writing %s: %v\n", manifest.FileName, err)
+				return
+			}
+		}
+		if err := registerModule(name, !settings.Disabled); err != nil {
+			fmt.Printf("Error adding module to %s: %v\n", moduleRegistryFile, err)
+			return
+		}
+		if resourceCRUD {
+			fmt.Printf("Resource '%s' created in app/%s with working CRUD handlers, mounted at %s.\n", name, name, mountPath)
+		} else {
+			fmt.Printf("Resource '%s' created in app/%s with CRUD stubs, mounted at %s.\n", name, name, mountPath)
+		}
+		printSettings(name, newConfig)
+		openIfRequested(moduleDir)
+	},
+}
+
+func init() {
+	resourceCmd.Flags().BoolVar(&resourceCRUD, "crud", false, "Emit working CRUD handlers backed by an in-memory repository instead of TODO stubs")
+	resourceCmd.Flags().BoolVar(&injectInterfaces, "inject-interfaces", false, "Depend on service and repository interfaces injected by name instead of concrete types")
+	resourceCmd.Flags().BoolVar(&moduleDynamic, "dynamic", false, "Take a <Name>Config in New<Name>Module, passed where the module is registered")
+	resourceCmd.Flags().StringSliceVar(&moduleDependencies, "depends-on", nil, "Modules initialized before this one, declared by its DependsOn method (repeatable)")
+	resourceCmd.Flags().StringVar(&modulePrefix, "prefix", "", "Route prefix the module mounts under (e.g. /api/v1)")
+	generateCmd.AddCommand(resourceCmd)
+	gCmd.AddCommand(resourceCmd)
+}
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/spf13/cobra"
+)
+
+// seedersDir holds the project's seeders, run in file name order
+var seedersDir = filepath.Join(databaseDir, "seeders")
+
+// seedCommandFile is the runner behind `gonext db seed`
+var seedCommandFile = filepath.Join("cmd", "seed", "main.go")
+
+// seederFileName matches numbered seeder files, e.g. 001_users.go
+var seederFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.go$`)
+
+const seederBaseTemplate = `package seeders
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// Seeder fills the database with data, such as reference data or demo records
+type Seeder interface {
+	Run(ctx context.Context, db *sql.Tx) error
+}
+
+// Entry is a seeder and the name 'gonext db seed --only' selects it by
+type Entry struct {
+	Name   string
+	Seeder Seeder
+}
+
+// Run runs the entries named in only, or all of them if only is empty, in order.
+// Each runs in its own transaction, rolled back if it fails; the seeders after
+// a failed one are not run.
+func Run(ctx context.Context, db *sql.DB, entries []Entry, only []string) error {
+	known := map[string]bool{}
+	for _, e := range entries {
+		known[e.Name] = true
+	}
+	selected := map[string]bool{}
+	for _, name := range only {
+		if !known[name] {
+			return fmt.Errorf("unknown seeder %q", name)
+		}
+		selected[name] = true
+	}
+	for _, e := range entries {
+		if len(only) > 0 && !selected[e.Name] {
+			continue
+		}
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if err := e.Seeder.Run(ctx, tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("seeder %s: %w", e.Name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("seeder %s: %w", e.Name, err)
+		}
+		log.Printf("seeded %s", e.Name)
+	}
+	return nil
+}
+
+// Reset drops everything in the public schema, for 'gonext db seed --fresh'
+func Reset(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, "DROP SCHEMA public CASCADE"); err != nil {
+		return err
+	}
+	_, err := db.ExecContext(ctx, "CREATE SCHEMA public")
+	return err
+}
+`
+
+const seedCommandTemplate = `package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"strings"
+
+	"%s/app/database"
+	"%s/app/database/seeders"
+)
+
+// Runs the seeders of app/database/seeders, run with 'gonext db seed'
+func main() {
+	only := flag.String("only", "", "comma-separated names of the seeders to run")
+	fresh := flag.Bool("fresh", false, "drop every table and re-apply the migrations first")
+	flag.Parse()
+	ctx := context.Background()
+	db, err := database.Open(ctx, database.PoolConfigFromEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	if *fresh {
+%s	}
+	var names []string
+	if *only != "" {
+		names = strings.Split(*only, ",")
+	}
+	if err := seeders.Run(ctx, db, seeders.All, names); err != nil {
+		log.Fatal(err)
+	}
+}
+`
+
+// seedFreshMigrate resets the database and re-applies the migrations
+const seedFreshMigrate = `		if err := seeders.Reset(ctx, db); err != nil {
+			log.Fatal(err)
+		}
+		if err := database.Migrate(ctx, db, database.MigrationsDir); err != nil {
+			log.Fatal(err)
+		}
+`
+
+// seedFreshUnavailable is used until the project has a migration runner
+const seedFreshUnavailable = `		log.Fatal("-fresh re-applies the migrations: generate the runner with 'gonext g db:provider --migrate-on-start'")
+`
+
+// seedCommandContent renders the runner, which supports -fresh once the project
+// has a migration runner
+func seedCommandContent() string {
+	fresh := seedFreshUnavailable
+	if _, err := os.Stat(filepath.Join(databaseDir, "migrate.go")); err == nil {
+		fresh = seedFreshMigrate
+	}
+	return fmt.Sprintf(seedCommandTemplate, getModuleName(), getModuleName(), fresh)
+}
+
+// seeder is a numbered seeder file
+type seeder struct {
+	Number int
+	Name   string // the file name without number, selected by --only
+	Type   string
+	Path   string
+}
+
+// discoverSeeders returns the seeders of seedersDir in the order they run
+func discoverSeeders() ([]seeder, error) {
+	entries, err := os.ReadDir(seedersDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var seeders []seeder
+	for _, e := range entries {
+		m := seederFileName.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+		path := filepath.Join(seedersDir, e.Name())
+		typ, err := seederType(path)
+		if err != nil {
+			return nil, err
+		}
+		n, _ := strconv.Atoi(m[1])
+		seeders = append(seeders, seeder{Number: n, Name: m[2], Type: typ, Path: path})
+	}
+	sort.SliceStable(seeders, func(i, j int) bool {
+		if seeders[i].Number != seeders[j].Number {
+			return seeders[i].Number < seeders[j].Number
+		}
+		return seeders[i].Name < seeders[j].Name
+	})
+	return seeders, nil
+}
+
+// seederType returns the type of a seeder file whose name ends in Seeder
+func seederType(path string) (string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return "", err
+	}
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if ts := spec.(*ast.TypeSpec); strings.HasSuffix(ts.Name.Name, "Seeder") && ts.Name.IsExported() {
+				return ts.Name.Name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%s declares no seeder type, such as UsersSeeder", path)
+}
+
+// seederRegistryContent lists the seeders in the order they run
+func seederRegistryContent(seeders []seeder) string {
+	var entries strings.Builder
+	for _, s := range seeders {
+		entries.WriteString(fmt.Sprintf("\t{Name: %q, Seeder: %s{}},\n", s.Name, s.Type))
+	}
+	return fmt.Sprintf(`package seeders
+
+// All lists the seeders in the order they run, by file number. It is rewritten
+// by 'gonext g seeder' and 'gonext db seed'.
+var All = []Entry{
+%s}
+`, entries.String())
+}
+
+// seederRegistryFiles returns the registry of seeders with the shared seeder
+// types, created if missing
+func seederRegistryFiles(seeders []seeder) []codegen.File {
+	files := []codegen.File{{Path: filepath.Join(seedersDir, "registry.go"), Content: seederRegistryContent(seeders)}}
+	return append(files, missingFile(codegen.File{Path: filepath.Join(seedersDir, "seeder.go"), Content: seederBaseTemplate})...)
+}
+
+// seederContent renders a seeder
+func seederContent(typ, table string) string {
+	return fmt.Sprintf(`package seeders
+
+import (
+	"context"
+	"database/sql"
+)
+
+// %[1]s fills the database with %[2]s.
+// 'gonext db seed' runs it after the seeders with a lower number, in a
+// transaction rolled back if it fails.
+type %[1]s struct{}
+
+// Run inserts the records. Keep it safe to run again, e.g. with ON CONFLICT DO NOTHING.
+func (%[1]s) Run(ctx context.Context, db *sql.Tx) error {
+	// TODO: Insert the records, e.g.
+	// _, err := db.ExecContext(ctx, `+"`"+`INSERT INTO %[2]s (name) VALUES ($1) ON CONFLICT DO NOTHING`+"`"+`, "example")
+	return nil
+}
+`, typ, table)
+}
+
+var seederCmd = &cobra.Command{
+	Use:   "seeder [name]",
+	Short: "Generate a numbered database seeder run by 'gonext db seed'",
+	Long: `Generates app/database/seeders/<number>_<name>.go with a <Name>Seeder whose Run
+method inserts records in a transaction. Seeders run in number order, so a new
+seeder runs after the existing ones; renumber the files to change the order.
+app/database/seeders/registry.go, which lists them, is rewritten.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := proto.SnakeCase(args[0])
+		if !regexp.MustCompile(`^[a-z][a-z0-9_]*$`).MatchString(name) {
+			fmt.Printf("Invalid seeder name %q: use letters, digits and underscores\n", args[0])
+			return
+		}
+		seeders, err := discoverSeeders()
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", seedersDir, err)
+			return
+		}
+		next := 1
+		for _, s := range seeders {
+			if s.Name == name {
+				fmt.Printf("Seeder '%s' already exists at %s\n", name, s.Path)
+				return
+			}
+			next = max(next, s.Number+1)
+		}
+		s := seeder{Number: next, Name: name, Type: proto.GoName(name) + "Seeder", Path: filepath.Join(seedersDir, fmt.Sprintf("%03d_%s.go", next, name))}
+		files := []codegen.File{{Path: s.Path, Content: seederContent(s.Type, name)}}
+		files = append(files, seederRegistryFiles(append(seeders, s))...)
+		if !writeGenerated(files...) {
+			return
+		}
+		fmt.Printf("Seeder '%s' created at %s. Run the seeders with 'gonext db seed'.\n", s.Type, s.Path)
+		openIfRequested(s.Path)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(seederCmd)
+	gCmd.AddCommand(seederCmd)
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+)
+
+// configFile holds the registry of module settings shared by all modules
+var configFile = filepath.Join("app", "config", "config.go")
+
+const configTemplate = `// Package config reads the settings of each module from the environment. A module
+// declares its settings as a struct in app/<module>/settings and registers it with
+// the prefix of its variables, e.g. ORDERS; Load fills every registered struct.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// section is a module's settings struct and the prefix of its variables
+type section struct {
+	prefix string
+	target any
+}
+
+var (
+	mu       sync.Mutex
+	sections []section
+)
+
+// Register records a module's settings. target points to a struct whose fields
+// are read from <prefix>_<env tag>, falling back to the default tag:
+//
+//	PageSize int    ` + "`" + `env:"PAGE_SIZE" default:"20"` + "`" + ` // ORDERS_PAGE_SIZE
+//	APIKey   string ` + "`" + `env:"API_KEY,required"` + "`" + `
+//
+// Strings, booleans, numbers, durations and comma-separated string slices are
+// supported. Settings packages call Register in init.
+func Register(prefix string, target any) {
+	mu.Lock()
+	defer mu.Unlock()
+	sections = append(sections, section{prefix: prefix, target: target})
+}
+
+// Load fills every registered settings struct from the environment and returns
+// one error listing all problems. Call it at startup, before the modules are
+// initialized.
+func Load() error {
+	mu.Lock()
+	defer mu.Unlock()
+	var problems []string
+	for _, s := range sections {
+		v := reflect.ValueOf(s.target)
+		if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+			problems = append(problems, fmt.Sprintf("%s: settings must be a pointer to a struct, got %T", s.prefix, s.target))
+			continue
+		}
+		problems = append(problems, load(s.prefix, v.Elem())...)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New("invalid configuration:\n  - " + strings.Join(problems, "\n  - "))
+}
+
+func load(prefix string, v reflect.Value) []string {
+	var problems []string
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("env")
+		if !ok || !f.IsExported() {
+			continue
+		}
+		key, option, _ := strings.Cut(tag, ",")
+		name := prefix + "_" + key
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			if option == "required" {
+				problems = append(problems, fmt.Sprintf("%s is required but not set", name))
+				continue
+			}
+			if value, ok = f.Tag.Lookup("default"); !ok {
+				continue
+			}
+		}
+		if err := setField(v.Field(i), value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s=%q %v", name, value, err))
+		}
+	}
+	return problems
+}
+
+func setField(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New("is not a valid duration (e.g. 30s, 5m)")
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("is not a valid boolean")
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("is not a valid integer")
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("is not a valid unsigned integer")
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return errors.New("is not a valid number")
+		}
+		field.SetFloat(n)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("has unsupported type %s", field.Type())
+		}
+		parts := strings.Split(value, ",")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		field.Set(reflect.ValueOf(parts).Convert(field.Type()))
+	default:
+		return fmt.Errorf("has unsupported type %s", field.Type())
+	}
+	return nil
+}
+`
+
+// envPrefix is the prefix of a module's environment variables, e.g. ORDERS for
+// orders and ORDER_ITEMS for orderItems
+func envPrefix(module string) string {
+	return strings.ToUpper(proto.SnakeCase(module))
+}
+
+// settingsContent renders the settings of a module, registered with app/config
+func settingsContent(module string) string {
+	return fmt.Sprintf(`package settings
+
+import "%[1]s/app/config"
+
+// EnvPrefix prefixes the environment variables of the %[2]s module's settings
+const EnvPrefix = %[3]q
+
+// Settings are the %[2]s module's settings, read from %[3]s_* environment
+// variables by config.Load at startup
+type Settings struct {
+	// TODO: Add the module's settings, e.g.
+	// PageSize int `+"`"+`env:"PAGE_SIZE" default:"20"`+"`"+` // %[3]s_PAGE_SIZE
+}
+
+// Values holds the settings once config.Load has run. %[4]sModule.Register binds
+// it as %[5]q; components inject it with inject:"name=%[5]s".
+var Values = &Settings{}
+
+func init() {
+	config.Register(EnvPrefix, Values)
+}
+`, getModuleName(), module, envPrefix(module), strings.Title(module), module+"Settings")
+}
+
+// settingsFiles returns a module's settings and, if missing, the config registry
+func settingsFiles(module string) []codegen.File {
+	files := []codegen.File{{Path: filepath.Join("app", module, "settings", "settings.go"), Content: settingsContent(module)}}
+	return append(files, missingFile(codegen.File{Path: configFile, Content: configTemplate})...)
+}
+
+// printSettings tells where a new module's settings are read from and, when the
+// config registry was just created, how to load them
+func printSettings(module string, newConfig bool) {
+	fmt.Printf("Its settings are read from %s_* environment variables, declared in app/%s/settings.\n", envPrefix(module), module)
+	if newConfig {
+		fmt.Println("Load the settings of every module at startup, before the modules are initialized:\n  if err := config.Load(); err != nil {\n  \tlog.Fatal(err)\n  }")
+	}
+}
+
+// configMissing reports whether the config registry is yet to be created
+func configMissing() bool {
+	_, err := os.Stat(configFile)
+	return os.IsNotExist(err)
+}
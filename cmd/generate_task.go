@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/spf13/cobra"
+)
+
+// taskCron is set by `g task --cron`
+var taskCron string
+
+// schedulerFile holds the registry of scheduled tasks shared by all modules
+var schedulerFile = filepath.Join("app", "scheduler", "scheduler.go")
+
+const schedulerTemplate = `package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Task is a scheduled task. Spec is a cron expression or a descriptor such as
+// @hourly or @every 15m.
+type Task interface {
+	Spec() string
+	Run(ctx context.Context) error
+}
+
+var (
+	mu    sync.Mutex
+	tasks []Task
+)
+
+// Register records a task. Modules call it in Register:
+//
+//	scheduler.Register(schedule.CleanupTask{})
+func Register(task Task) {
+	mu.Lock()
+	defer mu.Unlock()
+	tasks = append(tasks, task)
+}
+
+// AttachTasks adds every registered task to the app scheduler through add, e.g.
+//
+//	scheduler.AttachTasks(func(spec string, run func()) { cronScheduler.Add(spec, run) })
+//
+// A failing run is logged; the task runs again at its next tick.
+func AttachTasks(add func(spec string, run func())) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, task := range tasks {
+		task := task
+		add(task.Spec(), func() {
+			if err := task.Run(context.Background()); err != nil {
+				log.Printf("scheduled task %T failed: %v", task, err)
+			}
+		})
+	}
+}
+`
+
+// cronDescriptors are the predefined schedules accepted besides @every <duration>
+var cronDescriptors = map[string]bool{
+	"@yearly": true, "@annually": true, "@monthly": true, "@weekly": true,
+	"@daily": true, "@midnight": true, "@hourly": true,
+}
+
+// validateCron checks that spec is a descriptor, @every <duration>, or a cron
+// expression of 5 fields (minute first) or 6 (second first)
+func validateCron(spec string) error {
+	if every, ok := strings.CutPrefix(spec, "@every "); ok {
+		if d, err := time.ParseDuration(strings.TrimSpace(every)); err != nil || d <= 0 {
+			return fmt.Errorf("invalid --cron %q: @every takes a positive duration such as 15m", spec)
+		}
+		return nil
+	}
+	if strings.HasPrefix(spec, "@") {
+		if !cronDescriptors[spec] {
+			return fmt.Errorf("invalid --cron %q: unknown descriptor", spec)
+		}
+		return nil
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 && len(fields) != 6 {
+		return fmt.Errorf("invalid --cron %q: expected 5 fields (minute hour day month weekday) or 6 (with seconds first)", spec)
+	}
+	for _, field := range fields {
+		if strings.Trim(strings.ToUpper(field), "0123456789*/,-?LW#ABCDEFGHIJKMNOPRSTUVY") != "" {
+			return fmt.Errorf("invalid --cron %q: unexpected characters in %q", spec, field)
+		}
+	}
+	return nil
+}
+
+// taskContent renders a scheduled task with its cron expression
+func taskContent(module, titleName, spec string) string {
+	return fmt.Sprintf(`package schedule
+
+import (
+	"context"
+
+	"%[5]s/app/clock"
+)
+
+// %[1]sTaskSpec is when %[1]sTask runs
+const %[1]sTaskSpec = %[2]q
+
+// %[1]sTask is a scheduled task of the %[3]s module. It is registered in
+// %[4]sModule.Register.
+type %[1]sTask struct {
+	// Clock is where Run reads the time; the scheduler leaves it nil for the
+	// system clock and tests set a clock.Fake
+	Clock clock.Clock
+}
+
+// Spec is the task's schedule
+func (%[1]sTask) Spec() string {
+	return %[1]sTaskSpec
+}
+
+// Run does the work. A returned error is logged and the task runs again at its next tick.
+func (t %[1]sTask) Run(ctx context.Context) error {
+	// TODO: Do the work, reading the time from clock.Or(t.Clock).Now()
+	return nil
+}
+`, titleName, spec, module, strings.Title(module), getModuleName())
+}
+
+// registerTask registers the task in the module's Register method, or prints
+// the registration when the module has no module.go
+func registerTask(module, titleName string) error {
+	moduleFile := filepath.Join("app", module, "module.go")
+	register := fmt.Sprintf("scheduler.Register(schedule.%sTask{})", titleName)
+	if _, err := os.Stat(moduleFile); os.IsNotExist(err) {
+		fmt.Printf("app/%s has no module.go. Register the task at startup:\n  %s\n", module, register)
+		return nil
+	}
+	return editGenerated(moduleFile, func(src []byte) ([]byte, error) {
+		out, err := codemod.AppendStatement(src, "Register", register)
+		if err != nil {
+			return nil, err
+		}
+		if out, err = codemod.AddImport(out, getModuleName()+"/app/scheduler"); err != nil {
+			return nil, err
+		}
+		return codemod.AddImport(out, fmt.Sprintf("%s/app/%s/schedule", getModuleName(), module))
+	})
+}
+
+var taskCmd = &cobra.Command{
+	Use:   "task [name] [in_module]",
+	Short: "Generate a scheduled task with its cron expression and scheduler registration",
+	Long: `Generates app/<module>/schedule/<name>Task.go, a task run on the schedule given
+by --cron, and registers it in the module's Register method. The task registry in
+app/scheduler and the Clock the task reads the time from, in app/clock, are
+created if missing.
+
+--cron takes a cron expression of 5 fields ("0 * * * *", minute first) or 6
+(seconds first), a descriptor such as @hourly or @daily, or @every <duration>.
+Use the form the app scheduler parses. The registered tasks are added to it at
+startup:
+
+  scheduler.AttachTasks(func(spec string, run func()) { cronScheduler.Add(spec, run) })`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		module := args[1]
+		titleName := proto.GoName(name)
+		if err := validateCron(taskCron); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := ensureModuleDirs(module); err != nil {
+			fmt.Println(err)
+			return
+		}
+		taskFile := filepath.Join("app", module, "schedule", fmt.Sprintf("%sTask.go", name))
+		files := []codegen.File{{Path: taskFile, Content: taskContent(module, titleName, taskCron)}}
+		files = append(files, missingFile(codegen.File{Path: schedulerFile, Content: schedulerTemplate})...)
+		files = append(files, clockFiles()...)
+		if !writeGenerated(files...) {
+			return
+		}
+		if err := registerTask(module, titleName); err != nil {
+			fmt.Printf("Error registering the task in app/%s/module.go: %v\n", module, err)
+			return
+		}
+		fmt.Printf("Task '%s' (%s) created in app/%s/schedule. Add the registered tasks to the app scheduler at startup:\n  scheduler.AttachTasks(func(spec string, run func()) { cronScheduler.Add(spec, run) })\n", titleName, taskCron, module)
+		openIfRequested(taskFile)
+	},
+}
+
+func init() {
+	taskCmd.Flags().StringVar(&taskCron, "cron", "@hourly", "When the task runs: a cron expression (\"0 * * * *\"), a descriptor (@daily) or @every <duration>")
+	generateCmd.AddCommand(taskCmd)
+	gCmd.AddCommand(taskCmd)
+}
@@ -0,0 +1,435 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/proto"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// testClientDir holds the test client generated by `g test:client`
+var testClientDir = filepath.Join("app", "testclient")
+
+const testClientTemplate = `package testclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TokenFunc returns the bearer token As sends for a caller. The default suits
+// guards that don't verify tokens yet; replace it, e.g. in TestMain, with one
+// that signs tokens the app accepts.
+var TokenFunc = func(t testing.TB, subject string, roles ...string) string {
+	return "test:" + subject + ":" + strings.Join(roles, ",")
+}
+
+// Client sends requests to a fiber app in memory through app.Test and fails the
+// test on transport errors. The With methods return a copy, so a client can be
+// shared by the cases of a table.
+type Client struct {
+	t       testing.TB
+	app     *fiber.App
+	headers map[string]string
+}
+
+// New returns a client of app, e.g. one built like main.go builds it
+func New(t testing.TB, app *fiber.App) *Client {
+	return &Client{t: t, app: app, headers: map[string]string{}}
+}
+
+// WithHeader returns a copy of the client that sends the header with every request
+func (c *Client) WithHeader(name, value string) *Client {
+	copied := &Client{t: c.t, app: c.app, headers: make(map[string]string, len(c.headers)+1)}
+	for k, v := range c.headers {
+		copied.headers[k] = v
+	}
+	copied.headers[name] = value
+	return copied
+}
+
+// WithToken returns a copy of the client authenticated with a bearer token
+func (c *Client) WithToken(token string) *Client {
+	return c.WithHeader(fiber.HeaderAuthorization, "Bearer "+token)
+}
+
+// As returns a copy of the client authenticated as subject with roles, with a
+// token from TokenFunc
+func (c *Client) As(subject string, roles ...string) *Client {
+	return c.WithToken(TokenFunc(c.t, subject, roles...))
+}
+
+// Get sends a GET request
+func (c *Client) Get(path string) *Response {
+	return c.Do(fiber.MethodGet, path, nil)
+}
+
+// Post sends a POST request with body, see Do
+func (c *Client) Post(path string, body any) *Response {
+	return c.Do(fiber.MethodPost, path, body)
+}
+
+// Put sends a PUT request with body, see Do
+func (c *Client) Put(path string, body any) *Response {
+	return c.Do(fiber.MethodPut, path, body)
+}
+
+// Patch sends a PATCH request with body, see Do
+func (c *Client) Patch(path string, body any) *Response {
+	return c.Do(fiber.MethodPatch, path, body)
+}
+
+// Delete sends a DELETE request
+func (c *Client) Delete(path string) *Response {
+	return c.Do(fiber.MethodDelete, path, nil)
+}
+
+// Do sends a request. A string, []byte or io.Reader body is sent as is, any
+// other non-nil body as JSON; both with a JSON content type unless the client
+// sets another.
+func (c *Client) Do(method, path string, body any) *Response {
+	c.t.Helper()
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = strings.NewReader(b)
+	case []byte:
+		reader = bytes.NewReader(b)
+	case io.Reader:
+		reader = b
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatalf("%s %s: encoding the body: %v", method, path, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	return c.send(req)
+}
+
+// File is a file sent by Upload
+type File struct {
+	Field   string // form field
+	Name    string // file name
+	Content []byte
+}
+
+// Upload sends a multipart/form-data POST request with the form fields and files
+func (c *Client) Upload(path string, fields map[string]string, files ...File) *Response {
+	c.t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := w.WriteField(name, value); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+	for _, f := range files {
+		part, err := w.CreateFormFile(f.Field, f.Name)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		if _, err := part.Write(f.Content); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		c.t.Fatal(err)
+	}
+	req := httptest.NewRequest(fiber.MethodPost, path, &body)
+	req.Header.Set(fiber.HeaderContentType, w.FormDataContentType())
+	return c.send(req)
+}
+
+func (c *Client) send(req *http.Request) *Response {
+	c.t.Helper()
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+	res, err := c.app.Test(req, -1)
+	if err != nil {
+		c.t.Fatalf("%s %s: %v", req.Method, req.URL.Path, err)
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.t.Fatalf("%s %s: reading the body: %v", req.Method, req.URL.Path, err)
+	}
+	return &Response{t: c.t, request: req.Method + " " + req.URL.Path, Status: res.StatusCode, Header: res.Header, Body: data}
+}
+
+// Response is a response read in full. Its assertions fail the test and return
+// the response, so they can be chained:
+//
+//	client.Get("/api/users/1").AssertStatus(200).AssertJSONPath("email", "ada@example.com")
+type Response struct {
+	t       testing.TB
+	request string
+	Status  int
+	Header  http.Header
+	Body    []byte
+}
+
+// AssertStatus checks the status code, showing the body when it differs
+func (r *Response) AssertStatus(want int) *Response {
+	r.t.Helper()
+	if r.Status != want {
+		r.t.Fatalf("%s: status = %d, want %d; body: %s", r.request, r.Status, want, r.Body)
+	}
+	return r
+}
+
+// AssertHeader checks a response header
+func (r *Response) AssertHeader(name, want string) *Response {
+	r.t.Helper()
+	if got := r.Header.Get(name); got != want {
+		r.t.Errorf("%s: header %s = %q, want %q", r.request, name, got, want)
+	}
+	return r
+}
+
+// JSON decodes the body into v
+func (r *Response) JSON(v any) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("%s: decoding the body: %v; body: %s", r.request, err, r.Body)
+	}
+	return r
+}
+
+// AssertJSON checks that the body is the same JSON as want, ignoring
+// formatting and key order. want is JSON text as a string or []byte, or a
+// value encoded to JSON.
+func (r *Response) AssertJSON(want any) *Response {
+	r.t.Helper()
+	var got any
+	r.JSON(&got)
+	if expected := normalizeJSON(r.t, want); !reflect.DeepEqual(got, expected) {
+		r.t.Errorf("%s: body = %s, want %s", r.request, r.Body, mustJSON(expected))
+	}
+	return r
+}
+
+// AssertJSONPath checks the value at a dot-separated path of the body, with
+// array indexes as numbers, e.g. "data.0.email"
+func (r *Response) AssertJSONPath(path string, want any) *Response {
+	r.t.Helper()
+	var value any
+	r.JSON(&value)
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				r.t.Fatalf("%s: %s: no index %q in an array of %d", r.request, path, key, len(v))
+			}
+			value = v[i]
+		default:
+			r.t.Fatalf("%s: %s: %q is not in an object or array; body: %s", r.request, path, key, r.Body)
+		}
+	}
+	if expected := normalizeJSON(r.t, mustJSON(want)); !reflect.DeepEqual(value, expected) {
+		r.t.Errorf("%s: %s = %s, want %s", r.request, path, mustJSON(value), mustJSON(expected))
+	}
+	return r
+}
+
+// normalizeJSON decodes want into the generic values json.Unmarshal produces,
+// so it compares equal to a decoded body
+func normalizeJSON(t testing.TB, want any) any {
+	t.Helper()
+	var data []byte
+	switch w := want.(type) {
+	case string:
+		data = []byte(w)
+	case []byte:
+		data = w
+	default:
+		data = []byte(mustJSON(want))
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("invalid expected JSON %s: %v", data, err)
+	}
+	return v
+}
+
+func mustJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+`
+
+// testClientMethods are the HTTP methods that get typed route methods
+var testClientMethods = map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true}
+
+// testClientRoute is a route method of the typed client
+type testClientRoute struct {
+	Name   string
+	Route  workspace.Route
+	Params []string // path parameters, in order
+	Format string   // the path with %s for each parameter
+}
+
+// testClientRoutes names a method per route after its HTTP method and path,
+// e.g. GetUsersByID for GET /users/:id, numbered when two routes collide
+func testClientRoutes(routes []workspace.Route) []testClientRoute {
+	var methods []testClientRoute
+	taken := map[string]int{}
+	for _, r := range routes {
+		if !testClientMethods[r.Method] {
+			continue
+		}
+		m := testClientRoute{Route: r}
+		name := proto.GoName(strings.ToLower(r.Method))
+		var format []string
+		for _, seg := range strings.Split(strings.Trim(r.Path, "/"), "/") {
+			switch {
+			case seg == "":
+			case seg == "*" || strings.HasPrefix(seg, ":"):
+				param := strings.TrimSuffix(strings.TrimPrefix(seg, ":"), "?")
+				if seg == "*" {
+					param = "path"
+				}
+				param = proto.GoName(param)
+				name += "By" + param
+				if strings.ToUpper(param) == param {
+					m.Params = append(m.Params, strings.ToLower(param))
+				} else {
+					m.Params = append(m.Params, strings.ToLower(param[:1])+param[1:])
+				}
+				format = append(format, "%s")
+			default:
+				name += proto.GoName(seg)
+				format = append(format, strings.ReplaceAll(seg, "%", "%%"))
+			}
+		}
+		if len(format) == 0 {
+			name += "Root"
+		}
+		m.Format = "/" + strings.Join(format, "/")
+		if n := taken[name]; n > 0 {
+			taken[name]++
+			name = fmt.Sprintf("%s%d", name, n+1)
+		} else {
+			taken[name] = 1
+		}
+		m.Name = name
+		methods = append(methods, m)
+	}
+	return methods
+}
+
+// testClientRoutesContent renders the typed route methods of the client
+func testClientRoutesContent(routes []testClientRoute) string {
+	var b strings.Builder
+	b.WriteString(`package testclient
+`)
+	for _, r := range routes {
+		if len(r.Params) > 0 {
+			b.WriteString(`
+import (
+	"fmt"
+	"net/url"
+)
+`)
+			break
+		}
+	}
+	for _, r := range routes {
+		var params, args []string
+		for _, p := range r.Params {
+			params = append(params, p+" string")
+			args = append(args, "url.PathEscape("+p+")")
+		}
+		body := r.Route.Method == "POST" || r.Route.Method == "PUT" || r.Route.Method == "PATCH"
+		if body {
+			params = append(params, "body any")
+		}
+		path := fmt.Sprintf("%q", strings.ReplaceAll(r.Format, "%%", "%"))
+		if len(args) > 0 {
+			path = fmt.Sprintf("fmt.Sprintf(%q, %s)", r.Format, strings.Join(args, ", "))
+		}
+		bodyArg := "nil"
+		if body {
+			bodyArg = "body"
+		}
+		fmt.Fprintf(&b, `
+// %s sends %s %s (%s module)
+func (c *Client) %s(%s) *Response {
+	return c.Do(%q, %s, %s)
+}
+`, r.Name, r.Route.Method, r.Route.Path, r.Route.Module, r.Name, strings.Join(params, ", "), r.Route.Method, path, bodyArg)
+	}
+	return b.String()
+}
+
+var testClientCmd = &cobra.Command{
+	Use:   "test:client",
+	Short: "Generate an HTTP test client for the project's routes, wrapping app.Test",
+	Long: `Generates app/testclient, a client that sends requests to the fiber app in
+memory through app.Test:
+
+  client := testclient.New(t, app).As("user-1", "admin")
+  client.Post("/api/users", dto.CreateUserDTO{Email: "ada@example.com"}).
+  	AssertStatus(201).
+  	AssertJSONPath("email", "ada@example.com")
+  client.Upload("/api/files", map[string]string{"kind": "avatar"},
+  	testclient.File{Field: "file", Name: "a.png", Content: png})
+
+It has a typed method per route, such as GetUsersByID(id) for GET /users/:id,
+in routes.go. Run the command again after adding routes to regenerate it.
+
+As authenticates with a token from testclient.TokenFunc; replace it with one
+that signs tokens your guards accept. The test skeletons of 'gonext g test
+controller' send their requests with the client once it exists.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		routes, err := workspace.Routes()
+		if err != nil {
+			fmt.Printf("Error reading the routes: %v\n", err)
+			return
+		}
+		methods := testClientRoutes(routes)
+		clientFile := filepath.Join(testClientDir, "client.go")
+		files := missingFile(codegen.File{Path: clientFile, Content: testClientTemplate})
+		files = append(files, codegen.File{Path: filepath.Join(testClientDir, "routes.go"), Content: testClientRoutesContent(methods)})
+		if !writeGenerated(files...) {
+			return
+		}
+		fmt.Printf("Test client created in %s with %d route method(s). Use it in tests with:\n", testClientDir, len(methods))
+		fmt.Println("  client := testclient.New(t, app)")
+		fmt.Println("Run 'gonext g test:client' again after adding routes.")
+		openIfRequested(clientFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(testClientCmd)
+	gCmd.AddCommand(testClientCmd)
+}
@@ -0,0 +1,418 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/spf13/cobra"
+)
+
+// testParam is a parameter of a method under test
+type testParam struct {
+	Name     string
+	Type     string
+	Variadic bool
+}
+
+// testMethod is an exported method of the component under test
+type testMethod struct {
+	Name    string
+	Params  []testParam
+	Results []string
+	Handler bool // a fiber handler, tested with requests
+}
+
+// majorVersion matches the /vN suffix of module paths, which is not the package name
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// importName guesses the name of an imported package from its path
+func importName(importPath string) string {
+	name := path.Base(importPath)
+	if majorVersion.MatchString(name) {
+		name = path.Base(path.Dir(importPath))
+	}
+	return strings.TrimPrefix(name, "go-")
+}
+
+// testedMethods returns the receiver name and the exported methods of typeName
+// in a source file, with the imports their signatures use
+func testedMethods(file, typeName string) (receiver string, methods []testMethod, imports map[string]string, err error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	known := map[string]string{} // package name -> import path
+	for _, spec := range f.Imports {
+		p := strings.Trim(spec.Path.Value, `"`)
+		if spec.Name != nil {
+			known[spec.Name.Name] = p
+		} else {
+			known[importName(p)] = p
+		}
+	}
+	imports = map[string]string{}
+	use := func(expr ast.Expr) string {
+		ast.Inspect(expr, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if id, ok := sel.X.(*ast.Ident); ok && known[id.Name] != "" {
+					imports[id.Name] = known[id.Name]
+				}
+			}
+			return true
+		})
+		return types.ExprString(expr)
+	}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 || !fn.Name.IsExported() {
+			continue
+		}
+		recv := fn.Recv.List[0]
+		recvType := recv.Type
+		if star, ok := recvType.(*ast.StarExpr); ok {
+			recvType = star.X
+		}
+		if id, ok := recvType.(*ast.Ident); !ok || id.Name != typeName {
+			continue
+		}
+		if receiver == "" && len(recv.Names) > 0 && recv.Names[0].Name != "_" {
+			receiver = recv.Names[0].Name
+		}
+		m := testMethod{Name: fn.Name.Name}
+		for _, field := range fn.Type.Params.List {
+			typ := field.Type
+			variadic := false
+			if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+				typ, variadic = ellipsis.Elt, true
+			}
+			names := field.Names
+			if len(names) == 0 {
+				names = []*ast.Ident{{Name: "_"}}
+			}
+			for _, n := range names {
+				name := n.Name
+				if name == "_" {
+					name = fmt.Sprintf("arg%d", len(m.Params))
+				}
+				p := testParam{Name: name, Type: use(typ), Variadic: variadic}
+				if variadic {
+					p.Type = "[]" + p.Type
+				}
+				m.Params = append(m.Params, p)
+			}
+		}
+		if fn.Type.Results != nil {
+			for _, field := range fn.Type.Results.List {
+				for range max(1, len(field.Names)) {
+					m.Results = append(m.Results, use(field.Type))
+				}
+			}
+		}
+		m.Handler = len(m.Params) == 1 && m.Params[0].Type == "*fiber.Ctx" && len(m.Results) == 1 && m.Results[0] == "error"
+		methods = append(methods, m)
+	}
+	if receiver == "" {
+		receiver = strings.ToLower(typeName[:1])
+	}
+	return receiver, methods, imports, nil
+}
+
+// hasTestClient reports whether the project has the client of `g test:client`,
+// which handler tests then send their requests with
+func hasTestClient() bool {
+	_, err := os.Stat(filepath.Join(testClientDir, "client.go"))
+	return err == nil
+}
+
+// handlerTestContent renders a table-driven test of a fiber handler, sending
+// each case's request through app.Test, or the project's test client
+func handlerTestContent(typeName, receiver string, m testMethod) string {
+	send := fmt.Sprintf(`req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			res, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("%s() status = %%d, want %%d", res.StatusCode, tt.wantStatus)
+			}`, m.Name)
+	if hasTestClient() {
+		send = `testclient.New(t, app).Do(tt.method, tt.target, tt.body).AssertStatus(tt.wantStatus)`
+	}
+	return fmt.Sprintf(`
+func Test%[1]s_%[2]s(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		route      string
+		target     string
+		body       string
+		wantStatus int
+	}{
+		// TODO: Add test cases, e.g.
+		// {name: "ok", method: fiber.MethodGet, route: "/:id", target: "/1", wantStatus: fiber.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			%[3]s := &%[1]s{} // TODO: Set the dependencies, e.g. with fakes
+			app := fiber.New()
+			app.Add(tt.method, tt.route, %[3]s.%[2]s)
+			%[4]s
+		})
+	}
+}
+`, typeName, m.Name, receiver, send)
+}
+
+// methodTestContent renders a table-driven test of a method, with the
+// arguments, the wanted results and whether an error is wanted in each case
+func methodTestContent(typeName, receiver string, m testMethod) string {
+	var args, fields, call, checks strings.Builder
+	var callArgs []string
+	for _, p := range m.Params {
+		if p.Type == "context.Context" {
+			callArgs = append(callArgs, "context.Background()")
+			continue
+		}
+		fmt.Fprintf(&args, "\t\t%s %s\n", p.Name, p.Type)
+		arg := "tt.args." + p.Name
+		if p.Variadic {
+			arg += "..."
+		}
+		callArgs = append(callArgs, arg)
+	}
+	fields.WriteString("\t\tname string\n")
+	if args.Len() > 0 {
+		fields.WriteString("\t\targs args\n")
+	}
+	var got []string
+	results := m.Results
+	wantErr := len(results) > 0 && results[len(results)-1] == "error"
+	if wantErr {
+		results = results[:len(results)-1]
+		fmt.Fprintf(&checks, "\t\t\tif (err != nil) != tt.wantErr {\n\t\t\t\tt.Fatalf(\"%s() error = %%v, wantErr %%v\", err, tt.wantErr)\n\t\t\t}\n", m.Name)
+	}
+	for i, typ := range results {
+		suffix := ""
+		if i > 0 {
+			suffix = fmt.Sprint(i)
+		}
+		got = append(got, "got"+suffix)
+		fmt.Fprintf(&fields, "\t\twant%s %s\n", suffix, typ)
+		fmt.Fprintf(&checks, "\t\t\tif !reflect.DeepEqual(got%[1]s, tt.want%[1]s) {\n\t\t\t\tt.Errorf(\"%[2]s() got%[1]s = %%v, want %%v\", got%[1]s, tt.want%[1]s)\n\t\t\t}\n", suffix, m.Name)
+	}
+	if wantErr {
+		got = append(got, "err")
+		fields.WriteString("\t\twantErr bool\n")
+	}
+	if len(got) > 0 {
+		call.WriteString(strings.Join(got, ", ") + " := ")
+	}
+	fmt.Fprintf(&call, "%s.%s(%s)", receiver, m.Name, strings.Join(callArgs, ", "))
+	if len(got) == 0 {
+		checks.WriteString("\t\t\t// TODO: Check the effects of the call\n")
+	}
+	argsType := ""
+	if args.Len() > 0 {
+		argsType = fmt.Sprintf("\ttype args struct {\n%s\t}\n", args.String())
+	}
+	return fmt.Sprintf(`
+func Test%[1]s_%[2]s(t *testing.T) {
+%[3]s	tests := []struct {
+%[4]s	}{
+		// TODO: Add test cases.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			%[5]s := &%[1]s{} // TODO: Set the dependencies, e.g. with fakes
+			%[6]s
+%[7]s		})
+	}
+}
+`, typeName, m.Name, argsType, fields.String(), receiver, call.String(), checks.String())
+}
+
+// testImports returns the imports a set of generated tests needs, by name
+func testImports(methods []testMethod, sigImports map[string]string) map[string]string {
+	imports := map[string]string{"testing": "testing"}
+	for _, m := range methods {
+		if m.Handler {
+			imports["fiber"] = sigImports["fiber"]
+			if hasTestClient() {
+				imports["testclient"] = getModuleName() + "/app/testclient"
+			} else {
+				imports["httptest"] = "net/http/httptest"
+				imports["strings"] = "strings"
+			}
+			continue
+		}
+		for name, p := range sigImports {
+			imports[name] = p
+		}
+		for _, p := range m.Params {
+			if p.Type == "context.Context" {
+				imports["context"] = "context"
+			}
+		}
+		for i, r := range m.Results {
+			if r != "error" || i < len(m.Results)-1 {
+				imports["reflect"] = "reflect"
+			}
+		}
+	}
+	return imports
+}
+
+// testFileContent renders a new test file with the tests of methods
+func testFileContent(pkg string, imports map[string]string, tests string) (string, error) {
+	var std, other []string
+	for name, p := range imports {
+		spec := fmt.Sprintf("%q", p)
+		if importName(p) != name {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.Split(p, "/")[0], ".") {
+			other = append(other, "\t"+spec)
+		} else {
+			std = append(std, "\t"+spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	block := strings.Join(std, "\n")
+	if len(other) > 0 {
+		block += "\n\n" + strings.Join(other, "\n")
+	}
+	src, err := format.Source([]byte(fmt.Sprintf("package %s\n\nimport (\n%s\n)\n%s", pkg, block, tests)))
+	return string(src), err
+}
+
+// existingTests returns the names of the functions of a test file, if it exists
+func existingTests(file string) (map[string]bool, error) {
+	names := map[string]bool{}
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+	if os.IsNotExist(err) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+			names[fn.Name.Name] = true
+		}
+	}
+	return names, nil
+}
+
+var testSkeletonCmd = &cobra.Command{
+	Use:   "test [controller|service|repository] [name] [in_module]",
+	Short: "Generate a table-driven test skeleton for the exported methods of a component",
+	Long: `Reads app/<module>/<kind>/<name><Kind>.go and generates <name><Kind>_test.go
+next to it, with a table-driven test per exported method:
+
+  gonext g test controller users users
+  gonext g test service users users
+
+Fiber handlers get a table of requests (method, route, target, body) and the
+wanted status, sent through app.Test. Other methods get a table of arguments,
+wanted results and wantErr, compared with reflect.DeepEqual; context.Context
+arguments are passed context.Background(). The tables start empty, with TODOs.
+
+If the test file exists, only the tests of methods added since are appended.`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		kind, name, module := strings.ToLower(args[0]), args[1], args[2]
+		if kind != "controller" && kind != "service" && kind != "repository" {
+			fmt.Printf("Unknown component kind %q: expected controller, service or repository\n", args[0])
+			return
+		}
+		typeName := strings.Title(name) + strings.Title(kind)
+		source := filepath.Join("app", module, kind, name+strings.Title(kind)+".go")
+		if _, err := os.Stat(source); os.IsNotExist(err) {
+			fmt.Printf("%s does not exist. Generate it with 'gonext g %s %s %s'.\n", source, kind, name, module)
+			return
+		}
+		receiver, methods, sigImports, err := testedMethods(source, typeName)
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", source, err)
+			return
+		}
+		file := strings.TrimSuffix(source, ".go") + "_test.go"
+		existing, err := existingTests(file)
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", file, err)
+			return
+		}
+		var missing []testMethod
+		var tests strings.Builder
+		for _, m := range methods {
+			if existing[fmt.Sprintf("Test%s_%s", typeName, m.Name)] {
+				continue
+			}
+			missing = append(missing, m)
+			if m.Handler {
+				tests.WriteString(handlerTestContent(typeName, receiver, m))
+			} else {
+				tests.WriteString(methodTestContent(typeName, receiver, m))
+			}
+		}
+		if len(missing) == 0 {
+			fmt.Printf("Every exported method of %s already has a test in %s\n", typeName, file)
+			return
+		}
+		imports := testImports(missing, sigImports)
+
+		if len(existing) > 0 {
+			err := editGenerated(file, func(src []byte) ([]byte, error) {
+				src = append(src, tests.String()...)
+				var err error
+				for name, p := range imports {
+					if importName(p) != name {
+						src, err = codemod.AddNamedImport(src, name, p)
+					} else {
+						src, err = codemod.AddImport(src, p)
+					}
+					if err != nil {
+						return nil, err
+					}
+				}
+				return format.Source(src)
+			})
+			if err != nil {
+				fmt.Printf("Error updating %s: %v\n", file, err)
+				return
+			}
+			fmt.Printf("Added %d test(s) to %s\n", len(missing), file)
+			openIfRequested(file)
+			return
+		}
+		content, err := testFileContent(kind, imports, tests.String())
+		if err != nil {
+			fmt.Printf("Error rendering %s: %v\n", file, err)
+			return
+		}
+		if !writeGenerated(codegen.File{Path: file, Content: content}) {
+			return
+		}
+		fmt.Printf("Test skeleton for %s created at %s with %d test(s). Fill in the cases and run 'gonext test'.\n", typeName, file, len(missing))
+		openIfRequested(file)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(testSkeletonCmd)
+	gCmd.AddCommand(testSkeletonCmd)
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+// watchdogFile holds the dev goroutine and heap leak watchdog
+var watchdogFile = filepath.Join("app", "watchdog", "watchdog.go")
+
+const watchdogTemplate = `package watchdog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"runtime/pprof"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Sample is one measurement of the process
+type Sample struct {
+	Time       time.Time ` + "`json:\"time\"`" + `
+	Goroutines int       ` + "`json:\"goroutines\"`" + `
+	HeapBytes  uint64    ` + "`json:\"heapBytes\"`" + `
+}
+
+// Watchdog samples goroutine counts and heap usage and flags steady growth, the
+// signature of a leak (e.g. a worker starting goroutines that never exit)
+type Watchdog struct {
+	Interval        time.Duration // WATCHDOG_INTERVAL (default 10s)
+	Window          int           // samples compared, WATCHDOG_WINDOW (default 30)
+	GoroutineGrowth int           // WATCHDOG_GOROUTINE_GROWTH (default 50)
+	HeapGrowthBytes uint64        // WATCHDOG_HEAP_GROWTH_MB (default 64)
+
+	mu      sync.Mutex
+	samples []Sample
+	anomaly string
+}
+
+// Default is the process-wide watchdog
+var Default = New()
+
+// New returns a watchdog configured from the environment
+func New() *Watchdog {
+	return &Watchdog{
+		Interval:        envDuration("WATCHDOG_INTERVAL", 10*time.Second),
+		Window:          envInt("WATCHDOG_WINDOW", 30),
+		GoroutineGrowth: envInt("WATCHDOG_GOROUTINE_GROWTH", 50),
+		HeapGrowthBytes: uint64(envInt("WATCHDOG_HEAP_GROWTH_MB", 64)) << 20,
+	}
+}
+
+// Enabled reports whether the watchdog runs, set with WATCHDOG=true.
+// 'gonext start' enables it in development. It never runs when APP_ENV=production.
+func Enabled() bool {
+	on, _ := strconv.ParseBool(os.Getenv("WATCHDOG"))
+	return on && os.Getenv("APP_ENV") != "production"
+}
+
+// Start samples the process until ctx is done. It returns immediately unless Enabled.
+func (w *Watchdog) Start(ctx context.Context) {
+	if !Enabled() {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+		for {
+			w.sample()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Samples returns the samples in the current window, oldest first
+func (w *Watchdog) Samples() []Sample {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]Sample(nil), w.samples...)
+}
+
+// Check reports the current anomaly as an error. Register it on the readiness
+// probe with health.Default.AddCheck("watchdog", watchdog.Default.Check).
+func (w *Watchdog) Check(context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.anomaly != "" {
+		return errors.New(w.anomaly)
+	}
+	return nil
+}
+
+func (w *Watchdog) sample() {
+	s := Sample{Time: time.Now(), Goroutines: runtime.NumGoroutine(), HeapBytes: heapBytes()}
+	w.mu.Lock()
+	w.samples = append(w.samples, s)
+	if len(w.samples) > w.Window {
+		w.samples = w.samples[len(w.samples)-w.Window:]
+	}
+	previous := w.anomaly
+	w.anomaly = w.detect()
+	anomaly := w.anomaly
+	w.mu.Unlock()
+
+	if anomaly != "" && previous == "" {
+		log.Printf("watchdog: %s", anomaly)
+		if file, err := dumpGoroutines(); err == nil {
+			log.Printf("watchdog: goroutine stacks written to %s", file)
+		}
+	}
+	if anomaly == "" && previous != "" {
+		log.Printf("watchdog: back to normal (%d goroutines, %d MiB heap)", s.Goroutines, s.HeapBytes>>20)
+	}
+}
+
+// detect flags a full window in which goroutines or heap never went down and grew past the threshold
+func (w *Watchdog) detect() string {
+	if len(w.samples) < w.Window {
+		return ""
+	}
+	first, last := w.samples[0], w.samples[len(w.samples)-1]
+	goroutinesRising, heapRising := true, true
+	for i := 1; i < len(w.samples); i++ {
+		goroutinesRising = goroutinesRising && w.samples[i].Goroutines >= w.samples[i-1].Goroutines
+		heapRising = heapRising && w.samples[i].HeapBytes >= w.samples[i-1].HeapBytes
+	}
+	span := last.Time.Sub(first.Time).Round(time.Second)
+	if goroutinesRising && last.Goroutines-first.Goroutines >= w.GoroutineGrowth {
+		return fmt.Sprintf("goroutines grew from %d to %d over %s without going down, a likely leak", first.Goroutines, last.Goroutines, span)
+	}
+	if heapRising && last.HeapBytes-first.HeapBytes >= w.HeapGrowthBytes {
+		return fmt.Sprintf("heap grew from %d MiB to %d MiB over %s without going down, a likely leak", first.HeapBytes>>20, last.HeapBytes>>20, span)
+	}
+	return ""
+}
+
+// heapBytes returns the bytes held by live and not yet collected heap objects
+func heapBytes() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// dumpGoroutines writes the goroutine stacks, grouped by call site, to tmp/
+func dumpGoroutines() (string, error) {
+	file := filepath.Join("tmp", "goroutines-"+time.Now().Format("20060102-150405")+".txt")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return file, pprof.Lookup("goroutine").WriteTo(f, 1)
+}
+
+func envInt(key string, fallback int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return v
+	}
+	return fallback
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return v
+	}
+	return fallback
+}
+`
+
+var watchdogCmd = &cobra.Command{
+	Use:   "watchdog",
+	Short: "Generate a dev watchdog that flags goroutine and heap leaks and reports them on the readiness probe",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !writeGenerated(codegen.File{Path: watchdogFile, Content: watchdogTemplate}) {
+			return
+		}
+		fmt.Printf("Watchdog created in %s. Start it in main.go:\n", filepath.Dir(watchdogFile))
+		fmt.Print(`
+	watchdog.Default.Start(ctx)
+	health.Default.AddCheck("watchdog", watchdog.Default.Check)
+`)
+		fmt.Println("\n'gonext start' sets WATCHDOG=true. Anomalies are logged, with goroutine stacks in tmp/, and make /readyz report them.")
+		openIfRequested(watchdogFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(watchdogCmd)
+	gCmd.AddCommand(watchdogCmd)
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+const workerTemplate = `package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// component is a background part of the app the worker runs, such as a
+// queue.TaskQueue consumer or the cron scheduler.
+type component interface {
+	Start()
+	Shutdown()
+}
+
+// shutdownTimeout bounds how long in-flight jobs get to drain on SIGTERM
+const shutdownTimeout = 30 * time.Second
+
+func main() {
+	// TODO: Build the container and register only the non-HTTP parts of your modules,
+	// e.g. queue.NewRedisTaskQueue(cfg) with its handlers and the cron scheduler.
+	// Jobs from 'gonext g job' are attached with queue.AttachJobs(taskQueue).
+	// Tasks from 'gonext g task' are added with scheduler.AttachTasks.
+	components := []component{}
+
+	var ready atomic.Bool
+	health := startHealthServer(envOr("WORKER_HEALTH_ADDR", ":8081"), &ready)
+
+	for _, c := range components {
+		c.Start()
+	}
+	ready.Store(true)
+	log.Printf("%s started with %%d component(s)", len(components))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	log.Printf("%s shutting down...")
+	ready.Store(false)
+	done := make(chan struct{})
+	go func() {
+		// Stop in reverse start order so consumers drain before their dependencies close
+		for i := len(components) - 1; i >= 0; i-- {
+			components[i].Shutdown()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(shutdownTimeout):
+		log.Printf("%s shutdown timed out after %%s", shutdownTimeout)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = health.Shutdown(shutdownCtx)
+}
+
+// startHealthServer exposes /healthz (liveness) and /readyz (readiness) for orchestrators
+func startHealthServer(addr string, ready *atomic.Bool) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !ready.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("health server failed: %%v", err)
+		}
+	}()
+	return srv
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+`
+
+var workerCmd = &cobra.Command{
+	Use:   "worker [name]",
+	Short: "Generate a cmd/<name>/main.go entrypoint that runs queue consumers and schedulers without HTTP",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := "worker"
+		if len(args) == 1 {
+			name = args[0]
+		}
+		workerFile := filepath.Join("cmd", name, "main.go")
+		content := fmt.Sprintf(workerTemplate, name, name, name)
+		if !writeGenerated(codegen.File{Path: workerFile, Content: content}) {
+			return
+		}
+		fmt.Printf("Worker entrypoint created in cmd/%s. Run it with 'go run ./cmd/%s'.\n", name, name)
+		openIfRequested(workerFile)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(workerCmd)
+	gCmd.AddCommand(workerCmd)
+}
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+// goVersionPattern matches Go release versions such as 1.23 or 1.23.4
+var goVersionPattern = regexp.MustCompile(`^1\.\d+(\.\d+)?$`)
+
+// goDirectiveLine and toolchainDirectiveLine match the go and toolchain lines of go.mod and go.work
+var goDirectiveLine = regexp.MustCompile(`(?m)^go [^\s]+[ \t]*$`)
+var toolchainDirectiveLine = regexp.MustCompile(`(?m)\n*^toolchain [^\s]+[ \t]*$`)
+
+// workUse matches the directories of the use directives in go.work
+var workUse = regexp.MustCompile(`(?m)^\s*(?:use\s+)?(\.[^\s()]*)\s*$`)
+
+// localGoVersion returns the version of the installed Go toolchain, ignoring the
+// toolchain that GOTOOLCHAIN=auto would switch to for the project
+func localGoVersion() (string, error) {
+	c := exec.Command("go", "env", "GOVERSION")
+	c.Env = append(os.Environ(), "GOTOOLCHAIN=local")
+	out, err := c.Output()
+	if err != nil {
+		return "", fmt.Errorf("go env: %v", err)
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(out)), "go"), nil
+}
+
+// compareGoVersions compares two Go versions numerically, e.g. 1.9 < 1.23.0 < 1.23.4.
+// Pre-release suffixes such as rc1 are ignored.
+func compareGoVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(leadingDigits(as[i]))
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(leadingDigits(bs[i]))
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// leadingDigits returns the number at the start of a version element, e.g. 24 for 24rc1
+func leadingDigits(s string) string {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	return s[:end]
+}
+
+// goToolchainWarning describes how the local toolchain differs from the one required
+// in gonext.yaml, or returns "" when they match or no version is required
+func goToolchainWarning(required string) string {
+	if required == "" {
+		return ""
+	}
+	local, err := localGoVersion()
+	if err != nil {
+		return err.Error()
+	}
+	// A required 1.23 accepts any 1.23.x
+	if parts := strings.Split(local, "."); len(parts) > len(strings.Split(required, ".")) {
+		local = strings.Join(parts[:len(strings.Split(required, "."))], ".")
+	}
+	switch compareGoVersions(local, required) {
+	case -1:
+		return fmt.Sprintf("Go %s is installed but %s requires %s: the go command will download it (GOTOOLCHAIN=auto) or fail", local, manifest.FileName, required)
+	case 1:
+		return fmt.Sprintf("Go %s is installed but %s pins %s: builds may differ from CI", local, manifest.FileName, required)
+	}
+	return ""
+}
+
+// warnGoToolchain prints a warning when the local toolchain differs from the one in gonext.yaml
+func warnGoToolchain() {
+	m, err := manifest.Load()
+	if err != nil {
+		return
+	}
+	if warning := goToolchainWarning(m.Go); warning != "" {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+}
+
+// goDirectives returns the go and toolchain directives for a required version: modules
+// declare the language version, e.g. go 1.23.0, and pin the exact toolchain, e.g. go1.23.4
+func goDirectives(version string) (string, string) {
+	parts := strings.Split(version, ".")
+	lang := parts[0] + "." + parts[1] + ".0"
+	if len(parts) < 3 || parts[2] == "0" {
+		return lang, ""
+	}
+	return lang, "go" + version
+}
+
+// setGoDirectives rewrites the go and toolchain lines of a go.mod or go.work file.
+// The toolchain line is removed when toolchain is empty.
+func setGoDirectives(src []byte, lang, toolchain string) []byte {
+	out := toolchainDirectiveLine.ReplaceAllString(string(src), "")
+	line := "go " + lang
+	if toolchain != "" {
+		line += "\n\ntoolchain " + toolchain
+	}
+	if goDirectiveLine.MatchString(out) {
+		out = goDirectiveLine.ReplaceAllLiteralString(out, line)
+	} else {
+		// No go line yet: add it after the module line
+		module, rest, _ := strings.Cut(out, "\n")
+		out = module + "\n\n" + line + "\n" + rest
+	}
+	return []byte(out)
+}
+
+// workspaceGoFiles returns go.work and the go.mod files of its members, or just go.mod outside a workspace
+func workspaceGoFiles() []string {
+	files := []string{}
+	seen := map[string]bool{}
+	add := func(f string) {
+		if _, err := os.Stat(f); err == nil && !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+	if data, err := os.ReadFile("go.work"); err == nil {
+		add("go.work")
+		for _, m := range workUse.FindAllStringSubmatch(string(data), -1) {
+			add(filepath.Join(m[1], "go.mod"))
+		}
+	}
+	add("go.mod")
+	return files
+}
+
+// goDirectiveProblems lists the go.mod and go.work files whose go or toolchain line differs from the required version
+func goDirectiveProblems(version string) []string {
+	lang, toolchain := goDirectives(version)
+	var problems []string
+	for _, f := range workspaceGoFiles() {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		if expected := setGoDirectives(data, lang, toolchain); string(expected) != string(data) {
+			problems = append(problems, fmt.Sprintf("%s does not declare go %s / %s", f, lang, toolchainOrNone(toolchain)))
+		}
+	}
+	return problems
+}
+
+func toolchainOrNone(toolchain string) string {
+	if toolchain == "" {
+		return "no toolchain line"
+	}
+	return "toolchain " + toolchain
+}
+
+var fixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "Bring the project in line with gonext.yaml",
+}
+
+var fixGoVersionCmd = &cobra.Command{
+	Use:   "go-version [version]",
+	Short: "Set the go and toolchain directives of every workspace member to the Go version in gonext.yaml",
+	Long: `Updates go.mod, go.work and the go.mod of every module used by go.work so
+they agree on the Go version recorded in gonext.yaml:
+
+  go: 1.23.4
+
+becomes 'go 1.23.0' (the language version) and 'toolchain go1.23.4' in each file.
+Passing a version records it in gonext.yaml first. Without either, the installed
+toolchain is recorded.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		files := workspaceGoFiles()
+		if len(files) == 0 {
+			fmt.Println("No go.mod or go.work in the current directory. Run this command from the project root.")
+			return
+		}
+		m, err := manifest.Load()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		version := m.Go
+		if len(args) == 1 {
+			version = strings.TrimPrefix(args[0], "go")
+		}
+		if version == "" {
+			if version, err = localGoVersion(); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		if !goVersionPattern.MatchString(version) {
+			fmt.Printf("Invalid Go version '%s' (e.g. 1.23.4)\n", version)
+			return
+		}
+		if m.Go != version {
+			m.Go = version
+			if err := m.Save(); err != nil {
+				fmt.Printf("Error writing %s: %v\n", manifest.FileName, err)
+				return
+			}
+			fmt.Printf("Recorded Go %s in %s\n", version, manifest.FileName)
+		}
+		lang, toolchain := goDirectives(version)
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				fmt.Printf("Error reading %s: %v\n", f, err)
+				return
+			}
+			out := setGoDirectives(data, lang, toolchain)
+			if string(out) == string(data) {
+				fmt.Printf("  unchanged  %s\n", f)
+				continue
+			}
+			if err := os.WriteFile(f, out, 0644); err != nil {
+				fmt.Printf("Error writing %s: %v\n", f, err)
+				return
+			}
+			fmt.Printf("  updated    %s\n", f)
+		}
+		if warning := goToolchainWarning(version); warning != "" {
+			fmt.Printf("Warning: %s\n", warning)
+		}
+	},
+}
+
+func init() {
+	fixCmd.AddCommand(fixGoVersionCmd)
+	rootCmd.AddCommand(fixCmd)
+}
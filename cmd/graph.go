@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// graphJSON and graphDOT are set by `gonext graph --json/--dot`
+var graphJSON bool
+var graphDOT bool
+
+// graphEntry is a module in initialization order
+type graphEntry struct {
+	Order     int      `json:"order"`
+	Module    string   `json:"module"`
+	DependsOn []string `json:"depends_on"`
+	Disabled  bool     `json:"disabled,omitempty"`
+}
+
+// writeGraphDOT renders the module dependencies as a Graphviz digraph whose edges
+// point from a module to the modules initialized before it
+func writeGraphDOT(entries []graphEntry) {
+	fmt.Println("digraph modules {")
+	fmt.Println("  rankdir=LR;")
+	for _, e := range entries {
+		style := ""
+		if e.Disabled {
+			style = ", style=dashed"
+		}
+		fmt.Printf("  %q [label=\"%d. %s\"%s];\n", e.Module, e.Order, e.Module, style)
+	}
+	for _, e := range entries {
+		for _, dep := range e.DependsOn {
+			fmt.Printf("  %q -> %q;\n", e.Module, dep)
+		}
+	}
+	fmt.Println("}")
+}
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Show the order modules are initialized in, from their DependsOn declarations",
+	Long: `Reads the DependsOn method of each module.go and prints the modules in the
+order bootstrap.OrderedModules initializes them: every module after the modules it
+depends on. Modules disabled in gonext.yaml are marked.
+
+--dot prints a Graphviz digraph (gonext graph --dot | dot -Tsvg > modules.svg).
+Like the app at startup, the command fails on unknown dependencies, cycles and
+enabled modules depending on disabled ones.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		modules, err := workspace.Modules()
+		if err != nil {
+			fmt.Printf("Error scanning modules: %v\n", err)
+			os.Exit(1)
+		}
+		ordered, err := workspace.InitOrder(modules)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		m, err := manifest.Load()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		entries := make([]graphEntry, len(ordered))
+		for i, mod := range ordered {
+			entries[i] = graphEntry{Order: i + 1, Module: mod.Name, DependsOn: mod.DependsOn, Disabled: m.Modules[mod.Name].Disabled}
+			if entries[i].DependsOn == nil {
+				entries[i].DependsOn = []string{}
+			}
+		}
+		for _, e := range entries {
+			for _, dep := range e.DependsOn {
+				if !e.Disabled && m.Modules[dep].Disabled {
+					fmt.Printf("module %s depends on %s, which is disabled\n", e.Module, dep)
+					os.Exit(1)
+				}
+			}
+		}
+		switch {
+		case graphJSON:
+			data, err := json.MarshalIndent(entries, "", "  ")
+			if err != nil {
+				fmt.Printf("Error encoding JSON: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+		case graphDOT:
+			writeGraphDOT(entries)
+		case len(entries) == 0:
+			fmt.Println("Nothing found.")
+		default:
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ORDER\tMODULE\tDEPENDS ON\tSTATUS")
+			for _, e := range entries {
+				status := "enabled"
+				if e.Disabled {
+					status = "disabled"
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", e.Order, e.Module, strings.Join(e.DependsOn, ","), status)
+			}
+			w.Flush()
+		}
+	},
+}
+
+func init() {
+	graphCmd.Flags().BoolVar(&graphJSON, "json", false, "Output the order as JSON")
+	graphCmd.Flags().BoolVar(&graphDOT, "dot", false, "Output a Graphviz digraph of the module dependencies")
+	rootCmd.AddCommand(graphCmd)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+var headersCheck bool
+
+var headersCmd = &cobra.Command{
+	Use:   "headers",
+	Short: "Manage the file header configured in gonext.yaml",
+}
+
+var headersFixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "Add or update the configured header in every .go file of the project",
+	Run: func(cmd *cobra.Command, args []string) {
+		m, err := manifest.Load()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		header := codegen.HeaderComment(m.Header)
+		if header == "" {
+			fmt.Printf("No header configured. Add a 'header' section to %s first.\n", manifest.FileName)
+			return
+		}
+		state, err := codegen.LoadState()
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", codegen.StateFile, err)
+			return
+		}
+
+		var changed []string
+		err = filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				switch info.Name() {
+				case ".git", ".gonext", "vendor":
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(path, ".go") {
+				return nil
+			}
+			input, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			output := codegen.ApplyHeader(string(input), header, state.Header)
+			if output == string(input) {
+				return nil
+			}
+			changed = append(changed, path)
+			if headersCheck {
+				return nil
+			}
+			if err := os.WriteFile(path, []byte(output), 0644); err != nil {
+				return err
+			}
+			// Keep untouched scaffold files recognised as unchanged
+			if recorded, ok := state.Recorded(path); ok && recorded == codegen.Hash(input) {
+				return state.Record(path, []byte(output))
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("Error updating headers: %v\n", err)
+			return
+		}
+		for _, path := range changed {
+			fmt.Printf("  %s\n", path)
+		}
+		if headersCheck {
+			fmt.Printf("%d file(s) missing the configured header\n", len(changed))
+			if len(changed) > 0 {
+				os.Exit(1)
+			}
+			return
+		}
+		state.Header = header
+		if err := state.Save(); err != nil {
+			fmt.Printf("Error writing %s: %v\n", codegen.StateFile, err)
+			return
+		}
+		fmt.Printf("Updated header in %d file(s)\n", len(changed))
+	},
+}
+
+func init() {
+	headersFixCmd.Flags().BoolVar(&headersCheck, "check", false, "Only report files without the header (exits 1 if any)")
+	headersCmd.AddCommand(headersFixCmd)
+	rootCmd.AddCommand(headersCmd)
+}
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+// replayTarget, replayHeaders and replayCompareBody are set by `gonext http replay --target/--header/--compare-body`
+var replayTarget string
+var replayHeaders []string
+var replayCompareBody bool
+
+// recorderFile holds the dev middleware recording requests and responses to disk
+var recorderFile = filepath.Join("app", "recorder", "recorder.go")
+
+const recorderTemplate = `package recorder
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Exchange is one recorded request and its response, stored as a line of JSON
+type Exchange struct {
+	Time            time.Time         ` + "`json:\"time\"`" + `
+	Method          string            ` + "`json:\"method\"`" + `
+	URL             string            ` + "`json:\"url\"`" + `
+	Headers         map[string]string ` + "`json:\"headers\"`" + `
+	Body            string            ` + "`json:\"body,omitempty\"`" + `
+	Status          int               ` + "`json:\"status\"`" + `
+	ResponseHeaders map[string]string ` + "`json:\"responseHeaders\"`" + `
+	ResponseBody    string            ` + "`json:\"responseBody,omitempty\"`" + `
+	DurationMs      float64           ` + "`json:\"durationMs\"`" + `
+}
+
+// redacted headers are never written to disk; replay them with 'gonext http replay --header'
+var redacted = map[string]bool{"authorization": true, "cookie": true, "set-cookie": true, "x-api-key": true}
+
+// maxBody caps the recorded size of each request and response body
+const maxBody = 1 << 20
+
+// Middleware appends every request and its response to HTTP_RECORD_DIR/<start time>.jsonl.
+// It does nothing unless HTTP_RECORD_DIR is set, and never records when APP_ENV=production.
+func Middleware() fiber.Handler {
+	dir := os.Getenv("HTTP_RECORD_DIR")
+	if dir == "" || os.Getenv("APP_ENV") == "production" {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	var mu sync.Mutex
+	var file *os.File
+	session := filepath.Join(dir, time.Now().Format("20060102-150405")+".jsonl")
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		exchange := Exchange{
+			Time:    start.UTC(),
+			Method:  c.Method(),
+			URL:     c.OriginalURL(),
+			Headers: map[string]string{},
+			Body:    truncate(c.Body()),
+		}
+		c.Request().Header.VisitAll(func(key, value []byte) {
+			if !redacted[strings.ToLower(string(key))] {
+				exchange.Headers[string(key)] = string(value)
+			}
+		})
+
+		// Let the error handler write the response so the recording matches what the client saw
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		exchange.Status = c.Response().StatusCode()
+		exchange.ResponseHeaders = map[string]string{}
+		c.Response().Header.VisitAll(func(key, value []byte) {
+			if !redacted[strings.ToLower(string(key))] {
+				exchange.ResponseHeaders[string(key)] = string(value)
+			}
+		})
+		exchange.ResponseBody = truncate(c.Response().Body())
+		exchange.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+
+		line, err := json.Marshal(exchange)
+		if err != nil {
+			return nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if file == nil {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil
+			}
+			if file, err = os.OpenFile(session, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644); err != nil {
+				file = nil
+				return nil
+			}
+		}
+		_, _ = file.Write(append(line, '\n'))
+		return nil
+	}
+}
+
+func truncate(body []byte) string {
+	if len(body) > maxBody {
+		body = body[:maxBody]
+	}
+	return string(body)
+}
+`
+
+// exchange is a request recorded by the generated recorder middleware
+type exchange struct {
+	Method       string            `json:"method"`
+	URL          string            `json:"url"`
+	Headers      map[string]string `json:"headers"`
+	Body         string            `json:"body"`
+	Status       int               `json:"status"`
+	ResponseBody string            `json:"responseBody"`
+}
+
+// replayHopHeaders are recomputed by the HTTP client and not copied from the recording
+var replayHopHeaders = map[string]bool{"host": true, "content-length": true, "connection": true, "accept-encoding": true}
+
+// readSession reads a recorded session, one exchange per line
+func readSession(path string) ([]exchange, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var exchanges []exchange
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 4*1024*1024), 4*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e exchange
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, n, err)
+		}
+		exchanges = append(exchanges, e)
+	}
+	return exchanges, scanner.Err()
+}
+
+// sameBody compares response bodies, as values when both are JSON so key order and spacing do not matter
+func sameBody(recorded string, got []byte) bool {
+	var a, b any
+	if json.Unmarshal([]byte(recorded), &a) == nil && json.Unmarshal(got, &b) == nil {
+		x, _ := json.Marshal(a)
+		y, _ := json.Marshal(b)
+		return bytes.Equal(x, y)
+	}
+	return recorded == string(got)
+}
+
+// replay sends a recorded request to target and reports how its response differs from the recording
+func replay(client *http.Client, target string, e exchange, overrides http.Header) (string, error) {
+	req, err := http.NewRequest(e.Method, target+e.URL, strings.NewReader(e.Body))
+	if err != nil {
+		return "", err
+	}
+	for k, v := range e.Headers {
+		if !replayHopHeaders[strings.ToLower(k)] {
+			req.Header.Set(k, v)
+		}
+	}
+	for k, v := range overrides {
+		req.Header[k] = v
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != e.Status {
+		return fmt.Sprintf("status %d, recorded %d", resp.StatusCode, e.Status), nil
+	}
+	if replayCompareBody && !sameBody(e.ResponseBody, body) {
+		return "response body differs", nil
+	}
+	return "", nil
+}
+
+var httpCmd = &cobra.Command{
+	Use:   "http",
+	Short: "Record requests in development and replay them against another environment",
+}
+
+var httpRecordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Generate a middleware that records requests and responses to disk in development",
+	Long: `Generates app/recorder with a Fiber middleware that appends every request and
+its response to HTTP_RECORD_DIR/<start time>.jsonl. It is a no-op unless
+HTTP_RECORD_DIR is set and never records when APP_ENV=production. Authorization,
+Cookie, Set-Cookie and X-Api-Key headers are not recorded.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !writeGenerated(codegen.File{Path: recorderFile, Content: recorderTemplate}) {
+			return
+		}
+		fmt.Printf("Recorder created in %s\n", filepath.Dir(recorderFile))
+		if _, err := os.Stat(middlewareChainFile); err == nil {
+			if err := addGlobalMiddleware(getModuleName()+"/app/recorder", "recorder.Middleware()"); err != nil {
+				fmt.Printf("Error wiring middleware into %s: %v\n", middlewareChainFile, err)
+				return
+			}
+			fmt.Printf("recorder.Middleware() added to %s\n", middlewareChainFile)
+		} else {
+			fmt.Println("Register the middleware with server.Use(recorder.Middleware()) to record every request.")
+		}
+		fmt.Println("Set HTTP_RECORD_DIR=recordings to record, then replay a session with 'gonext http replay recordings/<session>.jsonl --target <url>'.")
+	},
+}
+
+var httpReplayCmd = &cobra.Command{
+	Use:   "replay <session.jsonl>",
+	Short: "Replay a recorded session against another environment and report differing responses",
+	Long: `Sends every request of a session recorded by 'gonext http record' to --target,
+in order, and compares the status code with the recording (and the body with
+--compare-body; JSON bodies are compared as values). Exits 1 if any response
+differs. Redacted headers such as Authorization can be supplied with --header.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exchanges, err := readSession(args[0])
+		if err != nil {
+			fmt.Printf("Error reading session: %v\n", err)
+			return
+		}
+		overrides := http.Header{}
+		for _, h := range replayHeaders {
+			k, v, ok := strings.Cut(h, ":")
+			if !ok {
+				fmt.Printf("Invalid header '%s' (expected 'Name: value')\n", h)
+				return
+			}
+			overrides.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+		}
+		target := strings.TrimSuffix(replayTarget, "/")
+		client := &http.Client{
+			Timeout: 30 * time.Second,
+			// Redirects are part of the recorded response, not followed
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+		differ := 0
+		for _, e := range exchanges {
+			start := time.Now()
+			diff, err := replay(client, target, e, overrides)
+			if err != nil {
+				fmt.Printf("Error replaying %s %s: %v\n", e.Method, e.URL, err)
+				return
+			}
+			mark := "ok"
+			if diff != "" {
+				mark = "DIFF"
+				differ++
+			}
+			fmt.Printf("  %-4s %s %s (%s) %s\n", mark, e.Method, e.URL, time.Since(start).Round(time.Millisecond), diff)
+		}
+		fmt.Printf("Replayed %d request(s) against %s, %d differ\n", len(exchanges), target, differ)
+		if differ > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	httpReplayCmd.Flags().StringVar(&replayTarget, "target", "http://localhost:3000", "Base URL of the environment to replay against")
+	httpReplayCmd.Flags().StringArrayVar(&replayHeaders, "header", nil, "Header to add to every request, e.g. 'Authorization: Bearer ...' (repeatable)")
+	httpReplayCmd.Flags().BoolVar(&replayCompareBody, "compare-body", false, "Also compare response bodies with the recording")
+	httpCmd.AddCommand(httpRecordCmd)
+	httpCmd.AddCommand(httpReplayCmd)
+	rootCmd.AddCommand(httpCmd)
+}
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// introspectJSON is set by `gonext introspect --json`
+var introspectJSON bool
+
+// introspectSchema is bumped when a field of the introspection document changes
+// meaning or is removed; new fields may be added without bumping it
+const introspectSchema = 1
+
+// Introspection describes the generators and the project layout for editor extensions
+type Introspection struct {
+	Schema     int                `json:"schema"`
+	Generators []GeneratorSpec    `json:"generators"`
+	Layout     Layout             `json:"layout"`
+	Modules    []workspace.Module `json:"modules"`
+}
+
+// GeneratorSpec is a `gonext generate` subcommand
+type GeneratorSpec struct {
+	Name        string     `json:"name"`
+	Command     []string   `json:"command"` // arguments before the positional args, e.g. ["generate", "module"]
+	Aliases     []string   `json:"aliases,omitempty"`
+	Usage       string     `json:"usage"`
+	Description string     `json:"description"`
+	Long        string     `json:"long,omitempty"`
+	Args        []ArgSpec  `json:"args"`
+	MinArgs     int        `json:"min_args"`
+	MaxArgs     int        `json:"max_args"` // -1 when unbounded
+	Flags       []FlagSpec `json:"flags"`
+}
+
+// ArgSpec is a positional argument, named after the command's usage line
+type ArgSpec struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	Variadic bool   `json:"variadic,omitempty"`
+}
+
+// FlagSpec is a flag of a generator
+type FlagSpec struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	Type      string `json:"type"` // bool, string, stringSlice, int, duration, ...
+	Default   string `json:"default"`
+	Usage     string `json:"usage"`
+	Inherited bool   `json:"inherited,omitempty"` // shared by every generator, e.g. --open
+}
+
+// Layout tells where generated code lives in the project
+type Layout struct {
+	Root       string            `json:"root"`
+	GoModule   string            `json:"go_module,omitempty"`
+	Manifest   string            `json:"manifest,omitempty"` // gonext.yaml, when present
+	AppDir     string            `json:"app_dir"`
+	ModuleDirs []string          `json:"module_dirs"`
+	Files      map[string]string `json:"files"` // component kind to path pattern with {module} and {name}
+}
+
+// componentFiles are the path patterns of the component generators
+var componentFiles = map[string]string{
+	"module":       "app/{module}/module.go",
+	"controller":   "app/{module}/controller/{name}Controller.go",
+	"service":      "app/{module}/service/{name}Service.go",
+	"repository":   "app/{module}/repository/{name}Repository.go",
+	"route":        "app/{module}/route/{name}Route.go",
+	"dto":          "app/{module}/dto/{name}DTO.go",
+	"entity":       "app/{module}/entity/{name}Entity.go",
+	"model":        "app/{module}/model/{name}Model.go",
+	"middleware":   "app/{module}/middleware/{name}Middleware.go",
+	"interceptor":  "app/{module}/interceptor/{name}Interceptor.go",
+	"job":          "app/{module}/job/{name}Job.go",
+	"task":         "app/{module}/schedule/{name}Task.go",
+	"provider":     "app/{module}/provider/{name}Provider.go",
+	"resolver":     "app/{module}/resolver/{name}Resolver.go",
+	"pipe":         "app/{module}/pipe/{name}Pipe.go",
+	"filter":       "app/{module}/filter/{name}Filter.go",
+	"event":        "app/{module}/event/{name}Event.go",
+	"listener":     "app/{module}/listener/{name}Listener.go",
+	"mail":         "app/{module}/mail/{name}.go",
+	"notification": "app/{module}/notification/{name}Notification.go",
+}
+
+// usageArgs reads the positional argument names from a usage line such as
+// "model [name] [field:type[:modifier...]...]"
+func usageArgs(use string) []ArgSpec {
+	_, rest, _ := strings.Cut(use, " ")
+	var args []ArgSpec
+	depth, start := 0, 0
+	for i, r := range rest {
+		switch r {
+		case '[':
+			if depth == 0 {
+				start = i + 1
+			}
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				name := rest[start:i]
+				variadic := strings.HasSuffix(name, "...")
+				args = append(args, ArgSpec{Name: strings.TrimSuffix(name, "..."), Variadic: variadic})
+			}
+		}
+	}
+	return args
+}
+
+// argBounds probes the command's argument validator for the accepted counts
+func argBounds(cmd *cobra.Command, declared int) (int, int) {
+	if cmd.Args == nil {
+		return 0, -1
+	}
+	accepts := func(n int) bool { return cmd.Args(cmd, make([]string, n)) == nil }
+	lo := 0
+	for lo <= declared && !accepts(lo) {
+		lo++
+	}
+	if accepts(declared + 8) {
+		return lo, -1
+	}
+	hi := lo
+	for hi < declared+8 && accepts(hi+1) {
+		hi++
+	}
+	return lo, hi
+}
+
+// flagSpecs lists the flags of a command, its own first
+func flagSpecs(cmd *cobra.Command) []FlagSpec {
+	flags := []FlagSpec{}
+	add := func(inherited bool) func(*pflag.Flag) {
+		return func(f *pflag.Flag) {
+			if f.Hidden || f.Name == "help" {
+				return
+			}
+			flags = append(flags, FlagSpec{Name: f.Name, Shorthand: f.Shorthand, Type: f.Value.Type(), Default: f.DefValue, Usage: f.Usage, Inherited: inherited})
+		}
+	}
+	cmd.LocalFlags().VisitAll(add(false))
+	cmd.InheritedFlags().VisitAll(add(true))
+	return flags
+}
+
+// generatorSpecs describes every generate subcommand
+func generatorSpecs() []GeneratorSpec {
+	specs := []GeneratorSpec{}
+	for _, c := range generateCmd.Commands() {
+		if c.Hidden || !c.IsAvailableCommand() {
+			continue
+		}
+		args := usageArgs(c.Use)
+		lo, hi := argBounds(c, len(args))
+		for i := range args {
+			args[i].Required = i < lo
+		}
+		if args == nil {
+			args = []ArgSpec{}
+		}
+		specs = append(specs, GeneratorSpec{
+			Name:        c.Name(),
+			Command:     []string{generateCmd.Name(), c.Name()},
+			Aliases:     append([]string{gCmd.Name() + " " + c.Name()}, c.Aliases...),
+			Usage:       rootCmd.Name() + " " + generateCmd.Name() + " " + c.Use,
+			Description: c.Short,
+			Long:        c.Long,
+			Args:        args,
+			MinArgs:     lo,
+			MaxArgs:     hi,
+			Flags:       flagSpecs(c),
+		})
+	}
+	return specs
+}
+
+// projectLayout describes the project in the current directory
+func projectLayout() Layout {
+	root, _ := os.Getwd()
+	layout := Layout{
+		Root:       root,
+		AppDir:     workspace.AppDir,
+		ModuleDirs: []string{"controller", "repository", "route", "service"},
+		Files:      componentFiles,
+	}
+	if _, err := os.Stat("go.mod"); err == nil {
+		layout.GoModule = getModuleName()
+	}
+	if _, err := os.Stat(manifest.FileName); err == nil {
+		layout.Manifest = manifest.FileName
+	}
+	return layout
+}
+
+var introspectCmd = &cobra.Command{
+	Use:   "introspect",
+	Short: "Describe the generators, their arguments and flags, and the project layout",
+	Long: `Prints what editor extensions need to build scaffolding forms on top of the
+CLI: every generator with its positional arguments, accepted argument counts and
+flags (name, type, default, description), the path pattern of each component and
+the project's modules.
+
+The --json document is stable: its "schema" field is bumped only when an existing
+field changes meaning or is removed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		modules, err := workspace.Modules()
+		if err != nil {
+			fmt.Printf("Error scanning modules: %v\n", err)
+			return
+		}
+		if modules == nil {
+			modules = []workspace.Module{}
+		}
+		doc := Introspection{Schema: introspectSchema, Generators: generatorSpecs(), Layout: projectLayout(), Modules: modules}
+		if introspectJSON {
+			data, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				fmt.Printf("Error encoding JSON: %v\n", err)
+				return
+			}
+			fmt.Println(string(data))
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "GENERATOR\tARGS\tFLAGS")
+		for _, g := range doc.Generators {
+			var names []string
+			for _, a := range g.Args {
+				names = append(names, a.Name)
+			}
+			var flags []string
+			for _, f := range g.Flags {
+				if !f.Inherited {
+					flags = append(flags, "--"+f.Name)
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", g.Name, strings.Join(names, " "), strings.Join(flags, " "))
+		}
+		w.Flush()
+		fmt.Printf("%d module(s) in %s/. Pass --json for the machine-readable document.\n", len(doc.Modules), doc.Layout.AppDir)
+	},
+}
+
+func init() {
+	introspectCmd.Flags().BoolVar(&introspectJSON, "json", false, "Print the description as JSON")
+	rootCmd.AddCommand(introspectCmd)
+}
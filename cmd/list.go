@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var listJSON bool
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print an inventory of modules, apps, routes or jobs in the project",
+}
+
+// printInventory prints items as JSON, or as a table built from the given columns
+func printInventory[T any](items []T, header string, row func(T) string) {
+	if listJSON {
+		if items == nil {
+			items = []T{}
+		}
+		data, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			fmt.Printf("Error encoding JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+	if len(items) == 0 {
+		fmt.Println("Nothing found.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, header)
+	for _, item := range items {
+		fmt.Fprintln(w, row(item))
+	}
+	w.Flush()
+}
+
+var listModulesCmd = &cobra.Command{
+	Use:   "modules",
+	Short: "List the modules in app/",
+	Run: func(cmd *cobra.Command, args []string) {
+		modules, err := workspace.Modules()
+		if err != nil {
+			fmt.Printf("Error scanning modules: %v\n", err)
+			return
+		}
+		printInventory(modules, "NAME\tPREFIX\tTAGS\tPATH", func(m workspace.Module) string {
+			return fmt.Sprintf("%s\t%s\t%s\t%s", m.Name, m.Prefix, strings.Join(m.Tags, ","), m.Path)
+		})
+	},
+}
+
+var listAppsCmd = &cobra.Command{
+	Use:   "apps",
+	Short: "List the runnable entrypoints of the project",
+	Run: func(cmd *cobra.Command, args []string) {
+		apps, err := workspace.Apps()
+		if err != nil {
+			fmt.Printf("Error scanning apps: %v\n", err)
+			return
+		}
+		printInventory(apps, "NAME\tPATH", func(a workspace.App) string {
+			return fmt.Sprintf("%s\t%s", a.Name, a.Path)
+		})
+	},
+}
+
+var listRoutesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: "List the HTTP routes registered by modules",
+	Run: func(cmd *cobra.Command, args []string) {
+		routes, err := workspace.Routes()
+		if err != nil {
+			fmt.Printf("Error scanning routes: %v\n", err)
+			return
+		}
+		printInventory(routes, "METHOD\tPATH\tMODULE\tHANDLER\tDEPRECATED\tLOCATION", func(r workspace.Route) string {
+			return fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s:%d", r.Method, r.Path, r.Module, r.Handler, deprecationColumn(r.Deprecation), r.File, r.Line)
+		})
+	},
+}
+
+// deprecationColumn shows when a deprecated route is removed
+func deprecationColumn(d *workspace.Deprecation) string {
+	switch {
+	case d == nil:
+		return ""
+	case d.Sunset != "":
+		return "sunset " + d.Sunset
+	default:
+		return "yes"
+	}
+}
+
+var listJobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "List background jobs, workers and scheduled tasks",
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs, err := workspace.Jobs()
+		if err != nil {
+			fmt.Printf("Error scanning jobs: %v\n", err)
+			return
+		}
+		printInventory(jobs, "NAME\tMODULE\tFILE", func(j workspace.Job) string {
+			return fmt.Sprintf("%s\t%s\t%s", j.Name, j.Module, j.File)
+		})
+	},
+}
+
+func init() {
+	listCmd.PersistentFlags().BoolVar(&listJSON, "json", false, "Output as JSON")
+	listCmd.AddCommand(listModulesCmd)
+	listCmd.AddCommand(listAppsCmd)
+	listCmd.AddCommand(listRoutesCmd)
+	listCmd.AddCommand(listJobsCmd)
+	rootCmd.AddCommand(listCmd)
+}
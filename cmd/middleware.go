@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/spf13/cobra"
+)
+
+var middlewareManageCmd = &cobra.Command{
+	Use:   "middleware",
+	Short: "List, reorder, enable or disable the global middleware chain in bootstrap/middleware.go",
+}
+
+// readMiddlewareChain returns the entries of the bootstrap middleware chain
+func readMiddlewareChain() ([]codemod.Entry, bool) {
+	src, err := os.ReadFile(middlewareChainFile)
+	if err != nil {
+		fmt.Printf("Error reading %s: %v (generate it with 'gonext g bootstrap')\n", middlewareChainFile, err)
+		return nil, false
+	}
+	entries, err := codemod.ReadSlice(src, "Middlewares")
+	if err != nil {
+		fmt.Printf("Error parsing %s: %v\n", middlewareChainFile, err)
+		return nil, false
+	}
+	return entries, true
+}
+
+// writeMiddlewareChain rewrites the bootstrap middleware chain and prints the result
+func writeMiddlewareChain(entries []codemod.Entry) {
+	err := editGenerated(middlewareChainFile, func(src []byte) ([]byte, error) {
+		src, err := codemod.WriteSlice(src, "Middlewares", entries)
+		if err != nil {
+			return nil, err
+		}
+		// Disabling the last use of a package would leave an unused import behind
+		return codemod.FixImports(src)
+	})
+	if err != nil {
+		fmt.Printf("Error updating %s: %v\n", middlewareChainFile, err)
+		return
+	}
+	printMiddlewareChain(entries)
+}
+
+func printMiddlewareChain(entries []codemod.Entry) {
+	if len(entries) == 0 {
+		fmt.Println("The middleware chain is empty.")
+		return
+	}
+	for i, e := range entries {
+		state := "enabled"
+		if !e.Enabled {
+			state = "disabled"
+		}
+		fmt.Printf("%2d. %-8s %s\n", i+1, state, e.Expr)
+	}
+}
+
+// findMiddleware returns the index of the single entry matching name (case-insensitive substring)
+func findMiddleware(entries []codemod.Entry, name string) (int, error) {
+	found := -1
+	for i, e := range entries {
+		if strings.Contains(strings.ToLower(e.Expr), strings.ToLower(name)) {
+			if found >= 0 {
+				return -1, fmt.Errorf("'%s' matches more than one middleware; be more specific", name)
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return -1, fmt.Errorf("no middleware matching '%s'", name)
+	}
+	return found, nil
+}
+
+var middlewareListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the global middleware chain in execution order",
+	Run: func(cmd *cobra.Command, args []string) {
+		if entries, ok := readMiddlewareChain(); ok {
+			printMiddlewareChain(entries)
+		}
+	},
+}
+
+var middlewareReorderCmd = &cobra.Command{
+	Use:   "reorder [name...]",
+	Short: "Move the named middlewares to the front of the chain in the given order",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, ok := readMiddlewareChain()
+		if !ok {
+			return
+		}
+		var ordered []codemod.Entry
+		taken := map[int]bool{}
+		for _, name := range args {
+			i, err := findMiddleware(entries, name)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if taken[i] {
+				fmt.Printf("'%s' is listed twice\n", name)
+				return
+			}
+			taken[i] = true
+			ordered = append(ordered, entries[i])
+		}
+		for i, e := range entries {
+			if !taken[i] {
+				ordered = append(ordered, e)
+			}
+		}
+		writeMiddlewareChain(ordered)
+	},
+}
+
+// toggleMiddlewareCmd builds the enable and disable commands
+func toggleMiddlewareCmd(use string, enabled bool) *cobra.Command {
+	return &cobra.Command{
+		Use:   use + " [name]",
+		Short: strings.Title(use) + " a middleware without removing it from the chain",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			entries, ok := readMiddlewareChain()
+			if !ok {
+				return
+			}
+			i, err := findMiddleware(entries, args[0])
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			entries[i].Enabled = enabled
+			writeMiddlewareChain(entries)
+		},
+	}
+}
+
+func init() {
+	middlewareManageCmd.AddCommand(middlewareListCmd)
+	middlewareManageCmd.AddCommand(middlewareReorderCmd)
+	middlewareManageCmd.AddCommand(toggleMiddlewareCmd("enable", true))
+	middlewareManageCmd.AddCommand(toggleMiddlewareCmd("disable", false))
+	rootCmd.AddCommand(middlewareManageCmd)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codemod"
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+var moduleManageCmd = &cobra.Command{
+	Use:   "module",
+	Short: "Enable or disable modules in the bootstrap module registry",
+	Long: `Flips the Enabled flag of a module in bootstrap/modules.go and records it in gonext.yaml.
+At runtime MODULE_<NAME>_ENABLED=true|false overrides the registry without code changes.`,
+}
+
+// setModuleEnabled updates the registry entry and the manifest for a module
+func setModuleEnabled(name string, enabled bool) error {
+	err := editGenerated(moduleRegistryFile, func(src []byte) ([]byte, error) {
+		entries, err := codemod.ReadSlice(src, "Modules")
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for i, e := range entries {
+			if !strings.Contains(e.Expr, fmt.Sprintf("Name: %q", name)) {
+				continue
+			}
+			found = true
+			entries[i].Expr = strings.Replace(e.Expr, fmt.Sprintf("Enabled: %t", !enabled), fmt.Sprintf("Enabled: %t", enabled), 1)
+		}
+		if !found {
+			return nil, fmt.Errorf("module '%s' is not registered", name)
+		}
+		return codemod.WriteSlice(src, "Modules", entries)
+	})
+	if err != nil {
+		return err
+	}
+
+	m, err := manifest.Load()
+	if err != nil {
+		return err
+	}
+	settings := m.Modules[name]
+	settings.Disabled = !enabled
+	m.SetModule(name, settings)
+	return m.Save()
+}
+
+// toggleModuleCmd builds the enable and disable commands
+func toggleModuleCmd(use string, enabled bool) *cobra.Command {
+	return &cobra.Command{
+		Use:   use + " [name]",
+		Short: strings.Title(use) + " a module in the bootstrap module registry",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := setModuleEnabled(args[0], enabled); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			fmt.Printf("Module '%s' %sd in %s\n", args[0], use, moduleRegistryFile)
+		},
+	}
+}
+
+func init() {
+	moduleManageCmd.AddCommand(toggleModuleCmd("enable", true))
+	moduleManageCmd.AddCommand(toggleModuleCmd("disable", false))
+	rootCmd.AddCommand(moduleManageCmd)
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,15 +11,21 @@ import (
 
 	"io/ioutil"
 
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/Alexigbokwe/gonext/internal/progress"
+	"github.com/Alexigbokwe/gonext/internal/utils"
 	"github.com/spf13/cobra"
 )
 
 const starterRepo = "https://github.com/Alexigbokwe/Go_Next.git"
 const oldModuleName = "goNext" // The module name used in the starter repo
 
+// newTemplate is set by `gonext new --template`
+var newTemplate string
+
 var newCmd = &cobra.Command{
 	Use:   "new [project name]",
-	Short: "Scaffold a new GoNext project from the official starter template",
+	Short: "Scaffold a new GoNext project from the official starter or an organization template",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		projectName := args[0]
@@ -30,22 +37,47 @@ var newCmd = &cobra.Command{
 			return
 		}
 
-		// Clone the starter repo into a temp directory
-		cmdGit := exec.Command("git", "clone", starterRepo, tempDir)
-		cmdGit.Stdout = os.Stdout
-		cmdGit.Stderr = os.Stderr
-		fmt.Printf("Cloning starter project from %s...\n", starterRepo)
-		if err := cmdGit.Run(); err != nil {
+		// Clone the starter repo, or the requested template, into a temp directory
+		src, err := resolveTemplate(newTemplate)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		spinner := progress.Start(fmt.Sprintf("Cloning starter project from %s", src.Repo))
+		err = runGit(src.Env, "clone", "--quiet", src.URL, tempDir)
+		spinner.Stop(err)
+		if err != nil {
 			fmt.Printf("Error cloning repository: %v\n", err)
 			return
 		}
 
+		// Remember which template commit the project was scaffolded from
+		template := &codegen.Template{Repo: src.Repo}
+		if out, err := exec.Command("git", "-C", tempDir, "rev-parse", "HEAD").Output(); err == nil {
+			template.Commit = strings.TrimSpace(string(out))
+		}
+
 		// Remove .git directory from the cloned project
 		gitDir := filepath.Join(tempDir, ".git")
 		if err := os.RemoveAll(gitDir); err != nil {
 			fmt.Printf("Warning: could not remove .git directory: %v\n", err)
 		}
 
+		// Refuse templates that ship credentials, unless they are known to be harmless
+		if !allowSecrets {
+			files, err := scaffoldedFiles(tempDir)
+			if err != nil {
+				fmt.Printf("Error reading the starter project: %v\n", err)
+				os.RemoveAll(tempDir)
+				return
+			}
+			if containsSecrets(files) {
+				fmt.Println("Refusing to create a project from a template containing secrets. Pass --allow-secrets to create it anyway.")
+				os.RemoveAll(tempDir)
+				return
+			}
+		}
+
 		// Rename the temp directory to the target project name
 		if err := os.Rename(tempDir, projectName); err != nil {
 			fmt.Printf("Error renaming project directory: %v\n", err)
@@ -63,15 +95,27 @@ var newCmd = &cobra.Command{
 
 		// Update go.mod in the new project directory
 		goModPath := filepath.Join(projectName, "go.mod")
+		templateModule := templateModuleName(goModPath)
 		if err := updateGoMod(goModPath, modulePath); err != nil {
 			fmt.Printf("Error updating go.mod: %v\n", err)
 		}
 
 		// Update all import paths in .go files
-		if err := updateImports(projectName, oldModuleName, modulePath); err != nil {
+		goFiles, err := collectGoFiles(projectName)
+		if err == nil {
+			spinner = progress.StartCount("Rewriting import paths", len(goFiles))
+			err = updateImports(goFiles, templateModule, modulePath, spinner.Increment)
+			spinner.Stop(err)
+		}
+		if err != nil {
 			fmt.Printf("Error updating import paths: %v\n", err)
 		}
 
+		// Record the scaffolded files as the template baseline for 'gonext diff' and re-generation
+		if err := recordBaseline(projectName, template); err != nil {
+			fmt.Printf("Warning: could not record template baseline: %v\n", err)
+		}
+
 		fmt.Printf("New GoNext project '%s' created.\n", projectName)
 		fmt.Println("Don't forget to run 'go mod tidy' in your new project!")
 	},
@@ -91,14 +135,73 @@ func updateGoMod(goModPath, newModule string) error {
 	return ioutil.WriteFile(goModPath, []byte(output), 0644)
 }
 
-func updateImports(rootDir, oldModule, newModule string) error {
-	return filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+// templateModuleName returns the module path a template's imports use, read from
+// its go.mod since organization templates don't share the starter's module name
+func templateModuleName(goModPath string) string {
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return oldModuleName
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`)
+		}
+	}
+	return oldModuleName
+}
+
+// recordBaseline stores the hashes and content of every scaffolded file in the project's .gonext directory
+func recordBaseline(projectDir string, template *codegen.Template) error {
+	state, err := codegen.LoadStateAt(projectDir)
+	if err != nil {
+		return err
+	}
+	state.Template = template
+	if err := state.RecordTree(); err != nil {
+		return err
+	}
+	return state.Save()
+}
+
+// scaffoldedFiles reads the text files of a scaffolded project, skipping binaries
+func scaffoldedFiles(rootDir string) ([]codegen.File, error) {
+	var files []codegen.File
+	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || info.Size() > 1<<20 {
+			return err
+		}
+		data, err := os.ReadFile(path)
 		if err != nil {
 			return err
 		}
-		if info.IsDir() || !strings.HasSuffix(path, ".go") {
-			return nil
+		if !bytes.Contains(data, []byte{0}) {
+			rel, _ := filepath.Rel(rootDir, path)
+			files = append(files, codegen.File{Path: rel, Content: string(data)})
 		}
+		return nil
+	})
+	return files, err
+}
+
+// collectGoFiles returns every .go file below rootDir
+func collectGoFiles(rootDir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, ".go") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// updateImports rewrites import paths in the given files concurrently, calling done after each file
+func updateImports(files []string, oldModule, newModule string, done func()) error {
+	return utils.ForEach(files, 0, func(path string) error {
+		defer done()
 		input, err := ioutil.ReadFile(path)
 		if err != nil {
 			return err
@@ -116,5 +219,7 @@ func updateImports(rootDir, oldModule, newModule string) error {
 }
 
 func init() {
+	newCmd.Flags().StringVar(&newTemplate, "template", "", "Template to scaffold from: <registry>/<repository>, a git URL or a path (default: the official starter)")
+	newCmd.Flags().BoolVar(&allowSecrets, "allow-secrets", false, "Create the project even if the template contains private keys or access keys")
 	rootCmd.AddCommand(newCmd)
 }
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Alexigbokwe/gonext/internal/openapi"
+	"github.com/spf13/cobra"
+)
+
+var openapiOutput string
+var openapiTitle string
+var openapiVersion string
+
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Generate an OpenAPI spec from module routes and DTO/entity struct tags",
+	Long: `Builds an OpenAPI 3 document from the routes in app/<module>/route and the structs in
+app/<module>/dto and app/<module>/entity. Struct tags improve the spec without hand edits:
+
+  json:"email"                 property name
+  validate:"required,email"    required fields, formats, lengths and ranges
+  example:"jane@example.com"   example value
+  description:"Login e-mail"   property description
+  deprecated:"true"            marks the property deprecated`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title := openapiTitle
+		if title == "" {
+			title = getModuleName()
+		}
+		doc, err := openapi.Build(title, openapiVersion)
+		if err != nil {
+			fmt.Printf("Error building OpenAPI spec: %v\n", err)
+			return
+		}
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			fmt.Printf("Error encoding OpenAPI spec: %v\n", err)
+			return
+		}
+		if openapiOutput == "-" {
+			fmt.Println(string(data))
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(openapiOutput), 0755); err != nil {
+			fmt.Printf("Error creating %s: %v\n", filepath.Dir(openapiOutput), err)
+			return
+		}
+		if err := os.WriteFile(openapiOutput, append(data, '\n'), 0644); err != nil {
+			fmt.Printf("Error writing %s: %v\n", openapiOutput, err)
+			return
+		}
+		fmt.Printf("OpenAPI spec written to %s (%d paths, %d schemas)\n", openapiOutput, len(doc.Paths), len(doc.Components.Schemas))
+	},
+}
+
+func init() {
+	openapiCmd.Flags().StringVarP(&openapiOutput, "output", "o", filepath.Join("docs", "openapi.json"), "Output file ('-' for stdout)")
+	openapiCmd.Flags().StringVar(&openapiTitle, "title", "", "API title (defaults to the Go module name)")
+	openapiCmd.Flags().StringVar(&openapiVersion, "version", "1.0.0", "API version")
+	rootCmd.AddCommand(openapiCmd)
+}
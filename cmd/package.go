@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+// packageTargets and packageFormats are set by `gonext package --targets/--nfpm`
+var packageTargets []string
+var packageFormats []string
+
+// distDir receives release archives, checksums and system packages
+const distDir = "dist"
+
+// packagingDir holds the nfpm config and the systemd unit shipped in deb/rpm packages
+const packagingDir = "packaging"
+
+const nfpmTemplate = `# Generated by 'gonext package --nfpm'. Edit freely: it is only created once.
+# VERSION and GOARCH are set by gonext for every package it builds.
+name: %[1]s
+arch: ${GOARCH}
+platform: linux
+version: ${VERSION}
+section: default
+priority: optional
+maintainer: TODO <team@example.com>
+description: %[1]s service
+contents:
+  - src: ./dist/%[1]s_linux_${GOARCH}/%[1]s
+    dst: /usr/bin/%[1]s
+    file_info:
+      mode: 0755
+  - src: ./packaging/%[1]s.service
+    dst: /lib/systemd/system/%[1]s.service
+    type: config
+  - dst: /etc/%[1]s
+    type: dir
+  - src: ./packaging/%[1]s.env
+    dst: /etc/%[1]s/%[1]s.env
+    type: config|noreplace
+`
+
+const systemdUnitTemplate = `[Unit]
+Description=%[1]s
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+ExecStart=/usr/bin/%[1]s
+EnvironmentFile=-/etc/%[1]s/%[1]s.env
+DynamicUser=yes
+Restart=on-failure
+# Graceful shutdown drains in-flight requests before exiting
+KillSignal=SIGTERM
+TimeoutStopSec=30
+
+[Install]
+WantedBy=multi-user.target
+`
+
+const packageEnvTemplate = `# Environment for the %s service, read by systemd. Keep secrets out of version control.
+APP_ENV=production
+SERVER_PORT=3000
+`
+
+// packageDocs are copied into every archive when present
+var packageDocs = []string{"README.md", "readMe.md", "LICENSE", ".env.example"}
+
+// packagingFiles returns the nfpm config, systemd unit and env file if missing
+func packagingFiles(app string) []codegen.File {
+	files := missingFile(codegen.File{Path: "nfpm.yaml", Content: fmt.Sprintf(nfpmTemplate, app)})
+	files = append(files, missingFile(codegen.File{Path: filepath.Join(packagingDir, app+".service"), Content: fmt.Sprintf(systemdUnitTemplate, app)})...)
+	return append(files, missingFile(codegen.File{Path: filepath.Join(packagingDir, app+".env"), Content: fmt.Sprintf(packageEnvTemplate, app)})...)
+}
+
+// crossBuild compiles a static binary for goos/goarch into dist/<app>_<os>_<arch>/
+func crossBuild(app, goos, goarch string) (string, error) {
+	binary := app
+	if goos == "windows" {
+		binary += ".exe"
+	}
+	out := filepath.Join(distDir, fmt.Sprintf("%s_%s_%s", app, goos, goarch), binary)
+	env := []string{"CGO_ENABLED=0", "GOOS=" + goos, "GOARCH=" + goarch}
+	err := runCommand(env, "go", "build", "-trimpath", "-ldflags", "-s -w", "-o", out, ".")
+	return out, err
+}
+
+// archive packs the binary and docs as a .zip on Windows and a .tar.gz elsewhere
+func archive(binary, name, goos string) (string, error) {
+	files := []string{binary}
+	for _, doc := range packageDocs {
+		if _, err := os.Stat(doc); err == nil {
+			files = append(files, doc)
+		}
+	}
+	if goos == "windows" {
+		path := filepath.Join(distDir, name+".zip")
+		return path, writeZip(path, files)
+	}
+	path := filepath.Join(distDir, name+".tar.gz")
+	return path, writeTarGz(path, files)
+}
+
+func writeTarGz(path string, files []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.Base(file)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if err := copyFile(tw, file); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeZip(path string, files []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		hdr, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.Base(file)
+		hdr.Method = zip.Deflate
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		if err := copyFile(w, file); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// writeChecksums records the SHA-256 of every artifact in dist/checksums.txt, in sha256sum format
+func writeChecksums(paths []string) (string, error) {
+	var b strings.Builder
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			return "", err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(fmt.Sprintf("%s  %s\n", hex.EncodeToString(h.Sum(nil)), filepath.Base(p)))
+	}
+	out := filepath.Join(distDir, "checksums.txt")
+	return out, os.WriteFile(out, []byte(b.String()), 0644)
+}
+
+// nfpmPackage builds a deb or rpm for a linux binary and returns its path
+func nfpmPackage(format, goarch, version string) (string, error) {
+	before, _ := filepath.Glob(filepath.Join(distDir, "*."+format))
+	// Debian and RPM versions must start with a digit
+	pkgVersion := strings.TrimPrefix(version, "v")
+	if pkgVersion == "" || pkgVersion[0] < '0' || pkgVersion[0] > '9' {
+		pkgVersion = "0.0.0~" + pkgVersion
+	}
+	env := []string{"GOARCH=" + goarch, "VERSION=" + pkgVersion}
+	if err := runCommand(env, "nfpm", "package", "--config", "nfpm.yaml", "--packager", format, "--target", distDir+"/"); err != nil {
+		return "", err
+	}
+	after, _ := filepath.Glob(filepath.Join(distDir, "*."+format))
+	for _, p := range after {
+		if !slices.Contains(before, p) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("nfpm did not produce a .%s package", format)
+}
+
+var packageCmd = &cobra.Command{
+	Use:   "package",
+	Short: "Build release archives, checksums and optional deb/rpm packages in dist/",
+	Long: `Cross-compiles a static binary for every --targets entry and packs it with the
+README, LICENSE and .env.example into dist/<app>_<version>_<os>_<arch>.tar.gz
+(.zip on Windows). dist/checksums.txt lists the SHA-256 of every artifact.
+dist/ is emptied first.
+
+--nfpm deb,rpm also builds Linux packages with nfpm, installing a systemd unit.
+nfpm.yaml and the unit in packaging/ are generated on first use.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, f := range packageFormats {
+			if f != "deb" && f != "rpm" && f != "apk" {
+				fmt.Printf("Unknown package format '%s' (expected deb, rpm or apk)\n", f)
+				return
+			}
+		}
+		if len(packageFormats) > 0 {
+			if _, err := exec.LookPath("nfpm"); err != nil {
+				fmt.Println("'nfpm' is required for --nfpm. Install it with 'go install github.com/goreleaser/nfpm/v2/cmd/nfpm@latest'.")
+				return
+			}
+		}
+		app := defaultImage()
+		version, _ := buildVersion()
+		if len(packageFormats) > 0 {
+			if files := packagingFiles(app); len(files) > 0 && !writeGenerated(files...) {
+				return
+			}
+		}
+
+		// Start from an empty dist/ so checksums only cover this release
+		if err := os.RemoveAll(distDir); err != nil {
+			fmt.Printf("Error cleaning %s: %v\n", distDir, err)
+			return
+		}
+		var artifacts []string
+		for _, target := range packageTargets {
+			goos, goarch, ok := strings.Cut(target, "/")
+			if !ok {
+				fmt.Printf("Invalid target '%s' (expected os/arch, e.g. linux/amd64)\n", target)
+				return
+			}
+			binary, err := crossBuild(app, goos, goarch)
+			if err != nil {
+				fmt.Printf("Error building %s: %v\n", target, err)
+				return
+			}
+			path, err := archive(binary, fmt.Sprintf("%s_%s_%s_%s", app, version, goos, goarch), goos)
+			if err != nil {
+				fmt.Printf("Error archiving %s: %v\n", target, err)
+				return
+			}
+			artifacts = append(artifacts, path)
+			if goos != "linux" {
+				continue
+			}
+			for _, format := range packageFormats {
+				pkg, err := nfpmPackage(format, goarch, version)
+				if err != nil {
+					fmt.Printf("Error building %s package for %s: %v\n", format, target, err)
+					return
+				}
+				artifacts = append(artifacts, pkg)
+			}
+		}
+		checksums, err := writeChecksums(artifacts)
+		if err != nil {
+			fmt.Printf("Error writing checksums: %v\n", err)
+			return
+		}
+		for _, a := range artifacts {
+			fmt.Printf("  %s\n", a)
+		}
+		fmt.Printf("Packaged %s %s: %d artifact(s), checksums in %s\n", app, version, len(artifacts), checksums)
+	},
+}
+
+func init() {
+	packageCmd.Flags().StringSliceVar(&packageTargets, "targets", []string{"linux/amd64", "linux/arm64", "darwin/amd64", "darwin/arm64", "windows/amd64"}, "GOOS/GOARCH pairs to build")
+	packageCmd.Flags().StringSliceVar(&packageFormats, "nfpm", nil, "Linux packages to build with nfpm: deb, rpm, apk")
+	rootCmd.AddCommand(packageCmd)
+}
@@ -0,0 +1,340 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Alexigbokwe/gonext/internal/codegen"
+	"github.com/spf13/cobra"
+)
+
+// profileURL, profileFile and profileSort are set by `gonext profile routes --url/--file/--sort`
+var profileURL string
+var profileFile string
+var profileSort string
+
+// profilerFile holds the dev middleware measuring per-route latency and allocations
+var profilerFile = filepath.Join("app", "profiler", "profiler.go")
+
+const profilerTemplate = `package profiler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime/metrics"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Path serves the current report, read by 'gonext profile routes'
+const Path = "/debug/routes"
+
+// samples is how many recent durations each route keeps for percentiles
+const samples = 1000
+
+// Route summarizes the requests served by one route
+type Route struct {
+	Method      string  ` + "`json:\"method\"`" + `
+	Route       string  ` + "`json:\"route\"`" + `
+	Count       int     ` + "`json:\"count\"`" + `
+	Errors      int     ` + "`json:\"errors\"`" + ` // 5xx responses
+	P50Ms       float64 ` + "`json:\"p50Ms\"`" + `
+	P95Ms       float64 ` + "`json:\"p95Ms\"`" + `
+	MaxMs       float64 ` + "`json:\"maxMs\"`" + `
+	AllocsBytes uint64  ` + "`json:\"allocsBytes\"`" + ` // heap allocated per request, on average
+}
+
+type route struct {
+	method, path string
+	count        int
+	errors       int
+	durations    []time.Duration // ring of the latest samples
+	max          time.Duration
+	allocs       uint64
+}
+
+type profiler struct {
+	mu     sync.Mutex
+	routes map[string]*route
+	dirty  bool
+}
+
+// Enabled reports whether routes are profiled, set with HTTP_PROFILE=true.
+// 'gonext start' enables it in development.
+func Enabled() bool {
+	on, _ := strconv.ParseBool(os.Getenv("HTTP_PROFILE"))
+	return on
+}
+
+// Middleware measures the latency, error rate and heap allocations of every
+// route and serves the report on GET /debug/routes. The report is also saved to
+// HTTP_PROFILE_FILE (default tmp/profile.json) every second, so it
+// survives a shutdown. Allocations are process-wide: under concurrent load
+// they are approximate. It does nothing unless HTTP_PROFILE is enabled.
+func Middleware() fiber.Handler {
+	if !Enabled() {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	p := &profiler{routes: map[string]*route{}}
+	go p.save(reportFile())
+	return func(c *fiber.Ctx) error {
+		if c.Method() == fiber.MethodGet && c.Path() == Path {
+			return c.JSON(p.report())
+		}
+		allocs := heapAllocs()
+		start := time.Now()
+		err := c.Next()
+		elapsed := time.Since(start)
+		allocated := heapAllocs() - allocs
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+		}
+		p.add(c.Method(), c.Route().Path, elapsed, allocated, status >= 500)
+		return err
+	}
+}
+
+func (p *profiler) add(method, path string, d time.Duration, allocs uint64, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := method + " " + path
+	r, ok := p.routes[key]
+	if !ok {
+		r = &route{method: method, path: path}
+		p.routes[key] = r
+	}
+	if len(r.durations) < samples {
+		r.durations = append(r.durations, d)
+	} else {
+		r.durations[r.count%samples] = d
+	}
+	r.count++
+	r.allocs += allocs
+	if failed {
+		r.errors++
+	}
+	if d > r.max {
+		r.max = d
+	}
+	p.dirty = true
+}
+
+func (p *profiler) report() []Route {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	report := make([]Route, 0, len(p.routes))
+	for _, r := range p.routes {
+		sorted := append([]time.Duration(nil), r.durations...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		report = append(report, Route{
+			Method:      r.method,
+			Route:       r.path,
+			Count:       r.count,
+			Errors:      r.errors,
+			P50Ms:       ms(percentile(sorted, 50)),
+			P95Ms:       ms(percentile(sorted, 95)),
+			MaxMs:       ms(r.max),
+			AllocsBytes: r.allocs / uint64(r.count),
+		})
+	}
+	return report
+}
+
+// save writes the report to file every second while it changes
+func (p *profiler) save(file string) {
+	for range time.Tick(time.Second) {
+		p.mu.Lock()
+		dirty := p.dirty
+		p.dirty = false
+		p.mu.Unlock()
+		if !dirty {
+			continue
+		}
+		data, err := json.MarshalIndent(p.report(), "", "  ")
+		if err != nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err == nil {
+			_ = os.WriteFile(file, data, 0644)
+		}
+	}
+}
+
+func reportFile() string {
+	if file := os.Getenv("HTTP_PROFILE_FILE"); file != "" {
+		return file
+	}
+	return filepath.Join("tmp", "profile.json")
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[(len(sorted)-1)*p/100]
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// heapAllocs returns the bytes allocated on the heap since the process started
+func heapAllocs() uint64 {
+	sample := []metrics.Sample{{Name: "/gc/heap/allocs:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+`
+
+// routeProfile is a route in the report served by the generated profiler
+type routeProfile struct {
+	Method      string  `json:"method"`
+	Route       string  `json:"route"`
+	Count       int     `json:"count"`
+	Errors      int     `json:"errors"`
+	P50Ms       float64 `json:"p50Ms"`
+	P95Ms       float64 `json:"p95Ms"`
+	MaxMs       float64 `json:"maxMs"`
+	AllocsBytes uint64  `json:"allocsBytes"`
+}
+
+func (r routeProfile) errorRate() float64 {
+	return float64(r.Errors) / float64(r.Count)
+}
+
+// profileSorts orders the report, slowest or busiest first
+var profileSorts = map[string]func(a, b routeProfile) bool{
+	"p50":    func(a, b routeProfile) bool { return a.P50Ms > b.P50Ms },
+	"p95":    func(a, b routeProfile) bool { return a.P95Ms > b.P95Ms },
+	"max":    func(a, b routeProfile) bool { return a.MaxMs > b.MaxMs },
+	"count":  func(a, b routeProfile) bool { return a.Count > b.Count },
+	"errors": func(a, b routeProfile) bool { return a.errorRate() > b.errorRate() },
+	"allocs": func(a, b routeProfile) bool { return a.AllocsBytes > b.AllocsBytes },
+}
+
+// readProfile fetches the report from the running app, falling back to the file it saved
+func readProfile() ([]routeProfile, string, error) {
+	var report []routeProfile
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(strings.TrimSuffix(profileURL, "/") + "/debug/routes")
+	if err == nil {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+				return nil, "", fmt.Errorf("invalid report from %s: %v", profileURL, err)
+			}
+			return report, profileURL, nil
+		}
+	}
+	data, err := os.ReadFile(profileFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("no report at %s/debug/routes or in %s; start the app with 'gonext start' and send it some traffic", profileURL, profileFile)
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, "", fmt.Errorf("invalid %s: %v", profileFile, err)
+	}
+	return report, profileFile, nil
+}
+
+// formatBytes renders a byte count with a binary unit
+func formatBytes(n uint64) string {
+	units := []string{"B", "KiB", "MiB", "GiB"}
+	v := float64(n)
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f %s", v, units[i])
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Report on the performance of the app in development",
+}
+
+var profileRoutesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: "Print per-route latency, error rate and allocations collected from dev traffic",
+	Long: `Reads the report of the profiler middleware (generate it with 'gonext g http:profile')
+from the running app, or from the file it saves when the app is stopped, and prints
+one line per route: request count, p50/p95/max latency, 5xx error rate and heap
+allocated per request.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		less, ok := profileSorts[profileSort]
+		if !ok {
+			fmt.Printf("Unknown sort '%s' (expected p50, p95, max, count, errors or allocs)\n", profileSort)
+			return
+		}
+		report, source, err := readProfile()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		sort.SliceStable(report, func(i, j int) bool { return less(report[i], report[j]) })
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "METHOD\tROUTE\tCOUNT\tP50\tP95\tMAX\tERRORS\tALLOCS/REQ")
+		for _, r := range report {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%.1fms\t%.1fms\t%.1fms\t%.1f%%\t%s\n", r.Method, r.Route, r.Count, r.P50Ms, r.P95Ms, r.MaxMs, 100*r.errorRate(), formatBytes(r.AllocsBytes))
+		}
+		w.Flush()
+		fmt.Printf("%d route(s) from %s\n", len(report), source)
+	},
+}
+
+var profilerCmd = &cobra.Command{
+	Use:   "http:profile",
+	Short: "Generate a dev middleware measuring per-route latency, error rate and allocations",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !writeGenerated(codegen.File{Path: profilerFile, Content: profilerTemplate}) {
+			return
+		}
+		fmt.Printf("Profiler created in %s\n", filepath.Dir(profilerFile))
+		if _, err := os.Stat(middlewareChainFile); err == nil {
+			if err := addGlobalMiddleware(getModuleName()+"/app/profiler", "profiler.Middleware()"); err != nil {
+				fmt.Printf("Error wiring middleware into %s: %v\n", middlewareChainFile, err)
+				return
+			}
+			fmt.Printf("profiler.Middleware() added to %s\n", middlewareChainFile)
+		} else {
+			fmt.Println("Register the middleware with server.Use(profiler.Middleware()) to profile every route.")
+		}
+		fmt.Println("'gonext start' sets HTTP_PROFILE=true. Print the report with 'gonext profile routes'.")
+		openIfRequested(profilerFile)
+	},
+}
+
+func init() {
+	profileRoutesCmd.Flags().StringVar(&profileURL, "url", "http://localhost:3000", "Base URL of the running app")
+	profileRoutesCmd.Flags().StringVar(&profileFile, "file", filepath.Join("tmp", "profile.json"), "Report saved by the app, read when it is not running")
+	profileRoutesCmd.Flags().StringVar(&profileSort, "sort", "p95", "Sort by p50, p95, max, count, errors or allocs")
+	profileCmd.AddCommand(profileRoutesCmd)
+	rootCmd.AddCommand(profileCmd)
+	generateCmd.AddCommand(profilerCmd)
+	gCmd.AddCommand(profilerCmd)
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 
+	"github.com/Alexigbokwe/gonext/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -20,6 +21,8 @@ var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start the GoNext project",
 	Run: func(cmd *cobra.Command, args []string) {
+		warnGoToolchain()
+		env := devServerEnv()
 		if watchMode {
 			// Try to use 'air' for hot reloading
 			if _, err := exec.LookPath("air"); err != nil {
@@ -28,6 +31,7 @@ var startCmd = &cobra.Command{
 			}
 			fmt.Println("Starting in watch mode (hot reload)...")
 			c := exec.Command("air")
+			c.Env = env
 			c.Stdout = os.Stdout
 			c.Stderr = os.Stderr
 			c.Stdin = os.Stdin
@@ -39,6 +43,7 @@ var startCmd = &cobra.Command{
 		// Default: go run main.go
 		fmt.Println("Starting GoNext project...")
 		c := exec.Command("go", "run", "main.go")
+		c.Env = env
 		c.Stdout = os.Stdout
 		c.Stderr = os.Stderr
 		c.Stdin = os.Stdin
@@ -48,6 +53,24 @@ var startCmd = &cobra.Command{
 	},
 }
 
+// devServerEnv returns the environment for the dev server. Inside containers the server
+// must listen on all interfaces to be reachable from the host, so SERVER_HOST defaults to 0.0.0.0.
+// The database query log, route profiler and leak watchdog are enabled unless set in the environment or .env.
+func devServerEnv() []string {
+	env := os.Environ()
+	if utils.InContainer() && os.Getenv("SERVER_HOST") == "" {
+		fmt.Println("Container detected, binding dev server to 0.0.0.0")
+		env = append(env, "SERVER_HOST=0.0.0.0")
+	}
+	dotenv, _ := readEnvFile(".env")
+	for _, key := range []string{"DB_QUERY_LOG", "HTTP_PROFILE", "WATCHDOG"} {
+		if os.Getenv(key) == "" && dotenv[key] == "" {
+			env = append(env, key+"=true")
+		}
+	}
+	return env
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -0,0 +1,362 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/openapi"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// schemaFormat, schemaDir and schemaModule are set by `gonext schema export --format/--dir/--module`
+var schemaFormat string
+var schemaDir string
+var schemaModule string
+
+// schemaExtensions are the file extensions of the export formats
+var schemaExtensions = map[string]string{"avro": ".avsc", "jsonschema": ".json"}
+
+// schemaVersionFile matches the versioned files of a schema, e.g. v3.avsc
+var schemaVersionFile = regexp.MustCompile(`^v(\d+)\.`)
+
+// avroName matches valid Avro names, which enum symbols must be
+var avroName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// nonAvroChars are replaced to turn identifiers into Avro names
+var nonAvroChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// refName returns the schema name of a $ref
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// jsonSchemaDocument renders a DTO as a JSON Schema (draft 2020-12) document
+// holding the schemas it references under $defs
+func jsonSchemaDocument(name string, s *openapi.Schema, all map[string]*openapi.Schema) map[string]any {
+	doc := jsonSchemaNode(name, s, all)
+	doc["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	doc["title"] = name
+	defs := map[string]any{}
+	var collect func(s *openapi.Schema)
+	collect = func(s *openapi.Schema) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" {
+			ref := refName(s.Ref)
+			if _, done := defs[ref]; !done && ref != name && all[ref] != nil {
+				defs[ref] = jsonSchemaNode(name, all[ref], all)
+				collect(all[ref])
+			}
+		}
+		collect(s.Items)
+		for _, p := range s.Properties {
+			collect(p)
+		}
+	}
+	collect(s)
+	if len(defs) > 0 {
+		doc["$defs"] = defs
+	}
+	return doc
+}
+
+// jsonSchemaNode converts an OpenAPI schema to JSON Schema: examples become a list
+// and references point at $defs, or at the document itself for the root schema.
+// References to types that are not structs (e.g. type Status string) are dropped.
+func jsonSchemaNode(root string, s *openapi.Schema, all map[string]*openapi.Schema) map[string]any {
+	data, _ := json.Marshal(s)
+	node := map[string]any{}
+	json.Unmarshal(data, &node)
+	var fix func(v any)
+	fix = func(v any) {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return
+		}
+		if ex, ok := m["example"]; ok {
+			m["examples"] = []any{ex}
+			delete(m, "example")
+		}
+		if ref, ok := m["$ref"].(string); ok {
+			switch name := refName(ref); {
+			case name == root:
+				m["$ref"] = "#"
+			case all[name] != nil:
+				m["$ref"] = "#/$defs/" + name
+			default:
+				delete(m, "$ref")
+			}
+		}
+		if items, ok := m["items"]; ok {
+			fix(items)
+		}
+		if props, ok := m["properties"].(map[string]any); ok {
+			for _, p := range props {
+				fix(p)
+			}
+		}
+	}
+	fix(node)
+	return node
+}
+
+// avroRecord and avroField are Avro schema objects, with their keys in the usual order
+type avroRecord struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Doc       string      `json:"doc,omitempty"`
+	Fields    []avroField `json:"fields"`
+}
+
+type avroField struct {
+	Name    string          `json:"name"`
+	Type    any             `json:"type"`
+	Doc     string          `json:"doc,omitempty"`
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+// avroSchema converts DTO schemas to Avro. Referenced records are defined inline
+// the first time they are used and by name afterwards, as Avro requires.
+type avroSchema struct {
+	namespace string
+	all       map[string]*openapi.Schema
+	defined   map[string]bool
+}
+
+func (a *avroSchema) record(name string, s *openapi.Schema) avroRecord {
+	a.defined[name] = true
+	record := avroRecord{Type: "record", Name: name, Namespace: a.namespace, Doc: s.Description, Fields: []avroField{}}
+	for _, prop := range s.PropertyOrder {
+		p := s.Properties[prop]
+		field := avroField{Name: prop, Type: a.fieldType(name, prop, p), Doc: p.Description}
+		if !slices.Contains(s.Required, prop) {
+			field.Type = []any{"null", field.Type}
+			field.Default = json.RawMessage("null")
+		}
+		if p.Deprecated {
+			field.Doc = strings.TrimSpace("Deprecated. " + field.Doc)
+		}
+		record.Fields = append(record.Fields, field)
+	}
+	return record
+}
+
+func (a *avroSchema) fieldType(record, field string, s *openapi.Schema) any {
+	if s.Ref != "" {
+		ref := refName(s.Ref)
+		if a.defined[ref] {
+			return ref
+		}
+		if target := a.all[ref]; target != nil {
+			return a.record(ref, target)
+		}
+		return "string"
+	}
+	switch s.Type {
+	case "string":
+		switch {
+		case s.Format == "date-time":
+			return map[string]any{"type": "long", "logicalType": "timestamp-millis"}
+		case s.Format == "uuid":
+			return map[string]any{"type": "string", "logicalType": "uuid"}
+		case s.Format == "byte":
+			return "bytes"
+		case len(s.Enum) > 0:
+			var symbols []string
+			for _, e := range s.Enum {
+				symbol := fmt.Sprint(e)
+				if !avroName.MatchString(symbol) {
+					return "string"
+				}
+				symbols = append(symbols, symbol)
+			}
+			name := record + strings.Title(nonAvroChars.ReplaceAllString(field, "_"))
+			return map[string]any{"type": "enum", "name": name, "symbols": symbols}
+		}
+		return "string"
+	case "integer":
+		if s.Format == "int64" {
+			return "long"
+		}
+		return "int"
+	case "number":
+		if s.Format == "float" {
+			return "float"
+		}
+		return "double"
+	case "boolean":
+		return "boolean"
+	case "array":
+		return map[string]any{"type": "array", "items": a.fieldType(record, field, s.Items)}
+	case "object":
+		return map[string]any{"type": "map", "values": "string"}
+	}
+	return "string"
+}
+
+// avroNamespace derives the Avro namespace of a module from the app name
+func avroNamespace(module string) string {
+	return nonAvroChars.ReplaceAllString(defaultImage(), "_") + "." + nonAvroChars.ReplaceAllString(module, "_")
+}
+
+// latestSchemaVersion returns the highest version stored in dir and its path
+func latestSchemaVersion(dir string) (int, string) {
+	entries, _ := os.ReadDir(dir)
+	latest, file := 0, ""
+	for _, e := range entries {
+		if m := schemaVersionFile.FindStringSubmatch(e.Name()); m != nil {
+			if n, _ := strconv.Atoi(m[1]); n > latest {
+				latest, file = n, filepath.Join(dir, e.Name())
+			}
+		}
+	}
+	return latest, file
+}
+
+// avroCompatibility compares the top-level fields of two Avro records and
+// describes changes that Kafka consumers or producers on the other version can't handle
+func avroCompatibility(previous, next []byte) []string {
+	type field struct {
+		Name    string          `json:"name"`
+		Default json.RawMessage `json:"default"`
+	}
+	var before, after struct {
+		Fields []field `json:"fields"`
+	}
+	if json.Unmarshal(previous, &before) != nil || json.Unmarshal(next, &after) != nil {
+		return nil
+	}
+	old := map[string]field{}
+	for _, f := range before.Fields {
+		old[f.Name] = f
+	}
+	var problems []string
+	for _, f := range after.Fields {
+		if _, ok := old[f.Name]; !ok && f.Default == nil {
+			problems = append(problems, fmt.Sprintf("new field %q has no default: not backward compatible", f.Name))
+		}
+		delete(old, f.Name)
+	}
+	for _, f := range before.Fields {
+		if _, removed := old[f.Name]; removed && f.Default == nil {
+			problems = append(problems, fmt.Sprintf("removed field %q had no default: not forward compatible", f.Name))
+		}
+	}
+	return problems
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Export DTO schemas for message brokers and schema registries",
+}
+
+var schemaExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write Avro or JSON Schema documents for the DTOs of every module to schemas/",
+	Long: `Converts the structs in app/<module>/dto to Avro (.avsc) or JSON Schema (.json)
+documents, using the same struct tags as 'gonext openapi'. Each DTO is kept under
+schemas/<format>/<module>/<Name>/ as v1, v2, ...: a new version is written only when
+the schema changes, so the directory records its history. Avro changes that break
+backward or forward compatibility are reported.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ext, ok := schemaExtensions[schemaFormat]
+		if !ok {
+			fmt.Printf("Unknown format '%s' (expected avro or jsonschema)\n", schemaFormat)
+			return
+		}
+		modules, err := workspace.Modules()
+		if err != nil {
+			fmt.Printf("Error scanning modules: %v\n", err)
+			return
+		}
+		// Every DTO and entity can be referenced from a DTO
+		all := map[string]*openapi.Schema{}
+		for _, pattern := range openapi.SchemaSources {
+			schemas, err := openapi.StructSchemas(pattern)
+			if err != nil {
+				fmt.Printf("Error reading structs: %v\n", err)
+				return
+			}
+			for name, s := range schemas {
+				all[name] = s
+			}
+		}
+		written, unchanged := 0, 0
+		for _, m := range modules {
+			if schemaModule != "" && m.Name != schemaModule {
+				continue
+			}
+			dtos, err := openapi.StructSchemas(filepath.Join(m.Path, "dto", "*.go"))
+			if err != nil {
+				fmt.Printf("Error reading the DTOs of %s: %v\n", m.Name, err)
+				return
+			}
+			names := make([]string, 0, len(dtos))
+			for name := range dtos {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				var doc any
+				if schemaFormat == "avro" {
+					a := &avroSchema{namespace: avroNamespace(m.Name), all: all, defined: map[string]bool{}}
+					doc = a.record(name, dtos[name])
+				} else {
+					doc = jsonSchemaDocument(name, dtos[name], all)
+				}
+				data, err := json.MarshalIndent(doc, "", "  ")
+				if err != nil {
+					fmt.Printf("Error encoding %s: %v\n", name, err)
+					return
+				}
+				data = append(data, '\n')
+				dir := filepath.Join(schemaDir, schemaFormat, m.Name, name)
+				version, latest := latestSchemaVersion(dir)
+				if latest != "" {
+					previous, err := os.ReadFile(latest)
+					if err == nil && bytes.Equal(previous, data) {
+						unchanged++
+						continue
+					}
+					if schemaFormat == "avro" {
+						for _, p := range avroCompatibility(previous, data) {
+							fmt.Printf("Warning: %s.%s: %s\n", m.Name, name, p)
+						}
+					}
+				}
+				file := filepath.Join(dir, fmt.Sprintf("v%d%s", version+1, ext))
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					fmt.Printf("Error creating %s: %v\n", dir, err)
+					return
+				}
+				if err := os.WriteFile(file, data, 0644); err != nil {
+					fmt.Printf("Error writing %s: %v\n", file, err)
+					return
+				}
+				fmt.Printf("  wrote      %s\n", file)
+				written++
+			}
+		}
+		fmt.Printf("%d schema version(s) written, %d unchanged\n", written, unchanged)
+	},
+}
+
+func init() {
+	schemaExportCmd.Flags().StringVar(&schemaFormat, "format", "avro", "Schema format: avro or jsonschema")
+	schemaExportCmd.Flags().StringVar(&schemaDir, "dir", "schemas", "Directory holding the versioned schemas")
+	schemaExportCmd.Flags().StringVar(&schemaModule, "module", "", "Only export the DTOs of this module")
+	schemaCmd.AddCommand(schemaExportCmd)
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// smokeHealth, smokeSample, smokeTimeout and smokeJSON are set by `gonext smoke` flags
+var smokeHealth string
+var smokeSample int
+var smokeTimeout time.Duration
+var smokeJSON bool
+
+// smokeBinary is the app binary built by `gonext smoke`
+var smokeBinary = filepath.Join(".gonext", "smoke", "app")
+
+// smokeParam matches route parameters, e.g. :id, :id? and *
+var smokeParam = regexp.MustCompile(`:[A-Za-z0-9_]+\??|\*`)
+
+// smokeResult is a request made by `gonext smoke`
+type smokeResult struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Passed bool   `json:"passed"`
+}
+
+// lockedBuffer collects the app's output, written from the process' pipes
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// freePort asks the OS for a port nobody listens on
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// smokePath turns a route into a concrete path: parameters become 1, optional
+// parameters are dropped
+func smokePath(route string) string {
+	p := smokeParam.ReplaceAllStringFunc(route, func(param string) string {
+		if strings.HasSuffix(param, "?") {
+			return ""
+		}
+		return "1"
+	})
+	p = strings.TrimSuffix(strings.ReplaceAll(p, "//", "/"), "/")
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+// smokeRoutes returns up to n GET routes spread over the modules, in path order.
+// Only GET routes are requested, so the check changes no data.
+func smokeRoutes(n int) ([]string, error) {
+	routes, err := workspace.Routes()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var paths []string
+	for _, r := range routes {
+		p := smokePath(r.Path)
+		if r.Method == "GET" && !seen[p] && p != smokeHealth {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	if n <= 0 || len(paths) <= n {
+		return paths, nil
+	}
+	sample := make([]string, n)
+	for i := range sample {
+		sample[i] = paths[i*len(paths)/n]
+	}
+	return sample, nil
+}
+
+// waitForApp polls base until the app answers, fails if it exits first, and
+// gives up after timeout
+func waitForApp(client *http.Client, base string, p *appProcess, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-p.done:
+			return fmt.Errorf("the app exited before listening: %v", p.cmd.ProcessState)
+		default:
+		}
+		if res, err := client.Get(base + smokeHealth); err == nil {
+			res.Body.Close()
+			return nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return fmt.Errorf("the app did not answer on %s within %s", base, timeout)
+}
+
+// smokeRequest requests path and records whether the app answered below 500,
+// or with a 2xx for the health endpoint
+func smokeRequest(client *http.Client, base, path string, health bool) smokeResult {
+	result := smokeResult{Method: "GET", Path: path}
+	res, err := client.Get(base + path)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	res.Body.Close()
+	result.Status = res.StatusCode
+	if health {
+		result.Passed = res.StatusCode >= 200 && res.StatusCode < 300
+	} else {
+		result.Passed = res.StatusCode < 500
+	}
+	return result
+}
+
+var smokeCmd = &cobra.Command{
+	Use:   "smoke",
+	Short: "Boot the app on a random port and check the health endpoint and a sample of routes",
+	Long: `Builds the app, starts it with SERVER_PORT set to a free port, and waits for it
+to answer. Then it requests the health endpoint, which must answer 2xx, and a
+sample of the GET routes registered by the modules, which must not answer 5xx.
+Route parameters are filled with 1. Only GET routes are requested, so the check
+changes no data.
+
+The app's output is shown if a check fails. The command exits with status 1 on
+any failure, for CI after heavy scaffolding:
+
+  gonext smoke --sample 50 --timeout 1m`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		paths, err := smokeRoutes(smokeSample)
+		if err != nil {
+			fmt.Printf("Error scanning routes: %v\n", err)
+			os.Exit(1)
+		}
+		port, err := freePort()
+		if err != nil {
+			fmt.Printf("Error finding a free port: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !smokeJSON {
+			fmt.Println("Building the app...")
+		}
+		build := exec.Command("go", "build", "-o", smokeBinary, ".")
+		build.Stdout = os.Stdout
+		build.Stderr = os.Stderr
+		if err := build.Run(); err != nil {
+			fmt.Println("Build failed.")
+			os.Exit(1)
+		}
+		binary, _ := filepath.Abs(smokeBinary)
+		output := &lockedBuffer{}
+		c := exec.Command(binary)
+		c.Env = append(os.Environ(), "SERVER_HOST=127.0.0.1", "SERVER_PORT="+strconv.Itoa(port))
+		c.Stdout = output
+		c.Stderr = output
+		if err := c.Start(); err != nil {
+			fmt.Printf("Error starting the app: %v\n", err)
+			os.Exit(1)
+		}
+		app := &appProcess{cmd: c, done: make(chan struct{})}
+		go func() {
+			c.Wait()
+			close(app.done)
+		}()
+
+		base := "http://127.0.0.1:" + strconv.Itoa(port)
+		client := &http.Client{Timeout: 10 * time.Second}
+		if !smokeJSON {
+			fmt.Printf("Waiting for the app on %s...\n", base)
+		}
+		var results []smokeResult
+		failed := false
+		if err := waitForApp(client, base, app, smokeTimeout); err != nil {
+			fmt.Println(err)
+			failed = true
+		} else {
+			results = append(results, smokeRequest(client, base, smokeHealth, true))
+			for _, p := range paths {
+				results = append(results, smokeRequest(client, base, p, false))
+			}
+		}
+		app.stop()
+
+		for _, r := range results {
+			failed = failed || !r.Passed
+		}
+		if smokeJSON {
+			data, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				fmt.Printf("Error encoding JSON: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+		} else if len(results) > 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "RESULT\tSTATUS\tMETHOD\tPATH")
+			for _, r := range results {
+				result, status := "ok", strconv.Itoa(r.Status)
+				if !r.Passed {
+					result = "FAIL"
+				}
+				if r.Error != "" {
+					status = r.Error
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result, status, r.Method, r.Path)
+			}
+			w.Flush()
+		}
+		if failed {
+			fmt.Printf("\nSmoke test failed. App output:\n%s", output.String())
+			os.Exit(1)
+		}
+		fmt.Printf("Smoke test passed: %d request(s)\n", len(results))
+	},
+}
+
+func init() {
+	smokeCmd.Flags().StringVar(&smokeHealth, "health", "/healthz", "Health endpoint that must answer 2xx")
+	smokeCmd.Flags().IntVar(&smokeSample, "sample", 20, "Number of GET routes to request (0 for all)")
+	smokeCmd.Flags().DurationVar(&smokeTimeout, "timeout", 30*time.Second, "How long to wait for the app to answer")
+	smokeCmd.Flags().BoolVar(&smokeJSON, "json", false, "Output the results as JSON")
+	rootCmd.AddCommand(smokeCmd)
+}
@@ -0,0 +1,352 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/config"
+	"github.com/Alexigbokwe/gonext/internal/progress"
+	"github.com/spf13/cobra"
+)
+
+// registryTokenEnv, registryToken, registryUsername and registrySSHKey are set by
+// `gonext registry add --token-env/--token/--username/--ssh-key`
+var registryTokenEnv string
+var registryToken string
+var registryUsername string
+var registrySSHKey string
+
+// templateSource is where a template is cloned from
+type templateSource struct {
+	URL  string   // repository or local cache directory passed to git clone
+	Repo string   // repository recorded as the project's template
+	Env  []string // git authentication for the registry, if any
+}
+
+// registryGitEnv returns the environment that authenticates git against r: a
+// Basic authorization header scoped to the registry URL for HTTPS, or an
+// explicit key for SSH. The token is passed through git's environment config
+// so it never appears in the command line or in the clone's remote URL.
+func registryGitEnv(r config.Registry) ([]string, error) {
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	if r.SSH() {
+		if r.SSHKey != "" {
+			env = append(env, fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %q -o IdentitiesOnly=yes", expandHome(r.SSHKey)))
+		}
+		return env, nil
+	}
+	token := r.Token
+	if r.TokenEnv != "" {
+		token = os.Getenv(r.TokenEnv)
+		if token == "" {
+			return nil, fmt.Errorf("registry '%s' reads its token from $%s, which is not set", r.Name, r.TokenEnv)
+		}
+	}
+	if token == "" {
+		return env, nil
+	}
+	username := r.Username
+	if username == "" {
+		username = "x-access-token"
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + token))
+	return append(env,
+		"GIT_CONFIG_COUNT=1",
+		fmt.Sprintf("GIT_CONFIG_KEY_0=http.%s/.extraHeader", strings.TrimSuffix(r.URL, "/")),
+		"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+	), nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if home, err := os.UserHomeDir(); err == nil && (path == "~" || strings.HasPrefix(path, "~/")) {
+		return filepath.Join(home, path[1:])
+	}
+	return path
+}
+
+// remoteTemplate resolves a template reference to a repository. A reference is a
+// git URL, a local path, or <registry>/<repository> for a configured registry.
+func remoteTemplate(ref string) (templateSource, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return templateSource{}, err
+	}
+	if strings.Contains(ref, "://") || strings.HasPrefix(ref, "git@") {
+		// Full URLs below a registry still use its credentials
+		for _, r := range cfg.Registries {
+			if strings.HasPrefix(ref, strings.TrimSuffix(r.URL, "/")+"/") {
+				env, err := registryGitEnv(r)
+				return templateSource{URL: ref, Repo: ref, Env: env}, err
+			}
+		}
+		return templateSource{URL: ref, Repo: ref}, nil
+	}
+	if _, err := os.Stat(ref); err == nil {
+		return templateSource{URL: ref, Repo: ref}, nil
+	}
+	name, repo, ok := strings.Cut(ref, "/")
+	if !ok || repo == "" {
+		return templateSource{}, fmt.Errorf("template '%s' is not a URL, a path or <registry>/<repository>", ref)
+	}
+	r, ok := cfg.Registry(name)
+	if !ok {
+		return templateSource{}, fmt.Errorf("unknown registry '%s' (add it with 'gonext registry add %s <url>')", name, name)
+	}
+	url := strings.TrimSuffix(r.URL, "/") + "/" + repo
+	if !strings.HasSuffix(url, ".git") {
+		url += ".git"
+	}
+	env, err := registryGitEnv(r)
+	return templateSource{URL: url, Repo: url, Env: env}, err
+}
+
+// cachedTemplateDir returns where `gonext template add` keeps a template
+func cachedTemplateDir(ref string) (string, error) {
+	dir, err := config.TemplatesDir()
+	if err != nil {
+		return "", err
+	}
+	name := ref
+	if strings.Contains(ref, "://") || strings.HasPrefix(ref, "git@") {
+		name = strings.TrimSuffix(filepath.Base(ref), ".git")
+	}
+	return filepath.Join(dir, filepath.FromSlash(name)), nil
+}
+
+// resolveTemplate returns where `gonext new` clones a template from: the starter
+// repository by default, the copy fetched by `gonext template add` if there is
+// one, and the registry or URL otherwise
+func resolveTemplate(ref string) (templateSource, error) {
+	if ref == "" {
+		return templateSource{URL: starterRepo, Repo: starterRepo}, nil
+	}
+	if dir, err := cachedTemplateDir(ref); err == nil {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			repo := dir
+			if out, err := exec.Command("git", "-C", dir, "remote", "get-url", "origin").Output(); err == nil {
+				repo = strings.TrimSpace(string(out))
+			}
+			return templateSource{URL: dir, Repo: repo}, nil
+		}
+	}
+	return remoteTemplate(ref)
+}
+
+// runGit runs git with the registry environment, returning its output on failure
+func runGit(env []string, args ...string) error {
+	c := exec.Command("git", args...)
+	c.Env = append(os.Environ(), env...)
+	var out bytes.Buffer
+	c.Stdout = &out
+	c.Stderr = &out
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("%v\n%s", err, strings.TrimSpace(out.String()))
+	}
+	return nil
+}
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Manage organization template registries",
+	Long: `A registry is a git host serving internal starters and generator templates,
+reached over HTTPS with an access token or over SSH. Templates are then named
+<registry>/<repository> in 'gonext new --template' and 'gonext template add'.
+Registries are kept in the user config, not in projects.`,
+}
+
+var registryAddCmd = &cobra.Command{
+	Use:   "add [name] [url]",
+	Short: "Add or replace a registry, e.g. add acme https://git.acme.dev/platform --token-env ACME_TOKEN",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		r := config.Registry{Name: args[0], URL: strings.TrimSuffix(args[1], "/"), TokenEnv: registryTokenEnv, Token: registryToken, Username: registryUsername, SSHKey: registrySSHKey}
+		if strings.Contains(r.Name, "/") {
+			fmt.Println("Registry names cannot contain '/'")
+			return
+		}
+		if r.SSH() && (r.Token != "" || r.TokenEnv != "" || r.Username != "") {
+			fmt.Println("--token, --token-env and --username apply to HTTPS registries; SSH registries use --ssh-key or the SSH agent")
+			return
+		}
+		if !r.SSH() && r.SSHKey != "" {
+			fmt.Println("--ssh-key applies to SSH registries (git@host:org or ssh://)")
+			return
+		}
+		if r.Token != "" && r.TokenEnv != "" {
+			fmt.Println("Pass either --token or --token-env, not both")
+			return
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			return
+		}
+		cfg.SetRegistry(r)
+		if err := cfg.Save(); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			return
+		}
+		fmt.Printf("Registry '%s' added (%s). Use templates as %s/<repository>.\n", r.Name, registryAuth(r), r.Name)
+	},
+}
+
+// registryAuth describes how a registry authenticates, without revealing the token
+func registryAuth(r config.Registry) string {
+	switch {
+	case r.SSH() && r.SSHKey != "":
+		return "ssh, key " + r.SSHKey
+	case r.SSH():
+		return "ssh, agent"
+	case r.TokenEnv != "":
+		return "https, token from $" + r.TokenEnv
+	case r.Token != "":
+		return "https, stored token"
+	}
+	return "https, no token"
+}
+
+var registryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configured registries",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			return
+		}
+		if len(cfg.Registries) == 0 {
+			fmt.Println("No registries configured. Add one with 'gonext registry add <name> <url>'.")
+			return
+		}
+		for _, r := range cfg.Registries {
+			fmt.Printf("%-12s %-40s %s\n", r.Name, r.URL, registryAuth(r))
+		}
+	},
+}
+
+var registryRemoveCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "Remove a registry and its credentials",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			return
+		}
+		if !cfg.RemoveRegistry(args[0]) {
+			fmt.Printf("No registry named '%s'\n", args[0])
+			return
+		}
+		if err := cfg.Save(); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			return
+		}
+		fmt.Printf("Registry '%s' removed\n", args[0])
+	},
+}
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Fetch and list project templates",
+}
+
+var templateAddCmd = &cobra.Command{
+	Use:   "add [template]",
+	Short: "Fetch a template from a registry or URL, or update the fetched copy",
+	Long: `Fetches a template so 'gonext new --template <template>' can use it without
+contacting the registry. The template is <registry>/<repository>, a git URL or a
+local path. Running it again pulls the latest commit.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ref := args[0]
+		src, err := remoteTemplate(ref)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		dir, err := cachedTemplateDir(ref)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			spinner := progress.Start(fmt.Sprintf("Updating template %s", ref))
+			err := runGit(src.Env, "-C", dir, "pull", "--quiet", "--ff-only")
+			spinner.Stop(err)
+			if err != nil {
+				fmt.Printf("Error updating template: %v\n", err)
+				return
+			}
+			fmt.Printf("Template '%s' updated in %s\n", ref, dir)
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			fmt.Printf("Error creating %s: %v\n", filepath.Dir(dir), err)
+			return
+		}
+		spinner := progress.Start(fmt.Sprintf("Fetching template %s", ref))
+		err = runGit(src.Env, "clone", "--quiet", src.URL, dir)
+		spinner.Stop(err)
+		if err != nil {
+			os.RemoveAll(dir)
+			fmt.Printf("Error fetching template: %v\n", err)
+			return
+		}
+		fmt.Printf("Template '%s' fetched. Create a project with 'gonext new <name> --template %s'.\n", ref, ref)
+	},
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the templates fetched with 'gonext template add'",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		root, err := config.TemplatesDir()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		found := 0
+		filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+				return nil
+			}
+			found++
+			name, _ := filepath.Rel(root, path)
+			origin := ""
+			if out, err := exec.Command("git", "-C", path, "remote", "get-url", "origin").Output(); err == nil {
+				origin = strings.TrimSpace(string(out))
+			}
+			fmt.Printf("%-30s %s\n", filepath.ToSlash(name), origin)
+			return filepath.SkipDir
+		})
+		if found == 0 {
+			fmt.Println("No templates fetched. Fetch one with 'gonext template add <registry>/<repository>'.")
+		}
+	},
+}
+
+func init() {
+	registryAddCmd.Flags().StringVar(&registryTokenEnv, "token-env", "", "Environment variable holding the HTTPS access token (recommended over --token)")
+	registryAddCmd.Flags().StringVar(&registryToken, "token", "", "HTTPS access token, stored in the user config")
+	registryAddCmd.Flags().StringVar(&registryUsername, "username", "", "HTTPS user sent with the token (default x-access-token)")
+	registryAddCmd.Flags().StringVar(&registrySSHKey, "ssh-key", "", "Private key for SSH registries (default: the SSH agent)")
+	registryCmd.AddCommand(registryAddCmd)
+	registryCmd.AddCommand(registryListCmd)
+	registryCmd.AddCommand(registryRemoveCmd)
+	templateCmd.AddCommand(templateAddCmd)
+	templateCmd.AddCommand(templateListCmd)
+	rootCmd.AddCommand(registryCmd)
+	rootCmd.AddCommand(templateCmd)
+}
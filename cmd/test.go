@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// testMutate and testMinScore are set by `gonext test --mutate/--min-score`
+var testMutate bool
+var testMinScore float64
+
+// testArgs splits the arguments of `gonext test` into packages and the go test
+// flags given after --
+func testArgs(cmd *cobra.Command, args []string) (packages, goFlags []string) {
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		packages, goFlags = args[:dash], args[dash:]
+	} else {
+		packages = args
+	}
+	return packages, goFlags
+}
+
+var testCmd = &cobra.Command{
+	Use:   "test [packages] [-- go test flags]",
+	Short: "Run the project's tests, optionally with mutation testing",
+	Long: `Runs 'go test' on the packages (./... by default). Flags after -- are passed to
+go test:
+
+  gonext test ./app/users/... -- -run TestCreate -v
+
+--mutate runs mutation testing on the service and repository packages of the
+modules, or on the package directories given. Each mutant changes one operator
+in the code, such as == into != or && into ||, and runs the package's tests
+against it through a go build overlay, so no source file is modified. A mutant
+the tests still pass with survives: the surviving mutants point at behaviour no
+test checks, such as generated test stubs that were never filled in.
+--min-score fails the command when the share of killed mutants is lower.
+
+--shards n splits the packages into n shards of balanced duration and runs
+them as concurrent go test processes. --shard i/n runs only shard i, for a CI
+matrix where each job runs one shard. The split uses how long each package took
+in earlier runs, recorded in .gonext/test-timings.json (--timings); commit or
+cache the file so every job sees the same timings and agrees on the split.
+
+--detect-flaky runs the tests, then reruns each failing test --runs times. A
+test that fails only some of the runs is flaky and is added to the quarantine
+in .gonext/quarantine.json (--quarantine); one that fails every run is broken
+and fails the command. Quarantined tests that pass every rerun are released.
+Quarantined tests are left out of the result of 'gonext test': with
+--quarantined report (the default) they run separately and their results are
+listed without counting, with skip they don't run, and with run they count
+like any other test.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		packages, goFlags := testArgs(cmd, args)
+		sharded := testShard != "" || testShards > 0
+		modes := 0
+		for _, on := range []bool{testShard != "", testShards > 0, testMutate, testDetectFlaky} {
+			if on {
+				modes++
+			}
+		}
+		if modes > 1 {
+			fmt.Println("--shard, --shards, --mutate and --detect-flaky can't be combined")
+			os.Exit(1)
+		}
+		if testQuarantined != "report" && testQuarantined != "skip" && testQuarantined != "run" {
+			fmt.Printf("Invalid --quarantined %q: expected report, skip or run\n", testQuarantined)
+			os.Exit(1)
+		}
+		if testDetectFlaky {
+			if err := detectFlaky(packages, goFlags); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+		if sharded {
+			if err := runShardedTests(packages, goFlags); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+		if testMutate {
+			report, err := mutationTest(packages, goFlags)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			printMutationReport(report)
+			if score, ok := report.score(); ok && score < testMinScore {
+				fmt.Printf("Mutation score %.1f%% is below --min-score %.1f%%\n", score, testMinScore)
+				os.Exit(1)
+			}
+			return
+		}
+		if len(packages) == 0 {
+			packages = []string{"./..."}
+		}
+		quarantine, err := loadQuarantine(testQuarantine)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if len(quarantine.Tests) == 0 || testQuarantined == "run" {
+			if err := runCommand(nil, "go", append(append([]string{"test"}, goFlags...), packages...)...); err != nil {
+				os.Exit(1)
+			}
+			return
+		}
+		listed, err := listPackages(packages)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		_, err = runTests(listed, goFlags, "", quarantine)
+		reportQuarantined(listed, goFlags, quarantine)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	testCmd.Flags().BoolVar(&testMutate, "mutate", false, "Run mutation testing on the service and repository packages and report surviving mutants")
+	testCmd.Flags().Float64Var(&testMinScore, "min-score", 0, "With --mutate, fail when the percentage of killed mutants is lower")
+	testCmd.Flags().StringVar(&testShard, "shard", "", "Run only shard i of n, e.g. 2/4, split by earlier durations")
+	testCmd.Flags().IntVar(&testShards, "shards", 0, "Split the packages into n shards of balanced duration and run them concurrently")
+	testCmd.Flags().StringVar(&testTimings, "timings", filepath.Join(".gonext", "test-timings.json"), "File recording how long each package's tests take")
+	testCmd.Flags().BoolVar(&testDetectFlaky, "detect-flaky", false, "Rerun failing tests to tell flaky from broken ones, and update the quarantine")
+	testCmd.Flags().IntVar(&testRuns, "runs", 5, "With --detect-flaky, how many times each failing test is rerun")
+	testCmd.Flags().StringVar(&testQuarantine, "quarantine", filepath.Join(".gonext", "quarantine.json"), "File listing the quarantined flaky tests")
+	testCmd.Flags().StringVar(&testQuarantined, "quarantined", "report", "What to do with quarantined tests: report (run them without counting), skip or run")
+	rootCmd.AddCommand(testCmd)
+}
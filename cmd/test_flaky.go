@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// testDetectFlaky, testRuns, testQuarantine and testQuarantined are set by
+// `gonext test --detect-flaky/--runs/--quarantine/--quarantined`
+var testDetectFlaky bool
+var testRuns int
+var testQuarantine string
+var testQuarantined string
+
+// quarantinedTest is a flaky test kept out of the result of `gonext test`
+type quarantinedTest struct {
+	Package  string `json:"package"`
+	Test     string `json:"test"`
+	Failures int    `json:"failures"` // failed runs when it was last checked
+	Runs     int    `json:"runs"`
+	Since    string `json:"since"`
+}
+
+// quarantineFile lists the flaky tests found by `gonext test --detect-flaky`
+type quarantineFile struct {
+	Tests []quarantinedTest `json:"tests"`
+}
+
+// loadQuarantine reads the quarantine file, empty if it does not exist
+func loadQuarantine(path string) (*quarantineFile, error) {
+	q := &quarantineFile{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, q); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", path, err)
+	}
+	return q, nil
+}
+
+// save writes the quarantine file, sorted by package and test
+func (q *quarantineFile) save(path string) error {
+	sort.Slice(q.Tests, func(i, j int) bool {
+		if q.Tests[i].Package != q.Tests[j].Package {
+			return q.Tests[i].Package < q.Tests[j].Package
+		}
+		return q.Tests[i].Test < q.Tests[j].Test
+	})
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// tests returns the quarantined tests of a package
+func (q *quarantineFile) tests(pkg string) []string {
+	var names []string
+	for _, t := range q.Tests {
+		if t.Package == pkg {
+			names = append(names, t.Test)
+		}
+	}
+	return names
+}
+
+// index returns the position of a test in the quarantine, or -1
+func (q *quarantineFile) index(pkg, test string) int {
+	for i, t := range q.Tests {
+		if t.Package == pkg && t.Test == test {
+			return i
+		}
+	}
+	return -1
+}
+
+// testNamePattern matches exactly the named top-level tests, for -run and -skip
+func testNamePattern(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = regexp.QuoteMeta(n)
+	}
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
+
+// runTests runs the tests of packages and leaves out the quarantined ones,
+// unless --quarantined run. Packages with quarantined tests run on their own
+// with -skip, so a test is only skipped in the package it is quarantined in.
+func runTests(packages, goFlags []string, prefix string, q *quarantineFile) (testRun, error) {
+	merged := testRun{Elapsed: map[string]float64{}, Results: map[string]map[string]bool{}}
+	var plain []string
+	var runs [][]string
+	for _, pkg := range packages {
+		if names := q.tests(pkg); len(names) > 0 && testQuarantined != "run" {
+			runs = append(runs, append(append(append([]string{}, goFlags...), "-skip", testNamePattern(names)), pkg))
+		} else {
+			plain = append(plain, pkg)
+		}
+	}
+	if len(plain) > 0 {
+		runs = append([][]string{append(append([]string{}, goFlags...), plain...)}, runs...)
+	}
+	var firstErr error
+	for _, args := range runs {
+		run, err := goTestJSON(args, prefix, false)
+		for pkg, seconds := range run.Elapsed {
+			merged.Elapsed[pkg] = seconds
+		}
+		for pkg, results := range run.Results {
+			merged.Results[pkg] = results
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return merged, firstErr
+}
+
+// reportQuarantined runs the quarantined tests of packages with --quarantined
+// report and prints their results, which don't count towards the command's
+func reportQuarantined(packages, goFlags []string, q *quarantineFile) {
+	if testQuarantined != "report" {
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := false
+	for _, pkg := range packages {
+		names := q.tests(pkg)
+		if len(names) == 0 {
+			continue
+		}
+		if !header {
+			fmt.Println("\nQuarantined tests (not counted):")
+			header = true
+		}
+		args := append(append([]string{}, goFlags...), "-count=1", "-run", testNamePattern(names), pkg)
+		run, _ := goTestJSON(args, "", true)
+		for _, name := range names {
+			result := "missing"
+			if passed, ok := run.Results[pkg][name]; ok && passed {
+				result = "pass"
+			} else if ok {
+				result = "FAIL"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", result, pkg, name)
+		}
+	}
+	w.Flush()
+}
+
+// flakyVerdict is the classification of a test rerun by --detect-flaky
+type flakyVerdict struct {
+	Class    string // failing, flaky or stable
+	Package  string
+	Test     string
+	Failures int
+	Runs     int
+}
+
+// detectFlaky runs the tests, reruns the failing and the quarantined ones
+// --runs times, and updates the quarantine: tests that fail only sometimes are
+// quarantined, quarantined tests that pass every run are released, and tests
+// that fail every run are reported as failing
+func detectFlaky(patterns, goFlags []string) error {
+	if testRuns < 1 {
+		return fmt.Errorf("--runs must be at least 1")
+	}
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	packages, err := listPackages(patterns)
+	if err != nil {
+		return err
+	}
+	q, err := loadQuarantine(testQuarantine)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Running the tests, including quarantined ones...")
+	run, runErr := goTestJSON(append(append([]string{"-count=1"}, goFlags...), packages...), "", false)
+	var candidates []flakyVerdict
+	for _, pkg := range packages {
+		var names []string
+		for name := range run.Results[pkg] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			passed := run.Results[pkg][name]
+			if !passed || q.index(pkg, name) >= 0 {
+				v := flakyVerdict{Package: pkg, Test: name, Runs: 1}
+				if !passed {
+					v.Failures = 1
+				}
+				candidates = append(candidates, v)
+			}
+		}
+	}
+	if runErr != nil && len(candidates) == 0 {
+		return fmt.Errorf("go test failed without a failing test, e.g. a build error: %v", runErr)
+	}
+	if len(candidates) == 0 {
+		fmt.Println("No failing or quarantined tests to rerun.")
+		return nil
+	}
+
+	fmt.Printf("\nRerunning %d test(s) %d time(s)...\n", len(candidates), testRuns)
+	for i := range candidates {
+		v := &candidates[i]
+		for range testRuns {
+			args := append(append([]string{}, goFlags...), "-count=1", "-run", testNamePattern([]string{v.Test}), v.Package)
+			r, _ := goTestJSON(args, "", true)
+			v.Runs++
+			if !r.Results[v.Package][v.Test] {
+				v.Failures++
+			}
+		}
+		switch {
+		case v.Failures == v.Runs:
+			v.Class = "failing"
+		case v.Failures > 0:
+			v.Class = "flaky"
+		default:
+			v.Class = "stable"
+		}
+	}
+
+	today := time.Now().Format("2006-01-02")
+	failing := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nCLASS\tFAILED\tPACKAGE\tTEST\tQUARANTINE")
+	for _, v := range candidates {
+		i := q.index(v.Package, v.Test)
+		action := ""
+		switch {
+		case v.Class == "flaky" && i < 0:
+			q.Tests = append(q.Tests, quarantinedTest{Package: v.Package, Test: v.Test, Failures: v.Failures, Runs: v.Runs, Since: today})
+			action = "added"
+		case v.Class == "flaky":
+			q.Tests[i].Failures, q.Tests[i].Runs = v.Failures, v.Runs
+			action = "kept"
+		case v.Class == "stable" && i >= 0:
+			q.Tests = append(q.Tests[:i], q.Tests[i+1:]...)
+			action = "released"
+		case v.Class == "failing" && i >= 0:
+			action = "kept"
+		}
+		if v.Class == "failing" && i < 0 {
+			failing++
+		}
+		fmt.Fprintf(w, "%s\t%d/%d\t%s\t%s\t%s\n", v.Class, v.Failures, v.Runs, v.Package, v.Test, action)
+	}
+	w.Flush()
+	if err := q.save(testQuarantine); err != nil {
+		return fmt.Errorf("Error writing %s: %v", testQuarantine, err)
+	}
+	fmt.Printf("\nQuarantine: %d test(s) in %s. 'gonext test' leaves them out of its result.\n", len(q.Tests), testQuarantine)
+	if failing > 0 {
+		return fmt.Errorf("%d test(s) failed every run; they are broken, not flaky", failing)
+	}
+	return nil
+}
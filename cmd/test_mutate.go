@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mutationSwaps are the operator mutations: each operator is replaced by the one
+// that inverts or changes its meaning
+var mutationSwaps = map[token.Token]token.Token{
+	token.EQL:  token.NEQ,
+	token.NEQ:  token.EQL,
+	token.LSS:  token.GEQ,
+	token.GEQ:  token.LSS,
+	token.GTR:  token.LEQ,
+	token.LEQ:  token.GTR,
+	token.LAND: token.LOR,
+	token.LOR:  token.LAND,
+	token.ADD:  token.SUB,
+	token.SUB:  token.ADD,
+	token.MUL:  token.QUO,
+	token.QUO:  token.MUL,
+}
+
+// mutant is one change to a source file
+type mutant struct {
+	File     string
+	Line     int
+	Column   int
+	Offset   int
+	Original string
+	Mutated  string
+	Status   string // killed, survived, timeout or invalid (does not compile)
+}
+
+// mutationPackage is the result of mutation testing a package directory
+type mutationPackage struct {
+	Dir     string
+	Skipped string // why the package was not mutated, if it was not
+	Mutants []mutant
+}
+
+// mutationReport is the result of `gonext test --mutate`
+type mutationReport struct {
+	Packages []mutationPackage
+}
+
+// counts returns the killed mutants, including timeouts, and the mutants that
+// compiled
+func (r mutationReport) counts() (killed, total int) {
+	for _, p := range r.Packages {
+		for _, m := range p.Mutants {
+			switch m.Status {
+			case "killed", "timeout":
+				killed++
+				total++
+			case "survived":
+				total++
+			}
+		}
+	}
+	return killed, total
+}
+
+// score returns the percentage of killed mutants, if any mutant ran
+func (r mutationReport) score() (float64, bool) {
+	killed, total := r.counts()
+	if total == 0 {
+		return 0, false
+	}
+	return 100 * float64(killed) / float64(total), true
+}
+
+// mutationDirs returns the directories given, or the service and repository
+// packages of the modules
+func mutationDirs(packages []string) ([]string, error) {
+	if len(packages) > 0 {
+		var dirs []string
+		for _, p := range packages {
+			if strings.Contains(p, "...") {
+				return nil, fmt.Errorf("--mutate takes package directories, not patterns such as %s", p)
+			}
+			dirs = append(dirs, filepath.Clean(p))
+		}
+		return dirs, nil
+	}
+	var dirs []string
+	for _, layer := range []string{"service", "repository"} {
+		matches, err := filepath.Glob(filepath.Join("app", "*", layer))
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, matches...)
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// findMutants returns the mutations of the function bodies of a source file
+func findMutants(path string, src []byte) ([]mutant, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	var mutants []mutant
+	add := func(pos token.Pos, original, mutated string) {
+		p := fset.Position(pos)
+		mutants = append(mutants, mutant{File: path, Line: p.Line, Column: p.Column, Offset: p.Offset, Original: original, Mutated: mutated})
+	}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.BinaryExpr:
+				if to, ok := mutationSwaps[n.Op]; ok {
+					add(n.OpPos, n.Op.String(), to.String())
+				}
+			case *ast.Ident:
+				if n.Name == "true" {
+					add(n.Pos(), "true", "false")
+				} else if n.Name == "false" {
+					add(n.Pos(), "false", "true")
+				}
+			}
+			return true
+		})
+	}
+	return mutants, nil
+}
+
+// hasTests reports whether a directory has test files
+func hasTests(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	return len(matches) > 0
+}
+
+// goTestPackage runs the tests of a package directory, with a build overlay when
+// overlay is not empty, and returns the output
+func goTestPackage(ctx context.Context, dir, overlay string, goFlags []string) ([]byte, error) {
+	args := []string{"test", "-count=1"}
+	if overlay != "" {
+		args = append(args, "-overlay", overlay)
+	}
+	args = append(append(args, goFlags...), "./"+filepath.ToSlash(dir))
+	return exec.CommandContext(ctx, "go", args...).CombinedOutput()
+}
+
+// runMutant runs the tests of a package with one mutant applied through an
+// overlay in work, and records whether they caught it
+func runMutant(m *mutant, src []byte, dir, work string, index int, timeout time.Duration, goFlags []string) error {
+	mutated := append(append(append([]byte{}, src[:m.Offset]...), m.Mutated...), src[m.Offset+len(m.Original):]...)
+	file := filepath.Join(work, fmt.Sprintf("mutant%d.go", index))
+	if err := os.WriteFile(file, mutated, 0644); err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(m.File)
+	if err != nil {
+		return err
+	}
+	overlay, err := json.Marshal(map[string]map[string]string{"Replace": {abs: file}})
+	if err != nil {
+		return err
+	}
+	overlayFile := filepath.Join(work, fmt.Sprintf("overlay%d.json", index))
+	if err := os.WriteFile(overlayFile, overlay, 0644); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	out, err := goTestPackage(ctx, dir, overlayFile, goFlags)
+	switch {
+	case ctx.Err() != nil:
+		m.Status = "timeout"
+	case err == nil:
+		m.Status = "survived"
+	case bytes.Contains(out, []byte("[build failed]")) || bytes.Contains(out, []byte("[setup failed]")):
+		m.Status = "invalid"
+	default:
+		m.Status = "killed"
+	}
+	return nil
+}
+
+// mutatePackage mutates the source files of a package directory and runs its
+// tests against each mutant, on as many workers as there are CPUs
+func mutatePackage(dir, work string, goFlags []string) (mutationPackage, error) {
+	pkg := mutationPackage{Dir: dir}
+	if !hasTests(dir) {
+		pkg.Skipped = "no tests"
+		return pkg, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return pkg, err
+	}
+	sources := map[string][]byte{}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return pkg, err
+		}
+		mutants, err := findMutants(file, src)
+		if err != nil {
+			return pkg, err
+		}
+		sources[file] = src
+		pkg.Mutants = append(pkg.Mutants, mutants...)
+	}
+	if len(pkg.Mutants) == 0 {
+		pkg.Skipped = "nothing to mutate"
+		return pkg, nil
+	}
+
+	start := time.Now()
+	if out, err := goTestPackage(context.Background(), dir, "", goFlags); err != nil {
+		pkg.Skipped = "tests fail without mutations"
+		pkg.Mutants = nil
+		fmt.Print(string(out))
+		return pkg, nil
+	}
+	timeout := max(10*time.Since(start), 30*time.Second)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	sem := make(chan struct{}, runtime.NumCPU())
+	for i := range pkg.Mutants {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			m := &pkg.Mutants[i]
+			if err := runMutant(m, sources[m.File], dir, work, i, timeout, goFlags); err != nil {
+				mu.Lock()
+				firstErr = err
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return pkg, firstErr
+}
+
+// mutationTest mutates the given package directories, or the service and
+// repository packages of the modules
+func mutationTest(packages, goFlags []string) (mutationReport, error) {
+	var report mutationReport
+	dirs, err := mutationDirs(packages)
+	if err != nil {
+		return report, err
+	}
+	if len(dirs) == 0 {
+		return report, fmt.Errorf("no service or repository packages found in app/*; name the package directories to mutate")
+	}
+	work, err := os.MkdirTemp("", "gonext-mutate-*")
+	if err != nil {
+		return report, err
+	}
+	defer os.RemoveAll(work)
+	for _, dir := range dirs {
+		fmt.Printf("Mutating %s...\n", dir)
+		pkg, err := mutatePackage(dir, work, goFlags)
+		if err != nil {
+			return report, err
+		}
+		report.Packages = append(report.Packages, pkg)
+	}
+	return report, nil
+}
+
+// printMutationReport prints the score of each package and the surviving mutants
+func printMutationReport(report mutationReport) {
+	fmt.Println()
+	var survivors []mutant
+	for _, p := range report.Packages {
+		if p.Skipped != "" {
+			fmt.Printf("  %-40s skipped: %s\n", p.Dir, p.Skipped)
+			continue
+		}
+		killed, total := mutationReport{Packages: []mutationPackage{p}}.counts()
+		score := "-"
+		if total > 0 {
+			score = fmt.Sprintf("%.1f%%", 100*float64(killed)/float64(total))
+		}
+		fmt.Printf("  %-40s %d/%d killed  %s\n", p.Dir, killed, total, score)
+		for _, m := range p.Mutants {
+			if m.Status == "survived" {
+				survivors = append(survivors, m)
+			}
+		}
+	}
+	if len(survivors) > 0 {
+		fmt.Println("\nSurviving mutants (no test failed with the change):")
+		for _, m := range survivors {
+			fmt.Printf("  %s:%d:%d  %s -> %s\n", m.File, m.Line, m.Column, m.Original, m.Mutated)
+		}
+	}
+	if score, ok := report.score(); ok {
+		killed, total := report.counts()
+		fmt.Printf("\nMutation score: %.1f%% (%d of %d mutants killed)\n", score, killed, total)
+	} else {
+		fmt.Println("\nNo mutants ran. Packages without tests are skipped; generate test skeletons with 'gonext g test'.")
+	}
+}
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// testShard, testShards and testTimings are set by `gonext test --shard/--shards/--timings`
+var testShard string
+var testShards int
+var testTimings string
+
+// defaultPackageSeconds is the estimate for packages without timing data when
+// no package has any
+const defaultPackageSeconds = 1.0
+
+// testEvent is an event of `go test -json`
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// parseShard parses --shard i/n, with i counted from 1
+func parseShard(s string) (index, total int, err error) {
+	i, n, ok := strings.Cut(s, "/")
+	index, err1 := strconv.Atoi(i)
+	total, err2 := strconv.Atoi(n)
+	if !ok || err1 != nil || err2 != nil || total < 1 || index < 1 || index > total {
+		return 0, 0, fmt.Errorf("invalid --shard %q: expected i/n with 1 <= i <= n, e.g. 2/4", s)
+	}
+	return index, total, nil
+}
+
+// listPackages returns the import paths of the packages matched by patterns
+func listPackages(patterns []string) ([]string, error) {
+	out, err := exec.Command("go", append([]string{"list"}, patterns...)...).Output()
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("go list: %s", strings.TrimSpace(string(exit.Stderr)))
+		}
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// loadTimings reads the seconds each package's tests took in earlier runs
+func loadTimings(path string) (map[string]float64, error) {
+	timings := map[string]float64{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return timings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &timings); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", path, err)
+	}
+	return timings, nil
+}
+
+// saveTimings merges measured into the timing data at path
+func saveTimings(path string, measured map[string]float64) error {
+	timings, err := loadTimings(path)
+	if err != nil {
+		return err
+	}
+	for pkg, seconds := range measured {
+		timings[pkg] = seconds
+	}
+	data, err := json.MarshalIndent(timings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// testShardPlan is the packages of a shard and their estimated duration
+type testShardPlan struct {
+	Packages []string
+	Seconds  float64
+}
+
+// shardPackages splits packages into n shards of balanced duration: the longest
+// packages first, each to the shard with the least work so far. Packages
+// without timing data are estimated at the average of the known ones. The
+// split only depends on the packages and the timings, so CI jobs given the
+// same timing file agree on it.
+func shardPackages(packages []string, timings map[string]float64, n int) []testShardPlan {
+	estimate := defaultPackageSeconds
+	known, sum := 0, 0.0
+	for _, pkg := range packages {
+		if seconds, ok := timings[pkg]; ok {
+			known++
+			sum += seconds
+		}
+	}
+	if known > 0 {
+		estimate = sum / float64(known)
+	}
+	seconds := func(pkg string) float64 {
+		if s, ok := timings[pkg]; ok {
+			return s
+		}
+		return estimate
+	}
+	sorted := append([]string{}, packages...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if seconds(sorted[i]) != seconds(sorted[j]) {
+			return seconds(sorted[i]) > seconds(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+	shards := make([]testShardPlan, n)
+	for _, pkg := range sorted {
+		least := 0
+		for i := range shards {
+			if shards[i].Seconds < shards[least].Seconds {
+				least = i
+			}
+		}
+		shards[least].Packages = append(shards[least].Packages, pkg)
+		shards[least].Seconds += seconds(pkg)
+	}
+	for i := range shards {
+		sort.Strings(shards[i].Packages)
+	}
+	return shards
+}
+
+// testRun is what a `go test -json` run reported
+type testRun struct {
+	Elapsed map[string]float64         // seconds per package, except cached results
+	Results map[string]map[string]bool // package -> top-level test -> passed
+}
+
+// goTestJSON runs `go test -json` with args and prints the output with prefix as
+// go test would, or nothing when quiet
+func goTestJSON(args []string, prefix string, quiet bool) (testRun, error) {
+	run := testRun{Elapsed: map[string]float64{}, Results: map[string]map[string]bool{}}
+	c := exec.Command("go", append([]string{"test", "-json"}, args...)...)
+	if !quiet {
+		c.Stderr = os.Stderr
+	}
+	stdout, err := c.StdoutPipe()
+	if err != nil {
+		return run, err
+	}
+	if err := c.Start(); err != nil {
+		return run, err
+	}
+	verbose := false
+	for _, a := range args {
+		verbose = verbose || a == "-v" || a == "-test.v"
+	}
+	// Without -v, a test's output is only shown if it fails, as go test does
+	held := map[string]string{}
+	// Cached results take no time, so they would replace real durations
+	cached := map[string]bool{}
+	print := func(s string) {
+		if !quiet {
+			fmt.Print(s)
+		}
+	}
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			print(prefix + scanner.Text() + "\n")
+			continue
+		}
+		key := e.Package + " " + e.Test
+		if e.Test == "" && strings.Contains(e.Output, "(cached)") {
+			cached[e.Package] = true
+		}
+		switch {
+		case e.Test == "" || verbose:
+			// go test only prints a passing package's PASS line with -v
+			if e.Output != "" && (verbose || e.Output != "PASS\n") {
+				print(prefix + e.Output)
+			}
+		case e.Output != "":
+			held[key] += prefix + e.Output
+		case e.Action == "fail":
+			print(held[key])
+			delete(held, key)
+		case e.Action == "pass" || e.Action == "skip":
+			delete(held, key)
+		}
+		if e.Test == "" && (e.Action == "pass" || e.Action == "fail" || e.Action == "skip") && !cached[e.Package] {
+			run.Elapsed[e.Package] = e.Elapsed
+		}
+		if e.Test != "" && !strings.Contains(e.Test, "/") && (e.Action == "pass" || e.Action == "fail") {
+			if run.Results[e.Package] == nil {
+				run.Results[e.Package] = map[string]bool{}
+			}
+			run.Results[e.Package][e.Test] = e.Action == "pass"
+		}
+	}
+	return run, c.Wait()
+}
+
+// runShardedTests runs the tests of packages split into balanced shards: only
+// shard --shard i/n, or all --shards n as concurrent processes
+func runShardedTests(patterns, goFlags []string) error {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	index, total := 0, testShards
+	if testShard != "" {
+		var err error
+		if index, total, err = parseShard(testShard); err != nil {
+			return err
+		}
+	}
+	packages, err := listPackages(patterns)
+	if err != nil {
+		return err
+	}
+	timings, err := loadTimings(testTimings)
+	if err != nil {
+		return err
+	}
+	quarantine, err := loadQuarantine(testQuarantine)
+	if err != nil {
+		return err
+	}
+	shards := shardPackages(packages, timings, total)
+
+	run := make([]int, 0, total)
+	if index > 0 {
+		run = append(run, index-1)
+	} else {
+		for i := range shards {
+			run = append(run, i)
+		}
+	}
+	for _, i := range run {
+		fmt.Printf("Shard %d/%d: %d package(s), about %.1fs\n", i+1, total, len(shards[i].Packages), shards[i].Seconds)
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	measured := map[string]float64{}
+	var failed []string
+	for _, i := range run {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			prefix := ""
+			if len(run) > 1 {
+				prefix = fmt.Sprintf("[%d/%d] ", i+1, total)
+			}
+			run, err := runTests(shards[i].Packages, goFlags, prefix, quarantine)
+			mu.Lock()
+			defer mu.Unlock()
+			for pkg, seconds := range run.Elapsed {
+				measured[pkg] = seconds
+			}
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%d/%d", i+1, total))
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := saveTimings(testTimings, measured); err != nil {
+		fmt.Printf("Error saving the timings to %s: %v\n", testTimings, err)
+	}
+	var ran []string
+	for _, i := range run {
+		ran = append(ran, shards[i].Packages...)
+	}
+	sort.Strings(ran)
+	reportQuarantined(ran, goFlags, quarantine)
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("tests failed in shard %s", strings.Join(failed, ", "))
+	}
+	fmt.Printf("Shard(s) passed in %s\n", time.Since(start).Round(time.Millisecond))
+	return nil
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+	"github.com/Alexigbokwe/gonext/internal/openapi"
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// watchNoRun and watchInterval are set by `gonext watch --no-run/--interval`
+var watchNoRun bool
+var watchInterval time.Duration
+
+// routeTableFile is the route table kept in sync by `gonext watch`
+var routeTableFile = filepath.Join("docs", "routes.json")
+
+// watchBinary is the app binary rebuilt by `gonext watch`
+var watchBinary = filepath.Join(".gonext", "watch", "app")
+
+// contractDirs are the module directories whose files change the API contract
+var contractDirs = map[string]bool{"controller": true, "dto": true, "entity": true, "model": true, "route": true}
+
+// sourceSnapshot maps each .go file of the project to its size and modification time
+func sourceSnapshot() map[string]string {
+	files := map[string]string{}
+	filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", ".gonext", "vendor", "node_modules":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".go") {
+			if info, err := d.Info(); err == nil {
+				files[path] = fmt.Sprintf("%d %d", info.Size(), info.ModTime().UnixNano())
+			}
+		}
+		return nil
+	})
+	return files
+}
+
+// changedFiles lists the files added, removed or modified between two snapshots
+func changedFiles(before, after map[string]string) []string {
+	var changed []string
+	for path, stamp := range after {
+		if before[path] != stamp {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+// affectsContract reports whether a file is a module controller, DTO, entity, model or route
+func affectsContract(path string) bool {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	return len(parts) >= 4 && parts[0] == workspace.AppDir && contractDirs[parts[2]]
+}
+
+// writeArtifact writes v as indented JSON, leaving the file untouched when its
+// content is unchanged so tools watching it aren't triggered for nothing
+func writeArtifact(path string, v any) (bool, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	data = append(data, '\n')
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, data) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, data, 0644)
+}
+
+// shellCommand runs a command line through the platform shell
+func shellCommand(line string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", line)
+	}
+	return exec.Command("sh", "-c", line)
+}
+
+// regenerateArtifacts rewrites the OpenAPI spec and the route table, then runs
+// the watch commands of gonext.yaml
+func regenerateArtifacts() {
+	doc, err := openapi.Build(getModuleName(), "1.0.0")
+	if err != nil {
+		fmt.Printf("Error building OpenAPI spec: %v\n", err)
+	} else if written, err := writeArtifact(filepath.Join("docs", "openapi.json"), doc); err != nil {
+		fmt.Printf("Error writing OpenAPI spec: %v\n", err)
+	} else if written {
+		fmt.Printf("  updated    docs/openapi.json (%d paths, %d schemas)\n", len(doc.Paths), len(doc.Components.Schemas))
+	}
+	routes, err := workspace.Routes()
+	if routes == nil {
+		routes = []workspace.Route{}
+	}
+	if err != nil {
+		fmt.Printf("Error scanning routes: %v\n", err)
+	} else if written, err := writeArtifact(routeTableFile, routes); err != nil {
+		fmt.Printf("Error writing %s: %v\n", routeTableFile, err)
+	} else if written {
+		fmt.Printf("  updated    %s (%d routes)\n", filepath.ToSlash(routeTableFile), len(routes))
+	}
+	m, err := manifest.Load()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, w := range m.Watch {
+		c := shellCommand(w.Run)
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			fmt.Printf("Error running watch command '%s': %v\n", w.Name, err)
+			continue
+		}
+		fmt.Printf("  ran        %s\n", w.Name)
+	}
+}
+
+// appProcess is the app started by `gonext watch`
+type appProcess struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// startApp builds the app into .gonext/watch and starts it. A build failure is
+// printed and leaves no app running until the next change.
+func startApp() *appProcess {
+	build := exec.Command("go", "build", "-o", watchBinary, ".")
+	build.Stdout = os.Stdout
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		fmt.Println("Build failed, waiting for changes...")
+		return nil
+	}
+	binary, _ := filepath.Abs(watchBinary)
+	c := exec.Command(binary)
+	c.Env = devServerEnv()
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Start(); err != nil {
+		fmt.Printf("Error starting the app: %v\n", err)
+		return nil
+	}
+	p := &appProcess{cmd: c, done: make(chan struct{})}
+	go func() {
+		c.Wait()
+		close(p.done)
+	}()
+	return p
+}
+
+// stop asks the app to shut down gracefully and kills it if it hasn't after 5 seconds
+func (p *appProcess) stop() {
+	if p == nil {
+		return
+	}
+	if err := p.cmd.Process.Signal(os.Interrupt); err != nil {
+		p.cmd.Process.Kill()
+	}
+	select {
+	case <-p.done:
+	case <-time.After(5 * time.Second):
+		p.cmd.Process.Kill()
+		<-p.done
+	}
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Restart the app and regenerate the OpenAPI spec, route table, SDKs and mocks on change",
+	Long: `Runs the app and rebuilds it whenever a .go file changes. When a module's
+controllers, DTOs, entities, models or routes change, derived artifacts are
+regenerated first so contracts stay in sync with the code:
+
+  docs/openapi.json   the OpenAPI spec, as written by 'gonext openapi'
+  docs/routes.json    the route table, as printed by 'gonext list routes --json'
+
+Client SDKs, mocks and other artifacts are produced by the commands listed under
+'watch' in gonext.yaml, run in order after the built-in artifacts:
+
+  watch:
+    - name: sdk
+      run: npx openapi-typescript docs/openapi.json -o web/src/api.ts
+    - name: mocks
+      run: mockery --dir app --all --output mocks`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(workspace.AppDir); err != nil {
+			fmt.Println("Error: no app/ directory here. Run 'gonext watch' from the project root.")
+			return
+		}
+		if !watchNoRun {
+			warnGoToolchain()
+		}
+		fmt.Println("Regenerating derived artifacts...")
+		regenerateArtifacts()
+		var app *appProcess
+		if !watchNoRun {
+			app = startApp()
+		}
+		snapshot := sourceSnapshot()
+		fmt.Printf("Watching for changes (every %s)...\n", watchInterval)
+		for range time.Tick(watchInterval) {
+			current := sourceSnapshot()
+			changed := changedFiles(snapshot, current)
+			if len(changed) == 0 {
+				continue
+			}
+			contract := false
+			for _, path := range changed {
+				contract = contract || affectsContract(path)
+			}
+			if contract {
+				fmt.Println("Contract changed, regenerating derived artifacts...")
+				regenerateArtifacts()
+			}
+			if !watchNoRun {
+				fmt.Printf("%d file(s) changed, restarting...\n", len(changed))
+				app.stop()
+				app = startApp()
+			}
+			// Files written by the watch commands are part of this round, not the next
+			snapshot = sourceSnapshot()
+		}
+	},
+}
+
+func init() {
+	watchCmd.Flags().BoolVar(&watchNoRun, "no-run", false, "Only regenerate derived artifacts, without running the app")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 500*time.Millisecond, "How often to check for changes")
+	rootCmd.AddCommand(watchCmd)
+}
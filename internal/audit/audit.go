@@ -0,0 +1,165 @@
+// Package audit implements the security checks of `gonext audit`: known
+// vulnerabilities reported by govulncheck, hardcoded secrets, mutating routes
+// without authentication and permissive CORS settings.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Levels of a finding, as in SARIF
+const (
+	Error   = "error"
+	Warning = "warning"
+	Note    = "note"
+)
+
+// Rule describes a kind of finding
+type Rule struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	HelpURI     string `json:"helpUri,omitempty"`
+}
+
+// Built-in rules; vulnerabilities use their OSV id as rule
+var (
+	SecretRule = Rule{ID: "hardcoded-secret", Description: "Secret committed in source or configuration"}
+	AuthRule   = Rule{ID: "missing-auth", Description: "Mutating route without authentication middleware"}
+	CORSRule   = Rule{ID: "permissive-cors", Description: "CORS allows any origin"}
+)
+
+// Finding is a problem reported by a check
+type Finding struct {
+	Rule    Rule   `json:"-"`
+	RuleID  string `json:"rule"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+}
+
+func newFinding(rule Rule, level, file string, line int, format string, args ...any) Finding {
+	return Finding{Rule: rule, RuleID: rule.ID, Level: level, Message: fmt.Sprintf(format, args...), File: file, Line: line}
+}
+
+// Sort orders findings by level, then location
+func Sort(findings []Finding) {
+	rank := map[string]int{Error: 0, Warning: 1, Note: 2}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if rank[a.Level] != rank[b.Level] {
+			return rank[a.Level] < rank[b.Level]
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+}
+
+// AtLeast reports whether any finding is at level or more severe
+func AtLeast(findings []Finding, level string) bool {
+	rank := map[string]int{Error: 0, Warning: 1, Note: 2}
+	for _, f := range findings {
+		if rank[f.Level] <= rank[level] {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON writes the findings as a JSON array
+func WriteJSON(w io.Writer, findings []Finding) error {
+	if findings == nil {
+		findings = []Finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(findings)
+}
+
+// sarifLog is the subset of SARIF 2.1.0 code scanning tools read
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID               string    `json:"id"`
+	ShortDescription sarifText `json:"shortDescription"`
+	HelpURI          string    `json:"helpUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifText       `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifURI     `json:"artifactLocation"`
+	Region           *sarifRegion `json:"region,omitempty"`
+}
+
+type sarifURI struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// WriteSARIF writes the findings as a SARIF 2.1.0 log, e.g. for GitHub code scanning
+func WriteSARIF(w io.Writer, findings []Finding) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "gonext audit", InformationURI: "https://github.com/Alexigbokwe/gonext", Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	seen := map[string]bool{}
+	for _, f := range findings {
+		if !seen[f.RuleID] {
+			seen[f.RuleID] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: f.RuleID, ShortDescription: sarifText{f.Rule.Description}, HelpURI: f.Rule.HelpURI})
+		}
+		result := sarifResult{RuleID: f.RuleID, Level: f.Level, Message: sarifText{f.Message}}
+		if f.File != "" {
+			location := sarifPhysicalLocation{ArtifactLocation: sarifURI{URI: f.File}}
+			if f.Line > 0 {
+				location.Region = &sarifRegion{StartLine: f.Line}
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: location}}
+		}
+		run.Results = append(run.Results, result)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Schema: "https://json.schemastore.org/sarif-2.1.0.json", Version: "2.1.0", Runs: []sarifRun{run}})
+}
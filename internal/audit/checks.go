@@ -0,0 +1,393 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+)
+
+// PublicMarker on or above a route registration accepts the route without authentication
+const PublicMarker = "audit:public"
+
+// authName matches the names of authentication middleware, e.g. middleware.JWT() or RequireAuth
+var authName = regexp.MustCompile(`(?i)auth|jwt|guard|protect|apikey|api_key|bearer|session`)
+
+// groupUse matches group-level middleware such as group.Use(middleware.Auth())
+var groupUse = regexp.MustCompile(`\.Use\(([^\n]*)\)`)
+
+// mutatingMethods change state and should not be reachable anonymously
+var mutatingMethods = map[string]bool{"POST": true, "PUT": true, "PATCH": true, "DELETE": true, "ALL": true}
+
+// MissingAuth reports the mutating routes that no authentication middleware protects:
+// none on the route itself, on its group in the route file or module.go, or in the
+// global middleware chain.
+func MissingAuth(routes []workspace.Route, globalChain string) []Finding {
+	if data, err := os.ReadFile(globalChain); err == nil && usesAuth(string(data)) {
+		return nil
+	}
+	protected := map[string]bool{}
+	groupAuth := func(file string) bool {
+		if covered, ok := protected[file]; ok {
+			return covered
+		}
+		data, _ := os.ReadFile(file)
+		covered := false
+		for _, m := range groupUse.FindAllStringSubmatch(string(data), -1) {
+			if authName.MatchString(m[1]) {
+				covered = true
+			}
+		}
+		protected[file] = covered
+		return covered
+	}
+	var findings []Finding
+	for _, r := range routes {
+		if !mutatingMethods[r.Method] || isPublic(r.File, r.Line) {
+			continue
+		}
+		if authName.MatchString(strings.Join(r.Middleware, " ")) || groupAuth(r.File) || groupAuth(filepath.Join(workspace.AppDir, r.Module, "module.go")) {
+			continue
+		}
+		findings = append(findings, newFinding(AuthRule, Warning, r.File, r.Line,
+			"%s %s has no authentication middleware; add one or mark the route with // %s", r.Method, r.Path, PublicMarker))
+	}
+	return findings
+}
+
+// usesAuth reports whether uncommented code mentions an authentication middleware
+func usesAuth(src string) bool {
+	for _, line := range strings.Split(src, "\n") {
+		if line = strings.TrimSpace(line); !strings.HasPrefix(line, "//") && authName.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPublic reports whether the line or the one above it carries the public marker
+func isPublic(file string, line int) bool {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	lines := strings.Split(string(data), "\n")
+	for _, l := range []int{line - 2, line - 1} {
+		if l >= 0 && l < len(lines) && strings.Contains(lines[l], PublicMarker) {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissiveCORS reports cors.New calls that allow every origin. Allowing every
+// origin with credentials is an error: any site can make authenticated requests.
+func PermissiveCORS(files []string) []Finding {
+	var findings []Finding
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".go") {
+			continue
+		}
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "New" {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "cors" {
+				return true
+			}
+			line := fset.Position(call.Pos()).Line
+			if len(call.Args) == 0 {
+				findings = append(findings, newFinding(CORSRule, Warning, file, line, "cors.New() without a config allows every origin; set AllowOrigins"))
+				return true
+			}
+			config, ok := call.Args[0].(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			wildcard, credentials := false, false
+			for _, elt := range config.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				key, _ := kv.Key.(*ast.Ident)
+				if key == nil {
+					continue
+				}
+				switch key.Name {
+				case "AllowOrigins":
+					wildcard = allowsAnyOrigin(kv.Value)
+				case "AllowCredentials":
+					v, ok := kv.Value.(*ast.Ident)
+					credentials = ok && v.Name == "true"
+				}
+			}
+			switch {
+			case wildcard && credentials:
+				findings = append(findings, newFinding(CORSRule, Error, file, line, "CORS allows every origin with credentials; list the allowed origins"))
+			case wildcard:
+				findings = append(findings, newFinding(CORSRule, Warning, file, line, "CORS allows every origin (AllowOrigins \"*\")"))
+			}
+			return true
+		})
+	}
+	return findings
+}
+
+// allowsAnyOrigin reports whether an AllowOrigins value is or contains "*"
+func allowsAnyOrigin(e ast.Expr) bool {
+	switch v := e.(type) {
+	case *ast.BasicLit:
+		s, _ := strconv.Unquote(v.Value)
+		for _, origin := range strings.Split(s, ",") {
+			if strings.TrimSpace(origin) == "*" {
+				return true
+			}
+		}
+	case *ast.CompositeLit:
+		for _, elt := range v.Elts {
+			if allowsAnyOrigin(elt) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// secretAssignment matches secret-looking keys assigned a quoted literal, e.g.
+// Password: "hunter22" in Go or api_key: "abc..." in YAML and JSON
+var secretAssignment = regexp.MustCompile(`(?i)["']?\b([a-z0-9_.-]*(?:password|passwd|secret|token|api_?key|private_?key|access_?key)[a-z0-9_]*)["']?\s*(?::=|[:=])\s*["']([^"'\s]{8,})["']`)
+
+// secretSetting matches unquoted values in .env, YAML and TOML files, e.g. JWT_SECRET=abc...
+var secretSetting = regexp.MustCompile(`(?i)^\s*([a-z0-9_.-]*(?:password|passwd|secret|token|api_?key|private_?key|access_?key)[a-z0-9_]*)\s*[:=]\s*([^"'\s#]{8,})\s*$`)
+
+// secretTokens match credentials recognizable by their format
+var secretTokens = map[string]*regexp.Regexp{
+	"AWS access key":    regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+	"private key":       regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+	"GitHub token":      regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),
+	"Slack token":       regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),
+	"Stripe secret key": regexp.MustCompile(`\bsk_live_[A-Za-z0-9]{16,}\b`),
+}
+
+// secretToken returns the kind of credential on a line recognizable by its format, or ""
+func secretToken(text string) string {
+	kinds := make([]string, 0, len(secretTokens))
+	for kind := range secretTokens {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		if secretTokens[kind].MatchString(text) {
+			return kind
+		}
+	}
+	return ""
+}
+
+// Secret is a credential found in rendered content
+type Secret struct {
+	Line int
+	Kind string
+}
+
+// ObviousSecrets returns the credentials in content recognizable by their format,
+// such as private keys and AWS access keys. Unlike HardcodedSecrets it ignores
+// secret-looking assignments, so generators can use it without false positives.
+func ObviousSecrets(content string) []Secret {
+	var secrets []Secret
+	for i, line := range strings.Split(content, "\n") {
+		if kind := secretToken(line); kind != "" {
+			secrets = append(secrets, Secret{Line: i + 1, Kind: kind})
+		}
+	}
+	return secrets
+}
+
+// placeholders are values that are clearly not real secrets
+var placeholders = regexp.MustCompile(`(?i)^\$|^\{\{|^<|example|changeme|change-me|placeholder|your[-_]|xxxx|dummy|redacted|\*\*\*|os\.Getenv|getenv`)
+
+// configExtensions are the files scanned for secrets, besides Go sources
+var configExtensions = map[string]bool{".yaml": true, ".yml": true, ".json": true, ".toml": true, ".tf": true, ".tfvars": true, ".properties": true}
+
+// ScannedFile reports whether the secret scan reads a file
+func ScannedFile(path string) bool {
+	base := filepath.Base(path)
+	if strings.HasSuffix(base, "_test.go") || strings.Contains(filepath.ToSlash(path), "testdata/") {
+		return false
+	}
+	return strings.HasSuffix(base, ".go") || configExtensions[filepath.Ext(base)] || strings.HasPrefix(base, ".env") ||
+		strings.HasPrefix(base, "Dockerfile") || strings.HasPrefix(base, "docker-compose")
+}
+
+// HardcodedSecrets reports secret-looking values in Go sources and configuration files
+func HardcodedSecrets(files []string) []Finding {
+	var findings []Finding
+	for _, file := range files {
+		if !ScannedFile(file) {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil || bytes.IndexByte(data, 0) >= 0 {
+			continue
+		}
+		config := !strings.HasSuffix(file, ".go")
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			text := scanner.Text()
+			if finding, ok := secretOnLine(text, config); ok {
+				findings = append(findings, newFinding(SecretRule, Error, file, line, "%s; read it from the environment instead", finding))
+			}
+		}
+	}
+	return findings
+}
+
+// secretOnLine describes the secret found on a line, if any
+func secretOnLine(text string, config bool) (string, bool) {
+	if kind := secretToken(text); kind != "" {
+		return kind + " in source", true
+	}
+	matches := [][]string{secretAssignment.FindStringSubmatch(text)}
+	if config {
+		matches = append(matches, secretSetting.FindStringSubmatch(text))
+	}
+	for _, m := range matches {
+		if m != nil && !placeholders.MatchString(m[2]) && !strings.Contains(m[2], "${") {
+			return "hardcoded value for " + m[1], true
+		}
+	}
+	return "", false
+}
+
+// relativePath makes the absolute paths govulncheck reports relative to the project
+func relativePath(path string) string {
+	if wd, err := os.Getwd(); err == nil && filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
+
+// govulncheckMessage is an entry of the `govulncheck -format json` stream
+type govulncheckMessage struct {
+	OSV *struct {
+		ID       string   `json:"id"`
+		Summary  string   `json:"summary"`
+		Aliases  []string `json:"aliases"`
+		Database struct {
+			URL string `json:"url"`
+		} `json:"database_specific"`
+	} `json:"osv"`
+	Finding *struct {
+		OSV          string `json:"osv"`
+		FixedVersion string `json:"fixed_version"`
+		Trace        []struct {
+			Module   string `json:"module"`
+			Version  string `json:"version"`
+			Package  string `json:"package"`
+			Function string `json:"function"`
+			Receiver string `json:"receiver"`
+			Position *struct {
+				Filename string `json:"filename"`
+				Line     int    `json:"line"`
+			} `json:"position"`
+		} `json:"trace"`
+	} `json:"finding"`
+}
+
+// Vulnerabilities converts the JSON output of govulncheck to findings. Vulnerable
+// code the project calls is an error; vulnerable modules or packages it only
+// depends on are notes.
+func Vulnerabilities(r io.Reader) ([]Finding, error) {
+	rules := map[string]Rule{}
+	type result struct {
+		finding Finding
+		called  bool
+	}
+	results := map[string]*result{}
+	var order []string
+	dec := json.NewDecoder(r)
+	for {
+		var msg govulncheckMessage
+		if err := dec.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if msg.OSV != nil {
+			description := msg.OSV.Summary
+			if len(msg.OSV.Aliases) > 0 {
+				description += " (" + strings.Join(msg.OSV.Aliases, ", ") + ")"
+			}
+			rules[msg.OSV.ID] = Rule{ID: msg.OSV.ID, Description: description, HelpURI: msg.OSV.Database.URL}
+		}
+		if msg.Finding == nil || len(msg.Finding.Trace) == 0 {
+			continue
+		}
+		frame := msg.Finding.Trace[0]
+		called := frame.Function != ""
+		res := results[msg.Finding.OSV]
+		if res == nil {
+			res = &result{}
+			results[msg.Finding.OSV] = res
+			order = append(order, msg.Finding.OSV)
+		}
+		if res.called && !called {
+			continue
+		}
+		fix := "no fixed version"
+		if msg.Finding.FixedVersion != "" {
+			fix = "fixed in " + frame.Module + "@" + msg.Finding.FixedVersion
+		}
+		f := Finding{RuleID: msg.Finding.OSV, Level: Note, File: "go.mod",
+			Message: msg.Finding.OSV + " in " + frame.Module + "@" + frame.Version + " is required but not called; " + fix}
+		if called {
+			caller := msg.Finding.Trace[len(msg.Finding.Trace)-1]
+			symbol := frame.Function
+			if frame.Receiver != "" {
+				symbol = frame.Receiver + "." + symbol
+			}
+			f.Level = Error
+			f.Message = msg.Finding.OSV + ": " + frame.Package + "." + symbol + " is called; " + fix
+			f.File, f.Line = "", 0
+			if caller.Position != nil {
+				f.File, f.Line = relativePath(caller.Position.Filename), caller.Position.Line
+			}
+		}
+		res.finding, res.called = f, called
+	}
+	var findings []Finding
+	for _, id := range order {
+		f := results[id].finding
+		f.Rule = rules[id]
+		if f.Rule.ID == "" {
+			f.Rule = Rule{ID: id, Description: id}
+		}
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
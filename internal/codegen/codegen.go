@@ -0,0 +1,7 @@
+package codegen
+
+// File is a single generated file
+type File struct {
+	Path    string
+	Content string
+}
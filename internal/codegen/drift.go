@@ -0,0 +1,148 @@
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Drift describes how a tracked file compares with its recorded template version
+type Drift string
+
+const (
+	Unchanged Drift = "unchanged"
+	Modified  Drift = "modified"
+	Missing   Drift = "missing"
+)
+
+// DriftResult is the drift status of a single tracked file
+type DriftResult struct {
+	Path   string
+	Status Drift
+}
+
+// Drift compares every tracked file with the hash recorded when it was last generated
+func (s *State) Drift() []DriftResult {
+	var results []DriftResult
+	for _, path := range s.Paths() {
+		recorded, _ := s.Recorded(path)
+		data, err := os.ReadFile(filepath.Join(s.root, path))
+		status := Unchanged
+		switch {
+		case err != nil:
+			status = Missing
+		case Hash(data) != recorded:
+			status = Modified
+		}
+		results = append(results, DriftResult{Path: path, Status: status})
+	}
+	return results
+}
+
+// RecordTree records every file below the state root as the template baseline,
+// skipping version control and GoNext metadata
+func (s *State) RecordTree() error {
+	return filepath.Walk(s.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if rel == ".git" || rel == ".gonext" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return s.Record(rel, data)
+	})
+}
+
+// UnifiedDiff renders a unified diff between two versions of a file with three lines of context
+func UnifiedDiff(oldName, newName, oldContent, newContent string) string {
+	a, b := splitLines(oldContent), splitLines(newContent)
+	matches := lcsMatches(a, b)
+
+	// Build the edit script as a sequence of ' ', '-', '+' operations
+	type op struct {
+		kind byte
+		line string
+	}
+	var ops []op
+	j := 0
+	for i, line := range a {
+		if matches[i] < 0 {
+			ops = append(ops, op{'-', line})
+			continue
+		}
+		for ; j < matches[i]; j++ {
+			ops = append(ops, op{'+', b[j]})
+		}
+		ops = append(ops, op{' ', line})
+		j++
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+
+	const context = 3
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		// Skip to the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		from := max(start-context, 0)
+		// Extend the hunk until there are more than 2*context unchanged lines in a row
+		end, same := start, 0
+		for end < len(ops) && same <= 2*context {
+			if ops[end].kind == ' ' {
+				same++
+			} else {
+				same = 0
+			}
+			end++
+		}
+		end -= max(same-context, 0)
+
+		oldStart, newStart := 1, 1
+		for _, o := range ops[:from] {
+			if o.kind != '+' {
+				oldStart++
+			}
+			if o.kind != '-' {
+				newStart++
+			}
+		}
+		oldLen, newLen := 0, 0
+		for _, o := range ops[from:end] {
+			if o.kind != '+' {
+				oldLen++
+			}
+			if o.kind != '-' {
+				newLen++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", oldStart, oldLen, newStart, newLen)
+		for _, o := range ops[from:end] {
+			sb.WriteByte(o.kind)
+			sb.WriteString(o.line)
+			if !strings.HasSuffix(o.line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		start = end
+	}
+	return sb.String()
+}
@@ -0,0 +1,72 @@
+package codegen
+
+import (
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+)
+
+// bannerLine marks files created by the generators when the header banner is enabled
+const bannerLine = "// Scaffolded by GoNext CLI (https://github.com/Alexigbokwe/gonext)."
+
+// HeaderComment renders the configured header as a Go comment block, or "" if none is configured
+func HeaderComment(h manifest.Header) string {
+	var lines []string
+	text := strings.TrimRight(h.Text, "\n")
+	if text != "" {
+		for _, line := range strings.Split(text, "\n") {
+			if strings.HasPrefix(line, "//") {
+				lines = append(lines, line)
+			} else if line == "" {
+				lines = append(lines, "//")
+			} else {
+				lines = append(lines, "// "+line)
+			}
+		}
+	}
+	if h.Banner {
+		lines = append(lines, bannerLine)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n\n"
+}
+
+// ApplyHeader ensures content starts with header. The previously configured
+// header, or an existing license header (a leading comment block separated from
+// the package clause by a blank line that mentions a copyright, SPDX identifier
+// or the GoNext banner), is replaced.
+func ApplyHeader(content, header, previous string) string {
+	if header == "" || strings.HasPrefix(content, header) {
+		return content
+	}
+	if previous != "" && strings.HasPrefix(content, previous) {
+		content = strings.TrimLeft(strings.TrimPrefix(content, previous), "\n")
+	} else if existing := leadingHeader(content); existing != "" {
+		content = strings.TrimPrefix(content, existing)
+	}
+	return header + content
+}
+
+// leadingHeader returns the license-style comment block at the top of content, including trailing blank lines
+func leadingHeader(content string) string {
+	lines := strings.SplitAfter(content, "\n")
+	n := 0
+	for n < len(lines) && strings.HasPrefix(lines[n], "//") {
+		n++
+	}
+	if n == 0 || n >= len(lines) || strings.TrimSpace(lines[n]) != "" {
+		// No comment block, or it is a package doc comment attached to the package clause
+		return ""
+	}
+	block := strings.Join(lines[:n], "")
+	lower := strings.ToLower(block)
+	if !strings.Contains(lower, "copyright") && !strings.Contains(lower, "spdx-license-identifier") && !strings.Contains(block, bannerLine) {
+		return ""
+	}
+	for n < len(lines) && strings.TrimSpace(lines[n]) == "" {
+		n++
+	}
+	return strings.Join(lines[:n], "")
+}
@@ -0,0 +1,131 @@
+package codegen
+
+import "strings"
+
+// Conflict markers written around regions where both the user and the generator changed the same lines
+const (
+	markerOurs   = "<<<<<<< yours\n"
+	markerSep    = "=======\n"
+	markerTheirs = ">>>>>>> generated\n"
+)
+
+// Merge3 performs a line based three-way merge of the user's version (ours) and
+// the newly generated version (theirs) against the previously generated base.
+// It returns the merged content and whether any conflict markers were written.
+func Merge3(base, ours, theirs string) (string, bool) {
+	b, o, t := splitLines(base), splitLines(ours), splitLines(theirs)
+	matchO, matchT := lcsMatches(b, o), lcsMatches(b, t)
+
+	var out []string
+	conflict := false
+	i, oi, ti := 0, 0, 0
+	for i < len(b) || oi < len(o) || ti < len(t) {
+		// Copy lines that are unchanged on both sides
+		k := 0
+		for i+k < len(b) && matchO[i+k] == oi+k && matchT[i+k] == ti+k {
+			k++
+		}
+		if k > 0 {
+			out = append(out, b[i:i+k]...)
+			i, oi, ti = i+k, oi+k, ti+k
+			continue
+		}
+
+		// Find the next base line both sides kept, the end of the changed chunk
+		j, jo, jt := len(b), len(o), len(t)
+		for n := i; n < len(b); n++ {
+			if matchO[n] >= oi && matchT[n] >= ti {
+				j, jo, jt = n, matchO[n], matchT[n]
+				break
+			}
+		}
+		baseChunk, oursChunk, theirsChunk := b[i:j], o[oi:jo], t[ti:jt]
+		switch {
+		case equal(oursChunk, baseChunk):
+			out = append(out, theirsChunk...)
+		case equal(theirsChunk, baseChunk), equal(oursChunk, theirsChunk):
+			out = append(out, oursChunk...)
+		default:
+			conflict = true
+			out = append(out, markerOurs)
+			out = append(out, terminated(oursChunk)...)
+			out = append(out, markerSep)
+			out = append(out, terminated(theirsChunk)...)
+			out = append(out, markerTheirs)
+		}
+		i, oi, ti = j, jo, jt
+	}
+	return strings.Join(out, ""), conflict
+}
+
+// splitLines splits content into lines, keeping line endings so the merge is lossless
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// terminated ensures the last line of a chunk ends with a newline so markers start on their own line
+func terminated(lines []string) []string {
+	if len(lines) == 0 || strings.HasSuffix(lines[len(lines)-1], "\n") {
+		return lines
+	}
+	out := append([]string{}, lines...)
+	out[len(out)-1] += "\n"
+	return out
+}
+
+// lcsMatches maps every line of a to its matching line in b according to the
+// longest common subsequence, or -1 when the line was removed
+func lcsMatches(a, b []string) []int {
+	n, m := len(a), len(b)
+	lengths := make([][]int, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	matches := make([]int, n)
+	for i := range matches {
+		matches[i] = -1
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[i] == b[j]:
+			matches[i] = j
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package codegen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// StateFile records the hash of every file the generators wrote, relative to the project root
+const StateFile = ".gonext/generated.json"
+
+// BaseDir keeps a copy of the last generated content of every file, used as the base of three-way merges
+const BaseDir = ".gonext/base"
+
+// Template identifies the starter template a project was scaffolded from
+type Template struct {
+	Repo   string `json:"repo"`
+	Commit string `json:"commit"`
+}
+
+// State tracks the content hashes of generated files so re-generation can
+// tell untouched scaffold apart from files the user has edited
+type State struct {
+	Template *Template         `json:"template,omitempty"`
+	Header   string            `json:"header,omitempty"` // header comment last applied to generated files
+	Files    map[string]string `json:"files"`
+	root     string
+	mu       sync.Mutex
+}
+
+// Hash returns the hex encoded sha256 of content
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadState reads the generation state of the project in the current directory
+func LoadState() (*State, error) {
+	return LoadStateAt(".")
+}
+
+// LoadStateAt reads the generation state of the project rooted at root,
+// returning an empty state if none exists yet
+func LoadStateAt(root string) (*State, error) {
+	st := &State{Files: map[string]string{}, root: root}
+	data, err := os.ReadFile(filepath.Join(root, StateFile))
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return st, err
+	}
+	if st.Files == nil {
+		st.Files = map[string]string{}
+	}
+	return st, nil
+}
+
+// Save writes the generation state back to disk
+func (s *State) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := filepath.Join(s.root, StateFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Paths returns the tracked file paths, sorted
+func (s *State) Paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths := make([]string, 0, len(s.Files))
+	for p := range s.Files {
+		paths = append(paths, filepath.FromSlash(p))
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Recorded returns the hash recorded for path, if any
+func (s *State) Recorded(path string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.Files[filepath.ToSlash(path)]
+	return h, ok
+}
+
+// Record stores the hash and a base copy of the content generated for path
+func (s *State) Record(path string, content []byte) error {
+	base := filepath.Join(s.root, BaseDir, path)
+	if err := os.MkdirAll(filepath.Dir(base), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(base, content, 0644); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Files[filepath.ToSlash(path)] = Hash(content)
+	return nil
+}
+
+// Base returns the content last generated for path, if a copy was kept
+func (s *State) Base(path string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(s.root, BaseDir, path))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
@@ -0,0 +1,134 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/Alexigbokwe/gonext/internal/utils"
+)
+
+// Status describes what happened to a file during generation
+type Status string
+
+const (
+	Created    Status = "created"
+	Updated    Status = "updated"
+	Skipped    Status = "skipped"
+	Merged     Status = "merged"
+	Conflicted Status = "conflicted"
+)
+
+// Result is the outcome for a single generated file
+type Result struct {
+	Path   string
+	Status Status
+}
+
+// Report collects the results of a generation run
+type Report struct {
+	Results []Result
+	mu      sync.Mutex
+}
+
+func (r *Report) add(path string, status Status) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Results = append(r.Results, Result{Path: path, Status: status})
+}
+
+// Count returns how many files ended with the given status
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Print writes one line per file followed by a summary
+func (r *Report) Print() {
+	sort.Slice(r.Results, func(i, j int) bool { return r.Results[i].Path < r.Results[j].Path })
+	for _, res := range r.Results {
+		fmt.Printf("  %-10s %s\n", res.Status, res.Path)
+	}
+	fmt.Printf("%d created, %d updated, %d merged, %d skipped, %d conflicted\n",
+		r.Count(Created), r.Count(Updated), r.Count(Merged), r.Count(Skipped), r.Count(Conflicted))
+}
+
+// Sync writes generated files incrementally. New files are created, files whose
+// content already matches are skipped and files still matching the last generated
+// hash are updated. Files the user has modified are three-way merged against the
+// last generated content; overlapping changes are written with conflict markers.
+// Existing files without a recorded base (hand-written or predating .gonext) are
+// reported as conflicted and left untouched.
+func Sync(files []File) (*Report, error) {
+	state, err := LoadState()
+	if err != nil {
+		return nil, fmt.Errorf("Error reading %s: %v", StateFile, err)
+	}
+	report := &Report{}
+	err = utils.ForEach(files, 0, func(f File) error {
+		content := []byte(f.Content)
+		existing, err := os.ReadFile(f.Path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Error reading %s: %v", f.Path, err)
+		}
+
+		status := Updated
+		switch {
+		case os.IsNotExist(err):
+			status = Created
+		case bytes.Equal(existing, content):
+			report.add(f.Path, Skipped)
+			return state.Record(f.Path, content)
+		default:
+			if recorded, ok := state.Recorded(f.Path); !ok || recorded != Hash(existing) {
+				// The user edited the file: merge their changes with the new output
+				base, ok := state.Base(f.Path)
+				if !ok {
+					// Nothing to merge against: an empty base would treat the whole
+					// file as conflicting, so leave it as the user wrote it
+					report.add(f.Path, Conflicted)
+					return nil
+				}
+				merged, conflict := Merge3(base, string(existing), f.Content)
+				status = Merged
+				if conflict {
+					status = Conflicted
+				}
+				if merged == string(existing) {
+					status = Skipped
+				} else if err := write(File{Path: f.Path, Content: merged}); err != nil {
+					return err
+				}
+				report.add(f.Path, status)
+				return state.Record(f.Path, content)
+			}
+		}
+		if err := write(f); err != nil {
+			return err
+		}
+		report.add(f.Path, status)
+		return state.Record(f.Path, content)
+	})
+	if saveErr := state.Save(); saveErr != nil && err == nil {
+		err = fmt.Errorf("Error writing %s: %v", StateFile, saveErr)
+	}
+	return report, err
+}
+
+func write(f File) error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return fmt.Errorf("Error creating %s: %v", filepath.Dir(f.Path), err)
+	}
+	if err := os.WriteFile(f.Path, []byte(f.Content), 0644); err != nil {
+		return fmt.Errorf("Error writing %s: %v", f.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,376 @@
+package codemod
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AddImport adds an import of path to src unless it is already imported
+func AddImport(src []byte, path string) ([]byte, error) {
+	return AddNamedImport(src, "", path)
+}
+
+// AddNamedImport adds an import of path under name to src, such as
+// ordersresolver "example.com/app/orders/resolver" when several packages share a
+// name, unless path is already imported. An empty name imports it unnamed.
+func AddNamedImport(src []byte, name, path string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	for _, imp := range f.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == path {
+			return src, nil
+		}
+	}
+	line := strconv.Quote(path)
+	if name != "" {
+		line = name + " " + line
+	}
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Rparen.IsValid() {
+			return insert(src, fset.Position(gen.Rparen).Offset, "\t"+line+"\n")
+		}
+		// Single import: turn it into a block
+		start, end := fset.Position(gen.Pos()).Offset, fset.Position(gen.End()).Offset
+		spec := string(src[fset.Position(gen.Specs[0].Pos()).Offset:end])
+		out := string(src[:start]) + "import (\n\t" + spec + "\n\t" + line + "\n)" + string(src[end:])
+		return format.Source([]byte(out))
+	}
+	// No imports yet: add a block after the package clause
+	return insert(src, fset.Position(f.Name.End()).Offset, "\n\nimport "+line+"\n")
+}
+
+// FixImports keeps imports in step with their use after slice edits. An import
+// whose package is no longer referenced becomes a blank import, so its path survives
+// for re-enabling, and a blank import whose package is referenced again is restored.
+func FixImports(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	for _, imp := range f.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := packageName(path)
+		switch {
+		case imp.Name == nil && !used[name]:
+			at := fset.Position(imp.Path.Pos()).Offset
+			edits = append(edits, edit{at, at, "_ "})
+		case imp.Name != nil && imp.Name.Name == "_" && used[name]:
+			edits = append(edits, edit{fset.Position(imp.Name.Pos()).Offset, fset.Position(imp.Path.Pos()).Offset, ""})
+		}
+	}
+	if len(edits) == 0 {
+		return src, nil
+	}
+	out := string(src)
+	for i := len(edits) - 1; i >= 0; i-- {
+		out = out[:edits[i].start] + edits[i].text + out[edits[i].end:]
+	}
+	return format.Source([]byte(out))
+}
+
+// packageName guesses the package name of an import path from its last element,
+// skipping major version suffixes such as /v2
+func packageName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	return name
+}
+
+// SliceLiteral locates the composite literal returned by the function named funcName
+func SliceLiteral(fset *token.FileSet, f *ast.File, funcName string) (*ast.CompositeLit, error) {
+	var lit *ast.CompositeLit
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != funcName || fn.Body == nil {
+			continue
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if ret, ok := n.(*ast.ReturnStmt); ok && lit == nil && len(ret.Results) == 1 {
+				lit, _ = ret.Results[0].(*ast.CompositeLit)
+			}
+			return lit == nil
+		})
+	}
+	if lit == nil {
+		return nil, fmt.Errorf("could not find a slice literal returned by %s()", funcName)
+	}
+	return lit, nil
+}
+
+// AppendToSlice appends elem as a new line at the end of the slice literal returned by funcName
+func AppendToSlice(src []byte, funcName, elem string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	lit, err := SliceLiteral(fset, f, funcName)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range lit.Elts {
+		if string(src[fset.Position(e.Pos()).Offset:fset.Position(e.End()).Offset]) == elem {
+			return src, nil
+		}
+	}
+	return insert(src, fset.Position(lit.Rbrace).Offset, "\t"+elem+",\n")
+}
+
+// AddStructField adds field as the last field of the struct type named typeName,
+// unless the struct already has a field called fieldName
+func AddStructField(src []byte, typeName, fieldName, field string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var st *ast.StructType
+	ast.Inspect(f, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.Name.Name == typeName {
+			st, _ = ts.Type.(*ast.StructType)
+		}
+		return st == nil
+	})
+	if st == nil {
+		return nil, fmt.Errorf("could not find struct type %s", typeName)
+	}
+	for _, fld := range st.Fields.List {
+		for _, name := range fld.Names {
+			if name.Name == fieldName {
+				return src, nil
+			}
+		}
+	}
+	return insert(src, fset.Position(st.Fields.Closing).Offset, "\t"+field+"\n")
+}
+
+// AppendStatement adds stmt as the last statement of the function or method named
+// funcName, unless the body already has a statement that reads the same
+func AppendStatement(src []byte, funcName, stmt string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != funcName || fn.Body == nil {
+			continue
+		}
+		for _, s := range fn.Body.List {
+			if string(src[fset.Position(s.Pos()).Offset:fset.Position(s.End()).Offset]) == stmt {
+				return src, nil
+			}
+		}
+		return insert(src, fset.Position(fn.Body.Rbrace).Offset, "\t"+stmt+"\n")
+	}
+	return nil, fmt.Errorf("could not find function %s", funcName)
+}
+
+// AddInterface declares the interface ifaceName with the exported methods of the
+// type typeName, after that type, along with a compile-time check that *typeName
+// implements it. A file that already declares ifaceName is returned unchanged.
+func AddInterface(src []byte, typeName, ifaceName, doc string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var decl *ast.GenDecl
+	for _, d := range f.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			switch ts := spec.(*ast.TypeSpec); ts.Name.Name {
+			case ifaceName:
+				return src, nil
+			case typeName:
+				decl = gen
+			}
+		}
+	}
+	if decl == nil {
+		return nil, fmt.Errorf("could not find type %s", typeName)
+	}
+	var methods []string
+	for _, d := range f.Decls {
+		fn, ok := d.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || !fn.Name.IsExported() || receiverName(fn.Recv.List[0].Type) != typeName {
+			continue
+		}
+		sig := string(src[fset.Position(fn.Type.Params.Pos()).Offset:fset.Position(fn.Type.End()).Offset])
+		methods = append(methods, "\t"+fn.Name.Name+sig+"\n")
+	}
+	text := fmt.Sprintf("\n\n// %s\ntype %s interface {\n%s}\n\nvar _ %s = (*%s)(nil)", doc, ifaceName, strings.Join(methods, ""), ifaceName, typeName)
+	at := fset.Position(decl.End()).Offset
+	return format.Source([]byte(string(src[:at]) + text + string(src[at:])))
+}
+
+// receiverName returns the type name of a method receiver such as *T or T
+func receiverName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if id, ok := expr.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// SetFieldType changes the type and tag of the field fieldName of the struct type
+// typeName, e.g. to depend on an interface instead of a concrete type
+func SetFieldType(src []byte, typeName, fieldName, fieldType, tag string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var field *ast.Field
+	ast.Inspect(f, func(n ast.Node) bool {
+		ts, ok := n.(*ast.TypeSpec)
+		if !ok || ts.Name.Name != typeName {
+			return field == nil
+		}
+		if st, ok := ts.Type.(*ast.StructType); ok {
+			for _, fld := range st.Fields.List {
+				for _, name := range fld.Names {
+					if name.Name == fieldName {
+						field = fld
+					}
+				}
+			}
+		}
+		return false
+	})
+	if field == nil {
+		return nil, fmt.Errorf("could not find field %s.%s", typeName, fieldName)
+	}
+	end := field.Type.End()
+	if field.Tag != nil {
+		end = field.Tag.End()
+	}
+	text := fieldType
+	if tag != "" {
+		text += " `" + tag + "`"
+	}
+	start, stop := fset.Position(field.Type.Pos()).Offset, fset.Position(end).Offset
+	return format.Source([]byte(string(src[:start]) + text + string(src[stop:])))
+}
+
+// EditFile applies edit to the file at path and writes the result back if it changed
+func EditFile(path string, edit func([]byte) ([]byte, error)) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	out, err := edit(src)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if string(out) == string(src) {
+		return nil
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+// insert adds text at offset, moving it after any indentation that precedes
+// the offset on its line, and gofmts the result
+func insert(src []byte, offset int, text string) ([]byte, error) {
+	lineStart := strings.LastIndex(string(src[:offset]), "\n") + 1
+	if strings.TrimSpace(string(src[lineStart:offset])) == "" {
+		offset = lineStart
+	} else {
+		text = "\n" + text
+	}
+	out := string(src[:offset]) + text + string(src[offset:])
+	return format.Source([]byte(out))
+}
+
+// AddRouteMiddleware inserts expr before the handler of every route registration
+// such as route.Delete("/:id", ctrl.Delete) whose path is path and whose method is
+// method (Get, Post, ...; empty matches any). Routes that already call the same
+// function as expr, with any arguments, are left alone. It returns the edited source and the number of routes changed.
+func AddRouteMiddleware(src []byte, method, path, expr string) ([]byte, int, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, 0, err
+	}
+	var offsets []int
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) < 2 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !RouteMethods[sel.Sel.Name] || (method != "" && sel.Sel.Name != method) {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		if p, _ := strconv.Unquote(lit.Value); p != path {
+			return true
+		}
+		callee, _, _ := strings.Cut(expr, "(")
+		for _, arg := range call.Args[1:] {
+			start, end := fset.Position(arg.Pos()).Offset, fset.Position(arg.End()).Offset
+			if text := string(src[start:end]); text == expr || strings.HasPrefix(text, callee+"(") {
+				return true
+			}
+		}
+		offsets = append(offsets, fset.Position(call.Args[len(call.Args)-1].Pos()).Offset)
+		return true
+	})
+	if len(offsets) == 0 {
+		return src, 0, nil
+	}
+	sort.Ints(offsets)
+	out := string(src)
+	for i := len(offsets) - 1; i >= 0; i-- {
+		out = out[:offsets[i]] + expr + ", " + out[offsets[i]:]
+	}
+	formatted, err := format.Source([]byte(out))
+	return formatted, len(offsets), err
+}
+
+// RouteMethods are the Fiber router methods that register a route
+var RouteMethods = map[string]bool{
+	"Get": true, "Post": true, "Put": true, "Patch": true,
+	"Delete": true, "Head": true, "Options": true, "All": true,
+}
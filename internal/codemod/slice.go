@@ -0,0 +1,132 @@
+package codemod
+
+import (
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// disabledPrefix marks a slice element that has been switched off
+const disabledPrefix = "// disabled: "
+
+// Entry is an element of a managed slice literal, such as the middleware chain.
+// Comments written by hand around an element travel with it when the slice is rewritten.
+type Entry struct {
+	Expr     string
+	Enabled  bool
+	Comments []string // Comment lines directly above the element
+	Trailing string   // Comment on the same line as the element
+}
+
+// ReadSlice returns the elements of the slice literal returned by funcName,
+// including elements disabled with a "// disabled: " comment, in source order
+func ReadSlice(src []byte, funcName string) ([]Entry, error) {
+	entries, _, err := readSlice(src, funcName)
+	return entries, err
+}
+
+// readSlice also returns the comments after the last element, which belong to no entry
+func readSlice(src []byte, funcName string) ([]Entry, []string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	lit, err := SliceLiteral(fset, f, funcName)
+	if err != nil {
+		return nil, nil, err
+	}
+	type positioned struct {
+		pos     token.Pos
+		entry   Entry
+		comment string // Set for ordinary comments
+	}
+	var items []positioned
+	for _, e := range lit.Elts {
+		expr := string(src[fset.Position(e.Pos()).Offset:fset.Position(e.End()).Offset])
+		items = append(items, positioned{pos: e.Pos(), entry: Entry{Expr: expr, Enabled: true}})
+	}
+	for _, group := range f.Comments {
+		if group.Pos() < lit.Lbrace || group.End() > lit.Rbrace {
+			continue
+		}
+		for _, c := range group.List {
+			if strings.HasPrefix(c.Text, disabledPrefix) {
+				expr := strings.TrimSuffix(strings.TrimSpace(strings.TrimPrefix(c.Text, disabledPrefix)), ",")
+				items = append(items, positioned{pos: c.Pos(), entry: Entry{Expr: expr, Enabled: false}})
+			} else {
+				items = append(items, positioned{pos: c.Pos(), comment: c.Text})
+			}
+		}
+	}
+	// Sort by position (insertion sort keeps this dependency free and lists are short)
+	for i := 1; i < len(items); i++ {
+		for j := i; j > 0 && items[j].pos < items[j-1].pos; j-- {
+			items[j], items[j-1] = items[j-1], items[j]
+		}
+	}
+	var entries []Entry
+	var pending []string
+	lastLine := 0
+	for _, it := range items {
+		line := fset.Position(it.pos).Line
+		if it.comment == "" {
+			it.entry.Comments = pending
+			pending = nil
+			entries = append(entries, it.entry)
+			lastLine = line
+			continue
+		}
+		if len(entries) > 0 && line == lastLine && entries[len(entries)-1].Trailing == "" {
+			entries[len(entries)-1].Trailing = it.comment
+			continue
+		}
+		pending = append(pending, it.comment)
+	}
+	return entries, pending, nil
+}
+
+// WriteSlice replaces the body of the slice literal returned by funcName with entries,
+// writing disabled entries as "// disabled: " comments and keeping other comments
+func WriteSlice(src []byte, funcName string, entries []Entry) ([]byte, error) {
+	_, tail, err := readSlice(src, funcName)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	lit, err := SliceLiteral(fset, f, funcName)
+	if err != nil {
+		return nil, err
+	}
+	var body strings.Builder
+	body.WriteString("{\n")
+	for _, e := range entries {
+		for _, c := range e.Comments {
+			body.WriteString("\t" + c + "\n")
+		}
+		if e.Enabled {
+			body.WriteString("\t" + e.Expr + ",")
+		} else {
+			body.WriteString("\t" + disabledPrefix + e.Expr + ",")
+		}
+		// A trailing comment would be swallowed by a disabled entry, so give it its own line
+		if e.Trailing != "" && e.Enabled {
+			body.WriteString(" " + e.Trailing)
+		} else if e.Trailing != "" {
+			body.WriteString("\n\t" + e.Trailing)
+		}
+		body.WriteString("\n")
+	}
+	for _, c := range tail {
+		body.WriteString("\t" + c + "\n")
+	}
+	body.WriteString("}")
+	start, end := fset.Position(lit.Lbrace).Offset, fset.Position(lit.Rbrace).Offset+1
+	out := string(src[:start]) + body.String() + string(src[end:])
+	return format.Source([]byte(out))
+}
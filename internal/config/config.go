@@ -0,0 +1,172 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// keys lists the settings that can be changed with `gonext config set`
+var keys = map[string]string{
+	"editor": "Command used to open files (overrides editor detection)",
+}
+
+// Registry is an organization's template registry: a git host that serves
+// starters and generator templates as repositories below URL
+type Registry struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`                 // https://git.acme.dev/platform or git@git.acme.dev:platform
+	TokenEnv string `json:"token_env,omitempty"` // environment variable holding the HTTPS access token
+	Token    string `json:"token,omitempty"`     // access token, when not read from TokenEnv
+	Username string `json:"username,omitempty"`  // HTTPS user sent with the token (default x-access-token)
+	SSHKey   string `json:"ssh_key,omitempty"`   // private key used for git over SSH instead of the agent
+}
+
+// SSH reports whether the registry is reached with git over SSH rather than HTTPS
+func (r Registry) SSH() bool {
+	return strings.HasPrefix(r.URL, "ssh://") || (!strings.Contains(r.URL, "://") && strings.Contains(r.URL, "@"))
+}
+
+// Config holds user-level CLI settings persisted between runs
+type Config struct {
+	Values     map[string]string `json:"values"`
+	Registries []Registry        `json:"registries,omitempty"`
+}
+
+// Path returns the location of the user config file
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gonext", "config.json"), nil
+}
+
+// Load reads the user config, returning an empty config if none exists yet
+func Load() (*Config, error) {
+	cfg := &Config{Values: map[string]string{}}
+	path, err := Path()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	if cfg.Values == nil {
+		cfg.Values = map[string]string{}
+	}
+	return cfg, nil
+}
+
+// TemplatesDir returns where `gonext template add` keeps fetched templates
+func TemplatesDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gonext", "templates"), nil
+}
+
+// Save writes the config back to disk, readable only by the user since it may hold registry tokens
+func (c *Config) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(path, 0600)
+}
+
+// Get returns the value of a setting, or an empty string if unset
+func (c *Config) Get(key string) string {
+	return c.Values[key]
+}
+
+// Set updates a known setting. An empty value removes it.
+func (c *Config) Set(key, value string) error {
+	if _, ok := keys[key]; !ok {
+		return fmt.Errorf("unknown config key '%s'", key)
+	}
+	if value == "" {
+		delete(c.Values, key)
+		return nil
+	}
+	c.Values[key] = value
+	return nil
+}
+
+// Registry returns the registry with the given name
+func (c *Config) Registry(name string) (Registry, bool) {
+	for _, r := range c.Registries {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Registry{}, false
+}
+
+// SetRegistry adds a registry or replaces the one with the same name
+func (c *Config) SetRegistry(r Registry) {
+	for i := range c.Registries {
+		if c.Registries[i].Name == r.Name {
+			c.Registries[i] = r
+			return
+		}
+	}
+	c.Registries = append(c.Registries, r)
+	sort.Slice(c.Registries, func(i, j int) bool { return c.Registries[i].Name < c.Registries[j].Name })
+}
+
+// RemoveRegistry deletes a registry and reports whether it existed
+func (c *Config) RemoveRegistry(name string) bool {
+	for i, r := range c.Registries {
+		if r.Name == name {
+			c.Registries = append(c.Registries[:i], c.Registries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Keys returns the supported setting names with their descriptions, sorted by name
+func Keys() [][2]string {
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	out := make([][2]string, 0, len(names))
+	for _, k := range names {
+		out = append(out, [2]string{k, keys[k]})
+	}
+	return out
+}
+
+// Value is a convenience that loads the config and returns a single setting
+func Value(key string) string {
+	cfg, err := Load()
+	if err != nil {
+		return ""
+	}
+	return cfg.Get(key)
+}
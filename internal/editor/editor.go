@@ -0,0 +1,197 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/config"
+	"github.com/Alexigbokwe/gonext/internal/utils"
+)
+
+// editor describes an editor that can be detected from the terminal environment
+type editor struct {
+	name  string
+	cmd   []string // Alternatives tried in order (e.g. goland, idea)
+	check func() bool
+}
+
+// Candidate is a resolved command that can be used to open files
+type Candidate struct {
+	Name    string   // Human readable name used in messages
+	Command []string // Executable followed by any extra arguments
+	Source  string   // Where the candidate came from: config, terminal, VISUAL or EDITOR
+}
+
+// Env abstracts environment lookups so detection can be driven from other sources
+type Env struct {
+	Getenv   func(string) string
+	LookPath func(string) (string, error)
+	Override func() string
+}
+
+// DefaultEnv reads from the process environment, the PATH and the user config
+var DefaultEnv = Env{
+	Getenv:   os.Getenv,
+	LookPath: exec.LookPath,
+	Override: func() string { return config.Value("editor") },
+}
+
+// detectors returns the known editors. Order matters: specific env vars should be checked first
+func detectors(env Env) []editor {
+	termProgram := env.Getenv("TERM_PROGRAM")
+	terminalEmulator := env.Getenv("TERMINAL_EMULATOR")
+
+	return []editor{
+		{
+			name:  "Cursor",
+			cmd:   []string{"cursor"},
+			check: func() bool { return termProgram == "cursor" },
+		},
+		{
+			name:  "VS Code",
+			cmd:   []string{"code"},
+			check: func() bool { return termProgram == "vscode" },
+		},
+		{
+			name: "JetBrains IDE (GoLand/IntelliJ)",
+			cmd:  []string{"goland", "idea"}, // Try goland first, then idea
+			check: func() bool {
+				return strings.Contains(terminalEmulator, "JetBrains") || env.Getenv("GO_NEXT_EDITOR") == "jetbrains"
+			},
+		},
+		{
+			name:  "Sublime Text",
+			cmd:   []string{"subl"},
+			check: func() bool { return termProgram == "Sublime" },
+		},
+		{
+			name:  "Zed",
+			cmd:   []string{"zed"},
+			check: func() bool { return termProgram == "Zed" },
+		},
+	}
+}
+
+// Candidates returns the editors to try, in order of precedence:
+// the `gonext config set editor` override, the detected terminal editor, then $VISUAL and $EDITOR.
+func Candidates(env Env) []Candidate {
+	var out []Candidate
+	if env.Override != nil {
+		if fields := strings.Fields(env.Override()); len(fields) > 0 {
+			out = append(out, Candidate{Name: fields[0], Command: fields, Source: "config"})
+		}
+	}
+	for _, e := range detectors(env) {
+		if !e.check() {
+			continue
+		}
+		for _, command := range e.cmd {
+			if _, err := env.LookPath(command); err == nil {
+				out = append(out, Candidate{Name: e.name, Command: []string{command}, Source: "terminal"})
+			}
+		}
+	}
+	for _, key := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(env.Getenv(key)); len(fields) > 0 {
+			out = append(out, Candidate{Name: fields[0], Command: fields, Source: key})
+		}
+	}
+	return out
+}
+
+// Open attempts to open the given paths using the configured or detected editor.
+// It returns false when no editor could be detected or launched. In SSH and container
+// sessions GUI editors (configured or detected from the terminal) are skipped, so
+// only $VISUAL and $EDITOR are tried.
+func Open(paths ...string) bool {
+	if len(paths) == 0 {
+		return false
+	}
+	remote := utils.IsRemote()
+	for _, c := range Candidates(DefaultEnv) {
+		if remote && isGUI(c) {
+			fmt.Printf("Remote session detected, skipping '%s' (%s). Files are at: %s\n", c.Command[0], describe(c), strings.Join(paths, ", "))
+			continue
+		}
+		fmt.Printf("Opening with '%s' (%s)...\n", c.Command[0], describe(c))
+		args := append(append([]string{}, c.Command[1:]...), paths...)
+		cmd := exec.Command(c.Command[0], args...)
+		// Terminal editors such as vim need the terminal attached
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err == nil {
+			return true
+		}
+		fmt.Printf("Failed to open with '%s', trying next option...\n", c.Command[0])
+	}
+	return false
+}
+
+// OpenWithSystem opens a path with the operating system's default application.
+// In SSH and container sessions there is no local GUI, so the path is printed instead.
+func OpenWithSystem(path string) bool {
+	if utils.IsRemote() {
+		fmt.Printf("Remote session detected, skipping auto-open. File is at: %s\n", path)
+		return false
+	}
+
+	// precise open command based on OS
+	var openCmd *exec.Cmd
+	switch {
+	case utils.IsWSL():
+		openCmd = wslOpenCommand(path)
+	case runtime.GOOS == "darwin":
+		openCmd = exec.Command("open", path)
+	case runtime.GOOS == "windows":
+		openCmd = exec.Command("cmd", "/c", "start", path)
+	case runtime.GOOS == "linux":
+		openCmd = exec.Command("xdg-open", path)
+	}
+	if openCmd == nil {
+		fmt.Println("Could not detect OS to auto-open file. Please open it manually.")
+		return false
+	}
+
+	fmt.Println("Opening with default application...")
+	if err := openCmd.Start(); err != nil {
+		fmt.Printf("⚠️  Could not auto-open file: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// wslOpenCommand prefers wslview and falls back to explorer.exe with a Windows path
+func wslOpenCommand(path string) *exec.Cmd {
+	if _, err := exec.LookPath("wslview"); err == nil {
+		return exec.Command("wslview", path)
+	}
+	if _, err := exec.LookPath("explorer.exe"); err != nil {
+		return nil
+	}
+	winPath := path
+	if out, err := exec.Command("wslpath", "-w", path).Output(); err == nil {
+		winPath = strings.TrimSpace(string(out))
+	}
+	return exec.Command("explorer.exe", winPath)
+}
+
+// isGUI reports whether a candidate may launch a desktop application rather than run in the terminal
+func isGUI(c Candidate) bool {
+	return c.Source == "config" || c.Source == "terminal"
+}
+
+// describe explains why a candidate was chosen
+func describe(c Candidate) string {
+	switch c.Source {
+	case "config":
+		return "configured editor"
+	case "terminal":
+		return "detected " + c.Name + " terminal"
+	default:
+		return "$" + c.Source
+	}
+}
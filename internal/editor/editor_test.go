@@ -0,0 +1,116 @@
+package editor
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testEnv builds an Env from fixed variables, executables found on the PATH and a config override
+func testEnv(vars map[string]string, onPath []string, override string) Env {
+	return Env{
+		Getenv: func(key string) string { return vars[key] },
+		LookPath: func(name string) (string, error) {
+			for _, p := range onPath {
+				if p == name {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", errors.New("not found")
+		},
+		Override: func() string { return override },
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	tests := []struct {
+		name     string
+		vars     map[string]string
+		onPath   []string
+		override string
+		want     []Candidate
+	}{
+		{
+			name: "nothing detected",
+		},
+		{
+			name:     "config override comes first",
+			vars:     map[string]string{"TERM_PROGRAM": "vscode", "EDITOR": "vim"},
+			onPath:   []string{"code"},
+			override: "code --wait",
+			want: []Candidate{
+				{Name: "code", Command: []string{"code", "--wait"}, Source: "config"},
+				{Name: "VS Code", Command: []string{"code"}, Source: "terminal"},
+				{Name: "vim", Command: []string{"vim"}, Source: "EDITOR"},
+			},
+		},
+		{
+			name:     "blank override is ignored",
+			vars:     map[string]string{"EDITOR": "nano"},
+			override: "   ",
+			want: []Candidate{
+				{Name: "nano", Command: []string{"nano"}, Source: "EDITOR"},
+			},
+		},
+		{
+			name:   "TERM_PROGRAM with editor on PATH",
+			vars:   map[string]string{"TERM_PROGRAM": "cursor"},
+			onPath: []string{"cursor"},
+			want: []Candidate{
+				{Name: "Cursor", Command: []string{"cursor"}, Source: "terminal"},
+			},
+		},
+		{
+			name: "TERM_PROGRAM with editor missing from PATH",
+			vars: map[string]string{"TERM_PROGRAM": "Zed"},
+		},
+		{
+			name:   "TERMINAL_EMULATOR tries goland then idea",
+			vars:   map[string]string{"TERMINAL_EMULATOR": "JetBrains-JediTerm"},
+			onPath: []string{"goland", "idea"},
+			want: []Candidate{
+				{Name: "JetBrains IDE (GoLand/IntelliJ)", Command: []string{"goland"}, Source: "terminal"},
+				{Name: "JetBrains IDE (GoLand/IntelliJ)", Command: []string{"idea"}, Source: "terminal"},
+			},
+		},
+		{
+			name:   "TERMINAL_EMULATOR falls back to idea",
+			vars:   map[string]string{"TERMINAL_EMULATOR": "JetBrains-JediTerm"},
+			onPath: []string{"idea"},
+			want: []Candidate{
+				{Name: "JetBrains IDE (GoLand/IntelliJ)", Command: []string{"idea"}, Source: "terminal"},
+			},
+		},
+		{
+			name:   "unrelated terminal is not detected",
+			vars:   map[string]string{"TERM_PROGRAM": "iTerm.app"},
+			onPath: []string{"code", "cursor", "subl", "zed"},
+		},
+		{
+			name: "VISUAL before EDITOR",
+			vars: map[string]string{"VISUAL": "emacs -nw", "EDITOR": "vi"},
+			want: []Candidate{
+				{Name: "emacs", Command: []string{"emacs", "-nw"}, Source: "VISUAL"},
+				{Name: "vi", Command: []string{"vi"}, Source: "EDITOR"},
+			},
+		},
+		{
+			name:   "terminal editor before VISUAL",
+			vars:   map[string]string{"TERM_PROGRAM": "Sublime", "VISUAL": "vim"},
+			onPath: []string{"subl"},
+			want: []Candidate{
+				{Name: "Sublime Text", Command: []string{"subl"}, Source: "terminal"},
+				{Name: "vim", Command: []string{"vim"}, Source: "VISUAL"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Candidates(testEnv(tt.vars, tt.onPath, tt.override))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Candidates() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
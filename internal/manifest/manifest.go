@@ -0,0 +1,93 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the project manifest, kept at the project root
+const FileName = "gonext.yaml"
+
+// Header configures the comment block prepended to every generated .go file
+type Header struct {
+	Text   string `yaml:"text,omitempty"`   // e.g. copyright and SPDX lines
+	Banner bool   `yaml:"banner,omitempty"` // adds a "scaffolded by gonext" line
+}
+
+// Module holds per-module settings
+type Module struct {
+	Owner    string   `yaml:"owner,omitempty"`  // owning team, e.g. @acme/payments
+	Prefix   string   `yaml:"prefix,omitempty"` // route prefix, e.g. /api/v1
+	Tags     []string `yaml:"tags,omitempty"`
+	Disabled bool     `yaml:"disabled,omitempty"` // excluded from the bootstrap module registry
+}
+
+// ScrubRule replaces the values of matching columns in `gonext db dump --anonymize`
+type ScrubRule struct {
+	Column string `yaml:"column"` // column or table.column, * matches anything, e.g. *email* or users.bio
+	With   string `yaml:"with"`   // email, name, phone, text, hash, null or keep
+}
+
+// Kubernetes configures the manifests generated by `gonext g k8s`
+type Kubernetes struct {
+	Host    string `yaml:"host,omitempty"`    // ingress host, e.g. api.example.com; no ingress when empty
+	Mesh    string `yaml:"mesh,omitempty"`    // istio or linkerd
+	Timeout string `yaml:"timeout,omitempty"` // per-request timeout enforced by the mesh, e.g. 15s
+	Retries int    `yaml:"retries,omitempty"` // retries of failed requests by the mesh
+	MTLS    string `yaml:"mtls,omitempty"`    // strict or permissive
+}
+
+// WatchCommand is run by `gonext watch` after the built-in artifacts are
+// regenerated, e.g. a client SDK or mock generator
+type WatchCommand struct {
+	Name string `yaml:"name"`
+	Run  string `yaml:"run"` // shell command, run from the project root
+}
+
+// Manifest holds project-level GoNext settings
+type Manifest struct {
+	Go         string            `yaml:"go,omitempty"` // required Go toolchain, e.g. 1.23.4
+	Header     Header            `yaml:"header,omitempty"`
+	Modules    map[string]Module `yaml:"modules,omitempty"`
+	Anonymize  []ScrubRule       `yaml:"anonymize,omitempty"` // checked before the built-in rules
+	Kubernetes Kubernetes        `yaml:"kubernetes,omitempty"`
+	Watch      []WatchCommand    `yaml:"watch,omitempty"`
+}
+
+// Load reads gonext.yaml from the current directory, returning an empty manifest if it doesn't exist
+func Load() (*Manifest, error) {
+	m := &Manifest{}
+	data, err := os.ReadFile(FileName)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return m, err
+	}
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return m, fmt.Errorf("invalid %s: %v", FileName, err)
+	}
+	return m, nil
+}
+
+// SetModule updates the settings of a module
+func (m *Manifest) SetModule(name string, mod Module) {
+	if m.Modules == nil {
+		m.Modules = map[string]Module{}
+	}
+	m.Modules[name] = mod
+}
+
+// Save writes the manifest to gonext.yaml in the current directory
+func (m *Manifest) Save() error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+	return os.WriteFile(FileName, buf.Bytes(), 0644)
+}
@@ -0,0 +1,253 @@
+package openapi
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Schema is the subset of an OpenAPI 3 schema object produced from Go structs
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Example     any                `json:"example,omitempty"`
+	Deprecated  bool               `json:"deprecated,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+
+	// PropertyOrder lists the properties in struct field order, for formats where order matters
+	PropertyOrder []string `json:"-"`
+}
+
+// StructSchemas parses the Go files matching pattern and returns a schema for
+// every exported struct. Struct tags drive the output:
+//
+//	json:"name"               property name (fields tagged "-" are skipped)
+//	validate:"required,min=3" required list, lengths and ranges, email/url formats
+//	example:"jane@doe.dev"    example value, typed according to the field
+//	description:"..."         property description (falls back to the field comment)
+//	deprecated:"true"         marks the property deprecated
+func StructSchemas(pattern string) (map[string]*Schema, error) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	schemas := map[string]*Schema{}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || !ts.Name.IsExported() {
+					continue
+				}
+				s := structSchema(st)
+				doc := ts.Doc
+				if doc == nil {
+					doc = gen.Doc
+				}
+				if doc != nil {
+					s.Description = strings.TrimSpace(doc.Text())
+				}
+				schemas[ts.Name.Name] = s
+			}
+		}
+	}
+	return schemas, nil
+}
+
+func structSchema(st *ast.StructType) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 {
+			continue // embedded fields are not expanded
+		}
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			if raw, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(raw)
+			}
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			propName := name.Name
+			if jsonTag := tag.Get("json"); jsonTag != "" {
+				jsonName := strings.Split(jsonTag, ",")[0]
+				if jsonName == "-" {
+					continue
+				}
+				if jsonName != "" {
+					propName = jsonName
+				}
+			}
+			prop := typeSchema(field.Type)
+			if d := tag.Get("description"); d != "" {
+				prop.Description = d
+			} else if field.Doc != nil {
+				prop.Description = strings.TrimSpace(field.Doc.Text())
+			} else if field.Comment != nil {
+				prop.Description = strings.TrimSpace(field.Comment.Text())
+			}
+			if ex, ok := tag.Lookup("example"); ok {
+				prop.Example = typedExample(prop.Type, ex)
+			}
+			if dep, _ := strconv.ParseBool(tag.Get("deprecated")); dep {
+				prop.Deprecated = true
+			}
+			if applyValidate(prop, tag.Get("validate")) {
+				s.Required = append(s.Required, propName)
+			}
+			s.Properties[propName] = prop
+			s.PropertyOrder = append(s.PropertyOrder, propName)
+		}
+	}
+	return s
+}
+
+// typeSchema maps a Go type expression to a schema
+func typeSchema(expr ast.Expr) *Schema {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return typeSchema(t.X)
+	case *ast.ArrayType:
+		if id, ok := t.Elt.(*ast.Ident); ok && id.Name == "byte" {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: typeSchema(t.Elt)}
+	case *ast.MapType:
+		return &Schema{Type: "object"}
+	case *ast.SelectorExpr:
+		switch exprName(t) {
+		case "time.Time":
+			return &Schema{Type: "string", Format: "date-time"}
+		case "time.Duration":
+			return &Schema{Type: "string", Example: "30s"}
+		case "uuid.UUID":
+			return &Schema{Type: "string", Format: "uuid"}
+		case "decimal.Decimal":
+			return &Schema{Type: "string", Format: "decimal"}
+		}
+		return &Schema{Type: "object"}
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return &Schema{Type: "string"}
+		case "bool":
+			return &Schema{Type: "boolean"}
+		case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32":
+			return &Schema{Type: "integer", Format: "int32"}
+		case "int64", "uint64":
+			return &Schema{Type: "integer", Format: "int64"}
+		case "float32":
+			return &Schema{Type: "number", Format: "float"}
+		case "float64":
+			return &Schema{Type: "number", Format: "double"}
+		case "any":
+			return &Schema{}
+		}
+		if t.IsExported() {
+			return &Schema{Ref: "#/components/schemas/" + t.Name}
+		}
+	case *ast.InterfaceType:
+		return &Schema{}
+	}
+	return &Schema{Type: "string"}
+}
+
+// applyValidate translates go-playground/validator rules and reports whether the field is required
+func applyValidate(s *Schema, rules string) bool {
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "url", "uri":
+			s.Format = "uri"
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+		case "oneof":
+			for _, v := range strings.Fields(value) {
+				s.Enum = append(s.Enum, typedExample(s.Type, v))
+			}
+		case "min", "max", "gte", "lte":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			isMin := key == "min" || key == "gte"
+			switch s.Type {
+			case "string":
+				i := int(n)
+				if isMin {
+					s.MinLength = &i
+				} else {
+					s.MaxLength = &i
+				}
+			case "integer", "number":
+				if isMin {
+					s.Minimum = &n
+				} else {
+					s.Maximum = &n
+				}
+			}
+		}
+	}
+	return required
+}
+
+// typedExample converts a tag value to the JSON type of the schema
+func typedExample(typ, value string) any {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func exprName(e ast.Expr) string {
+	switch v := e.(type) {
+	case *ast.Ident:
+		return v.Name
+	case *ast.SelectorExpr:
+		return exprName(v.X) + "." + v.Sel.Name
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+package openapi
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/workspace"
+)
+
+// Document is an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+// Info is the API metadata
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Operation describes a single route
+type Operation struct {
+	OperationID string              `json:"operationId,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is a path parameter
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// Response is an operation response
+type Response struct {
+	Description string `json:"description"`
+}
+
+// Components holds the reusable schemas
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// SchemaSources are the files whose structs become component schemas
+var SchemaSources = []string{
+	filepath.Join(workspace.AppDir, "*", "dto", "*.go"),
+	filepath.Join(workspace.AppDir, "*", "entity", "*.go"),
+}
+
+var pathParam = regexp.MustCompile(`:(\w+)\??`)
+
+// Build assembles the spec from the module routes and the DTO/entity structs
+func Build(title, version string) (*Document, error) {
+	doc := &Document{
+		OpenAPI:    "3.0.3",
+		Info:       Info{Title: title, Version: version},
+		Paths:      map[string]map[string]Operation{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+
+	routes, err := workspace.Routes()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range routes {
+		path := pathParam.ReplaceAllString(r.Path, "{$1}")
+		op := Operation{
+			Tags:      append([]string{r.Module}, r.Tags...),
+			Responses: map[string]Response{"200": {Description: "OK"}},
+		}
+		if r.Handler != "" {
+			name := r.Handler[strings.LastIndex(r.Handler, ".")+1:]
+			op.OperationID = name
+			op.Summary = name
+		}
+		if d := r.Deprecation; d != nil {
+			op.Deprecated = true
+			op.Description = deprecationNote(d)
+		}
+		for _, m := range pathParam.FindAllStringSubmatch(r.Path, -1) {
+			op.Parameters = append(op.Parameters, Parameter{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		methods := []string{strings.ToLower(r.Method)}
+		if r.Method == "ALL" {
+			methods = []string{"get", "post", "put", "patch", "delete"}
+		}
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]Operation{}
+		}
+		for _, m := range methods {
+			doc.Paths[path][m] = op
+		}
+	}
+
+	for _, pattern := range SchemaSources {
+		schemas, err := StructSchemas(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for name, s := range schemas {
+			doc.Components.Schemas[name] = s
+		}
+	}
+	return doc, nil
+}
+
+// deprecationNote describes a deprecated route for the operation description
+func deprecationNote(d *workspace.Deprecation) string {
+	note := "Deprecated."
+	if d.Message != "" {
+		note = "Deprecated: " + d.Message
+	}
+	if d.Sunset != "" {
+		note += " Removed after " + d.Sunset + "."
+	}
+	if d.Link != "" {
+		note += " See " + d.Link + "."
+	}
+	return note
+}
@@ -0,0 +1,108 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+var frames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// IsTTY reports whether stdout is an interactive terminal
+func IsTTY() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Spinner shows an animated indicator with elapsed time while a task runs.
+// When stdout is not a terminal it falls back to plain start/finish log lines.
+type Spinner struct {
+	message string
+	out     io.Writer
+	tty     bool
+	start   time.Time
+	total   int
+	current int
+	stop    chan struct{}
+	done    sync.WaitGroup
+	mu      sync.Mutex
+}
+
+// Start begins a spinner for the given task
+func Start(message string) *Spinner {
+	s := &Spinner{message: message, out: os.Stdout, tty: IsTTY(), start: time.Now(), stop: make(chan struct{})}
+	if !s.tty {
+		fmt.Fprintf(s.out, "%s...\n", message)
+		return s
+	}
+	s.done.Add(1)
+	go s.run()
+	return s
+}
+
+// StartCount begins a progress indicator for a task with a known number of steps
+func StartCount(message string, total int) *Spinner {
+	s := Start(message)
+	s.mu.Lock()
+	s.total = total
+	s.mu.Unlock()
+	return s
+}
+
+// Increment advances a counted task by one step
+func (s *Spinner) Increment() {
+	s.mu.Lock()
+	s.current++
+	s.mu.Unlock()
+}
+
+// Interactive reports whether the spinner is animating in a terminal
+func (s *Spinner) Interactive() bool {
+	return s.tty
+}
+
+func (s *Spinner) run() {
+	defer s.done.Done()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			fmt.Fprintf(s.out, "\r\033[K%s %s%s (%s)", frames[i%len(frames)], s.message, s.counter(), s.elapsed())
+		}
+	}
+}
+
+func (s *Spinner) counter() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.total == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [%d/%d]", s.current, s.total)
+}
+
+func (s *Spinner) elapsed() string {
+	return time.Since(s.start).Round(100 * time.Millisecond).String()
+}
+
+// Stop ends the spinner and prints the final status of the task
+func (s *Spinner) Stop(err error) {
+	if s.tty {
+		close(s.stop)
+		s.done.Wait()
+		fmt.Fprint(s.out, "\r\033[K")
+	}
+	if err != nil {
+		fmt.Fprintf(s.out, "✗ %s failed after %s\n", s.message, s.elapsed())
+		return
+	}
+	fmt.Fprintf(s.out, "✓ %s (%s)\n", s.message, s.elapsed())
+}
@@ -0,0 +1,619 @@
+// Package proto converts between the DTO/entity structs of a module and
+// protobuf message definitions, keeping field numbers stable across runs
+package proto
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Field is a message field
+type Field struct {
+	Name     string // snake_case, from the json tag
+	Type     string // proto type, e.g. string, google.protobuf.Timestamp or map<string, int64>
+	Repeated bool
+	Optional bool // pointer to a scalar
+	Number   int
+	Comment  string
+}
+
+// Message is a protobuf message
+type Message struct {
+	Name          string
+	Comment       string
+	Fields        []Field
+	Reserved      []int    // numbers of removed fields, never reused
+	ReservedNames []string // names of removed fields
+}
+
+// wellKnown maps the well-known types used by the converted fields to their imports
+var wellKnown = map[string]string{
+	"google.protobuf.Timestamp": "google/protobuf/timestamp.proto",
+	"google.protobuf.Duration":  "google/protobuf/duration.proto",
+	"google.protobuf.Struct":    "google/protobuf/struct.proto",
+	"google.protobuf.Value":     "google/protobuf/struct.proto",
+	"google.protobuf.ListValue": "google/protobuf/struct.proto",
+}
+
+// FromGo parses the Go files matching the patterns and returns a message for every
+// exported struct, in file order. Field numbers are left to Number.
+func FromGo(patterns ...string) ([]Message, error) {
+	var messages []Message
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ParseComments)
+			if err != nil {
+				return nil, err
+			}
+			for _, decl := range f.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok || !ts.Name.IsExported() {
+						continue
+					}
+					m := Message{Name: ts.Name.Name, Fields: structFields(st)}
+					doc := ts.Doc
+					if doc == nil {
+						doc = gen.Doc
+					}
+					if doc != nil {
+						m.Comment = strings.TrimSpace(doc.Text())
+					}
+					messages = append(messages, m)
+				}
+			}
+		}
+	}
+	// Structs from other packages or files have no message of their own
+	known := map[string]bool{}
+	for _, m := range messages {
+		known[m.Name] = true
+	}
+	for _, m := range messages {
+		for i, f := range m.Fields {
+			m.Fields[i].Type = resolve(f.Type, known)
+		}
+	}
+	return messages, nil
+}
+
+// resolve replaces references to unknown messages with google.protobuf.Struct
+func resolve(typ string, known map[string]bool) string {
+	if inner, ok := strings.CutPrefix(typ, "map<"); ok {
+		key, value, _ := strings.Cut(strings.TrimSuffix(inner, ">"), ", ")
+		if v := resolve(value, known); v != value {
+			return "google.protobuf.Struct"
+		}
+		return "map<" + key + ", " + value + ">"
+	}
+	if scalar(typ) || strings.HasPrefix(typ, "google.protobuf.") || known[typ] {
+		return typ
+	}
+	return "google.protobuf.Struct"
+}
+
+func structFields(st *ast.StructType) []Field {
+	var fields []Field
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 {
+			continue // embedded fields are not expanded
+		}
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			if raw, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(raw)
+			}
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			fieldName := SnakeCase(name.Name)
+			if jsonName := strings.Split(tag.Get("json"), ",")[0]; jsonName == "-" {
+				continue
+			} else if jsonName != "" {
+				fieldName = SnakeCase(jsonName)
+			}
+			f := goField(field.Type)
+			f.Name = fieldName
+			if field.Doc != nil {
+				f.Comment = strings.TrimSpace(field.Doc.Text())
+			} else if field.Comment != nil {
+				f.Comment = strings.TrimSpace(field.Comment.Text())
+			}
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// goField maps a Go type expression to a proto field type
+func goField(expr ast.Expr) Field {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		f := goField(t.X)
+		f.Optional = !f.Repeated && scalar(f.Type)
+		return f
+	case *ast.ArrayType:
+		if id, ok := t.Elt.(*ast.Ident); ok && id.Name == "byte" {
+			return Field{Type: "bytes"}
+		}
+		elem := goField(t.Elt)
+		if elem.Repeated || strings.HasPrefix(elem.Type, "map<") {
+			return Field{Type: "google.protobuf.ListValue"}
+		}
+		return Field{Type: elem.Type, Repeated: true}
+	case *ast.MapType:
+		key, value := goField(t.Key), goField(t.Value)
+		if value.Repeated || strings.HasPrefix(value.Type, "map<") || !validMapKey(key.Type) {
+			return Field{Type: "google.protobuf.Struct"}
+		}
+		return Field{Type: fmt.Sprintf("map<%s, %s>", key.Type, value.Type)}
+	case *ast.SelectorExpr:
+		switch exprName(t) {
+		case "time.Time":
+			return Field{Type: "google.protobuf.Timestamp"}
+		case "time.Duration":
+			return Field{Type: "google.protobuf.Duration"}
+		case "uuid.UUID", "decimal.Decimal":
+			return Field{Type: "string"}
+		}
+		return Field{Type: "google.protobuf.Struct"}
+	case *ast.InterfaceType:
+		return Field{Type: "google.protobuf.Value"}
+	case *ast.Ident:
+		if typ, ok := goScalars[t.Name]; ok {
+			return Field{Type: typ}
+		}
+		if t.Name == "any" {
+			return Field{Type: "google.protobuf.Value"}
+		}
+		if t.Obj != nil {
+			if ts, ok := t.Obj.Decl.(*ast.TypeSpec); ok {
+				if _, isStruct := ts.Type.(*ast.StructType); !isStruct {
+					return goField(ts.Type) // named basic type, e.g. type Status string
+				}
+			}
+		}
+		if t.IsExported() {
+			return Field{Type: t.Name}
+		}
+	}
+	return Field{Type: "string"}
+}
+
+// goScalars maps Go basic types to proto scalar types
+var goScalars = map[string]string{
+	"string": "string", "bool": "bool",
+	"int": "int64", "int8": "int32", "int16": "int32", "int32": "int32", "int64": "int64",
+	"uint": "uint64", "uint8": "uint32", "uint16": "uint32", "uint32": "uint32", "uint64": "uint64",
+	"float32": "float", "float64": "double",
+}
+
+func scalar(typ string) bool {
+	for _, t := range goScalars {
+		if t == typ {
+			return true
+		}
+	}
+	return typ == "bytes"
+}
+
+func validMapKey(typ string) bool {
+	return scalar(typ) && typ != "float" && typ != "double" && typ != "bytes"
+}
+
+// Sync brings the messages generated from Go in line with previous, the messages of
+// the existing .proto file. Messages keep their position and new ones are appended,
+// messages without a struct are kept as they are, and field numbers are assigned by number.
+func Sync(messages, previous []Message) []Message {
+	number(messages, previous)
+	generated := map[string]Message{}
+	for _, m := range messages {
+		generated[m.Name] = m
+	}
+	var synced []Message
+	for _, m := range previous {
+		if g, ok := generated[m.Name]; ok {
+			m = g
+			delete(generated, m.Name)
+		}
+		synced = append(synced, m)
+	}
+	for _, m := range messages {
+		if _, ok := generated[m.Name]; ok {
+			synced = append(synced, m)
+		}
+	}
+	return synced
+}
+
+// number assigns field numbers, reusing those of the fields with the same name in
+// previous. New fields get numbers above any used before, and fields that
+// disappeared are reserved so they are never reused.
+func number(messages, previous []Message) {
+	before := map[string]Message{}
+	for _, m := range previous {
+		before[m.Name] = m
+	}
+	for i := range messages {
+		m := &messages[i]
+		prev := before[m.Name]
+		numbers := map[string]int{}
+		next := 1
+		for _, f := range prev.Fields {
+			numbers[f.Name] = f.Number
+			next = max(next, f.Number+1)
+		}
+		for _, n := range prev.Reserved {
+			next = max(next, n+1)
+		}
+		m.Reserved = append([]int(nil), prev.Reserved...)
+		m.ReservedNames = append([]string(nil), prev.ReservedNames...)
+		kept := map[string]bool{}
+		for j := range m.Fields {
+			f := &m.Fields[j]
+			if n, ok := numbers[f.Name]; ok {
+				f.Number = n
+			} else {
+				f.Number = next
+				next++
+			}
+			kept[f.Name] = true
+		}
+		// A field added back under a reserved name gets a new number
+		m.ReservedNames = slices.DeleteFunc(m.ReservedNames, func(name string) bool { return kept[name] })
+		for _, f := range prev.Fields {
+			if !kept[f.Name] {
+				m.Reserved = append(m.Reserved, f.Number)
+				m.ReservedNames = append(m.ReservedNames, f.Name)
+			}
+		}
+		sort.Ints(m.Reserved)
+		sort.Strings(m.ReservedNames)
+	}
+}
+
+// Render writes the messages as a proto3 file
+func Render(header, pkg, goPackage string, messages []Message) string {
+	imports := map[string]bool{}
+	for _, m := range messages {
+		for _, f := range m.Fields {
+			for typ, file := range wellKnown {
+				if strings.Contains(f.Type, typ) {
+					imports[file] = true
+				}
+			}
+		}
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(header, "\n") {
+		b.WriteString("// " + line + "\n")
+	}
+	fmt.Fprintf(&b, "syntax = \"proto3\";\n\npackage %s;\n", pkg)
+	if len(imports) > 0 {
+		b.WriteString("\n")
+		for _, file := range sortedKeys(imports) {
+			fmt.Fprintf(&b, "import %q;\n", file)
+		}
+	}
+	fmt.Fprintf(&b, "\noption go_package = %q;\n", goPackage)
+	for _, m := range messages {
+		b.WriteString("\n")
+		writeComment(&b, "", m.Comment)
+		fmt.Fprintf(&b, "message %s {\n", m.Name)
+		if len(m.Reserved) > 0 {
+			fmt.Fprintf(&b, "  reserved %s;\n", joinInts(m.Reserved))
+		}
+		if len(m.ReservedNames) > 0 {
+			quoted := make([]string, len(m.ReservedNames))
+			for i, n := range m.ReservedNames {
+				quoted[i] = strconv.Quote(n)
+			}
+			fmt.Fprintf(&b, "  reserved %s;\n", strings.Join(quoted, ", "))
+		}
+		for _, f := range m.Fields {
+			writeComment(&b, "  ", f.Comment)
+			label := ""
+			if f.Repeated {
+				label = "repeated "
+			} else if f.Optional {
+				label = "optional "
+			}
+			fmt.Fprintf(&b, "  %s%s %s = %d;\n", label, f.Type, f.Name, f.Number)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func writeComment(b *strings.Builder, indent, comment string) {
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		b.WriteString(strings.TrimRight(indent+"// "+line, " ") + "\n")
+	}
+}
+
+var (
+	messageStart = regexp.MustCompile(`^message\s+(\w+)\s*\{`)
+	fieldLine    = regexp.MustCompile(`^(repeated\s+|optional\s+)?(map\s*<\s*\w+\s*,\s*[\w.]+\s*>|[\w.]+)\s+(\w+)\s*=\s*(\d+)`)
+	reservedLine = regexp.MustCompile(`^reserved\s+(.+);`)
+	mapSpaces    = regexp.MustCompile(`\s*([<>,])\s*`)
+)
+
+// Parse reads the top-level messages of a .proto file. Nested messages, enums and
+// services are skipped.
+func Parse(src string) ([]Message, error) {
+	var messages []Message
+	var current *Message
+	var comment []string
+	depth := 0
+	for i, raw := range strings.Split(src, "\n") {
+		line := strings.TrimSpace(raw)
+		if c, ok := strings.CutPrefix(line, "//"); ok {
+			comment = append(comment, strings.TrimPrefix(c, " "))
+			continue
+		}
+		text := strings.Join(comment, "\n")
+		comment = nil
+		switch {
+		case depth == 0:
+			if m := messageStart.FindStringSubmatch(line); m != nil {
+				messages = append(messages, Message{Name: m[1], Comment: text})
+				current = &messages[len(messages)-1]
+			}
+		case depth == 1 && current != nil:
+			if m := reservedLine.FindStringSubmatch(line); m != nil {
+				for _, part := range strings.Split(m[1], ",") {
+					part = strings.TrimSpace(part)
+					if name, err := strconv.Unquote(part); err == nil {
+						current.ReservedNames = append(current.ReservedNames, name)
+					} else if n, err := strconv.Atoi(part); err == nil {
+						current.Reserved = append(current.Reserved, n)
+					} else if lo, hi, ok := strings.Cut(part, " to "); ok {
+						from, err1 := strconv.Atoi(strings.TrimSpace(lo))
+						to, err2 := strconv.Atoi(strings.TrimSpace(hi))
+						if err1 != nil || err2 != nil || to-from > 1000 {
+							return nil, fmt.Errorf("line %d: unsupported reserved range %q", i+1, part)
+						}
+						for n := from; n <= to; n++ {
+							current.Reserved = append(current.Reserved, n)
+						}
+					}
+				}
+			} else if m := fieldLine.FindStringSubmatch(line); m != nil {
+				n, _ := strconv.Atoi(m[4])
+				label := strings.TrimSpace(m[1])
+				typ := mapSpaces.ReplaceAllString(m[2], "$1")
+				typ = strings.ReplaceAll(strings.ReplaceAll(typ, ",", ", "), "map <", "map<")
+				current.Fields = append(current.Fields, Field{Name: m[3], Type: typ, Repeated: label == "repeated", Optional: label == "optional", Number: n, Comment: text})
+			}
+		}
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if depth < 0 {
+			return nil, fmt.Errorf("line %d: unbalanced braces", i+1)
+		}
+		if depth == 0 {
+			current = nil
+		}
+	}
+	return messages, nil
+}
+
+// RPC is a method of a service
+type RPC struct {
+	Name         string
+	Request      string // message type, possibly qualified, e.g. google.protobuf.Empty
+	Response     string
+	ClientStream bool
+	ServerStream bool
+	Comment      string
+}
+
+// Service is a protobuf service
+type Service struct {
+	Name    string
+	Comment string
+	RPCs    []RPC
+}
+
+var (
+	packageLine  = regexp.MustCompile(`^package\s+([\w.]+)\s*;`)
+	serviceStart = regexp.MustCompile(`^service\s+(\w+)\s*\{`)
+	rpcLine      = regexp.MustCompile(`^rpc\s+(\w+)\s*\(\s*(stream\s+)?([\w.]+)\s*\)\s*returns\s*\(\s*(stream\s+)?([\w.]+)\s*\)`)
+)
+
+// Package returns the package declared by a .proto file, or "" if it has none
+func Package(src string) string {
+	for _, line := range strings.Split(src, "\n") {
+		if m := packageLine.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// ParseServices reads the services of a .proto file with their RPCs. A service
+// or RPC whose declaration spans several lines is not recognized.
+func ParseServices(src string) ([]Service, error) {
+	var services []Service
+	var current *Service
+	var comment []string
+	depth := 0
+	for i, raw := range strings.Split(src, "\n") {
+		line := strings.TrimSpace(raw)
+		if c, ok := strings.CutPrefix(line, "//"); ok {
+			comment = append(comment, strings.TrimPrefix(c, " "))
+			continue
+		}
+		text := strings.Join(comment, "\n")
+		comment = nil
+		switch {
+		case depth == 0:
+			if m := serviceStart.FindStringSubmatch(line); m != nil {
+				services = append(services, Service{Name: m[1], Comment: text})
+				current = &services[len(services)-1]
+			}
+		case depth == 1 && current != nil:
+			if m := rpcLine.FindStringSubmatch(line); m != nil {
+				current.RPCs = append(current.RPCs, RPC{Name: m[1], Request: m[3], Response: m[5], ClientStream: m[2] != "", ServerStream: m[4] != "", Comment: text})
+			}
+		}
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if depth < 0 {
+			return nil, fmt.Errorf("line %d: unbalanced braces", i+1)
+		}
+		if depth == 0 {
+			current = nil
+		}
+	}
+	return services, nil
+}
+
+// protoScalars maps proto types to Go types
+var protoScalars = map[string]string{
+	"string": "string", "bool": "bool", "bytes": "[]byte",
+	"int32": "int32", "sint32": "int32", "sfixed32": "int32",
+	"int64": "int64", "sint64": "int64", "sfixed64": "int64",
+	"uint32": "uint32", "fixed32": "uint32", "uint64": "uint64", "fixed64": "uint64",
+	"float": "float32", "double": "float64",
+	"google.protobuf.Timestamp": "time.Time",
+	"google.protobuf.Duration":  "time.Duration",
+	"google.protobuf.Struct":    "map[string]any",
+	"google.protobuf.Value":     "any",
+	"google.protobuf.ListValue": "[]any",
+}
+
+// goType maps a proto field to a Go type
+func goType(f Field) string {
+	typ := f.Type
+	if inner, ok := strings.CutPrefix(typ, "map<"); ok {
+		key, value, _ := strings.Cut(strings.TrimSuffix(inner, ">"), ",")
+		typ = "map[" + goType(Field{Type: strings.TrimSpace(key)}) + "]" + goType(Field{Type: strings.TrimSpace(value)})
+	} else if t, ok := protoScalars[typ]; ok {
+		typ = t
+	} else {
+		typ = typ[strings.LastIndex(typ, ".")+1:]
+	}
+	switch {
+	case f.Repeated:
+		return "[]" + typ
+	case f.Optional:
+		return "*" + typ
+	}
+	return typ
+}
+
+// GoStructs renders the messages as Go structs with json tags in the named package
+func GoStructs(pkg string, messages []Message) string {
+	var body strings.Builder
+	usesTime := false
+	for _, m := range messages {
+		body.WriteString("\n")
+		comment := m.Comment
+		if comment == "" {
+			comment = m.Name + " mirrors the " + m.Name + " protobuf message"
+		}
+		writeComment(&body, "", comment)
+		fmt.Fprintf(&body, "type %s struct {\n", m.Name)
+		for _, f := range m.Fields {
+			typ := goType(f)
+			usesTime = usesTime || strings.Contains(typ, "time.")
+			writeComment(&body, "\t", f.Comment)
+			fmt.Fprintf(&body, "\t%s %s `json:\"%s\"`\n", GoName(f.Name), typ, f.Name)
+		}
+		body.WriteString("}\n")
+	}
+	imports := ""
+	if usesTime {
+		imports = "\nimport \"time\"\n"
+	}
+	return fmt.Sprintf("package %s\n%s%s", pkg, imports, body.String())
+}
+
+// initialisms are written in capitals in Go names
+var initialisms = map[string]bool{"id": true, "url": true, "uri": true, "api": true, "http": true, "json": true, "uuid": true, "ip": true, "sql": true}
+
+// GoName turns a snake_case field name into an exported Go name, e.g. user_id to UserID
+func GoName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		if initialisms[strings.ToLower(part)] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		r := []rune(part)
+		b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+	return b.String()
+}
+
+// SnakeCase turns a Go or camelCase name into snake_case, e.g. UserID to user_id
+func SnakeCase(name string) string {
+	r := []rune(name)
+	var b strings.Builder
+	for i, c := range r {
+		if unicode.IsUpper(c) {
+			if i > 0 && (unicode.IsLower(r[i-1]) || (i+1 < len(r) && unicode.IsLower(r[i+1]) && unicode.IsUpper(r[i-1]))) {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func exprName(e ast.Expr) string {
+	switch v := e.(type) {
+	case *ast.Ident:
+		return v.Name
+	case *ast.SelectorExpr:
+		return exprName(v.X) + "." + v.Sel.Name
+	}
+	return ""
+}
+
+func joinInts(ns []int) string {
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ", ")
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,74 @@
+package scaffolding
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+)
+
+// CodeownersFile is where GitHub and GitLab look for ownership rules
+var CodeownersFile = filepath.Join(".github", "CODEOWNERS")
+
+// Markers delimiting the section of CODEOWNERS managed by the CLI
+const (
+	ownersBegin = "# BEGIN gonext modules (managed by `gonext`, do not edit by hand)"
+	ownersEnd   = "# END gonext modules"
+)
+
+// OwnersFile renders the per-module OWNERS metadata
+func OwnersFile(module, owner string) string {
+	return fmt.Sprintf("# Owners of the %s module\n%s\n", module, owner)
+}
+
+// UpdateCodeowners rewrites the managed section of CODEOWNERS from the module owners
+// in the manifest, preserving any rules outside of it
+func UpdateCodeowners(m *manifest.Manifest) error {
+	var names []string
+	for name, mod := range m.Modules {
+		if mod.Owner != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var section []string
+	section = append(section, ownersBegin)
+	for _, name := range names {
+		section = append(section, fmt.Sprintf("/app/%s/ %s", name, m.Modules[name].Owner))
+	}
+	section = append(section, ownersEnd)
+
+	existing, err := os.ReadFile(CodeownersFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	content := replaceSection(string(existing), strings.Join(section, "\n")+"\n")
+	if err := os.MkdirAll(filepath.Dir(CodeownersFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(CodeownersFile, []byte(content), 0644)
+}
+
+// replaceSection swaps the managed section in content, appending it if missing
+func replaceSection(content, section string) string {
+	start := strings.Index(content, ownersBegin)
+	end := strings.Index(content, ownersEnd)
+	if start < 0 || end < start {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		if content != "" {
+			content += "\n"
+		}
+		return content + section
+	}
+	end += len(ownersEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:start] + section + content[end:]
+}
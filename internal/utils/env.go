@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// IsWSL reports whether the CLI is running inside Windows Subsystem for Linux
+func IsWSL() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	if os.Getenv("WSL_DISTRO_NAME") != "" || os.Getenv("WSL_INTEROP") != "" {
+		return true
+	}
+	data, err := os.ReadFile("/proc/version")
+	if err != nil {
+		return false
+	}
+	version := strings.ToLower(string(data))
+	return strings.Contains(version, "microsoft") || strings.Contains(version, "wsl")
+}
+
+// InContainer reports whether the CLI is running inside a container or devcontainer
+func InContainer() bool {
+	if os.Getenv("REMOTE_CONTAINERS") != "" || os.Getenv("CODESPACES") != "" || os.Getenv("DEVCONTAINER") != "" {
+		return true
+	}
+	if _, err := os.Stat("/.dockerenv"); err == nil {
+		return true
+	}
+	if _, err := os.Stat("/run/.containerenv"); err == nil {
+		return true
+	}
+	return false
+}
+
+// IsSSH reports whether the CLI is running in an SSH session
+func IsSSH() bool {
+	return os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != ""
+}
+
+// IsRemote reports whether there is likely no local GUI to open files in
+func IsRemote() bool {
+	return InContainer() || IsSSH()
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ForEach runs fn for every item using a bounded pool of workers and returns
+// all failures joined together. A workers value <= 0 uses the number of CPUs.
+func ForEach[T any](items []T, workers int, fn func(T) error) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	jobs := make(chan T)
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				if err := fn(item); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, item := range items {
+		jobs <- item
+	}
+	close(jobs)
+	wg.Wait()
+	return errors.Join(errs...)
+}
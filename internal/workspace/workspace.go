@@ -0,0 +1,1121 @@
+package workspace
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Alexigbokwe/gonext/internal/manifest"
+)
+
+// AppDir is where generated modules live
+const AppDir = "app"
+
+// Module is a feature module under app/
+type Module struct {
+	Name      string   `json:"name"`
+	Path      string   `json:"path"`
+	Prefix    string   `json:"prefix,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	DependsOn []string `json:"depends_on,omitempty"` // modules initialized first, from the DependsOn method
+}
+
+// App is a runnable entrypoint (main package)
+type App struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// Route is an HTTP route registered by a module
+type Route struct {
+	Method      string       `json:"method"`
+	Path        string       `json:"path"`
+	Module      string       `json:"module"`
+	Handler     string       `json:"handler,omitempty"`
+	Middleware  []string     `json:"middleware,omitempty"` // handlers registered before Handler
+	Tags        []string     `json:"tags,omitempty"`
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+	File        string       `json:"file"`
+	Line        int          `json:"line"`
+}
+
+// Deprecation is read from a comment above a route registration:
+//
+//	// Deprecated: use GET /api/v2/users instead.
+//	// Since: 2025-01-31
+//	// Sunset: 2025-12-31
+//	// Link: https://example.com/docs/migrate-to-v2
+//	router.Get("/users", ctrl.List)
+type Deprecation struct {
+	Message string `json:"message,omitempty"`
+	Since   string `json:"since,omitempty"`  // date the route was deprecated, YYYY-MM-DD
+	Sunset  string `json:"sunset,omitempty"` // date the route will be removed, YYYY-MM-DD
+	Link    string `json:"link,omitempty"`   // migration guide
+}
+
+// Job is a background job, worker or scheduled task
+type Job struct {
+	Name   string `json:"name"`
+	Module string `json:"module"`
+	File   string `json:"file"`
+}
+
+// Component is a service or repository type declared in a module
+type Component struct {
+	Type   string `json:"type"`   // e.g. UserService
+	Kind   string `json:"kind"`   // service or repository
+	Module string `json:"module"` // module directory name
+	Dir    string `json:"dir"`    // package directory, e.g. app/users/service
+}
+
+// Graph is the dependency graph of the project: modules importing each other and
+// components injected into one another through `inject` struct tags
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a module (ID users) or a component type (ID users/service.UserService)
+type GraphNode struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"` // module, or the package directory of a component, e.g. service
+	Module string `json:"module"`
+	File   string `json:"file,omitempty"`
+}
+
+// GraphEdge points from a dependent to its dependency
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"` // imports, injects, or depends (declared by DependsOn)
+}
+
+// Event is a topic of the event catalog: its payload struct and the calls that
+// publish or consume it. The topic of a payload is read from its doc comment:
+//
+//	// UserCreated is published after a user signs up.
+//	// Topic: users.created
+//	type UserCreated struct { ... }
+//
+// and defaults to the struct name.
+type Event struct {
+	Topic       string     `json:"topic"`
+	Payload     string     `json:"payload,omitempty"` // struct in app/<module>/event(s)
+	Module      string     `json:"module,omitempty"`  // module declaring the payload
+	Description string     `json:"description,omitempty"`
+	File        string     `json:"file,omitempty"`
+	Producers   []EventUse `json:"producers,omitempty"`
+	Consumers   []EventUse `json:"consumers,omitempty"`
+}
+
+// EventUse is a Publish/Produce/Emit or Subscribe/Consume call naming a topic
+type EventUse struct {
+	Module   string `json:"module"`
+	Function string `json:"function,omitempty"` // enclosing function, e.g. UserService.Create
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// EventDirs hold the event payload structs of a module
+var EventDirs = []string{"event", "events"}
+
+// producerMethods and consumerMethods are the broker calls the event catalog looks for
+var producerMethods = map[string]bool{"Publish": true, "Produce": true, "Emit": true}
+var consumerMethods = map[string]bool{"Subscribe": true, "Consume": true}
+
+// httpMethods maps Fiber router methods to HTTP verbs
+var httpMethods = map[string]string{
+	"Get": "GET", "Post": "POST", "Put": "PUT", "Patch": "PATCH",
+	"Delete": "DELETE", "Head": "HEAD", "Options": "OPTIONS", "All": "ALL",
+}
+
+// Modules returns every directory under app/ containing a module.go
+func Modules() ([]Module, error) {
+	entries, err := os.ReadDir(AppDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m, err := manifest.Load()
+	if err != nil {
+		return nil, err
+	}
+	var modules []Module
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		moduleGo := filepath.Join(AppDir, e.Name(), "module.go")
+		if _, err := os.Stat(moduleGo); err != nil {
+			continue
+		}
+		modules = append(modules, newModule(e.Name(), nil, m))
+	}
+	return modules, nil
+}
+
+// newModule describes the module with the given name, reading its prefix from
+// src, or from its module.go when src is nil
+func newModule(name string, src any, m *manifest.Manifest) Module {
+	settings := m.Modules[name]
+	var prefix string
+	var dependsOn []string
+	if f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(AppDir, name, "module.go"), src, 0); err == nil {
+		prefix, dependsOn = groupPrefix(f), moduleDependsOn(f)
+	}
+	if prefix == "" && settings.Prefix != "" {
+		// Mounted through a variable: fall back to the prefix recorded in the manifest
+		prefix = path.Join("/", settings.Prefix, name+"s")
+	}
+	return Module{Name: name, Path: filepath.Join(AppDir, name), Prefix: prefix, Tags: settings.Tags, DependsOn: dependsOn}
+}
+
+// moduleDependsOn returns the module names listed by the DependsOn method of module.go
+func moduleDependsOn(f *ast.File) []string {
+	var deps []string
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Name.Name != "DependsOn" || fn.Body == nil {
+			continue
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.CompositeLit); ok {
+				for _, elt := range lit.Elts {
+					if name := stringLit(elt); name != "" {
+						deps = append(deps, name)
+					}
+				}
+				return false
+			}
+			return true
+		})
+	}
+	return deps
+}
+
+// ModuleCycleError reports modules whose dependencies form a cycle
+type ModuleCycleError struct {
+	Cycle []string // e.g. [a b a]
+}
+
+func (e *ModuleCycleError) Error() string {
+	return "module dependency cycle: " + strings.Join(e.Cycle, " -> ")
+}
+
+// InitOrder sorts modules so each one comes after the modules it depends on,
+// keeping the given order where dependencies allow. Dependencies on modules that
+// are not in the list are reported, as are cycles (a *ModuleCycleError).
+func InitOrder(modules []Module) ([]Module, error) {
+	byName := map[string]Module{}
+	for _, m := range modules {
+		byName[m.Name] = m
+	}
+	for _, m := range modules {
+		for _, dep := range m.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				return nil, fmt.Errorf("module %s depends on %s, which does not exist", m.Name, dep)
+			}
+		}
+	}
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var order []Module
+	var stack []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			i := 0
+			for stack[i] != name {
+				i++
+			}
+			return &ModuleCycleError{Cycle: append(append([]string{}, stack[i:]...), name)}
+		}
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, dep := range byName[name].DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = done
+		order = append(order, byName[name])
+		return nil
+	}
+	for _, m := range modules {
+		if err := visit(m.Name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// groupPrefix returns the first router.Group prefix mounted in module.go
+func groupPrefix(f *ast.File) string {
+	prefix := ""
+	ast.Inspect(f, func(n ast.Node) bool {
+		if prefix != "" {
+			return false
+		}
+		if name, args := selectorCall(n); name == "Group" && len(args) > 0 {
+			prefix = stringLit(args[0])
+		}
+		return true
+	})
+	return prefix
+}
+
+// Apps returns the main packages of the project: the root main.go and cmd/*/main.go
+func Apps() ([]App, error) {
+	var apps []App
+	if _, err := os.Stat("main.go"); err == nil {
+		apps = append(apps, App{Name: "api", Path: "main.go"})
+	}
+	matches, err := filepath.Glob(filepath.Join("cmd", "*", "main.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	for _, m := range matches {
+		apps = append(apps, App{Name: filepath.Base(filepath.Dir(m)), Path: m})
+	}
+	return apps, nil
+}
+
+// Routes parses the route files of every module and returns the registered routes
+func Routes() ([]Route, error) {
+	modules, err := Modules()
+	if err != nil {
+		return nil, err
+	}
+	var routes []Route
+	for _, m := range modules {
+		files, _ := filepath.Glob(filepath.Join(m.Path, "route", "*.go"))
+		for _, file := range files {
+			found, err := fileRoutes(m, file, nil)
+			if err != nil {
+				return nil, err
+			}
+			routes = append(routes, found...)
+		}
+	}
+	return routes, nil
+}
+
+// PlannedRoutes returns the routes registered by route files about to be written.
+// files maps paths such as app/<module>/route/<name>Route.go to their content; a
+// module.go among them gives the prefix of a module that doesn't exist yet.
+func PlannedRoutes(files map[string][]byte) ([]Route, error) {
+	m, err := manifest.Load()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for file := range files {
+		paths = append(paths, file)
+	}
+	sort.Strings(paths)
+	var routes []Route
+	for _, file := range paths {
+		parts := strings.Split(filepath.ToSlash(file), "/")
+		if len(parts) != 4 || parts[0] != AppDir || parts[2] != "route" || !strings.HasSuffix(file, ".go") {
+			continue
+		}
+		var moduleSrc any
+		if src, ok := files[filepath.Join(AppDir, parts[1], "module.go")]; ok {
+			moduleSrc = src
+		}
+		found, err := fileRoutes(newModule(parts[1], moduleSrc, m), file, files[file])
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, found...)
+	}
+	return routes, nil
+}
+
+// fileRoutes parses the routes registered in a route file of the module, reading
+// src, or the file when src is nil
+func fileRoutes(m Module, file string, src any) ([]Route, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	// Comments by the line they end on, to find the annotations of each route
+	comments := map[int]*ast.CommentGroup{}
+	for _, c := range f.Comments {
+		comments[fset.Position(c.End()).Line] = c
+	}
+	var routes []Route
+	ast.Inspect(f, func(n ast.Node) bool {
+		name, args := selectorCall(n)
+		method, ok := httpMethods[name]
+		if !ok || len(args) == 0 {
+			return true
+		}
+		path := stringLit(args[0])
+		if path == "" && !isStringLit(args[0]) {
+			return true
+		}
+		r := Route{Method: method, Path: joinPath(m.Prefix, path), Module: m.Name, Tags: m.Tags, File: file, Line: fset.Position(n.Pos()).Line}
+		r.Deprecation = deprecation(comments[r.Line-1])
+		if trailing := comments[fset.Position(n.End()).Line]; r.Deprecation == nil && trailing != nil && trailing.Pos() > n.End() {
+			r.Deprecation = deprecation(trailing)
+		}
+		if len(args) > 1 {
+			r.Handler = exprString(args[len(args)-1])
+			for _, a := range args[1 : len(args)-1] {
+				r.Middleware = append(r.Middleware, exprString(a))
+			}
+		}
+		routes = append(routes, r)
+		return true
+	})
+	return routes, nil
+}
+
+// RouteConflict is a planned route that clashes with a registered one
+type RouteConflict struct {
+	Route    Route  // the planned route
+	Existing Route  // the registered route it clashes with
+	Kind     string // duplicate, shadowed (Existing catches its requests) or shadows (it catches Existing's)
+}
+
+// RouteConflicts compares planned routes with the registered ones. Routes of the
+// files being rewritten are replaced by the planned routes, so they don't count.
+func RouteConflicts(planned, registered []Route) []RouteConflict {
+	rewritten := map[string]bool{}
+	for _, r := range planned {
+		rewritten[filepath.Clean(r.File)] = true
+	}
+	var conflicts []RouteConflict
+	for _, p := range planned {
+		for _, e := range registered {
+			if rewritten[filepath.Clean(e.File)] || (p.Method != e.Method && p.Method != "ALL" && e.Method != "ALL") {
+				continue
+			}
+			pSegs, eSegs := pathSegments(p.Path), pathSegments(e.Path)
+			switch {
+			case strings.Join(pSegs, "/") == strings.Join(eSegs, "/"):
+				conflicts = append(conflicts, RouteConflict{Route: p, Existing: e, Kind: "duplicate"})
+			case covers(eSegs, pSegs):
+				conflicts = append(conflicts, RouteConflict{Route: p, Existing: e, Kind: "shadowed"})
+			case covers(pSegs, eSegs):
+				conflicts = append(conflicts, RouteConflict{Route: p, Existing: e, Kind: "shadows"})
+			}
+		}
+	}
+	return conflicts
+}
+
+// pathSegments normalizes a Fiber path for comparison: matching is case-insensitive,
+// ignores trailing slashes, and parameter names don't matter
+func pathSegments(p string) []string {
+	var segs []string
+	for _, s := range strings.Split(strings.Trim(strings.ToLower(p), "/"), "/") {
+		switch {
+		case s == "":
+			continue
+		case strings.HasPrefix(s, ":") && strings.HasSuffix(s, "?"):
+			s = ":?"
+		case strings.HasPrefix(s, ":"):
+			s = ":"
+		case strings.HasPrefix(s, "*") || strings.HasPrefix(s, "+"):
+			s = s[:1]
+		}
+		segs = append(segs, s)
+	}
+	return segs
+}
+
+// covers reports whether every request matching the segments b also matches a
+func covers(a, b []string) bool {
+	for i, s := range a {
+		switch s {
+		case "*":
+			return true
+		case "+":
+			return i < len(b)
+		case ":?":
+			if i == len(a)-1 && i >= len(b) {
+				return true
+			}
+		}
+		if i >= len(b) {
+			return false
+		}
+		if s != b[i] && s != ":" && s != ":?" {
+			return false
+		}
+		if (s == ":" || s == ":?") && (b[i] == "*" || b[i] == "+" || b[i] == ":?") {
+			// b also matches requests of another length
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+// Jobs returns the jobs, workers and scheduled tasks found in modules
+func Jobs() ([]Job, error) {
+	modules, err := Modules()
+	if err != nil {
+		return nil, err
+	}
+	var jobs []Job
+	for _, m := range modules {
+		for _, dir := range []string{"job", "jobs", "worker", "schedule"} {
+			files, _ := filepath.Glob(filepath.Join(m.Path, dir, "*.go"))
+			for _, file := range files {
+				if strings.HasSuffix(file, "_test.go") {
+					continue
+				}
+				jobs = append(jobs, Job{Name: strings.TrimSuffix(filepath.Base(file), ".go"), Module: m.Name, File: file})
+			}
+		}
+	}
+	return jobs, nil
+}
+
+// Components returns the exported *Service and *Repository struct types declared in modules
+func Components() ([]Component, error) {
+	modules, err := Modules()
+	if err != nil {
+		return nil, err
+	}
+	var components []Component
+	for _, m := range modules {
+		for _, kind := range []string{"service", "repository"} {
+			dir := filepath.Join(m.Path, kind)
+			files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+			for _, file := range files {
+				if strings.HasSuffix(file, "_test.go") {
+					continue
+				}
+				f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+				if err != nil {
+					continue
+				}
+				for _, decl := range f.Decls {
+					gen, ok := decl.(*ast.GenDecl)
+					if !ok || gen.Tok != token.TYPE {
+						continue
+					}
+					for _, spec := range gen.Specs {
+						ts := spec.(*ast.TypeSpec)
+						if _, ok := ts.Type.(*ast.StructType); !ok || !ts.Name.IsExported() {
+							continue
+						}
+						if !strings.HasSuffix(strings.ToLower(ts.Name.Name), kind) {
+							continue
+						}
+						components = append(components, Component{Type: ts.Name.Name, Kind: kind, Module: m.Name, Dir: dir})
+					}
+				}
+			}
+		}
+	}
+	return components, nil
+}
+
+// TypeCollision is a component type about to be written whose name is already
+// declared by a component of another module
+type TypeCollision struct {
+	Type     string   `json:"type"`   // e.g. UserService
+	Kind     string   `json:"kind"`   // controller, service or repository
+	Module   string   `json:"module"` // module of the new type
+	File     string   `json:"file"`
+	Existing []string `json:"existing"` // package directories already declaring the name
+}
+
+// injectedKinds are the component directories whose types the container injects by type
+var injectedKinds = map[string]bool{"controller": true, "service": true, "repository": true}
+
+// TypeCollisions compares the component types declared in files about to be written,
+// keyed by path, with those of the other modules. Injection by type tells
+// same-named components apart by name only, so each shared name is reported.
+func TypeCollisions(files map[string][]byte) ([]TypeCollision, error) {
+	declared := map[string][]string{} // type name to package directories
+	existing, _ := filepath.Glob(filepath.Join(AppDir, "*", "*", "*.go"))
+	for _, file := range existing {
+		parts := strings.Split(filepath.ToSlash(file), "/")
+		if _, planned := files[file]; planned || !injectedKinds[parts[2]] || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		for _, name := range structTypes(file, nil) {
+			declared[name] = append(declared[name], filepath.Dir(file))
+		}
+	}
+	var paths []string
+	for file := range files {
+		paths = append(paths, file)
+	}
+	sort.Strings(paths)
+	var collisions []TypeCollision
+	for _, file := range paths {
+		parts := strings.Split(filepath.ToSlash(file), "/")
+		if len(parts) != 4 || parts[0] != AppDir || !injectedKinds[parts[2]] || !strings.HasSuffix(file, ".go") {
+			continue
+		}
+		for _, name := range structTypes(file, files[file]) {
+			var others []string
+			for _, dir := range declared[name] {
+				if dir != filepath.Dir(file) {
+					others = append(others, dir)
+				}
+			}
+			if len(others) > 0 {
+				collisions = append(collisions, TypeCollision{Type: name, Kind: parts[2], Module: parts[1], File: file, Existing: others})
+			}
+		}
+	}
+	return collisions, nil
+}
+
+// structTypes returns the exported struct types declared in a file, reading src,
+// or the file when src is nil
+func structTypes(file string, src any) []string {
+	f, err := parser.ParseFile(token.NewFileSet(), file, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if _, ok := ts.Type.(*ast.StructType); ok && ts.Name.IsExported() {
+				names = append(names, ts.Name.Name)
+			}
+		}
+	}
+	return names
+}
+
+// Events returns the event payloads declared in modules and the producers and
+// consumers of each topic. A call's topic is its first string literal argument,
+// or the topic of the event payload it is passed, e.g. bus.Publish(ctx, event.UserCreated{...}).
+func Events() ([]Event, error) {
+	modules, err := Modules()
+	if err != nil {
+		return nil, err
+	}
+	events := map[string]*Event{}
+	payloads := map[string]string{} // payload struct name to topic
+	for _, m := range modules {
+		for _, dir := range EventDirs {
+			files, _ := filepath.Glob(filepath.Join(m.Path, dir, "*.go"))
+			for _, file := range files {
+				if strings.HasSuffix(file, "_test.go") {
+					continue
+				}
+				f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ParseComments)
+				if err != nil {
+					return nil, err
+				}
+				for _, decl := range f.Decls {
+					gen, ok := decl.(*ast.GenDecl)
+					if !ok || gen.Tok != token.TYPE {
+						continue
+					}
+					for _, spec := range gen.Specs {
+						ts := spec.(*ast.TypeSpec)
+						if _, ok := ts.Type.(*ast.StructType); !ok || !ts.Name.IsExported() {
+							continue
+						}
+						doc := ts.Doc
+						if doc == nil && len(gen.Specs) == 1 {
+							doc = gen.Doc
+						}
+						topic, description := eventDoc(doc)
+						if topic == "" {
+							topic = ts.Name.Name
+						}
+						if _, taken := payloads[ts.Name.Name]; taken {
+							continue
+						}
+						payloads[ts.Name.Name] = topic
+						events[topic] = &Event{Topic: topic, Payload: ts.Name.Name, Module: m.Name, Description: description, File: file}
+					}
+				}
+			}
+		}
+	}
+	for _, m := range modules {
+		err := filepath.WalkDir(m.Path, func(file string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+				return err
+			}
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+			if err != nil {
+				return err
+			}
+			for _, decl := range f.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Body == nil {
+					continue
+				}
+				function := fn.Name.Name
+				if fn.Recv != nil && len(fn.Recv.List) > 0 {
+					function = exprString(receiverType(fn.Recv.List[0].Type)) + "." + function
+				}
+				ast.Inspect(fn.Body, func(n ast.Node) bool {
+					name, args := selectorCall(n)
+					if !producerMethods[name] && !consumerMethods[name] {
+						return true
+					}
+					topic := callTopic(args, payloads)
+					if topic == "" {
+						return true
+					}
+					e := events[topic]
+					if e == nil {
+						e = &Event{Topic: topic}
+						events[topic] = e
+					}
+					use := EventUse{Module: m.Name, Function: function, File: file, Line: fset.Position(n.Pos()).Line}
+					if producerMethods[name] {
+						e.Producers = append(e.Producers, use)
+					} else {
+						e.Consumers = append(e.Consumers, use)
+					}
+					return true
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	topics := make([]string, 0, len(events))
+	for t := range events {
+		topics = append(topics, t)
+	}
+	sort.Strings(topics)
+	list := make([]Event, 0, len(topics))
+	for _, t := range topics {
+		list = append(list, *events[t])
+	}
+	return list, nil
+}
+
+// Dependencies returns the graph of the project's modules and injected components
+func Dependencies() (*Graph, error) {
+	modules, err := Modules()
+	if err != nil {
+		return nil, err
+	}
+	g := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	isModule := map[string]bool{}
+	for _, m := range modules {
+		isModule[m.Name] = true
+		g.Nodes = append(g.Nodes, GraphNode{ID: m.Name, Kind: "module", Module: m.Name, File: filepath.Join(m.Path, "module.go")})
+	}
+	seen := map[GraphEdge]bool{}
+	addEdge := func(e GraphEdge) {
+		if !seen[e] {
+			seen[e] = true
+			g.Edges = append(g.Edges, e)
+		}
+	}
+	for _, m := range modules {
+		for _, dep := range m.DependsOn {
+			addEdge(GraphEdge{From: m.Name, To: dep, Kind: "depends"})
+		}
+	}
+	for _, m := range modules {
+		err := filepath.WalkDir(m.Path, func(file string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+				return err
+			}
+			f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+			if err != nil {
+				return nil
+			}
+			// Imports of other modules, and the app-relative package of each import name
+			packages := map[string]string{}
+			for _, imp := range f.Imports {
+				p, _ := strconv.Unquote(imp.Path.Value)
+				rel, ok := appPackage(p)
+				if !ok {
+					continue
+				}
+				name := path.Base(p)
+				if imp.Name != nil {
+					name = imp.Name.Name
+				}
+				packages[name] = rel
+				if dep := strings.Split(rel, "/")[0]; isModule[dep] && dep != m.Name {
+					addEdge(GraphEdge{From: m.Name, To: dep, Kind: "imports"})
+				}
+			}
+			dir, _ := filepath.Rel(AppDir, filepath.Dir(file))
+			dir = filepath.ToSlash(dir)
+			for _, decl := range f.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					id := dir + "." + ts.Name.Name
+					injected := false
+					for _, field := range st.Fields.List {
+						if field.Tag == nil || !strings.Contains(field.Tag.Value, "inject:") {
+							continue
+						}
+						injected = true
+						typ := field.Type
+						if star, ok := typ.(*ast.StarExpr); ok {
+							typ = star.X
+						}
+						to := exprString(typ)
+						if sel, ok := typ.(*ast.SelectorExpr); ok {
+							if pkg, ok := packages[exprString(sel.X)]; ok {
+								to = pkg + "." + sel.Sel.Name
+							}
+						} else {
+							to = dir + "." + to
+						}
+						addEdge(GraphEdge{From: id, To: to, Kind: "injects"})
+					}
+					if injected || strings.HasSuffix(ts.Name.Name, "Service") || strings.HasSuffix(ts.Name.Name, "Repository") || strings.HasSuffix(ts.Name.Name, "Controller") {
+						g.Nodes = append(g.Nodes, GraphNode{ID: id, Kind: path.Base(dir), Module: m.Name, File: file})
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	return g, nil
+}
+
+// Injection is a struct field filled by the DI container, from its inject tag
+type Injection struct {
+	Owner string `json:"owner"` // e.g. users/service.UsersService
+	Field string `json:"field"`
+	Type  string `json:"type"`           // e.g. users/repository.UsersRepository for types under app/, else as written
+	Name  string `json:"name,omitempty"` // provider name; empty when injected by type
+	Tag   string `json:"tag"`            // the inject tag as written
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+}
+
+// Provider is a value bound in the container under a name, by a Bind("name", ...)
+// call or a {Provider: "name", ...} entry such as the database NamedConnections
+type Provider struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// InjectName returns the provider name of an inject tag: inject:"name=primaryDB"
+// and the token form inject:"queue" inject by name, inject:"type" by type
+func InjectName(tag string) string {
+	if tag == "type" {
+		return ""
+	}
+	if name, ok := strings.CutPrefix(tag, "name="); ok {
+		return name
+	}
+	return tag
+}
+
+// Injections returns the injected fields of the structs declared under app/
+func Injections() ([]Injection, error) {
+	var injections []Injection
+	err := walkAppStructs(func(fset *token.FileSet, file, dir string, packages map[string]string, ts *ast.TypeSpec, st *ast.StructType) {
+		for _, field := range st.Fields.List {
+			if field.Tag == nil {
+				continue
+			}
+			tag, _ := strconv.Unquote(field.Tag.Value)
+			value, ok := reflect.StructTag(tag).Lookup("inject")
+			if !ok {
+				continue
+			}
+			typ := field.Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			to := exprString(typ)
+			if sel, ok := typ.(*ast.SelectorExpr); ok {
+				if pkg, ok := packages[exprString(sel.X)]; ok {
+					to = pkg + "." + sel.Sel.Name
+				}
+			} else if ast.IsExported(to) {
+				to = dir + "." + to
+			}
+			for _, name := range field.Names {
+				injections = append(injections, Injection{Owner: dir + "." + ts.Name.Name, Field: name.Name, Type: to, Name: InjectName(value), Tag: value, File: file, Line: fset.Position(field.Pos()).Line})
+			}
+		}
+	})
+	return injections, err
+}
+
+// DeclaredTypes returns the struct types declared under app/, keyed like
+// Injection.Type, with the file declaring each
+func DeclaredTypes() (map[string]string, error) {
+	types := map[string]string{}
+	err := walkAppStructs(func(fset *token.FileSet, file, dir string, packages map[string]string, ts *ast.TypeSpec, st *ast.StructType) {
+		types[dir+"."+ts.Name.Name] = file
+	})
+	return types, err
+}
+
+// walkAppStructs calls visit for every struct type declared in the packages under
+// app/, with the file's app-relative package directory and its imports of other
+// app/ packages by name
+func walkAppStructs(visit func(fset *token.FileSet, file, dir string, packages map[string]string, ts *ast.TypeSpec, st *ast.StructType)) error {
+	err := filepath.WalkDir(AppDir, func(file string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+			return err
+		}
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil
+		}
+		packages := map[string]string{}
+		for _, imp := range f.Imports {
+			p, _ := strconv.Unquote(imp.Path.Value)
+			if rel, ok := appPackage(p); ok {
+				name := path.Base(p)
+				if imp.Name != nil {
+					name = imp.Name.Name
+				}
+				packages[name] = rel
+			}
+		}
+		dir, _ := filepath.Rel(AppDir, filepath.Dir(file))
+		dir = filepath.ToSlash(dir)
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					visit(fset, file, dir, packages, ts, st)
+				}
+			}
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Providers returns the named providers bound anywhere in the project
+func Providers() ([]Provider, error) {
+	var providers []Provider
+	err := filepath.WalkDir(".", func(file string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", ".gonext", "vendor", "node_modules":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+			return nil
+		}
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			name := ""
+			if call, args := selectorCall(n); call == "Bind" && len(args) == 2 {
+				name = stringLit(args[0])
+			} else if kv, ok := n.(*ast.KeyValueExpr); ok && exprString(kv.Key) == "Provider" {
+				name = stringLit(kv.Value)
+			}
+			if name != "" {
+				providers = append(providers, Provider{Name: name, File: file, Line: fset.Position(n.Pos()).Line})
+			}
+			return true
+		})
+		return nil
+	})
+	return providers, err
+}
+
+// appPackage returns the part of an import path after its app/ directory, e.g.
+// users/service for example.com/shop/app/users/service
+func appPackage(importPath string) (string, bool) {
+	i := strings.Index(importPath, "/"+AppDir+"/")
+	if i < 0 {
+		return "", false
+	}
+	return importPath[i+len(AppDir)+2:], true
+}
+
+// eventDoc splits a payload's doc comment into its Topic: line and the description
+func eventDoc(c *ast.CommentGroup) (string, string) {
+	if c == nil {
+		return "", ""
+	}
+	topic := ""
+	var description []string
+	for _, line := range strings.Split(strings.TrimSpace(c.Text()), "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "Topic:"); ok {
+			topic = strings.TrimSpace(value)
+			continue
+		}
+		description = append(description, line)
+	}
+	return topic, strings.TrimSpace(strings.Join(description, "\n"))
+}
+
+// callTopic returns the topic named by the arguments of a broker call
+func callTopic(args []ast.Expr, payloads map[string]string) string {
+	for _, arg := range args {
+		if topic := stringLit(arg); topic != "" {
+			return topic
+		}
+		if u, ok := arg.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			arg = u.X
+		}
+		if lit, ok := arg.(*ast.CompositeLit); ok {
+			name := exprString(lit.Type)
+			if topic, ok := payloads[name[strings.LastIndex(name, ".")+1:]]; ok {
+				return topic
+			}
+		}
+	}
+	return ""
+}
+
+// receiverType strips the pointer and type parameters of a method receiver
+func receiverType(e ast.Expr) ast.Expr {
+	if star, ok := e.(*ast.StarExpr); ok {
+		e = star.X
+	}
+	switch v := e.(type) {
+	case *ast.IndexExpr:
+		return v.X
+	case *ast.IndexListExpr:
+		return v.X
+	}
+	return e
+}
+
+// deprecation parses the Deprecated:, Since:, Sunset: and Link: lines of a route's comment
+func deprecation(c *ast.CommentGroup) *Deprecation {
+	if c == nil {
+		return nil
+	}
+	var d *Deprecation
+	fields := map[string]*string{}
+	for _, line := range strings.Split(c.Text(), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if key == "Deprecated" {
+			d = &Deprecation{Message: value}
+			fields = map[string]*string{"Since": &d.Since, "Sunset": &d.Sunset, "Link": &d.Link}
+			continue
+		}
+		if field, ok := fields[key]; ok {
+			*field = value
+		}
+	}
+	return d
+}
+
+// selectorCall returns the method name and arguments of calls like x.Name(args)
+func selectorCall(n ast.Node) (string, []ast.Expr) {
+	call, ok := n.(*ast.CallExpr)
+	if !ok {
+		return "", nil
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", nil
+	}
+	return sel.Sel.Name, call.Args
+}
+
+func isStringLit(e ast.Expr) bool {
+	lit, ok := e.(*ast.BasicLit)
+	return ok && lit.Kind == token.STRING
+}
+
+func stringLit(e ast.Expr) string {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// exprString renders simple handler expressions such as ctrl.GetUser
+func exprString(e ast.Expr) string {
+	switch v := e.(type) {
+	case *ast.Ident:
+		return v.Name
+	case *ast.SelectorExpr:
+		return exprString(v.X) + "." + v.Sel.Name
+	case *ast.CallExpr:
+		return exprString(v.Fun) + "()"
+	}
+	return ""
+}
+
+func joinPath(prefix, path string) string {
+	joined := strings.TrimRight(prefix, "/") + "/" + strings.TrimLeft(path, "/")
+	if len(joined) > 1 {
+		joined = strings.TrimRight(joined, "/")
+	}
+	return joined
+}